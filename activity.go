@@ -0,0 +1,146 @@
+package clog
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/********************************************************************************
+* A C T I V I T Y
+*********************************************************************************/
+
+// activityWindow is how far back CountSince counts the entries, in activityBuckets of one second.
+const (
+	activityWindow  = 15 * time.Minute
+	activityBuckets = int(activityWindow / time.Second)
+)
+
+// levelActivity counts the entries logged at a level, by second, over the last activityWindow.
+type levelActivity struct {
+	last time.Time
+	// counts are the counts by second, indexed by the Unix second modulo activityBuckets, and seconds the second
+	// that each count is for.
+	counts  [activityBuckets]int
+	seconds [activityBuckets]int64
+}
+
+var activity = struct {
+	sync.Mutex
+	byLevel map[int]*levelActivity
+}{byLevel: make(map[int]*levelActivity)}
+
+// activityTracked is set by TrackActivity. Until then, recordActivity returns right away, so that processes that
+// do not use the activity do not pay for it on every log call.
+var activityTracked atomic.Bool
+
+// TrackActivity starts counting the logged messages for LastEntryTime, CountSince and HealthHandler. They call it
+// themselves, so that only the processes that use them pay for the counting, but the messages logged before the
+// first call are not counted: call it at startup to count them from the start.
+func TrackActivity() {
+	activityTracked.Store(true)
+}
+
+// recordActivity counts e for LastEntryTime and CountSince, if TrackActivity has been called.
+func recordActivity(e *Entry) {
+	if !activityTracked.Load() {
+		return
+	}
+	sec := e.Time.Unix()
+	// the remainder is negative for the times before 1970, e.g. set using SetClock
+	n := int64(activityBuckets)
+	i := int(((sec % n) + n) % n)
+	activity.Lock()
+	defer activity.Unlock()
+	a := activity.byLevel[e.Level]
+	if a == nil {
+		a = new(levelActivity)
+		activity.byLevel[e.Level] = a
+	}
+	if e.Time.After(a.last) {
+		a.last = e.Time
+	}
+	if a.seconds[i] != sec {
+		a.seconds[i], a.counts[i] = sec, 0
+	}
+	a.counts[i]++
+}
+
+// LastEntryTime returns the time of the last message logged at level, whether or not it passed the LogLevel, or
+// the zero time if there was none. Only the messages logged since the first call to TrackActivity are taken into
+// account, which it makes itself.
+func LastEntryTime(level int) time.Time {
+	TrackActivity()
+	activity.Lock()
+	defer activity.Unlock()
+	if a := activity.byLevel[level]; a != nil {
+		return a.last
+	}
+	return time.Time{}
+}
+
+// CountSince returns the number of messages logged at level since the given time, whether or not they passed
+// the LogLevel, to the second. Only the last 15 minutes are counted: the messages logged before are not, nor
+// are those logged before the first call to TrackActivity, which it makes itself.
+func CountSince(level int, since time.Time) int {
+	TrackActivity()
+	activity.Lock()
+	defer activity.Unlock()
+	a := activity.byLevel[level]
+	if a == nil {
+		return 0
+	}
+	from := since.Unix()
+	if oldest := now().Unix() - int64(activityBuckets) + 1; from < oldest {
+		from = oldest
+	}
+	count := 0
+	for i := range a.counts {
+		if a.seconds[i] >= from {
+			count += a.counts[i]
+		}
+	}
+	return count
+}
+
+// lastEntryAtLeast returns the time of the last message logged at level or above.
+func lastEntryAtLeast(level int) time.Time {
+	activity.Lock()
+	defer activity.Unlock()
+	var last time.Time
+	for l, a := range activity.byLevel {
+		if IsAtLeast(l, level) && a.last.After(last) {
+			last = a.last
+		}
+	}
+	return last
+}
+
+// healthStatus is the JSON body of the responses of HealthHandler.
+type healthStatus struct {
+	Status   string     `json:"status"`
+	LastCrit *time.Time `json:"last_crit,omitempty"`
+}
+
+// HealthHandler returns an http.Handler for readiness or health probes, which responds with the 503 status code
+// if a message was logged at the Crit level or above within maxCritAge, and with 200 otherwise. The body is a
+// JSON object, e.g. {"status":"unhealthy","last_crit":"2006-01-02T15:04:05Z"}. The messages are counted from the
+// call to HealthHandler on (see TrackActivity).
+func HealthHandler(maxCritAge time.Duration) http.Handler {
+	TrackActivity()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := healthStatus{Status: "ok"}
+		code := http.StatusOK
+		if last := lastEntryAtLeast(LogLevelCrit); !last.IsZero() {
+			status.LastCrit = &last
+			if now().Sub(last) < maxCritAge {
+				status.Status, code = "unhealthy", http.StatusServiceUnavailable
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(status)
+	})
+}
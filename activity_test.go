@@ -0,0 +1,97 @@
+package clog
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// resetActivity forgets the counted messages and stops counting them, until tb is done as well.
+func resetActivity(tb testing.TB) {
+	reset := func() {
+		activityTracked.Store(false)
+		activity.Lock()
+		activity.byLevel = make(map[int]*levelActivity)
+		activity.Unlock()
+	}
+	reset()
+	tb.Cleanup(reset)
+}
+
+func TestActivityIsOnlyRecordedOnceTracked(t *testing.T) {
+	setupTest(t)
+	resetActivity(t)
+	CaptureOutput(func() { Error("before") })
+	if got := CountSince(LogLevelError, testTime.Add(-time.Minute)); got != 0 {
+		t.Errorf("%d messages counted before TrackActivity, want 0", got)
+	}
+	CaptureOutput(func() { Error("after") })
+	if got := CountSince(LogLevelError, testTime.Add(-time.Minute)); got != 1 {
+		t.Errorf("%d messages counted after TrackActivity, want 1", got)
+	}
+}
+
+func TestCountSinceAndLastEntryTime(t *testing.T) {
+	setupTest(t)
+	resetActivity(t)
+	TrackActivity()
+	SetLogLevel(LogLevelCrit)
+	clockAt := func(d time.Duration) { SetClock(func() time.Time { return testTime.Add(d) }) }
+	CaptureOutput(func() {
+		clockAt(-20 * time.Minute)
+		Warning("outside the window")
+		clockAt(-10 * time.Second)
+		Warning("filtered by the level, but counted")
+		Warning("again")
+		clockAt(0)
+		Warning("now")
+	})
+	if got := CountSince(LogLevelWarning, testTime.Add(-time.Hour)); got != 3 {
+		t.Errorf("CountSince over the window: got %d, want 3", got)
+	}
+	if got := CountSince(LogLevelWarning, testTime.Add(-5*time.Second)); got != 1 {
+		t.Errorf("CountSince over 5s: got %d, want 1", got)
+	}
+	if got := CountSince(LogLevelError, testTime.Add(-time.Hour)); got != 0 {
+		t.Errorf("CountSince of another level: got %d, want 0", got)
+	}
+	if got := LastEntryTime(LogLevelWarning); !got.Equal(testTime) {
+		t.Errorf("LastEntryTime: got %v, want %v", got, testTime)
+	}
+}
+
+func TestActivityBefore1970(t *testing.T) {
+	setupTest(t)
+	resetActivity(t)
+	TrackActivity()
+	before1970 := time.Date(1969, time.July, 20, 20, 17, 0, 0, time.UTC)
+	SetClock(func() time.Time { return before1970 })
+	CaptureOutput(func() { Info("one small step") })
+	if got := CountSince(LogLevelInfo, before1970.Add(-time.Second)); got != 1 {
+		t.Errorf("got %d, want 1", got)
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	setupTest(t)
+	resetActivity(t)
+	h := HealthHandler(time.Minute)
+	check := func(wantCode int, wantStatus string) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+		var status healthStatus
+		if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != wantCode || status.Status != wantStatus {
+			t.Errorf("got %d %q, want %d %q", w.Code, status.Status, wantCode, wantStatus)
+		}
+	}
+	check(200, "ok")
+	CaptureOutput(func() { Crit("out of memory") })
+	check(503, "unhealthy")
+	SetClock(func() time.Time { return testTime.Add(2 * time.Minute) })
+	check(200, "ok")
+}
@@ -0,0 +1,240 @@
+package clog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// adminState is the JSON representation of the runtime configuration served by AdminHandler.
+type adminState struct {
+	Level             string            `json:"level"`
+	Cloggers          map[string]string `json:"cloggers"`
+	LogToStdOut       bool              `json:"log_to_stdout"`
+	LogToSyslog       bool              `json:"log_to_syslog"`
+	UseDecoration     bool              `json:"use_decoration"`
+	PrependTimestamp  bool              `json:"prepend_timestamp"`
+	PrependLoggerName bool              `json:"prepend_logger_name"`
+}
+
+// adminUpdate is the JSON body accepted by AdminHandler to change the runtime configuration. Only
+// the keys that are present are changed.
+type adminUpdate struct {
+	Level             *string           `json:"level"`
+	Cloggers          map[string]string `json:"cloggers"`
+	LogToStdOut       *bool             `json:"log_to_stdout"`
+	LogToSyslog       *bool             `json:"log_to_syslog"`
+	UseDecoration     *bool             `json:"use_decoration"`
+	PrependTimestamp  *bool             `json:"prepend_timestamp"`
+	PrependLoggerName *bool             `json:"prepend_logger_name"`
+}
+
+// AdminHandler returns an http.Handler to inspect and change the logging configuration of a running process.
+// A GET request returns the global LogLevel, the LogLevel of every registered Clogger and the flags as JSON.
+// A POST or PUT request with a JSON body changes them, e.g.
+//
+//	curl -XPOST localhost:6060/clog -d '{"level":"debug","cloggers":{"Error":"warning"},"use_decoration":false}'
+//
+// Every change is logged using the "Notice" default clogger. A GET request with a tail parameter, e.g.
+// ?tail=200, returns the last lines of the log file of the first file destination (see NewFileDestination) as
+// plain text instead. A GET request with a level, q or limit parameter, e.g. ?level=error&q=timeout&limit=100,
+// returns the entries of the first memory destination (see NewMemoryDestination) that match, using Query, as a
+// JSON array of WebhookEntry objects. A POST request to a path ending in /selftest runs SelfTest and returns the
+// status of every destination as JSON, with the 200 status code even if some of them failed.
+func AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/selftest") {
+			serveSelfTest(w)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			if tail := r.URL.Query().Get("tail"); tail != "" {
+				serveTail(w, tail)
+				return
+			}
+			if q := r.URL.Query(); q.Has("level") || q.Has("q") || q.Has("limit") {
+				serveQuery(w, q)
+				return
+			}
+		case http.MethodPost, http.MethodPut:
+			var update adminUpdate
+			if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+				http.Error(w, fmt.Sprintf("%s: invalid request body: %v", PACKAGE_NAME, err), http.StatusBadRequest)
+				return
+			}
+			if err := applyAdminUpdate(update); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(currentAdminState())
+	})
+}
+
+// adminDestinationStatus is the JSON representation of a DestinationStatus.
+type adminDestinationStatus struct {
+	Name      string  `json:"name"`
+	OK        bool    `json:"ok"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+func serveSelfTest(w http.ResponseWriter) {
+	statuses := []adminDestinationStatus{}
+	for _, s := range SelfTest() {
+		status := adminDestinationStatus{Name: s.Name, OK: s.OK, LatencyMS: float64(s.Latency) / float64(time.Millisecond)}
+		if s.Err != nil {
+			status.Error = s.Err.Error()
+		}
+		statuses = append(statuses, status)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statuses)
+}
+
+func currentAdminState() adminState {
+	ensureDefaults()
+	state := adminState{
+		Level:             levelName(GetLogLevel()),
+		Cloggers:          make(map[string]string),
+		LogToStdOut:       getFlag(&LogToStdOut),
+		LogToSyslog:       getFlag(&LogToSyslog),
+		UseDecoration:     getFlag(&UseDecoration),
+		PrependTimestamp:  getFlag(&PrependTimestamp),
+		PrependLoggerName: getFlag(&PrependLoggerName),
+	}
+	for _, cl := range defaultRegistry.List() {
+		state.Cloggers[cl.Name] = levelName(cl.GetLogLevel())
+	}
+	return state
+}
+
+// applyAdminUpdate validates all the changes in update before applying any of them, so that an invalid
+// request does not leave the configuration half changed.
+func applyAdminUpdate(update adminUpdate) error {
+	ensureDefaults()
+	var level *int
+	if update.Level != nil {
+		l, err := ParseLogLevel(*update.Level)
+		if err != nil {
+			return err
+		}
+		level = &l
+	}
+	names := make([]string, 0, len(update.Cloggers))
+	cloggers := make(map[string]*Clogger, len(update.Cloggers))
+	levels := make(map[string]int, len(update.Cloggers))
+	for name, levelName := range update.Cloggers {
+		l, err := ParseLogLevel(levelName)
+		if err != nil {
+			return err
+		}
+		// Clogger.SetLogLevel fails for the levels without a syslog priority, which must not happen halfway
+		if _, ok := syslogPriority(l); !ok {
+			return fmt.Errorf("%s: invalid LogLevel %d as no syslog.Priority is associated with it", PACKAGE_NAME, l)
+		}
+		cl, exists := defaultRegistry.Get(name)
+		if !exists {
+			return fmt.Errorf("%s: no logger with name %s", PACKAGE_NAME, name)
+		}
+		names = append(names, name)
+		cloggers[name] = cl
+		levels[name] = l
+	}
+	sort.Strings(names)
+
+	if level != nil {
+		SetLogLevel(*level)
+		Noticef("log level changed to %s by the admin handler", levelName(*level))
+	}
+	for _, name := range names {
+		// the level has been validated above, so it cannot fail
+		cloggers[name].SetLogLevel(levels[name])
+		Noticef("log level of clogger %s changed to %s by the admin handler", name, levelName(levels[name]))
+	}
+	flags := []struct {
+		name  string
+		value *bool
+		set   func(bool)
+	}{
+		{"log_to_stdout", update.LogToStdOut, SetLogToStdOut},
+		{"log_to_syslog", update.LogToSyslog, SetLogToSyslog},
+		{"use_decoration", update.UseDecoration, SetUseDecoration},
+		{"prepend_timestamp", update.PrependTimestamp, SetPrependTimestamp},
+		{"prepend_logger_name", update.PrependLoggerName, SetPrependLoggerName},
+	}
+	for _, f := range flags {
+		if f.value != nil {
+			f.set(*f.value)
+			Noticef("flag %s set to %t by the admin handler", f.name, *f.value)
+		}
+	}
+	return nil
+}
+
+// serveTail writes the last lines of the active log file, the number of which is given by tail.
+func serveTail(w http.ResponseWriter, tail string) {
+	n, err := strconv.Atoi(tail)
+	if err != nil || n < 0 {
+		http.Error(w, fmt.Sprintf("%s: invalid tail '%s'", PACKAGE_NAME, tail), http.StatusBadRequest)
+		return
+	}
+	d := activeFileDestination()
+	if d == nil {
+		http.Error(w, fmt.Sprintf("%s: no file destination", PACKAGE_NAME), http.StatusNotFound)
+		return
+	}
+	lines, err := d.Tail(n)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// serveQuery writes the entries of the memory destination that match the level, q and limit parameters of query.
+func serveQuery(w http.ResponseWriter, query url.Values) {
+	level := MinLevel
+	if name := query.Get("level"); name != "" {
+		l, err := ParseLogLevel(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		level = l
+	}
+	limit := 0
+	if s := query.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			http.Error(w, fmt.Sprintf("%s: invalid limit '%s'", PACKAGE_NAME, s), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	d := activeMemoryDestination()
+	if d == nil {
+		http.Error(w, fmt.Sprintf("%s: no memory destination", PACKAGE_NAME), http.StatusNotFound)
+		return
+	}
+	entries := []WebhookEntry{}
+	for _, e := range d.Query(level, query.Get("q"), limit) {
+		entries = append(entries, newWebhookEntry(&e))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
@@ -0,0 +1,155 @@
+package clog
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// adminRequest serves a request with the given method and body using AdminHandler.
+func adminRequest(t *testing.T, method string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	CaptureOutput(func() {
+		AdminHandler().ServeHTTP(w, httptest.NewRequest(method, "/clog", strings.NewReader(body)))
+	})
+	return w
+}
+
+func TestAdminHandlerGet(t *testing.T) {
+	setupTest(t)
+	SetLogLevel(LogLevelWarning)
+	w := adminRequest(t, "GET", "")
+	var state adminState
+	if err := json.Unmarshal(w.Body.Bytes(), &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Level != "warning" || state.Cloggers["Error"] != "error" || !state.LogToStdOut {
+		t.Errorf("got %+v", state)
+	}
+}
+
+func TestAdminHandlerUpdate(t *testing.T) {
+	setupTest(t)
+	w := adminRequest(t, "POST", `{"level":"error","cloggers":{"Debug":"warning"},"prepend_timestamp":false}`)
+	if w.Code != 200 {
+		t.Fatalf("got %d %s", w.Code, w.Body)
+	}
+	if GetLogLevel() != LogLevelError {
+		t.Errorf("global level %d, want %d", GetLogLevel(), LogLevelError)
+	}
+	if got := GetCloggerByName("Debug").GetLogLevel(); got != LogLevelWarning {
+		t.Errorf("level of Debug %d, want %d", got, LogLevelWarning)
+	}
+	if getFlag(&PrependTimestamp) {
+		t.Error("PrependTimestamp is still set")
+	}
+}
+
+func TestAdminHandlerInvalidUpdateChangesNothing(t *testing.T) {
+	setupTest(t)
+	bodies := []string{
+		`{"level":"error","cloggers":{"NoSuchClogger":"warning"}}`,
+		`{"level":"error","cloggers":{"Debug":"loud"}}`,
+		`{"level":"loud","cloggers":{"Debug":"warning"}}`,
+	}
+	for _, body := range bodies {
+		w := adminRequest(t, "PUT", body)
+		if w.Code != 400 {
+			t.Errorf("%s: got %d, want 400", body, w.Code)
+		}
+		if GetLogLevel() != LogLevelDebug || GetCloggerByName("Debug").GetLogLevel() != LogLevelDebug {
+			t.Errorf("%s: the configuration was changed", body)
+		}
+	}
+}
+
+func TestAdminHandlerTail(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	tail := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		AdminHandler().ServeHTTP(w, httptest.NewRequest("GET", "/clog?tail="+query, nil))
+		return w
+	}
+	if w := tail("2"); w.Code != http.StatusNotFound {
+		t.Errorf("without a file destination: got %d %s, want 404", w.Code, w.Body)
+	}
+	d, err := NewFileDestination(filepath.Join(t.TempDir(), "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	useDestinations(t, NewDestination(io.Discard), d)
+	CaptureOutput(func() {
+		Info("user signed in")
+		Error("disk full")
+		Info("user signed out")
+	})
+	w := tail("2")
+	if got, want := w.Body.String(), "[ERROR] disk full\n[INFO] user signed out\n"; w.Code != 200 || got != want {
+		t.Errorf("got %d %q, want %q", w.Code, got, want)
+	}
+	if w := tail("-1"); w.Code != http.StatusBadRequest {
+		t.Errorf("with a negative tail: got %d, want 400", w.Code)
+	}
+}
+
+func TestAdminHandlerSelfTest(t *testing.T) {
+	setupTest(t)
+	useDestinations(t, NewDestination(&fullWriter{limit: 0}))
+	w := httptest.NewRecorder()
+	CaptureOutput(func() {
+		AdminHandler().ServeHTTP(w, httptest.NewRequest("POST", "/clog/selftest", nil))
+	})
+	var statuses []adminDestinationStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &statuses); err != nil {
+		t.Fatalf("%v in %q", err, w.Body)
+	}
+	if w.Code != 200 || len(statuses) != 2 {
+		t.Fatalf("got %d %+v, want the status of the output and of the destination", w.Code, statuses)
+	}
+	if !statuses[0].OK || statuses[0].Name != "output" {
+		t.Errorf("output: got %+v", statuses[0])
+	}
+	if statuses[1].OK || !strings.Contains(statuses[1].Error, "no space left on device") {
+		t.Errorf("destination: got %+v", statuses[1])
+	}
+}
+
+func TestAdminHandlerQuery(t *testing.T) {
+	setupTest(t)
+	query := func(params string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		AdminHandler().ServeHTTP(w, httptest.NewRequest("GET", "/clog?"+params, nil))
+		return w
+	}
+	if w := query("level=error"); w.Code != http.StatusNotFound {
+		t.Errorf("without a memory destination: got %d %s, want 404", w.Code, w.Body)
+	}
+	useDestinations(t, NewMemoryDestination(1<<20))
+	CaptureOutput(func() {
+		Error("request timeout")
+		Warning("slow request, close to the timeout")
+		Error("disk full")
+		Crit("query timeout")
+	})
+	if w := query("level=loud"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid level: got %d %s, want 400", w.Code, w.Body)
+	}
+	if w := query("limit=-1"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid limit: got %d %s, want 400", w.Code, w.Body)
+	}
+	w := query("level=error&q=timeout&limit=1")
+	var entries []WebhookEntry
+	if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("%v in %q", err, w.Body)
+	}
+	if len(entries) != 1 || entries[0].Message != "query timeout" || entries[0].Level != "crit" {
+		t.Errorf("got %d %+v, want the last error with timeout", w.Code, entries)
+	}
+}
@@ -0,0 +1,158 @@
+package clog
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/********************************************************************************
+* A U D I T
+*********************************************************************************/
+
+// auditMACLength is the number of hex digits of the truncated HMAC-SHA256 of an audit line.
+const auditMACLength = 32
+
+// auditGenesis is the previous MAC of the first line of an audit chain.
+var auditGenesis = strings.Repeat("0", auditMACLength)
+
+var auditKey []byte
+var auditKeyLock sync.RWMutex
+
+// SetAuditKey sets the key of the HMAC that seals the lines of the audit destinations (see WithAudit). The key
+// is copied.
+func SetAuditKey(key []byte) {
+	auditKeyLock.Lock()
+	defer auditKeyLock.Unlock()
+	auditKey = append([]byte(nil), key...)
+}
+
+func getAuditKey() []byte {
+	auditKeyLock.RLock()
+	defer auditKeyLock.RUnlock()
+	return auditKey
+}
+
+// auditChain is the state of the audit chain of a destination: the sequence number and the MAC of the last
+// line, and the file that they are persisted to, if any.
+type auditChain struct {
+	seq       uint64
+	prev      string
+	statePath string
+}
+
+// WithAudit makes the destination a tamper-evident audit trail: each line is written as
+// "SEQ PREV line MAC", where SEQ is a sequence number increasing by 1, PREV is the MAC of the previous line and
+// MAC is a truncated HMAC-SHA256 of SEQ, PREV and the line, using the key set with SetAuditKey. Gaps and
+// modifications are detected by VerifyAuditLog. Writes fail if no key is set. Line breaks in the line are
+// replaced by spaces.
+func WithAudit() DestOption {
+	return destOptionFunc(func(d *WriterDestination) {
+		if d.audit == nil {
+			d.audit = &auditChain{prev: auditGenesis}
+		}
+	})
+}
+
+// WithAuditState is like WithAudit, and persists the state of the chain to the file at path after each line,
+// so that the chain continues across restarts of the process.
+func WithAuditState(path string) DestOption {
+	return destOptionFunc(func(d *WriterDestination) {
+		WithAudit().applyToDestination(d)
+		d.audit.statePath = path
+		if err := d.audit.restore(); err != nil {
+			internalf(LogLevelError, "could not restore the audit state from %s, starting a new chain: %v", path, err)
+		}
+	})
+}
+
+// restore reads the state of c from its state file, if it exists.
+func (c *auditChain) restore() error {
+	data, err := os.ReadFile(c.statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	seq, prev, ok := strings.Cut(strings.TrimSpace(string(data)), " ")
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if !ok || err != nil || len(prev) != auditMACLength {
+		return fmt.Errorf("%s: invalid audit state %q", PACKAGE_NAME, data)
+	}
+	c.seq, c.prev = n, prev
+	return nil
+}
+
+// seal returns line as the next line of the chain, along with its MAC. The chain only advances once the line is
+// written, using advance. The lock of the destination must be held.
+func (c *auditChain) seal(line string) (sealed string, mac string, err error) {
+	key := getAuditKey()
+	if len(key) == 0 {
+		return "", "", fmt.Errorf("%s: no audit key, see SetAuditKey", PACKAGE_NAME)
+	}
+	line = strings.ReplaceAll(strings.TrimRight(line, "\n"), "\n", " ")
+	mac = auditMAC(key, c.seq+1, c.prev, line)
+	return fmt.Sprintf("%d %s %s %s\n", c.seq+1, c.prev, line, mac), mac, nil
+}
+
+// advance advances the chain past the line sealed with mac, once it is written, and persists the state of the
+// chain, if it has a state file. The lock of the destination must be held.
+func (c *auditChain) advance(mac string) error {
+	c.seq, c.prev = c.seq+1, mac
+	if c.statePath == "" {
+		return nil
+	}
+	if err := os.WriteFile(c.statePath, []byte(fmt.Sprintf("%d %s\n", c.seq, c.prev)), 0600); err != nil {
+		return fmt.Errorf("%s: could not persist the audit state: %v", PACKAGE_NAME, err)
+	}
+	return nil
+}
+
+// auditMAC returns the truncated HMAC-SHA256 of seq, prev and line.
+func auditMAC(key []byte, seq uint64, prev string, line string) string {
+	h := hmac.New(sha256.New, key)
+	fmt.Fprintf(h, "%d\n%s\n%s", seq, prev, line)
+	return hex.EncodeToString(h.Sum(nil))[:auditMACLength]
+}
+
+// VerifyAuditLog re-derives the chain of the lines written by an audit destination (see WithAudit) to r, using
+// key. It returns true if the chain is intact, or false and the number of the first line, starting at 1, that
+// is modified, missing its predecessor or out of sequence. The first line is trusted to continue a previous
+// chain, so that a rotated file can be verified on its own. err is only set if r cannot be read.
+func VerifyAuditLog(r io.Reader, key []byte) (ok bool, firstBadLine int, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var prevSeq uint64
+	var prevMAC string
+	for n := 1; scanner.Scan(); n++ {
+		text := scanner.Text()
+		seqStr, rest, ok1 := strings.Cut(text, " ")
+		prev, rest, ok2 := strings.Cut(rest, " ")
+		i := strings.LastIndexByte(rest, ' ')
+		seq, perr := strconv.ParseUint(seqStr, 10, 64)
+		if !ok1 || !ok2 || i < 0 || perr != nil {
+			return false, n, nil
+		}
+		line, mac := rest[:i], rest[i+1:]
+		if n > 1 && (seq != prevSeq+1 || prev != prevMAC) {
+			return false, n, nil
+		}
+		if !hmac.Equal([]byte(mac), []byte(auditMAC(key, seq, prev, line))) {
+			return false, n, nil
+		}
+		prevSeq, prevMAC = seq, mac
+	}
+	if err := scanner.Err(); err != nil {
+		return false, 0, fmt.Errorf("%s: could not read the audit log: %v", PACKAGE_NAME, err)
+	}
+	return true, 0, nil
+}
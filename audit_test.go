@@ -0,0 +1,136 @@
+package clog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testAuditKey = []byte("audit key")
+
+// useAuditKey sets the audit key to key until tb is done.
+func useAuditKey(tb testing.TB, key []byte) {
+	SetAuditKey(key)
+	tb.Cleanup(func() { SetAuditKey(nil) })
+}
+
+// writeAudit writes an entry with each of msgs to d.
+func writeAudit(t *testing.T, d Destination, msgs ...string) {
+	t.Helper()
+	for _, msg := range msgs {
+		if err := d.Write(&Entry{Time: testTime, Level: LogLevelInfo, Logger: "Audit", Message: msg}); err != nil {
+			t.Fatalf("writing %q: %v", msg, err)
+		}
+	}
+}
+
+// auditLines returns the lines of an audit log.
+func auditLines(log string) []string {
+	return strings.SplitAfter(strings.TrimSuffix(log, "\n"), "\n")
+}
+
+func TestAuditChain(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	useAuditKey(t, testAuditKey)
+	var buf lockedBuffer
+	writeAudit(t, NewDestination(&buf, WithAudit()), "user 42 signed in", "role changed to admin", "user 42 signed out")
+	lines := auditLines(buf.String())
+
+	tests := []struct {
+		name     string
+		log      string
+		key      []byte
+		ok       bool
+		firstBad int
+	}{
+		{"intact", buf.String(), testAuditKey, true, 0},
+		{"modified line", strings.Replace(buf.String(), "admin", "guest", 1), testAuditKey, false, 2},
+		{"deleted line", lines[0] + lines[2], testAuditKey, false, 2},
+		{"rotated file", lines[1] + lines[2], testAuditKey, true, 0},
+		{"wrong key", buf.String(), []byte("another key"), false, 1},
+		{"missing key", buf.String(), nil, false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, firstBad, err := VerifyAuditLog(strings.NewReader(tt.log), tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.ok || firstBad != tt.firstBad {
+				t.Errorf("got %t and line %d, want %t and line %d", ok, firstBad, tt.ok, tt.firstBad)
+			}
+		})
+	}
+}
+
+func TestAuditNoKey(t *testing.T) {
+	setupTest(t)
+	var buf lockedBuffer
+	d := NewDestination(&buf, WithAudit())
+	if err := d.Write(&Entry{Time: testTime, Level: LogLevelInfo, Message: "user 42 signed in"}); err == nil {
+		t.Error("an audit line was written without a key")
+	}
+	if buf.String() != "" {
+		t.Errorf("got %q, want nothing", buf.String())
+	}
+}
+
+// TestAuditStateRestart continues a chain with a second destination, as after a restart of the process.
+func TestAuditStateRestart(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	useAuditKey(t, testAuditKey)
+	state := filepath.Join(t.TempDir(), "audit.state")
+	var buf lockedBuffer
+	writeAudit(t, NewDestination(&buf, WithAuditState(state)), "user 42 signed in", "role changed to admin")
+	writeAudit(t, NewDestination(&buf, WithAuditState(state)), "user 42 signed out")
+
+	lines := auditLines(buf.String())
+	if len(lines) != 3 || !strings.HasPrefix(lines[2], "3 ") {
+		t.Fatalf("got %q, want the third line of the chain", lines)
+	}
+	if ok, firstBad, err := VerifyAuditLog(strings.NewReader(buf.String()), testAuditKey); !ok || err != nil {
+		t.Errorf("the chain is broken at line %d (%v): %q", firstBad, err, lines)
+	}
+}
+
+// flakyWriter fails the writes while failing is set.
+type flakyWriter struct {
+	lockedBuffer
+	failing bool
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	if w.failing {
+		return 0, errors.New("connection reset")
+	}
+	return w.lockedBuffer.Write(p)
+}
+
+// TestAuditFailedWrite checks that a line that could not be written neither advances the chain nor its state.
+func TestAuditFailedWrite(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	recordInternal(t)
+	useAuditKey(t, testAuditKey)
+	state := filepath.Join(t.TempDir(), "audit.state")
+	w := &flakyWriter{}
+	d := NewDestination(w, WithAuditState(state))
+	writeAudit(t, d, "user 42 signed in")
+	w.failing = true
+	if err := d.Write(&Entry{Time: testTime, Level: LogLevelInfo, Message: "role changed to admin"}); err == nil {
+		t.Fatal("the write did not fail")
+	}
+	data, err := os.ReadFile(state)
+	if err != nil || !strings.HasPrefix(string(data), "1 ") {
+		t.Errorf("got the state %q (%v), want the first line", data, err)
+	}
+	w.failing = false
+	writeAudit(t, d, "user 42 signed out")
+	if ok, firstBad, err := VerifyAuditLog(strings.NewReader(w.String()), testAuditKey); !ok || err != nil {
+		t.Errorf("the chain is broken at line %d (%v): %q", firstBad, err, w.String())
+	}
+}
@@ -0,0 +1,37 @@
+package clog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BadgeStyle flag determines whether decorated messages show the log level as a badge, e.g. " ERROR " in
+// reverse video using the decorations of the Clogger, followed by the undecorated message. Badges are padded
+// to the same width, so that the messages align. It is ignored if colors are not enabled (see ColorsEnabled).
+var BadgeStyle bool = false
+
+// SetBadgeStyle sets the BadgeStyle flag. It is safe to call while other goroutines are logging.
+func SetBadgeStyle(b bool) {
+	setFlag(&BadgeStyle, b)
+}
+
+// OptBadgeStyle overrides the BadgeStyle flag for the Clogger.
+func OptBadgeStyle(b bool) CloggerOption {
+	return cloggerOptionFunc(func(cl *Clogger) { cl.toggles.badge = toggleOf(b) })
+}
+
+// SetBadgeStyle overrides the BadgeStyle flag for the Clogger. It is safe to call while other goroutines
+// are logging using the Clogger.
+func (l *Clogger) SetBadgeStyle(t Toggle) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.toggles.badge = t
+}
+
+// badge returns the badge of e, i.e. its level name padded to the width of the longest level name and
+// decorated with the decorations of the Clogger in reverse video, followed by a space.
+func (e *Entry) badge() string {
+	width := maxLevelNameLength()
+	token := fmt.Sprintf(" %-*s ", width, strings.ToUpper(levelName(e.Level)))
+	return decorateWithCode(token, e.decorationPrefix()+string(REVERSE)) + " "
+}
@@ -0,0 +1,45 @@
+package clog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBadgeStyle(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetColorMode(ColorAlways)
+	SetBadgeStyle(true)
+	lines := captureLines(func() {
+		Error("disk full")
+		Info("retrying")
+	})
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], string(FG_RED)+string(REVERSE)+" ERROR ") {
+		t.Fatalf("got %q, want a red ERROR badge", lines)
+	}
+	// the badges are padded, so that the messages align
+	var plain []string
+	for _, line := range lines {
+		plain = append(plain, StripDecorations(line))
+	}
+	if want := []string{" ERROR    disk full", " INFO     retrying"}; !reflect.DeepEqual(plain, want) {
+		t.Errorf("got %q, want %q", plain, want)
+	}
+}
+
+func TestBadgeStyleOverrides(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetBadgeStyle(true)
+	// badges need colors
+	if got := captureLines(func() { Error("disk full") }); len(got) != 1 || got[0] != "[ERROR] disk full" {
+		t.Errorf("without colors: got %q, want \"[ERROR] disk full\"", got)
+	}
+	SetColorMode(ColorAlways)
+	cl := NewClogger("Billing", LogLevelError, OptBadgeStyle(false))
+	got := captureLines(func() { cl.Print("card declined") })
+	if len(got) != 1 || StripDecorations(got[0]) != "[BILLING] card declined" {
+		t.Errorf("with OptBadgeStyle(false): got %q, want \"[BILLING] card declined\"", got)
+	}
+}
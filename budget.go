@@ -0,0 +1,59 @@
+package clog
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type logBudgetKey struct{}
+
+// logBudget is the number of lines that the Ctx functions and methods may log using a context.
+type logBudget struct {
+	max     int64
+	used    atomic.Int64
+	dropped atomic.Int64
+}
+
+// WithLogBudget returns a copy of ctx in which at most maxLines messages are logged by the Ctx functions and
+// methods, e.g. so that a single pathological request cannot flood the logs. Messages beyond the budget are
+// dropped and counted, and a single Warning "log budget exhausted (dropped N)" is logged when ctx is done, or
+// when FlushBudget is called. Only the messages that pass the LogLevel, or are logged because of
+// WithDebugEnabled, count towards the budget. Messages logged without a context are never affected.
+func WithLogBudget(ctx context.Context, maxLines int) context.Context {
+	b := &logBudget{max: int64(maxLines)}
+	context.AfterFunc(ctx, b.flush)
+	return context.WithValue(ctx, logBudgetKey{}, b)
+}
+
+// FlushBudget logs the number of messages dropped since the budget of ctx was exhausted (see WithLogBudget), or
+// since the last call to FlushBudget, if there are any, e.g. at the end of a request whose context is never
+// cancelled. It does nothing if ctx has no budget.
+func FlushBudget(ctx context.Context) {
+	if b, ok := ctx.Value(logBudgetKey{}).(*logBudget); ok {
+		b.flush()
+	}
+}
+
+// flush logs the number of messages dropped since the last flush, if there are any.
+func (b *logBudget) flush() {
+	if n := b.dropped.Swap(0); n > 0 {
+		defaultCloggerForLevel(LogLevelWarning).Printf("log budget exhausted (dropped %d)", n)
+	}
+}
+
+// budgetAllows reports whether a message logged by l using ctx may be logged, consuming the budget of ctx if it
+// passes the LogLevel. bypassLevel is true if it is logged regardless of the LogLevel.
+func budgetAllows(ctx context.Context, l *Clogger, bypassLevel bool) bool {
+	b, ok := ctx.Value(logBudgetKey{}).(*logBudget)
+	if !ok {
+		return true
+	}
+	if !bypassLevel && !IsAtLeast(l.GetLogLevel(), GetLogLevel()) {
+		return true
+	}
+	if b.used.Add(1) <= b.max {
+		return true
+	}
+	b.dropped.Add(1)
+	return false
+}
@@ -0,0 +1,62 @@
+package clog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestLogBudget checks that the Ctx messages beyond the budget are dropped and counted once FlushBudget is
+// called, and that neither the filtered messages nor the messages logged without a context count.
+func TestLogBudget(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetLogLevel(LogLevelInfo)
+	ctx := WithLogBudget(context.Background(), 2)
+	lines := captureLines(func() {
+		DebugCtx(ctx, "filtered")
+		for i := 1; i <= 5; i++ {
+			InfoCtxf(ctx, "step %d", i)
+			Infof("outside %d", i)
+		}
+		FlushBudget(ctx)
+		FlushBudget(ctx)
+		InfoCtx(ctx, "still exhausted")
+		FlushBudget(context.Background())
+	})
+	want := []string{
+		"[INFO] step 1", "[INFO] outside 1",
+		"[INFO] step 2", "[INFO] outside 2",
+		"[INFO] outside 3",
+		"[INFO] outside 4",
+		"[INFO] outside 5",
+		"[WARNING] log budget exhausted (dropped 3)",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+}
+
+// TestLogBudgetCancel checks that the dropped messages are reported once the context is cancelled.
+func TestLogBudgetCancel(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	var out lockedBuffer
+	previous := GetOutput()
+	SetOutput(&out)
+	t.Cleanup(func() { SetOutput(previous) })
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = WithLogBudget(ctx, 1)
+	for i := 0; i < 3; i++ {
+		ErrorCtx(ctx, "query failed")
+	}
+	cancel()
+	want := "[ERROR] query failed\n[WARNING] log budget exhausted (dropped 2)\n"
+	for deadline := time.Now().Add(5 * time.Second); out.String() != want && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if got := out.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
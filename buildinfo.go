@@ -0,0 +1,68 @@
+package clog
+
+import (
+	"runtime/debug"
+)
+
+// shortCommitLength is the number of characters of the vcs.revision used by EnableBuildInfoField.
+const shortCommitLength = 7
+
+// buildInfoFields returns the module path and version, the go version and the vcs settings of the
+// running binary as alternating keys and values. Keys that are not available, e.g. because the binary
+// was built without VCS stamping, are omitted.
+func buildInfoFields() []interface{} {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	var keysAndValues []interface{}
+	if info.Main.Path != "" {
+		keysAndValues = append(keysAndValues, "module", info.Main.Path)
+	}
+	if info.Main.Version != "" {
+		keysAndValues = append(keysAndValues, "version", info.Main.Version)
+	}
+	if info.GoVersion != "" {
+		keysAndValues = append(keysAndValues, "go", info.GoVersion)
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision", "vcs.time", "vcs.modified":
+			if s.Value != "" {
+				keysAndValues = append(keysAndValues, s.Key, s.Value)
+			}
+		}
+	}
+	return keysAndValues
+}
+
+// LogBuildInfo logs the module version, go version, and the vcs.revision, vcs.time and vcs.modified
+// settings of the running binary using cl. It is meant to be called once, at the start of the process.
+// Information that was not stamped into the binary is omitted.
+func LogBuildInfo(cl *Clogger) {
+	keysAndValues := buildInfoFields()
+	if keysAndValues == nil {
+		cl.Print("build info: not available")
+		return
+	}
+	cl.PrintFields("build info", keysAndValues...)
+}
+
+// EnableBuildInfoField adds the short vcs.revision of the running binary as the "commit" global field.
+// It does nothing if the binary was built without VCS stamping.
+func EnableBuildInfoField() {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" && s.Value != "" {
+			commit := s.Value
+			if len(commit) > shortCommitLength {
+				commit = commit[:shortCommitLength]
+			}
+			AddGlobalField("commit", commit)
+			return
+		}
+	}
+}
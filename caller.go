@@ -0,0 +1,37 @@
+package clog
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// packagePrefix is the prefix of the names of the functions of this package, i.e. "github.com/teejays/clog.".
+var packagePrefix string = reflect.TypeOf(Clogger{}).PkgPath() + "."
+
+// caller returns the first frame of the stack that is outside of this package, i.e. the code that called
+// into clog. It returns false if there is no such frame.
+func caller() (runtime.Frame, bool) {
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packagePrefix) {
+			return frame, frame.Function != ""
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+// callerLocation returns the file:line of the code that called into clog, or "unknown" if it can not be found.
+func callerLocation() string {
+	frame, ok := caller()
+	if !ok {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+}
@@ -0,0 +1,71 @@
+package clog_test
+
+import (
+	"log"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/teejays/clog"
+)
+
+// The caller is looked up outside of the clog package, so it is tested from another package.
+
+func TestSetStdFlagsCaller(t *testing.T) {
+	timestampFormat := clog.TimestampFormat
+	t.Cleanup(func() {
+		clog.SetStdFlags(log.LstdFlags)
+		clog.TimestampFormat = timestampFormat
+		clog.SetClock(nil)
+		clog.SetColorMode(clog.ColorAuto)
+		clog.ResetForTesting()
+	})
+	clog.ResetForTesting()
+	clog.SetColorMode(clog.ColorNever)
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	clog.SetClock(func() time.Time { return time.Date(2024, time.March, 5, 16, 7, 9, 123456789, zone) })
+	if err := clog.SetStdFlags(log.LstdFlags | log.Lmicroseconds | log.LUTC | log.Lshortfile); err != nil {
+		t.Fatal(err)
+	}
+	var line int
+	got := clog.CaptureOutput(func() {
+		_, _, line, _ = runtime.Caller(0)
+		clog.Info("migrated")
+	})
+	want := "2024/03/05 14:07:09.123456 caller_test.go:" + strconv.Itoa(line+1) + ": [INFO] migrated\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	clog.SetStdFlags(log.Llongfile)
+	got = clog.CaptureOutput(func() {
+		_, _, line, _ = runtime.Caller(0)
+		clog.Info("migrated")
+	})
+	_, file, _, _ := runtime.Caller(0)
+	if want := file + ":" + strconv.Itoa(line+1) + ": [INFO] migrated\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestOnceCallSite checks that the Once functions use the call site as the key if it is empty.
+func TestOnceCallSite(t *testing.T) {
+	t.Cleanup(func() {
+		clog.SetPrependTimestamp(true)
+		clog.ResetAllOnce()
+		clog.ResetForTesting()
+	})
+	clog.ResetForTesting()
+	clog.ResetAllOnce()
+	clog.SetPrependTimestamp(false)
+	got := clog.CaptureOutput(func() {
+		for i := 0; i < 3; i++ {
+			clog.InfoOncef("", "first site, attempt %d", i)
+			clog.InfoOncef("", "second site, attempt %d", i)
+		}
+	})
+	if want := "[INFO] first site, attempt 0\n[INFO] second site, attempt 0\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
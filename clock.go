@@ -0,0 +1,29 @@
+package clog
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// clock holds the function set using SetClock, or nil if it was not set, in which case time.Now is used. It is an
+// atomic pointer rather than a locked variable, so that reading it costs nothing on every log call.
+var clock atomic.Pointer[func() time.Time]
+
+// SetClock sets the function that returns the current time, which is used to timestamp the messages. It
+// defaults to time.Now, and is meant to be replaced by tests with a fake clock, so that the output is
+// deterministic. A nil fn restores time.Now.
+func SetClock(fn func() time.Time) {
+	if fn == nil {
+		clock.Store(nil)
+		return
+	}
+	clock.Store(&fn)
+}
+
+// now returns the current time, according to the clock set using SetClock.
+func now() time.Time {
+	if fn := clock.Load(); fn != nil {
+		return (*fn)()
+	}
+	return time.Now()
+}
@@ -8,15 +8,15 @@
 // Clog is the primary logger object, a profile. It holds information
 // neccesary for both Syslog and Std. Out logging for that particular profile. Therefore, messages
 // logged with the same Clogger will show same behavior and use the same decorations. This package
-// comes with some default Cloggers, namely Debug, Info, Warning, Error, Critical, Fatal. These cloggers have
+// comes with some default Cloggers, namely Debug, Info, Notice, Warning, Error, Crit, Alert, Emerg, Fatal, Panic and Print. These cloggers have
 // preset configuration making it very easy to use it out of the box.
 package clog
 
 import (
 	"fmt"
-	"log"
-	"os"
-	"time"
+	"runtime/debug"
+	"strings"
+	"sync"
 )
 
 const PACKAGE_NAME string = `Clog`
@@ -29,21 +29,65 @@ const (
 	LogLevelWarning
 	LogLevelError
 	LogLevelCrit
-	// LogLevelAlert // Not implemented
-	// LogLevelEmergency // Not implemented
+	LogLevelAlert
+	LogLevelEmergency
 )
 
 const default_log_level = LogLevelDebug
 
 var LogLevel = default_log_level // Default LogLevel
 
+var logLevelLock sync.RWMutex
+
+// SetLogLevel sets the LogLevel. Unlike assigning to LogLevel directly, it is safe to call while other
+// goroutines are logging.
+func SetLogLevel(level int) {
+	logLevelLock.Lock()
+	old := LogLevel
+	LogLevel = level
+	logLevelLock.Unlock()
+	notifyLevelChange(old, level)
+}
+
+// GetLogLevel returns the LogLevel. It is safe to call while other goroutines call SetLogLevel.
+func GetLogLevel() int {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+	return LogLevel
+}
+
+// SetLevelTemporarily sets the LogLevel to level and returns a function that restores it to the value it had
+// before the call. Nested overrides restore to the previous override, as long as the restore functions are called
+// in the reverse order. Since the LogLevel is global, the override applies to all goroutines.
+func SetLevelTemporarily(level int) (restore func()) {
+	logLevelLock.Lock()
+	previous := LogLevel
+	LogLevel = level
+	logLevelLock.Unlock()
+	notifyLevelChange(previous, level)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { SetLogLevel(previous) })
+	}
+}
+
+// WithLevel sets the LogLevel to level for the duration of fn, and restores it afterwards, even if fn panics.
+// Since the LogLevel is global, the override applies to all goroutines while fn runs.
+func WithLevel(level int, fn func()) {
+	restore := SetLevelTemporarily(level)
+	defer restore()
+	fn()
+}
+
 // LogToStdOut flag determines if messages should be logged to the standard terminal output
 var LogToStdOut bool = true
 
 // LogToSyslog flag determines if messages should be logged to the syslog
 var LogToSyslog bool = false
 
-// UseDecoration flag determines whether standard output logs should use any of the decorations associated with the logger
+// UseDecoration flag determines whether standard output logs should use any of the decorations associated with the logger.
+// It is ignored if the ColorMode is ColorAlways or ColorNever.
 var UseDecoration bool = true
 
 // PrependTimestamp flag determines whether standard output logs should prepend timestamp
@@ -52,55 +96,103 @@ var PrependTimestamp bool = true
 // PrependLoggerName determines whether standard output logs with the name of the logger profile prepended
 var PrependLoggerName bool = true
 
+// ShowSyslogPriority flag determines whether the prepended name of the logger is followed by the syslog severity
+// of the message, e.g. [ERROR/3], to cross-reference the standard output with the syslog.
+var ShowSyslogPriority bool = false
+
+var flagsLock sync.RWMutex
+
+// SetLogToStdOut sets the LogToStdOut flag. It is safe to call while other goroutines are logging.
+func SetLogToStdOut(b bool) {
+	setFlag(&LogToStdOut, b)
+}
+
+// SetLogToSyslog sets the LogToSyslog flag. It is safe to call while other goroutines are logging.
+func SetLogToSyslog(b bool) {
+	setFlag(&LogToSyslog, b)
+}
+
+// SetUseDecoration sets the UseDecoration flag. It is safe to call while other goroutines are logging.
+func SetUseDecoration(b bool) {
+	setFlag(&UseDecoration, b)
+}
+
+// SetPrependTimestamp sets the PrependTimestamp flag. It is safe to call while other goroutines are logging.
+func SetPrependTimestamp(b bool) {
+	setFlag(&PrependTimestamp, b)
+}
+
+// SetPrependLoggerName sets the PrependLoggerName flag. It is safe to call while other goroutines are logging.
+func SetPrependLoggerName(b bool) {
+	setFlag(&PrependLoggerName, b)
+}
+
+// SetShowSyslogPriority sets the ShowSyslogPriority flag. It is safe to call while other goroutines are logging.
+func SetShowSyslogPriority(b bool) {
+	setFlag(&ShowSyslogPriority, b)
+}
+
+func setFlag(flag *bool, b bool) {
+	flagsLock.Lock()
+	defer flagsLock.Unlock()
+	*flag = b
+}
+
+func getFlag(flag *bool) bool {
+	flagsLock.RLock()
+	defer flagsLock.RUnlock()
+	return *flag
+}
+
 // TimestampFormat is the format of the timestamp that is prepernded to std out logs. The default value
 // is 2006/01/02 15:04:05
 var TimestampFormat string = "2006/01/02 15:04:05"
 
 // Debug logs the msg using the "Debug" default clogger.
 func Debug(msg string) {
-	clogger := GetCloggerByName("Debug")
-	clogger.Print(msg)
+	clogger := defaultCloggerForLevel(LogLevelDebug)
+	clogger.printMsg(msg)
 }
 
 // Debugf formats the message using the provided args, and logs the message using the 'Debug' default clogger.
 func Debugf(formatString string, args ...interface{}) {
-	clogger := GetCloggerByName("Debug")
+	clogger := defaultCloggerForLevel(LogLevelDebug)
 	clogger.Printf(formatString, args...)
 }
 
 // Info logs the msg using the "Info" default clogger.
 func Info(msg string) {
-	clogger := GetCloggerByName("Info")
-	clogger.Print(msg)
+	clogger := defaultCloggerForLevel(LogLevelInfo)
+	clogger.printMsg(msg)
 }
 
 // Infof formats the message using the provided args, and logs the message using the 'Info' default clogger.
 func Infof(formatString string, args ...interface{}) {
-	clogger := GetCloggerByName("Info")
+	clogger := defaultCloggerForLevel(LogLevelInfo)
 	clogger.Printf(formatString, args...)
 }
 
 // Notice logs the msg using the "Notice" default clogger.
 func Notice(msg string) {
-	clogger := GetCloggerByName("Notice")
-	clogger.Print(msg)
+	clogger := defaultCloggerForLevel(LogLevelNotice)
+	clogger.printMsg(msg)
 }
 
 // Noticef formats the message using the provided args, and logs the message using the 'Notice' default clogger.
 func Noticef(formatString string, args ...interface{}) {
-	clogger := GetCloggerByName("Notice")
+	clogger := defaultCloggerForLevel(LogLevelNotice)
 	clogger.Printf(formatString, args...)
 }
 
 // Warning logs the msg using the "Warning" default clogger.
 func Warning(msg string) {
-	clogger := GetCloggerByName("Warning")
-	clogger.Print(msg)
+	clogger := defaultCloggerForLevel(LogLevelWarning)
+	clogger.printMsg(msg)
 }
 
 // Warningf formats the message using the provided args, and logs the message using the 'Warning' default clogger.
 func Warningf(formatString string, args ...interface{}) {
-	clogger := GetCloggerByName("Warning")
+	clogger := defaultCloggerForLevel(LogLevelWarning)
 	clogger.Printf(formatString, args...)
 }
 
@@ -116,73 +208,167 @@ func Warnf(formatString string, args ...interface{}) {
 
 // Error logs the msg using the "Error" default clogger.
 func Error(msg string) {
-	clogger := GetCloggerByName("Error")
-	clogger.Print(msg)
+	clogger := defaultCloggerForLevel(LogLevelError)
+	clogger.printMsg(msg)
 }
 
 // Errorf formats the message using the provided args, and logs the message using the 'Error' default clogger.
 func Errorf(formatString string, args ...interface{}) {
-	clogger := GetCloggerByName("Error")
+	clogger := defaultCloggerForLevel(LogLevelError)
 	clogger.Printf(formatString, args...)
 }
 
 // Crit logs the msg using the "Crit" default clogger.
 func Crit(msg string) {
-	clogger := GetCloggerByName("Crit")
-	clogger.Print(msg)
+	clogger := defaultCloggerForLevel(LogLevelCrit)
+	clogger.printMsg(msg)
 }
 
 // Critf formats the message using the provided args, and logs the message using the 'Crit' default clogger.
 func Critf(formatString string, args ...interface{}) {
-	clogger := GetCloggerByName("Crit")
+	clogger := defaultCloggerForLevel(LogLevelCrit)
+	clogger.Printf(formatString, args...)
+}
+
+// ErrorD logs the msg using the "Error" default clogger, with the extra decorations for this message only.
+func ErrorD(msg string, extra ...Decoration) {
+	clogger := defaultCloggerForLevel(LogLevelError)
+	clogger.PrintD(msg, extra...)
+}
+
+// CritD logs the msg using the "Crit" default clogger, with the extra decorations for this message only.
+func CritD(msg string, extra ...Decoration) {
+	clogger := defaultCloggerForLevel(LogLevelCrit)
+	clogger.PrintD(msg, extra...)
+}
+
+// Alert logs the msg using the "Alert" default clogger.
+func Alert(msg string) {
+	clogger := defaultCloggerForLevel(LogLevelAlert)
+	clogger.printMsg(msg)
+}
+
+// Alertf formats the message using the provided args, and logs the message using the 'Alert' default clogger.
+func Alertf(formatString string, args ...interface{}) {
+	clogger := defaultCloggerForLevel(LogLevelAlert)
 	clogger.Printf(formatString, args...)
 }
 
-// Fatal logs the msg using the "Fatal" default clogger. It also terminates the process by calling log.Fatal.
+// Emerg logs the msg using the "Emerg" default clogger.
+func Emerg(msg string) {
+	clogger := defaultCloggerForLevel(LogLevelEmergency)
+	clogger.printMsg(msg)
+}
+
+// Emergf formats the message using the provided args, and logs the message using the 'Emerg' default clogger.
+func Emergf(formatString string, args ...interface{}) {
+	clogger := defaultCloggerForLevel(LogLevelEmergency)
+	clogger.Printf(formatString, args...)
+}
+
+// Fatal logs the msg using the "Fatal" default clogger. It also terminates the process with exit code 1, see
+// FatalCode.
 func Fatal(msg string) {
-	Crit(msg)
-	os.Exit(1) // this should exit the process
+	FatalCode(1, msg)
 }
 
+// FatalErr logs the err using the "Fatal" default clogger and terminates the process with exit code 1, if err
+// is not nil. It does nothing if err is nil.
 func FatalErr(err error) {
+	if err == nil {
+		return
+	}
 	Fatal(err.Error())
 }
 
 // Fatalf formats the message using the provided args, and logs the message using the 'Fatal' default clogger.
-// It also terminates the process by calling log.Fatalf.
+// It also terminates the process with exit code 1, see FatalCode.
 func Fatalf(formatString string, args ...interface{}) {
-	Critf(formatString, args...)
-	log.Fatalf(formatString, args...)
+	FatalCodef(1, formatString, args...)
+}
+
+// FatalCode logs the msg using the "Fatal" default clogger. It then runs the exit hooks (see AddExitHook),
+// flushes the output, and terminates the process with the exit code by calling ExitFunc.
+func FatalCode(code int, msg string) {
+	clogger := GetCloggerByName("Fatal")
+	clogger.Print(msg)
+	fatalExit(code, msg)
+}
+
+// FatalCodef formats the message using the provided args, and logs it like FatalCode.
+func FatalCodef(code int, formatString string, args ...interface{}) {
+	clogger := GetCloggerByName("Fatal")
+	msg := sprintf(formatString, args...)
+	clogger.Print(msg)
+	fatalExit(code, msg)
 }
 
 func Redf(msg string, args ...interface{}) {
-	Red(fmt.Sprintf(msg, args...))
+	Red(sprintf(msg, args...))
 }
 func Red(msg string) {
 	PrintWithDecorations(msg, FG_RED)
 }
 
 func Greenf(msg string, args ...interface{}) {
-	Green(fmt.Sprintf(msg, args...))
+	Green(sprintf(msg, args...))
 }
 func Green(msg string) {
 	PrintWithDecorations(msg, FG_GREEN)
 }
 
 func Yellowf(msg string, args ...interface{}) {
-	Yellow(fmt.Sprintf(msg, args...))
+	Yellow(sprintf(msg, args...))
 }
 func Yellow(msg string) {
 	PrintWithDecorations(msg, FG_YELLOW)
 }
 
 func Bluef(msg string, args ...interface{}) {
-	Blue(fmt.Sprintf(msg, args...))
+	Blue(sprintf(msg, args...))
 }
 func Blue(msg string) {
 	PrintWithDecorations(msg, FG_BLUE)
 }
 
+// Colorize returns msg decorated with the decorations and followed by RESET, like the messages printed by
+// PrintWithDecorations, e.g. to embed it in a larger message. If ColorsEnabled returns false, it returns msg
+// as is.
+func Colorize(msg string, decorations ...Decoration) string {
+	if len(decorations) == 0 || !ColorsEnabled() {
+		return msg
+	}
+	return decorate(msg, decorations...)
+}
+
+func SRedf(msg string, args ...interface{}) string {
+	return SRed(sprintf(msg, args...))
+}
+func SRed(msg string) string {
+	return Colorize(msg, FG_RED)
+}
+
+func SGreenf(msg string, args ...interface{}) string {
+	return SGreen(sprintf(msg, args...))
+}
+func SGreen(msg string) string {
+	return Colorize(msg, FG_GREEN)
+}
+
+func SYellowf(msg string, args ...interface{}) string {
+	return SYellow(sprintf(msg, args...))
+}
+func SYellow(msg string) string {
+	return Colorize(msg, FG_YELLOW)
+}
+
+func SBluef(msg string, args ...interface{}) string {
+	return SBlue(sprintf(msg, args...))
+}
+func SBlue(msg string) string {
+	return Colorize(msg, FG_BLUE)
+}
+
 func Println(msg string) {
 	fmt.Println(msg)
 }
@@ -191,35 +377,83 @@ func Printf(msg string, args ...interface{}) {
 	fmt.Printf(msg, args...)
 }
 
+// PrintWithDecorations logs msg with the given decorations using the "Print" default clogger, which logs at
+// the Info level without a timestamp or a name. It goes through the same output, formatter and hooks as the
+// other cloggers, so the messages respect the LogToStdOut flag, the writer set using SetOutput, and the ColorMode.
 func PrintWithDecorations(msg string, decorations ...Decoration) {
-	msg = decorate(msg, decorations...)
-	fmt.Println(msg)
+	clogger := GetCloggerByName("Print")
+	e := clogger.newEntry(msg, nil)
+	e.Decorations = decorations
+	e.decorationCode = joinDecorations(decorations)
+	clogger.printEntry(e)
 }
 
-// Panic takes an error as an argument and calls logs.Panic
-func Panic(v ...interface{}) {
-	log.Panic(v)
+// Panic logs the msg using the "Panic" default clogger, and then panics with msg. Use Recover to log a
+// recovered panic.
+func Panic(msg string) {
+	clogger := GetCloggerByName("Panic")
+	clogger.Print(msg)
+	panic(msg)
 }
-func Panicf(format string, v ...interface{}) {
-	log.Panicf(format, v)
+
+// Panicf formats the message using the provided args, and logs it using the "Panic" default clogger. It then
+// panics with the formatted message.
+func Panicf(formatString string, args ...interface{}) {
+	msg := sprintf(formatString, args...)
+	clogger := GetCloggerByName("Panic")
+	clogger.Print(msg)
+	panic(msg)
+}
+
+// Recover recovers a panic, if there is one, and logs it with the stack trace of the panicking goroutine using
+// the "Crit" default clogger. It must be deferred directly, e.g. defer clog.Recover(), for the panic to be
+// recovered. If crash reports are enabled (see SetCrashReportDir), a report of the panic is written as well.
+func Recover() {
+	if r := recover(); r != nil {
+		clogger := defaultCloggerForLevel(LogLevelCrit)
+		stack := debug.Stack()
+		clogger.Printf("recovered panic: %v\n%s", r, stack)
+		writeCrashReport("panic", sprint(r), nil, stack)
+	}
 }
 
-func prependTimestamp(msg string) string {
-	return fmt.Sprintf("%s %s", timestamp(), msg)
+// prependTimestamp prepends the current time to msg, in the timestamp format of l.
+func (l *Clogger) prependTimestamp(msg string) string {
+	return fmt.Sprintf("%s %s", timestamp(l.getTimestampFormat()), msg)
 }
 
 func decorate(msg string, Decorations ...Decoration) string {
-	var decorationsCode string
-	for _, d := range Decorations {
-		decorationsCode += string(d)
+	return decorateWithCode(msg, joinDecorations(Decorations))
+}
+
+// decorateWithCode wraps msg between code, the joined escape sequences of one or more decorations, and RESET.
+func decorateWithCode(msg string, code string) string {
+	return code + msg + string(RESET)
+}
+
+// joinDecorations concatenates the escape sequences of the decorations.
+func joinDecorations(decorations []Decoration) string {
+	switch len(decorations) {
+	case 0:
+		return ""
+	case 1:
+		return string(decorations[0])
 	}
-	return fmt.Sprintf("%s%s%s", decorationsCode, msg, RESET)
+	var b strings.Builder
+	for _, d := range decorations {
+		b.WriteString(string(d))
+	}
+	return b.String()
 }
 
 func addBreak(msg string) string {
 	return fmt.Sprintf("%s%s", msg, "\n")
 }
 
-func timestamp() string {
-	return time.Now().Format(TimestampFormat)
+// timestamp returns the current time in format, or in TimestampFormat if format is empty.
+func timestamp(format string) string {
+	if format == "" {
+		format = TimestampFormat
+	}
+	return now().Format(format)
 }
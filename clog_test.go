@@ -0,0 +1,310 @@
+package clog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/********************************************************************************
+* G O L D E N   O U T P U T
+*********************************************************************************/
+
+func TestDefaultCloggersGoldenOutput(t *testing.T) {
+	tests := []struct {
+		name       string
+		decoration bool
+		timestamp  bool
+		want       string
+	}{
+		{"Debug", false, false, "[DEBUG] disk 93% full\n"},
+		{"Debug", false, true, "2024/03/05 14:07:09 [DEBUG] disk 93% full\n"},
+		{"Debug", true, false, "\x1b[90m[DEBUG] disk 93% full\x1b[0m\n"},
+		{"Debug", true, true, "2024/03/05 14:07:09 \x1b[90m[DEBUG] disk 93% full\x1b[0m\n"},
+		{"Info", false, false, "[INFO] disk 93% full\n"},
+		{"Info", false, true, "2024/03/05 14:07:09 [INFO] disk 93% full\n"},
+		{"Info", true, false, "\x1b[32m[INFO] disk 93% full\x1b[0m\n"},
+		{"Info", true, true, "2024/03/05 14:07:09 \x1b[32m[INFO] disk 93% full\x1b[0m\n"},
+		{"Notice", false, false, "[NOTICE] disk 93% full\n"},
+		{"Notice", false, true, "2024/03/05 14:07:09 [NOTICE] disk 93% full\n"},
+		{"Notice", true, false, "\x1b[36m[NOTICE] disk 93% full\x1b[0m\n"},
+		{"Notice", true, true, "2024/03/05 14:07:09 \x1b[36m[NOTICE] disk 93% full\x1b[0m\n"},
+		{"Warning", false, false, "[WARNING] disk 93% full\n"},
+		{"Warning", false, true, "2024/03/05 14:07:09 [WARNING] disk 93% full\n"},
+		{"Warning", true, false, "\x1b[33m[WARNING] disk 93% full\x1b[0m\n"},
+		{"Warning", true, true, "2024/03/05 14:07:09 \x1b[33m[WARNING] disk 93% full\x1b[0m\n"},
+		{"Error", false, false, "[ERROR] disk 93% full\n"},
+		{"Error", false, true, "2024/03/05 14:07:09 [ERROR] disk 93% full\n"},
+		{"Error", true, false, "\x1b[31m[ERROR] disk 93% full\x1b[0m\n"},
+		{"Error", true, true, "2024/03/05 14:07:09 \x1b[31m[ERROR] disk 93% full\x1b[0m\n"},
+		{"Crit", false, false, "[CRIT] disk 93% full\n"},
+		{"Crit", false, true, "2024/03/05 14:07:09 [CRIT] disk 93% full\n"},
+		{"Crit", true, false, "\x1b[35m[CRIT] disk 93% full\x1b[0m\n"},
+		{"Crit", true, true, "2024/03/05 14:07:09 \x1b[35m[CRIT] disk 93% full\x1b[0m\n"},
+		{"Alert", false, false, "[ALERT] disk 93% full\n"},
+		{"Alert", false, true, "2024/03/05 14:07:09 [ALERT] disk 93% full\n"},
+		{"Alert", true, false, "\x1b[31m\x1b[1m[ALERT] disk 93% full\x1b[0m\n"},
+		{"Alert", true, true, "2024/03/05 14:07:09 \x1b[31m\x1b[1m[ALERT] disk 93% full\x1b[0m\n"},
+		{"Emerg", false, false, "[EMERG] disk 93% full\n"},
+		{"Emerg", false, true, "2024/03/05 14:07:09 [EMERG] disk 93% full\n"},
+		{"Emerg", true, false, "\x1b[41m\x1b[37m\x1b[1m[EMERG] disk 93% full\x1b[0m\n"},
+		{"Emerg", true, true, "2024/03/05 14:07:09 \x1b[41m\x1b[37m\x1b[1m[EMERG] disk 93% full\x1b[0m\n"},
+		{"Fatal", false, false, "[FATAL] disk 93% full\n"},
+		{"Fatal", false, true, "2024/03/05 14:07:09 [FATAL] disk 93% full\n"},
+		{"Fatal", true, false, "\x1b[31m\x1b[1m[FATAL] disk 93% full\x1b[0m\n"},
+		{"Fatal", true, true, "2024/03/05 14:07:09 \x1b[31m\x1b[1m[FATAL] disk 93% full\x1b[0m\n"},
+		{"Panic", false, false, "[PANIC] disk 93% full\n"},
+		{"Panic", false, true, "2024/03/05 14:07:09 [PANIC] disk 93% full\n"},
+		{"Panic", true, false, "\x1b[35m\x1b[1m[PANIC] disk 93% full\x1b[0m\n"},
+		{"Panic", true, true, "2024/03/05 14:07:09 \x1b[35m\x1b[1m[PANIC] disk 93% full\x1b[0m\n"},
+		// the Print clogger has no timestamp, no name and no decorations, but the RESET is always written
+		{"Print", false, false, "disk 93% full\n"},
+		{"Print", false, true, "disk 93% full\n"},
+		{"Print", true, false, "disk 93% full\x1b[0m\n"},
+		{"Print", true, true, "disk 93% full\x1b[0m\n"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/decoration=%t/timestamp=%t", tt.name, tt.decoration, tt.timestamp), func(t *testing.T) {
+			setupTest(t)
+			if tt.decoration {
+				SetColorMode(ColorAlways)
+			}
+			SetPrependTimestamp(tt.timestamp)
+			got := CaptureOutput(func() { GetCloggerByName(tt.name).Print("disk 93% full") })
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackageFunctionsGoldenOutput(t *testing.T) {
+	setupTest(t)
+	got := CaptureOutput(func() {
+		Debug("a")
+		Debugf("%s", "b")
+		Info("c")
+		Infof("%d", 4)
+		Notice("e")
+		Noticef("%s", "f")
+		Warning("g")
+		Warningf("%s", "h")
+		Error("i")
+		Errorf("%s", "j")
+		Crit("k")
+		Critf("%s", "l")
+	})
+	want := "2024/03/05 14:07:09 [DEBUG] a\n" +
+		"2024/03/05 14:07:09 [DEBUG] b\n" +
+		"2024/03/05 14:07:09 [INFO] c\n" +
+		"2024/03/05 14:07:09 [INFO] 4\n" +
+		"2024/03/05 14:07:09 [NOTICE] e\n" +
+		"2024/03/05 14:07:09 [NOTICE] f\n" +
+		"2024/03/05 14:07:09 [WARNING] g\n" +
+		"2024/03/05 14:07:09 [WARNING] h\n" +
+		"2024/03/05 14:07:09 [ERROR] i\n" +
+		"2024/03/05 14:07:09 [ERROR] j\n" +
+		"2024/03/05 14:07:09 [CRIT] k\n" +
+		"2024/03/05 14:07:09 [CRIT] l\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLogLevelFiltersOutput(t *testing.T) {
+	setupTest(t)
+	SetLogLevel(LogLevelWarning)
+	got := captureLines(func() {
+		Debug("hidden")
+		Info("hidden")
+		Warning("shown")
+		Error("shown")
+	})
+	want := []string{"2024/03/05 14:07:09 [WARNING] shown", "2024/03/05 14:07:09 [ERROR] shown"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+/********************************************************************************
+* R A C E S
+*********************************************************************************/
+
+// TestConcurrentPrint logs from 100 goroutines while the flags are toggled, and is meant to be run with the
+// race detector. Every line must be written whole.
+func TestConcurrentPrint(t *testing.T) {
+	setupTest(t)
+	const goroutines, messages = 100, 50
+	var out lockedBuffer
+	SetOutput(&out)
+	t.Cleanup(func() { SetOutput(io.Discard) })
+
+	stop := make(chan struct{})
+	toggled := make(chan struct{})
+	go func() {
+		defer close(toggled)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			on := i%2 == 0
+			SetUseDecoration(on)
+			SetPrependTimestamp(on)
+			SetPrependLoggerName(on)
+			SetShowSyslogPriority(on)
+			if on {
+				SetColorMode(ColorAlways)
+				SetLogLevel(LogLevelDebug)
+			} else {
+				SetColorMode(ColorAuto)
+				SetLogLevel(LogLevelInfo)
+			}
+			SetClock(func() time.Time { return testTime.Add(time.Duration(i) * time.Second) })
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < messages; i++ {
+				if i%2 == 0 {
+					Info("message")
+				} else {
+					GetCloggerByName("Warning").Printf("goroutine %d message %d", g, i)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(stop)
+	<-toggled
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != goroutines*messages {
+		t.Fatalf("got %d lines, want %d", len(lines), goroutines*messages)
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "message") {
+			t.Fatalf("garbled line %q", line)
+		}
+	}
+}
+
+// lockedBuffer is a strings.Builder that can be read while it is written to.
+type lockedBuffer struct {
+	lock sync.Mutex
+	b    strings.Builder
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.b.String()
+}
+
+/********************************************************************************
+* B E N C H M A R K S
+*********************************************************************************/
+
+// setupBenchmark is like setupTest, with the output discarded.
+func setupBenchmark(b *testing.B) {
+	setupTest(b)
+	previous := GetOutput()
+	SetOutput(io.Discard)
+	b.Cleanup(func() { SetOutput(previous) })
+	b.ReportAllocs()
+}
+
+func BenchmarkPrint(b *testing.B) {
+	setupBenchmark(b)
+	cl := GetCloggerByName("Info")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cl.Print("request served")
+	}
+}
+
+func BenchmarkPrintf(b *testing.B) {
+	setupBenchmark(b)
+	cl := GetCloggerByName("Info")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cl.Printf("request %d served in %s", i, time.Millisecond)
+	}
+}
+
+func BenchmarkPrintPlain(b *testing.B) {
+	setupBenchmark(b)
+	SetColorMode(ColorNever)
+	cl := GetCloggerByName("Emerg")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cl.Print("request served")
+	}
+}
+
+func BenchmarkPrintDecorated(b *testing.B) {
+	setupBenchmark(b)
+	SetColorMode(ColorAlways)
+	cl := GetCloggerByName("Emerg")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cl.Print("request served")
+	}
+}
+
+func BenchmarkInfo(b *testing.B) {
+	setupBenchmark(b)
+	for i := 0; i < b.N; i++ {
+		Info("request served")
+	}
+}
+
+func BenchmarkInfof(b *testing.B) {
+	setupBenchmark(b)
+	for i := 0; i < b.N; i++ {
+		Infof("request %d served", i)
+	}
+}
+
+func BenchmarkDebugFiltered(b *testing.B) {
+	setupBenchmark(b)
+	SetLogLevel(LogLevelInfo)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Debug("request served")
+	}
+}
+
+// TestFilteredAllocs checks that a message filtered by the LogLevel costs no allocation, and that its entry is
+// still built if something needs the filtered entries, e.g. a hook.
+func TestFilteredAllocs(t *testing.T) {
+	setupTest(t)
+	SetLogLevel(LogLevelInfo)
+	msg := strings.Repeat("request served ", 2)
+	cl := GetCloggerByName("Debug")
+	if n := testing.AllocsPerRun(100, func() {
+		Debug(msg)
+		cl.PrintFields(msg)
+	}); n != 0 {
+		t.Errorf("got %v allocations per filtered message, want 0", n)
+	}
+	var levels []int
+	addTestHook(t, func(e *Entry) { levels = append(levels, e.Level) })
+	if lines := captureLines(func() { Debug(msg) }); len(lines) != 0 || len(levels) != 1 {
+		t.Errorf("got the lines %q and the hooks called with %v, want only the hooks called", lines, levels)
+	}
+}
@@ -5,50 +5,134 @@ import (
 	"log"
 	"log/syslog"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 const DEFAULT_LOG_FACILITY = syslog.LOG_LOCAL1
 
-var cloggers map[string]*Clogger = make(map[string]*Clogger)
+// defaultCloggerSpecs describes the default cloggers, which are registered by ensureDefaults.
+var defaultCloggerSpecs = []struct {
+	name     string
+	logLevel int
+	options  []CloggerOption
+}{
+	{"Debug", LogLevelDebug, []CloggerOption{FG_GRAY_LIGHT}},
+	{"Info", LogLevelInfo, []CloggerOption{FG_GREEN}},
+	{"Notice", LogLevelNotice, []CloggerOption{FG_CYAN}},
+	{"Warning", LogLevelWarning, []CloggerOption{FG_YELLOW}},
+	{"Error", LogLevelError, []CloggerOption{FG_RED}},
+	{"Crit", LogLevelCrit, []CloggerOption{FG_MAGENTA}},
+	{"Alert", LogLevelAlert, []CloggerOption{FG_RED, BRIGHT}},
+	{"Emerg", LogLevelEmergency, []CloggerOption{BG_RED, FG_WHITE, BRIGHT}},
+	{"Fatal", LogLevelCrit, []CloggerOption{FG_RED, BRIGHT}},
+	{"Panic", LogLevelCrit, []CloggerOption{FG_MAGENTA, BRIGHT}},
+	{"Print", LogLevelInfo, []CloggerOption{OptTimestamp(false), OptLoggerName(false), OptBadgeStyle(false)}},
+}
+
+var defaultsOnce *sync.Once = new(sync.Once)
+
+// defaultCloggerForLevel returns the default clogger that logs at level, e.g. "Error" for LogLevelError.
+// It falls back to the "Info" default clogger if there is none. It does not look the clogger up by name,
+// so that the package functions are cheap to call.
+func defaultCloggerForLevel(level int) *Clogger {
+	ensureDefaults()
+	if level >= 0 && level < len(defaultsByLevel) {
+		if cl := defaultsByLevel[level].Load(); cl != nil {
+			return cl
+		}
+	}
+	return GetCloggerByName("Info")
+}
+
+// ensureDefaults registers the default cloggers the first time it is called. A default clogger is skipped
+// if a clogger with the same name has already been registered, so that it never panics.
+func ensureDefaults() {
+	defaultsOnce.Do(func() {
+		for _, spec := range defaultCloggerSpecs {
+			if _, exists := defaultRegistry.Get(spec.name); exists {
+				continue
+			}
+			cl, err := newClogger(spec.name, spec.logLevel, spec.options...)
+			if err != nil {
+				internalf(LogLevelError, "could not create the default clogger '%s': %v", spec.name, err)
+				continue
+			}
+			// registration only fails if the name was taken in the meantime, in which case the existing clogger is kept
+			registerClogger(cl)
+		}
+		cacheDefaults()
+	})
+}
 
-// default cloggers
-var defaultCloggers []*Clogger = []*Clogger{
-	NewClogger("Debug", LogLevelDebug, FG_GRAY_LIGHT),
-	NewClogger("Info", LogLevelInfo, FG_GREEN),
-	NewClogger("Notice", LogLevelNotice, FG_CYAN),
-	NewClogger("Warning", LogLevelWarning, FG_YELLOW),
-	NewClogger("Error", LogLevelError, FG_RED),
-	NewClogger("Crit", LogLevelCrit, FG_MAGENTA),
+// defaultsByLevel holds the default clogger of each log level, so that it can be used without a lookup by name.
+var defaultsByLevel [LogLevelEmergency + 1]atomic.Pointer[Clogger]
+
+// cacheDefaults stores the registered default cloggers in defaultsByLevel.
+func cacheDefaults() {
+	for i := range defaultsByLevel {
+		defaultsByLevel[i].Store(nil)
+	}
+	for _, spec := range defaultCloggerSpecs {
+		if defaultsByLevel[spec.logLevel].Load() == nil {
+			cl, _ := defaultRegistry.Get(spec.name)
+			defaultsByLevel[spec.logLevel].Store(cl)
+		}
+	}
 }
 
-// registerLogger adds a new Clogger to the cloggers map, which can then be fetched
+// ResetForTesting removes all the registered cloggers, including any custom ones, and re-creates the default
+// cloggers. It allows test suites to isolate the state of the package between tests. It must not be called
+// while other goroutines are logging.
+func ResetForTesting() {
+	defaultRegistry.clear()
+	defaultsOnce = new(sync.Once)
+	closed.Store(false)
+	closedWarned.Store(false)
+	ensureDefaults()
+}
+
+// registerLogger adds a new Clogger to the default registry, which can then be fetched
 // by calling the GetCloggerByName method.
 func registerClogger(cl *Clogger) error {
-	if _, exists := cloggers[cl.Name]; exists {
-		return fmt.Errorf("%s: a logger with the name %s already exists", PACKAGE_NAME, cl.Name)
-	}
-	cloggers[cl.Name] = cl
-	return nil
+	return defaultRegistry.Register(cl)
+}
+
+// ReplaceClogger registers cl in place of the Clogger with the same name, e.g. to replace the "Error" default
+// clogger with one that has a different configuration. The package functions, such as Error, use cl from then
+// on. It returns the replaced Clogger, or nil if there was none.
+func ReplaceClogger(cl *Clogger) *Clogger {
+	ensureDefaults()
+	old := defaultRegistry.Replace(cl)
+	cacheDefaults()
+	return old
 }
 
 // GetCloggerByName provides the pointer to the Clogger that is stored by the given name.
 // It panics if a clogger by that name doesn't exist.
 func GetCloggerByName(name string) *Clogger {
-	cl, exist := cloggers[name]
+	ensureDefaults()
+	cl, exist := defaultRegistry.Get(name)
 	// panics if loggers[name] doesn't exist
 	if !exist {
-		log.Panicf("%s: no logger with name %s", PACKAGE_NAME, name)
+		panic(fmt.Errorf("%s: no logger with name %s", PACKAGE_NAME, name))
 	}
 	return cl
 }
 
+// LogLevelSysLogPriorityMap holds the syslog priorities of the built-in levels. It does not hold those of the
+// levels registered using RegisterLevel, and it is not guarded by any lock, so it must not be written to.
+//
+// Deprecated: use LevelSyslogPriority, which covers all the levels and is safe for concurrent use.
 var LogLevelSysLogPriorityMap map[int]syslog.Priority = map[int]syslog.Priority{
-	LogLevelDebug:   syslog.LOG_DEBUG,
-	LogLevelInfo:    syslog.LOG_INFO,
-	LogLevelNotice:  syslog.LOG_NOTICE,
-	LogLevelWarning: syslog.LOG_WARNING,
-	LogLevelError:   syslog.LOG_ERR,
-	LogLevelCrit:    syslog.LOG_CRIT,
+	LogLevelDebug:     syslog.LOG_DEBUG,
+	LogLevelInfo:      syslog.LOG_INFO,
+	LogLevelNotice:    syslog.LOG_NOTICE,
+	LogLevelWarning:   syslog.LOG_WARNING,
+	LogLevelError:     syslog.LOG_ERR,
+	LogLevelCrit:      syslog.LOG_CRIT,
+	LogLevelAlert:     syslog.LOG_ALERT,
+	LogLevelEmergency: syslog.LOG_EMERG,
 }
 
 /********************************************************************************
@@ -57,72 +141,257 @@ var LogLevelSysLogPriorityMap map[int]syslog.Priority = map[int]syslog.Priority{
 
 // Clogger is the primary logger of this package. It represents a logger profile that has
 // associated decorations, syslog priority level and the go's builtin log.logger struct that
-// helps print to syslog. This package come with some default Cloggers, but Clogger can also
+// helps print to syslog, which is not exposed: all the Print, Fatal and Panic methods go through
+// the same pipeline. This package come with some default Cloggers, but Clogger can also
 // be created using the NewClogger() method.
 type Clogger struct {
 	Name string
 	syslog.Priority
 	Decorations []Decoration
-	*log.Logger
-	LogLevel int
+	LogLevel    int
+	// sysLogger writes to the syslog with the Priority of the Clogger. It is nil if the syslog could not be
+	// reached.
+	sysLogger *log.Logger
+
+	lock sync.RWMutex
+	// decorationCode caches the joined escape sequences of decorationCodeFor, which is the value of Decorations
+	// it was computed from.
+	decorationCode    string
+	decorationCodeFor []Decoration
+	toggles           toggles
+	fields            Fields
+	sampler           *sampler
+	sampleNote        int
+	throttler         *throttler
+	timestampFormat   string
+	scope             []string
+	tags              []string
+	// verbose is set for the Cloggers of ForPackage that match CLOG_DEBUG, whose messages ignore the LogLevel.
+	verbose bool
+	// pooled is set for the children that come from cloggerPool, and released once they are given back.
+	pooled   bool
+	released atomic.Bool
 }
 
-// NewClogger creates a new Clogger object. It accepts the name of the new Clogger, priority level
-// in the form of syslog.Priority and one or more Decorations. It returns a pointer to a new Clogger
-// object with those properties. It panics if it encounters an error.
-func NewClogger(name string, logLevel int, decorations ...Decoration) *Clogger {
+// NewClogger creates a new Clogger object. It accepts the name of the new Clogger, its LogLevel and
+// options, which can be one or more Decorations and the Opt... overrides of the global flags, e.g.
+// NewClogger("Plain", LogLevelInfo, OptDecoration(false), OptTimestamp(false)). It returns a pointer
+// to a new Clogger object with those properties. It panics if it encounters an error.
+func NewClogger(name string, logLevel int, options ...CloggerOption) *Clogger {
+	clogger, err := newClogger(name, logLevel, options...)
+	if err != nil {
+		panic(err)
+	}
+	err = registerClogger(clogger)
+	if err != nil {
+		panic(err)
+	}
+	return clogger
+}
+
+// newClogger creates a new Clogger object without registering it. It returns an error if there is
+// no syslog.Priority associated with logLevel.
+func newClogger(name string, logLevel int, options ...CloggerOption) (*Clogger, error) {
 	clogger := new(Clogger)
 	clogger.Name = name
 	clogger.LogLevel = logLevel
 	// Get the syslog.Level from the map
-	priority, hasKey := LogLevelSysLogPriorityMap[logLevel]
+	priority, hasKey := syslogPriority(logLevel)
 	if !hasKey {
-		log.Panicf("Invalid LogLevel parameter provided as no syslog.Priority associated with LogLevel %d", logLevel)
+		return nil, fmt.Errorf("Invalid LogLevel parameter provided as no syslog.Priority associated with LogLevel %d", logLevel)
 	}
 	clogger.Priority = priority | DEFAULT_LOG_FACILITY
-	clogger.Decorations = decorations
+	for _, o := range options {
+		o.applyTo(clogger)
+	}
 	// https://en.wikipedia.org/wiki/Syslog
-	logger, err := syslog.NewLogger(clogger.Priority, 0)
+	logger, err := newSyslogLogger(clogger.Priority)
 	if err != nil {
-		log.Printf("[%s] Clogger profile '%s' will not log to syslog as it failed to initialize syslog.Logger(): %v", PACKAGE_NAME, clogger.Name, err)
+		internalf(LogLevelWarning, "Clogger profile '%s' will not log to syslog as it failed to initialize syslog.Logger(): %v", clogger.Name, err)
 	} else {
-		clogger.Logger = logger
+		clogger.sysLogger = logger
 	}
-
-	err = registerClogger(clogger)
-	if err != nil {
-		log.Panic(err)
-	}
-	return clogger
+	return clogger, nil
 }
 
 // AddDecoration (deprecated) adds the decoration to the Clogger. It probably should not be used
 // hence it is being deprecated.
 func (l *Clogger) AddDecoration(d Decoration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
 	l.Decorations = append(l.Decorations, d)
+	l.decorationCodeFor = nil
 }
 
 // RemoveDecoration (deprecated) removes the decorations from the Clogger. It probably should not be used
 // hence it is being deprecated.
 func (l *Clogger) RemoveDecoration(d Decoration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
 	for i, _d := range l.Decorations {
 		if d == _d {
 			// delete the decoration from the list
 			l.Decorations = append(l.Decorations[:i], l.Decorations[i+1:]...)
 		}
 	}
+	l.decorationCodeFor = nil
+}
+
+// SetDecorations replaces the decorations of the Clogger with decorations. It should be preferred over
+// modifying the Decorations field directly, which is not safe while other goroutines are logging.
+func (l *Clogger) SetDecorations(decorations ...Decoration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.Decorations = decorations
+	l.decorationCodeFor = nil
+}
+
+// decorations returns the decorations of the Clogger, and their joined escape sequences. The escape sequences
+// are computed once and cached until the decorations change.
+func (l *Clogger) decorations() ([]Decoration, string) {
+	l.lock.RLock()
+	if sameDecorations(l.decorationCodeFor, l.Decorations) {
+		decorations, code := l.Decorations, l.decorationCode
+		l.lock.RUnlock()
+		return decorations, code
+	}
+	l.lock.RUnlock()
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.decorationCode = joinDecorations(l.Decorations)
+	l.decorationCodeFor = l.Decorations
+	return l.Decorations, l.decorationCode
+}
+
+// clone returns a new, unregistered Clogger with the same configuration as l.
+func (l *Clogger) clone() *Clogger {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	return &Clogger{
+		Name:        l.Name,
+		Priority:    l.Priority,
+		Decorations: l.Decorations,
+		sysLogger:   l.sysLogger,
+		LogLevel:    l.LogLevel,
+		toggles:     l.toggles,
+		fields:      l.fields,
+		sampler:     l.sampler,
+		sampleNote:  l.sampleNote,
+		throttler:   l.throttler,
+
+		timestampFormat: l.timestampFormat,
+		scope:           l.scope,
+		tags:            l.tags,
+		verbose:         l.verbose,
+	}
+}
+
+// sameDecorations reports whether a and b are the same slice, i.e. have the same length and backing array.
+func sameDecorations(a, b []Decoration) bool {
+	if a == nil || len(a) != len(b) {
+		return false
+	}
+	return len(a) == 0 || &a[0] == &b[0]
+}
+
+// SetLogLevel changes the LogLevel of the Clogger, and the syslog priority that its messages are logged with.
+// It is safe to call while other goroutines are logging using the Clogger. It returns an error if there is no
+// syslog priority associated with level.
+func (l *Clogger) SetLogLevel(level int) error {
+	priority, hasKey := syslogPriority(level)
+	if !hasKey {
+		return fmt.Errorf("%s: invalid LogLevel %d as no syslog.Priority is associated with it", PACKAGE_NAME, level)
+	}
+	priority |= DEFAULT_LOG_FACILITY
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.LogLevel = level
+	if priority != l.Priority {
+		l.Priority = priority
+		if logger, err := newSyslogLogger(priority); err == nil {
+			l.sysLogger = logger
+		}
+	}
+	return nil
+}
+
+// GetLogLevel returns the LogLevel of the Clogger. It is safe to call while other goroutines call SetLogLevel.
+func (l *Clogger) GetLogLevel() int {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	return l.LogLevel
 }
 
 // Print logs the message in the Syslog if LogToSyslog is set to true. It logs to the standard out
-// (terminal) if LogToStdOut flag is set to true.
-func (l *Clogger) Print(msg string) {
-	msg = fmt.Sprintf("[%s] %s", strings.ToUpper(l.Name), msg)
-	if LogToSyslog && l.Logger != nil {
-		l.Logger.Print(msg)
+// (terminal) if LogToStdOut flag is set to true. The message is built from v like fmt.Sprint, i.e. with
+// spaces between operands when neither is a string.
+func (l *Clogger) Print(v ...interface{}) {
+	if !l.mayLog(l.GetLogLevel(), false) {
+		return
 	}
-	if LogToStdOut && LogLevel <= l.LogLevel {
-		l.PrintStdOut(msg)
+	l.printEntry(l.newEntry(sprintArgs(v), nil))
+}
+
+// printMsg logs msg like Print. It saves the level functions, e.g. Debug, from converting msg to an interface,
+// so that a filtered message costs no allocation.
+func (l *Clogger) printMsg(msg string) {
+	if !l.mayLog(l.GetLogLevel(), false) {
+		return
+	}
+	l.printEntry(l.newEntry(msg, nil))
+}
+
+// Println logs the message like Print. The message is built from v like fmt.Sprintln, i.e. with spaces
+// between all operands, without the trailing newline.
+func (l *Clogger) Println(v ...interface{}) {
+	if !l.mayLog(l.GetLogLevel(), false) {
+		return
 	}
+	l.printEntry(l.newEntry(sprintlnArgs(v), nil))
+}
+
+// Fatal logs the message like Print, and then terminates the process with exit code 1, after running the exit
+// hooks (see AddExitHook).
+func (l *Clogger) Fatal(v ...interface{}) {
+	msg := sprintArgs(v)
+	l.printEntry(l.newEntry(msg, nil))
+	fatalExit(1, msg)
+}
+
+// Fatalf logs the message like Printf, and then terminates the process like Fatal.
+func (l *Clogger) Fatalf(formatString string, args ...interface{}) {
+	msg := sprintf(formatString, args...)
+	l.printEntry(l.newEntry(msg, nil))
+	fatalExit(1, msg)
+}
+
+// Fatalln logs the message like Println, and then terminates the process like Fatal.
+func (l *Clogger) Fatalln(v ...interface{}) {
+	msg := sprintlnArgs(v)
+	l.printEntry(l.newEntry(msg, nil))
+	fatalExit(1, msg)
+}
+
+// Panic logs the message like Print, and then panics with it.
+func (l *Clogger) Panic(v ...interface{}) {
+	msg := sprintArgs(v)
+	l.printEntry(l.newEntry(msg, nil))
+	panic(msg)
+}
+
+// Panicf logs the message like Printf, and then panics with it.
+func (l *Clogger) Panicf(formatString string, args ...interface{}) {
+	msg := sprintf(formatString, args...)
+	l.printEntry(l.newEntry(msg, nil))
+	panic(msg)
+}
+
+// Panicln logs the message like Println, and then panics with it.
+func (l *Clogger) Panicln(v ...interface{}) {
+	msg := sprintlnArgs(v)
+	l.printEntry(l.newEntry(msg, nil))
+	panic(msg)
 }
 
 // Printf formats the msg with the provided args and logs to Syslog. If LogToStdOut flag
@@ -130,32 +399,167 @@ func (l *Clogger) Print(msg string) {
 // with the provided args. It logs the message in the Syslog if LogToSyslog is
 // set to true. It logs to the standard out (terminal) if LogToStdOut flag is set to true.
 func (l *Clogger) Printf(formatString string, args ...interface{}) {
-	formatString = fmt.Sprintf("[%s] %s", strings.ToUpper(l.Name), formatString)
-	if LogToSyslog && l.Logger != nil {
-		l.Logger.Printf(formatString, args...)
+	if !l.mayLog(l.GetLogLevel(), false) {
+		return
 	}
-	if LogToStdOut && LogLevel <= l.LogLevel {
-		l.PrintfStdOut(formatString, args...)
+	l.printEntry(l.newEntry(sprintf(formatString, args...), nil))
+}
+
+// PrintFields logs msg with the keysAndValues attached as fields. keysAndValues should alternate
+// between keys and values, e.g. PrintFields("request failed", "status", 500).
+func (l *Clogger) PrintFields(msg string, keysAndValues ...interface{}) {
+	if !l.mayLog(l.GetLogLevel(), false) {
+		return
 	}
+	l.printEntry(l.newEntry(msg, fieldsFromKeysAndValues(keysAndValues)))
+}
+
+// PrintD logs msg like Print, with the extra decorations applied after the decorations of the Clogger for
+// this message only. The Clogger is not changed.
+func (l *Clogger) PrintD(msg string, extra ...Decoration) {
+	e := l.newEntry(msg, nil)
+	e.addDecorations(extra)
+	l.printEntry(e)
+}
+
+// PrintfD formats the msg with the provided args, and logs it like PrintD with the extra decorations.
+func (l *Clogger) PrintfD(extra []Decoration, formatString string, args ...interface{}) {
+	l.PrintD(sprintf(formatString, args...), extra...)
+}
+
+// printEntry logs e in the Syslog if LogToSyslog is set to true, and to the standard out using the
+// current Formatter if LogToStdOut is set to true and e passes the LogLevel. The name of the Clogger is not
+// part of the syslog message, since the syslog records the priority of the message on its own, unless the
+// SyslogFormat carries it (see SetSyslogFormat).
+func (l *Clogger) printEntry(e *Entry) {
+	l.writeEntry(e, false)
+}
+
+// mayLog reports whether a message logged by l at level may have any effect, so that the entry of a message
+// that has none, e.g. a filtered Debug message, is not even built. A message that does not pass the LogLevel
+// may still be written to the syslog, passed to the hooks, escalated, counted, traced or written after Close.
+// Like writeEntry, it panics if l has been released and poisoning is enabled.
+func (l *Clogger) mayLog(level int, bypassLevel bool) bool {
+	l.checkReleased()
+	if bypassLevel || l.verbose || IsAtLeast(level, GetLogLevel()) {
+		return true
+	}
+	return getFlag(&LogToSyslog) || hasHooks() || hasEscalationRules() || activityTracked.Load() ||
+		(errorWatchdogEnabled.Load() && IsAtLeast(level, LogLevelError)) || routingTraces.Load() > 0 || closed.Load()
+}
+
+// writeEntry logs e like printEntry, but if bypassLevel is true the LogLevel is not checked.
+func (l *Clogger) writeEntry(e *Entry, bypassLevel bool) {
+	l.checkReleased()
+	tr := newRoutingTrace(e)
+	defer tr.emit()
+	if tagsSuppressed(e.Tags) {
+		tr.note("suppressed by SuppressTags")
+		return
+	}
+	sl := l.escalate(e)
+	if sl != l {
+		tr.note("escalated to " + levelName(e.Level))
+	}
+	passesLevel := bypassLevel || l.verbose || IsAtLeast(e.Level, GetLogLevel())
+	// the messages that are not written anywhere do not use up the samples, nor the messages allowed by Every
+	if passesLevel || sl.WouldLog(e.Level) {
+		if !l.sample(e) {
+			tr.note("dropped by sampling")
+			return
+		}
+		if !l.throttle(e) {
+			tr.note("dropped by Every")
+			return
+		}
+	}
+	if closed.Load() {
+		tr.note("clog is closed, written to the standard error")
+		writeAfterClose(e)
+		return
+	}
+	attachSourceSnippet(e)
+	fireHooks(e)
+	recordActivity(e)
+	// the entry is counted once it has been written, since the threshold may terminate the process
+	defer countError(e)
+	if getFlag(&LogToSyslog) {
+		// the lock is held during the write, so that Close does not close the syslog writer in the meantime
+		sl.lock.RLock()
+		if sl.sysLogger != nil {
+			format, cookie := getSyslogFormat()
+			sl.sysLogger.Print(syslogMessage(e, format, cookie))
+			tr.note("syslog: written")
+		} else {
+			tr.note("syslog: skipped, the Clogger has no syslog writer")
+		}
+		sl.lock.RUnlock()
+	} else {
+		tr.note("syslog: skipped, LogToSyslog is false")
+	}
+	if !passesLevel {
+		tr.notef("output and destinations: skipped, level %s is below the LogLevel %s", levelName(e.Level), levelName(GetLogLevel()))
+		return
+	}
+	recordRecentEntry(e)
+	if getFlag(&LogToStdOut) {
+		f := GetFormatter()
+		e.highlight = true
+		writeEntryLine(e, formatEntry(f, e))
+		e.highlight = false
+		tr.notef("output: written using %T", f)
+	} else {
+		tr.note("output: skipped, LogToStdOut is false")
+	}
+	r := getRouter()
+	for _, dest := range r.Route(*e) {
+		err := dest.Write(e)
+		tr.notef("destination %T: routed by %T, written (error: %v)", dest, r, err)
+		if err != nil {
+			internalf(LogLevelError, "destination %T could not write an entry: %v", dest, err)
+		}
+	}
+}
+
+// namePrefix returns the "[NAME] " prefix of the messages logged by the Clogger with the given name. It is
+// concatenated to the already formatted message, so that a '%' in the name is never treated as a verb.
+func namePrefix(name string) string {
+	return "[" + strings.ToUpper(name) + "] "
+}
+
+// namePrefixFor returns the name prefix of a message logged at level by the Clogger with the given name, which
+// includes the syslog severity of level if ShowSyslogPriority is set, e.g. "[ERROR/3] ".
+func namePrefixFor(name string, level int) string {
+	if !getFlag(&ShowSyslogPriority) {
+		return namePrefix(name)
+	}
+	priority, _ := syslogPriority(level)
+	// the facility bits are not part of the severity
+	return fmt.Sprintf("[%s/%d] ", strings.ToUpper(name), priority&0x07)
 }
 
 // StdPrintf formats msg with the provided args and prints it as a line in the standard output. If PrependTimestamp is
-// set to true, it prepends timestamp to the log messages. If UseDecoration is set to true, it adds all the decorations
-// associated with the l Clogger.
+// set to true, it prepends timestamp to the log messages. If PrependLoggerName is set to true, it prepends the name of
+// the l Clogger. If UseDecoration is set to true, it adds all the decorations associated with the l Clogger.
 func (l *Clogger) PrintfStdOut(formatString string, args ...interface{}) {
-	msg := fmt.Sprintf(formatString, args...)
+	msg := sprintf(formatString, args...)
 	l.PrintStdOut(msg)
 }
 
-// StdPrint prints msg as a line in the standard output (terminal). If PrependTimestamp is set to true,
-// it prepends timestamp to the log messages. If UseDecoration is set to true, it adds all the decorations
-// associated with the l Clogger.
+// StdPrint prints msg as a line in the standard output (terminal), or the writer set using SetOutput. If PrependTimestamp is set to true,
+// it prepends timestamp to the log messages. If PrependLoggerName is set to true, it prepends the name of
+// the l Clogger. If colors are enabled (see ColorsEnabled), it adds all the decorations associated with the l Clogger.
 func (l *Clogger) PrintStdOut(msg string) {
-	if UseDecoration {
-		msg = decorate(msg, l.Decorations...)
+	t := l.getToggles()
+	if t.loggerName.resolve(&PrependLoggerName) {
+		msg = namePrefixFor(l.Name, l.GetLogLevel()) + msg
+	}
+	if colorsEnabled(t.decoration) {
+		_, code := l.decorations()
+		msg = decorateWithCode(msg, code)
 	}
-	if PrependTimestamp {
-		msg = prependTimestamp(msg)
+	if t.timestamp.resolve(&PrependTimestamp) {
+		msg = l.prependTimestamp(msg)
 	}
-	fmt.Println(msg)
+	writeLine(msg)
 }
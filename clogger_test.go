@@ -0,0 +1,195 @@
+package clog
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+	"testing"
+)
+
+// TestPercentInNameAndMessage checks that a '%' in the name of the Clogger or in a message is never treated as
+// a formatting verb, neither in the output nor in the syslog message.
+func TestPercentInNameAndMessage(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	cl := NewClogger("100%CPU", LogLevelWarning)
+	var syslog []string
+	addTestHook(t, func(e *Entry) { syslog = append(syslog, syslogMessage(e, SyslogFieldsInMessage, "")) })
+
+	got := captureLines(func() {
+		cl.Print("literal %d in the message")
+		cl.Printf("load %d%% on %s", 100, "cpu0")
+		cl.Printf("%s", "arg with %d and 100%CPU")
+	})
+	want := []string{
+		"[100%CPU] literal %d in the message",
+		"[100%CPU] load 100% on cpu0",
+		"[100%CPU] arg with %d and 100%CPU",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("output: got %q, want %q", got, want)
+	}
+	wantSyslog := []string{"literal %d in the message", "load 100% on cpu0", "arg with %d and 100%CPU"}
+	if strings.Join(syslog, "\n") != strings.Join(wantSyslog, "\n") {
+		t.Errorf("syslog: got %q, want %q", syslog, wantSyslog)
+	}
+}
+
+func TestReplaceCloggerUpdatesPackageFunctions(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	original := GetCloggerByName("Error")
+	replacement, err := newClogger("Error", LogLevelError, OptLoggerName(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old := ReplaceClogger(replacement); old != original {
+		t.Errorf("ReplaceClogger returned %p, want the original clogger %p", old, original)
+	}
+	if got := defaultCloggerForLevel(LogLevelError); got != replacement {
+		t.Errorf("the cached Error clogger is %p, want the replacement %p", got, replacement)
+	}
+	if got := CaptureOutput(func() { Error("disk full") }); got != "disk full\n" {
+		t.Errorf("got %q, want the message logged by the replacement", got)
+	}
+}
+
+func TestPrependLoggerName(t *testing.T) {
+	tests := []struct {
+		prepend bool
+		want    string
+	}{
+		{true, "[WARNING] disk 93% full\n"},
+		{false, "disk 93% full\n"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.prepend), func(t *testing.T) {
+			setupTest(t)
+			SetPrependTimestamp(false)
+			rec := recordSyslog(t)
+			SetPrependLoggerName(tt.prepend)
+			got := CaptureOutput(func() { Warning("disk 93% full") })
+			if got != tt.want {
+				t.Errorf("output: got %q, want %q", got, tt.want)
+			}
+			// the syslog records the priority on its own, so the name is never part of the syslog message
+			if want := []string{"disk 93% full"}; strings.Join(rec.messages(), "\n") != strings.Join(want, "\n") {
+				t.Errorf("syslog: got %q, want %q", rec.messages(), want)
+			}
+		})
+	}
+}
+
+func TestShowSyslogPriority(t *testing.T) {
+	tests := []struct {
+		name  string
+		want  string
+		plain string
+	}{
+		{"Debug", "[DEBUG/7] disk 93% full\n", "[DEBUG] disk 93% full\n"},
+		{"Info", "[INFO/6] disk 93% full\n", "[INFO] disk 93% full\n"},
+		{"Notice", "[NOTICE/5] disk 93% full\n", "[NOTICE] disk 93% full\n"},
+		{"Warning", "[WARNING/4] disk 93% full\n", "[WARNING] disk 93% full\n"},
+		{"Error", "[ERROR/3] disk 93% full\n", "[ERROR] disk 93% full\n"},
+		{"Crit", "[CRIT/2] disk 93% full\n", "[CRIT] disk 93% full\n"},
+		{"Alert", "[ALERT/1] disk 93% full\n", "[ALERT] disk 93% full\n"},
+		{"Emerg", "[EMERG/0] disk 93% full\n", "[EMERG] disk 93% full\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			SetPrependTimestamp(false)
+			SetShowSyslogPriority(true)
+			if got := CaptureOutput(func() { GetCloggerByName(tt.name).Print("disk 93% full") }); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			SetShowSyslogPriority(false)
+			if got := CaptureOutput(func() { GetCloggerByName(tt.name).Print("disk 93% full") }); got != tt.plain {
+				t.Errorf("without ShowSyslogPriority: got %q, want %q", got, tt.plain)
+			}
+		})
+	}
+}
+
+// TestShowSyslogPriorityOfEntry checks that the severity shown is the one of the level of the entry, rather than
+// that of the Clogger, and that the facility bits of a registered level are not part of it.
+func TestShowSyslogPriorityOfEntry(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetShowSyslogPriority(true)
+	e := GetCloggerByName("Info").newEntry("disk 93% full", nil)
+	e.Level = LogLevelCrit
+	if got, want := formatEntry(GetFormatter(), e), "[INFO/2] disk 93% full"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	registerTestLevel(t, "AUDIT", 35, syslog.LOG_LOCAL3|syslog.LOG_NOTICE)
+	got := CaptureOutput(func() { GetCloggerByName("AUDIT").Print("login") })
+	if want := "[AUDIT/5] login\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// BenchmarkLookupByName measures the lookup of a default clogger by name, which the package functions did before
+// the default cloggers were cached, for comparison with BenchmarkLookupCached.
+func BenchmarkLookupByName(b *testing.B) {
+	setupBenchmark(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GetCloggerByName("Info")
+	}
+}
+
+func BenchmarkLookupCached(b *testing.B) {
+	setupBenchmark(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		defaultCloggerForLevel(LogLevelInfo)
+	}
+}
+
+// BenchmarkInfoViaName logs like Info did before the default cloggers were cached, for comparison with
+// BenchmarkInfo.
+func BenchmarkInfoViaName(b *testing.B) {
+	setupBenchmark(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GetCloggerByName("Info").Print("request served")
+	}
+}
+
+// TestPrintlnFatalPanic checks that the methods of the standard log.Logger go through the pipeline of clog,
+// with the formatting of their fmt counterparts.
+func TestPrintlnFatalPanic(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	codes := stubExit(t)
+	cl := GetCloggerByName("Error")
+	var recovered interface{}
+	lines := captureLines(func() {
+		cl.Print("disk", 3, 4, "full")
+		cl.Println("disk", 3, 4, "full")
+		cl.Fatalf("exit %d", 1)
+		cl.Fatalln("exit", 2)
+		func() {
+			defer func() { recovered = recover() }()
+			cl.Panicln("out of", "memory")
+		}()
+	})
+	want := []string{
+		"[ERROR] disk3 4full",
+		"[ERROR] disk 3 4 full",
+		"[ERROR] exit 1",
+		"[ERROR] exit 2",
+		"[ERROR] out of memory",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+	if len(*codes) != 2 || (*codes)[0] != 1 || (*codes)[1] != 1 {
+		t.Errorf("got the exit codes %v, want [1 1]", *codes)
+	}
+	if recovered != "out of memory" {
+		t.Errorf("recovered %v, want the message", recovered)
+	}
+}
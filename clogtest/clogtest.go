@@ -0,0 +1,163 @@
+// Package clogtest provides utilities to exercise the error paths of the logging of a program in its tests:
+// writers that fail or are slow, a syslog dialer that fails, and a recorder of the diagnostics of clog itself.
+package clogtest
+
+import (
+	"errors"
+	"io"
+	"log/syslog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/teejays/clog"
+)
+
+// ErrInjected is the error returned by the utilities of this package when no other error is set.
+var ErrInjected = errors.New("clogtest: injected failure")
+
+// FailingWriter writes to W, or discards, the first FailAfter bytes, and fails afterwards with Err, or
+// ErrInjected. A write that crosses the limit is partially written. It is safe for concurrent use.
+type FailingWriter struct {
+	FailAfter int
+	Err       error
+	W         io.Writer
+
+	lock    sync.Mutex
+	written int
+}
+
+// Write writes p, up to the limit of w.
+func (w *FailingWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	remaining := w.FailAfter - w.written
+	if remaining >= len(p) {
+		return w.write(p)
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	n, _ := w.write(p[:remaining])
+	return n, w.err()
+}
+
+func (w *FailingWriter) write(p []byte) (int, error) {
+	out := w.W
+	if out == nil {
+		out = io.Discard
+	}
+	n, err := out.Write(p)
+	w.written += n
+	return n, err
+}
+
+func (w *FailingWriter) err() error {
+	if w.Err != nil {
+		return w.Err
+	}
+	return ErrInjected
+}
+
+// Written returns the number of bytes written so far.
+func (w *FailingWriter) Written() int {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.written
+}
+
+// SlowWriter waits for Delay before every write to W, or discards.
+type SlowWriter struct {
+	Delay time.Duration
+	W     io.Writer
+}
+
+// Write waits, and then writes p.
+func (w *SlowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.Delay)
+	if w.W == nil {
+		return len(p), nil
+	}
+	return w.W.Write(p)
+}
+
+// FlakySyslogDialer is a syslog dialer whose first FailFirst dials fail with Err, or ErrInjected. The later ones
+// succeed, and return W, or a writer that discards. It is installed using clog.SetSyslogDialer(d.Dial).
+type FlakySyslogDialer struct {
+	FailFirst int
+	Err       error
+	W         io.Writer
+
+	lock  sync.Mutex
+	dials int
+}
+
+// Dial implements clog.SyslogDialer.
+func (d *FlakySyslogDialer) Dial(priority syslog.Priority) (io.Writer, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.dials++
+	if d.dials <= d.FailFirst {
+		if d.Err != nil {
+			return nil, d.Err
+		}
+		return nil, ErrInjected
+	}
+	if d.W == nil {
+		return io.Discard, nil
+	}
+	return d.W, nil
+}
+
+// Dials returns the number of dials so far.
+func (d *FlakySyslogDialer) Dials() int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.dials
+}
+
+// InternalEvent is a diagnostic of clog itself (see clog.SetInternalLogger).
+type InternalEvent struct {
+	Level   int
+	Message string
+}
+
+// InternalRecorder records the diagnostics of clog itself.
+type InternalRecorder struct {
+	lock   sync.Mutex
+	events []InternalEvent
+}
+
+// RecordInternal installs an InternalRecorder as the internal logger of clog, until Restore is called, which
+// restores the default internal logger.
+func RecordInternal() *InternalRecorder {
+	r := &InternalRecorder{}
+	clog.SetInternalLogger(func(level int, msg string) {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		r.events = append(r.events, InternalEvent{level, msg})
+	})
+	return r
+}
+
+// Events returns the diagnostics recorded so far.
+func (r *InternalRecorder) Events() []InternalEvent {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]InternalEvent(nil), r.events...)
+}
+
+// Contains reports whether a diagnostic containing substr was recorded.
+func (r *InternalRecorder) Contains(substr string) bool {
+	for _, e := range r.Events() {
+		if strings.Contains(e.Message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Restore restores the default internal logger of clog.
+func (r *InternalRecorder) Restore() {
+	clog.SetInternalLogger(nil)
+}
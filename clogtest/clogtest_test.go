@@ -0,0 +1,72 @@
+package clogtest
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/teejays/clog"
+)
+
+func TestFailingWriter(t *testing.T) {
+	var buf bytes.Buffer
+	full := errors.New("no space left on device")
+	w := &FailingWriter{FailAfter: 10, Err: full, W: &buf}
+	if n, err := w.Write([]byte("12345678")); n != 8 || err != nil {
+		t.Errorf("got %d, %v, want 8, nil", n, err)
+	}
+	if n, err := w.Write([]byte("90ab")); n != 2 || err != full {
+		t.Errorf("got %d, %v, want a partial write of 2 and the error", n, err)
+	}
+	if n, err := (&FailingWriter{}).Write([]byte("x")); n != 0 || err != ErrInjected {
+		t.Errorf("got %d, %v, want 0, ErrInjected", n, err)
+	}
+	if buf.String() != "1234567890" || w.Written() != 10 {
+		t.Errorf("got %q and %d written, want the first 10 bytes", buf.String(), w.Written())
+	}
+}
+
+func TestSlowWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &SlowWriter{Delay: 20 * time.Millisecond, W: &buf}
+	start := time.Now()
+	if _, err := w.Write([]byte("late")); err != nil || buf.String() != "late" {
+		t.Errorf("got %q, %v", buf.String(), err)
+	}
+	if elapsed := time.Since(start); elapsed < w.Delay {
+		t.Errorf("the write took %s, want at least %s", elapsed, w.Delay)
+	}
+}
+
+// TestFlakySyslogDialer fails the first dial of the syslog, and expects clog to report it, and the Cloggers
+// created afterwards to log to the syslog.
+func TestFlakySyslogDialer(t *testing.T) {
+	t.Cleanup(func() {
+		clog.SetSyslogDialer(nil)
+		clog.SetLogToSyslog(false)
+		clog.ResetForTesting()
+	})
+	clog.ResetForTesting()
+	rec := RecordInternal()
+	defer rec.Restore()
+	var syslog bytes.Buffer
+	d := &FlakySyslogDialer{FailFirst: 1, W: &syslog}
+	clog.SetSyslogDialer(d.Dial)
+	clog.SetLogToSyslog(true)
+
+	clog.NewClogger("Payments", clog.LogLevelInfo)
+	billing := clog.NewClogger("Billing", clog.LogLevelInfo)
+	clog.CaptureOutput(func() { billing.Print("invoice sent") })
+
+	if d.Dials() != 2 {
+		t.Errorf("got %d dials, want 2", d.Dials())
+	}
+	if !rec.Contains("Clogger profile 'Payments' will not log to syslog") || len(rec.Events()) != 1 {
+		t.Errorf("got the diagnostics %+v, want the failed dial", rec.Events())
+	}
+	if got := syslog.String(); !strings.Contains(got, "invoice sent") {
+		t.Errorf("syslog: got %q, want the message", got)
+	}
+}
@@ -0,0 +1,91 @@
+package clog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+)
+
+// closed is set by Close. Messages logged afterwards are written to the standard error.
+var closed atomic.Bool
+
+// closedWarned is set once the warning about logging after Close has been written.
+var closedWarned atomic.Bool
+
+// Close shuts clog down: it flushes and closes the output (unless it is the standard output or error), the
+// destinations (see AddDestination, RouteTag and RuleRouter), and the syslog writers of the Cloggers of the default registry. Messages
+// logged afterwards are written as plain text to the standard error, after a one-time warning. It is safe to
+// call while other goroutines are logging, and calling it more than once does no harm.
+func Close() error {
+	closed.Store(true)
+	var errs []error
+	for _, cl := range defaultRegistry.List() {
+		errs = append(errs, cl.Close())
+	}
+	for _, dest := range allDestinations() {
+		if c, ok := dest.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	outputLock.Lock()
+	errs = append(errs, closeWriter(output))
+	output = os.Stderr
+	outputLock.Unlock()
+	return errors.Join(errs...)
+}
+
+// allDestinations returns the destinations added using AddDestination and RouteTag, and those of the Router,
+// each once.
+func allDestinations() []Destination {
+	dests := append(append([]Destination{}, getDestinations()...), allTagDestinations()...)
+	if r, ok := getRouter().(interface{ Destinations() []Destination }); ok {
+		for _, dest := range r.Destinations() {
+			if !containsDestination(dests, dest) {
+				dests = append(dests, dest)
+			}
+		}
+	}
+	return dests
+}
+
+// Close closes the syslog writer of l. l no longer logs to the syslog afterwards, but still to the output.
+func (l *Clogger) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.sysLogger == nil {
+		return nil
+	}
+	w := l.sysLogger.Writer()
+	l.sysLogger = nil
+	if c, ok := w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// closeWriter flushes w, if it buffers data, and closes it if it is an io.Closer other than the standard output
+// or error.
+func closeWriter(w io.Writer) error {
+	if f, ok := w.(interface{ Flush() error }); ok {
+		if err := f.Flush(); err != nil {
+			return err
+		}
+	}
+	if w == os.Stdout || w == os.Stderr {
+		return nil
+	}
+	if c, ok := w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// writeAfterClose writes e as plain text to the standard error, since clog has been closed.
+func writeAfterClose(e *Entry) {
+	if closedWarned.CompareAndSwap(false, true) {
+		internalf(LogLevelWarning, "logging after Close, messages are written to the standard error")
+	}
+	fmt.Fprintln(os.Stderr, StripDecorations(formatEntry(GetFormatter(), e)))
+}
@@ -0,0 +1,190 @@
+package clog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// ColorMode determines whether decorations (colors) are used, following the --color=auto|always|never
+// convention of command line tools.
+type ColorMode int
+
+const (
+	// ColorAuto uses decorations if the UseDecoration flag is set, the NO_COLOR environment variable is not set,
+	// and the output is a terminal or the FORCE_COLOR environment variable is set. It is the default.
+	ColorAuto ColorMode = iota
+	// ColorAlways always uses decorations, even if the output is not a terminal, e.g. when piping into less -R.
+	ColorAlways
+	// ColorNever never uses decorations.
+	ColorNever
+)
+
+// colorState holds the inputs of the color decision that are expensive to look up for every message.
+type colorState struct {
+	mode             ColorMode
+	noColor          bool
+	forceColor       bool
+	outputIsTerminal bool
+}
+
+var colors colorState = colorState{
+	noColor:          os.Getenv("NO_COLOR") != "",
+	forceColor:       forceColorSet(),
+	outputIsTerminal: isTerminal(os.Stdout),
+}
+var colorsLock sync.RWMutex
+
+// forceColorSet reports whether the FORCE_COLOR environment variable is set to a value other than 0 or false.
+func forceColorSet() bool {
+	v := os.Getenv("FORCE_COLOR")
+	return v != "" && v != "0" && v != "false"
+}
+
+// SetColorMode sets the ColorMode. It also re-reads the NO_COLOR and FORCE_COLOR environment variables.
+func SetColorMode(mode ColorMode) {
+	colorsLock.Lock()
+	defer colorsLock.Unlock()
+	colors.mode = mode
+	colors.noColor = os.Getenv("NO_COLOR") != ""
+	colors.forceColor = forceColorSet()
+}
+
+// GetColorMode returns the ColorMode.
+func GetColorMode() ColorMode {
+	colorsLock.RLock()
+	defer colorsLock.RUnlock()
+	return colors.mode
+}
+
+// ColorsEnabled reports whether messages logged to the output are currently decorated, taking into account the
+// ColorMode, the UseDecoration flag and, if the ColorMode is ColorAuto, the NO_COLOR and FORCE_COLOR
+// environment variables and whether the output is a terminal. It is useful to callers composing their own
+// decorated strings.
+func ColorsEnabled() bool {
+	return colorsEnabled(ToggleInherit)
+}
+
+// colorsEnabled reports whether messages logged to the output by a Clogger with the decoration toggle t are
+// decorated. A Clogger that has decorations turned off is never decorated, and a Clogger that has them turned
+// on ignores the UseDecoration flag.
+func colorsEnabled(t Toggle) bool {
+	colorsLock.RLock()
+	isTerminal := colors.outputIsTerminal
+	colorsLock.RUnlock()
+	return colorsEnabledFor(t, isTerminal)
+}
+
+// entryColorsEnabled is like colorsEnabled, for the Clogger that logged e. If e has decorations that are not
+// shown, it may log a Notice, see NoticeSuppressedColors.
+func entryColorsEnabled(e *Entry) bool {
+	colorsLock.RLock()
+	isTerminal := colors.outputIsTerminal
+	colorsLock.RUnlock()
+	d := decideColors(e.toggles.decoration, isTerminal)
+	if !d.enabled && e.decorationPrefix() != "" {
+		noticeSuppressedColors(d)
+	}
+	return d.enabled
+}
+
+// colorsEnabledFor is like colorsEnabled, for a destination that isTerminal or not.
+func colorsEnabledFor(t Toggle, isTerminal bool) bool {
+	return decideColors(t, isTerminal).enabled
+}
+
+// colorDecision is the outcome of deciding whether to use colors, with the reason for it.
+type colorDecision struct {
+	enabled bool
+	reason  string
+	// implicit is true if colors are disabled although nothing was configured to disable them, i.e. because
+	// of the NO_COLOR environment variable or because the output is not a terminal, with ColorAuto.
+	implicit bool
+}
+
+// decideColors decides whether messages logged by a Clogger with the decoration toggle t to a destination
+// that isTerminal or not are decorated. It is the only place where that decision is made.
+func decideColors(t Toggle, isTerminal bool) colorDecision {
+	colorsLock.RLock()
+	c := colors
+	colorsLock.RUnlock()
+	switch {
+	case consoleBackend:
+		return colorDecision{reason: "the output is the browser console"}
+	case c.mode == ColorNever:
+		return colorDecision{reason: "the ColorMode is ColorNever"}
+	case t == ToggleOff:
+		return colorDecision{reason: "decorations are turned off for the Clogger"}
+	case c.mode == ColorAlways:
+		return colorDecision{enabled: true, reason: "the ColorMode is ColorAlways"}
+	case t == ToggleInherit && !getFlag(&UseDecoration):
+		return colorDecision{reason: "the UseDecoration flag is false"}
+	case c.noColor:
+		return colorDecision{reason: "the NO_COLOR environment variable is set", implicit: true}
+	case c.forceColor:
+		return colorDecision{enabled: true, reason: "the FORCE_COLOR environment variable is set"}
+	case !isTerminal:
+		return colorDecision{reason: "the output is not a terminal", implicit: true}
+	}
+	return colorDecision{enabled: true, reason: "the output is a terminal"}
+}
+
+// ExplainColorState logs, using the "Debug" default clogger, whether messages logged to the output are
+// decorated and why, along with the inputs of that decision: the ColorMode, the UseDecoration flag, the
+// NO_COLOR and FORCE_COLOR environment variables, and whether the output is a terminal, which only matter if the
+// ColorMode is ColorAuto.
+func ExplainColorState() {
+	colorsLock.RLock()
+	c := colors
+	colorsLock.RUnlock()
+	d := decideColors(ToggleInherit, c.outputIsTerminal)
+	state := "disabled"
+	if d.enabled {
+		state = "enabled"
+	}
+	defaultCloggerForLevel(LogLevelDebug).PrintFields("colors are "+state+" because "+d.reason,
+		"color_mode", c.mode.String(),
+		"use_decoration", getFlag(&UseDecoration),
+		"no_color", c.noColor,
+		"force_color", c.forceColor,
+		"output_is_terminal", c.outputIsTerminal,
+		"windows_vt", windowsVTState,
+	)
+}
+
+// String returns the name of the ColorMode, e.g. "auto".
+func (m ColorMode) String() string {
+	switch m {
+	case ColorAuto:
+		return "auto"
+	case ColorAlways:
+		return "always"
+	case ColorNever:
+		return "never"
+	}
+	return fmt.Sprintf("ColorMode(%d)", int(m))
+}
+
+// NoticeSuppressedColors flag determines whether a Notice is logged, once, the first time a decorated message
+// is logged without its decorations because of the NO_COLOR environment variable or because the output is not
+// a terminal, with ColorAuto. It helps finding out why the colors are missing.
+var NoticeSuppressedColors bool = false
+
+var suppressedColorsNoticed atomic.Bool
+
+// noticeSuppressedColors logs a Notice with the reason that colors are disabled, if NoticeSuppressedColors is
+// set and it has not been logged yet.
+func noticeSuppressedColors(d colorDecision) {
+	if !d.implicit || !getFlag(&NoticeSuppressedColors) || !suppressedColorsNoticed.CompareAndSwap(false, true) {
+		return
+	}
+	defaultCloggerForLevel(LogLevelNotice).Print("decorations are not shown because " + d.reason + ", see clog.ExplainColorState")
+}
+
+// setOutputIsTerminal records whether the output is a terminal, so that it is not checked for every message.
+func setOutputIsTerminal(b bool) {
+	colorsLock.Lock()
+	defer colorsLock.Unlock()
+	colors.outputIsTerminal = b
+}
@@ -0,0 +1,230 @@
+package clog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestColorModes(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          ColorMode
+		useDecoration bool
+		env           map[string]string
+		want          string
+	}{
+		// the output of the tests is never a terminal
+		{"auto does not decorate pipes", ColorAuto, true, nil, "[ERROR] disk full\n"},
+		{"auto with FORCE_COLOR", ColorAuto, true, map[string]string{"FORCE_COLOR": "1"}, "\x1b[31m[ERROR] disk full\x1b[0m\n"},
+		{"auto with FORCE_COLOR=0", ColorAuto, true, map[string]string{"FORCE_COLOR": "0"}, "[ERROR] disk full\n"},
+		{"auto with NO_COLOR", ColorAuto, true, map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "1"}, "[ERROR] disk full\n"},
+		{"auto without UseDecoration", ColorAuto, false, map[string]string{"FORCE_COLOR": "1"}, "[ERROR] disk full\n"},
+		{"always", ColorAlways, true, nil, "\x1b[31m[ERROR] disk full\x1b[0m\n"},
+		{"always without UseDecoration", ColorAlways, false, map[string]string{"NO_COLOR": "1"}, "\x1b[31m[ERROR] disk full\x1b[0m\n"},
+		{"never", ColorNever, true, map[string]string{"FORCE_COLOR": "1"}, "[ERROR] disk full\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			t.Setenv("NO_COLOR", "")
+			t.Setenv("FORCE_COLOR", "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			SetPrependTimestamp(false)
+			SetUseDecoration(tt.useDecoration)
+			SetColorMode(tt.mode)
+			var enabled bool
+			got := CaptureOutput(func() {
+				enabled = ColorsEnabled()
+				Error("disk full")
+			})
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if decorated := strings.HasPrefix(tt.want, "\x1b"); enabled != decorated {
+				t.Errorf("ColorsEnabled() = %t, want %t", enabled, decorated)
+			}
+		})
+	}
+}
+
+func TestColorAutoTerminal(t *testing.T) {
+	setupTest(t)
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "")
+	SetColorMode(ColorAuto)
+	if !colorsEnabledFor(ToggleInherit, true) {
+		t.Error("a terminal is not decorated")
+	}
+	if colorsEnabledFor(ToggleInherit, false) {
+		t.Error("a pipe is decorated")
+	}
+	if colorsEnabledFor(ToggleOff, true) {
+		t.Error("a Clogger with decorations turned off is decorated")
+	}
+}
+
+func TestColorNeverStripsHelpers(t *testing.T) {
+	setupTest(t)
+	SetColorMode(ColorNever)
+	got := CaptureOutput(func() {
+		Red("red")
+		PrintWithDecorations("bold", BRIGHT)
+	})
+	if want := "red\nbold\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	SetColorMode(ColorAlways)
+	got = CaptureOutput(func() { Red("red") })
+	if want := "\x1b[31mred\x1b[0m\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestColorize checks that Colorize and the color helpers wrap the message in escape codes only when colors are
+// enabled.
+func TestColorize(t *testing.T) {
+	setupTest(t)
+	SetColorMode(ColorAlways)
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{Colorize("ok", BRIGHT, FG_GREEN), "\x1b[1m\x1b[32mok\x1b[0m"},
+		{Colorize("plain"), "plain"},
+		{SRed("failed"), "\x1b[31mfailed\x1b[0m"},
+		{SYellowf("%d%% done", 50), "\x1b[33m50% done\x1b[0m"},
+		{"status: " + SGreen("up") + ", " + SBluef("%s", "idle"), "status: \x1b[32mup\x1b[0m, \x1b[34midle\x1b[0m"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+	SetColorMode(ColorNever)
+	if got := SRedf("%d failed", 3); got != "3 failed" {
+		t.Errorf("without colors: got %q, want \"3 failed\"", got)
+	}
+}
+
+func TestColorModeString(t *testing.T) {
+	tests := []struct {
+		mode ColorMode
+		want string
+	}{
+		{ColorAuto, "auto"},
+		{ColorAlways, "always"},
+		{ColorNever, "never"},
+		{ColorMode(9), "ColorMode(9)"},
+	}
+	for _, tt := range tests {
+		if got := tt.mode.String(); got != tt.want {
+			t.Errorf("ColorMode(%d).String() = %q, want %q", int(tt.mode), got, tt.want)
+		}
+	}
+}
+
+func TestExplainColorState(t *testing.T) {
+	setupTest(t)
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "")
+	SetPrependTimestamp(false)
+	tests := []struct {
+		mode ColorMode
+		want string
+	}{
+		{ColorAuto, "[DEBUG] colors are disabled because the output is not a terminal color_mode=auto use_decoration=true"},
+		{ColorAlways, "[DEBUG] colors are enabled because the ColorMode is ColorAlways color_mode=always use_decoration=true"},
+	}
+	for _, tt := range tests {
+		SetColorMode(tt.mode)
+		got := CaptureOutput(ExplainColorState)
+		// the decorations, if any, are left out
+		got = strings.NewReplacer("\x1b[90m", "", "\x1b[0m", "").Replace(got)
+		if !strings.HasPrefix(got, tt.want) {
+			t.Errorf("%s: got %q, want it to start with %q", tt.mode, got, tt.want)
+		}
+	}
+}
+
+// TestNoticeSuppressedColors logs decorated messages to a pipe with ColorAuto, which logs the Notice only once.
+func TestNoticeSuppressedColors(t *testing.T) {
+	setupTest(t)
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "")
+	SetPrependTimestamp(false)
+	SetColorMode(ColorAuto)
+	setFlag(&NoticeSuppressedColors, true)
+	suppressedColorsNoticed.Store(false)
+	t.Cleanup(func() { suppressedColorsNoticed.Store(false) })
+	got := captureLines(func() {
+		Error("disk full")
+		Error("disk still full")
+	})
+	want := []string{
+		"[NOTICE] decorations are not shown because the output is not a terminal, see clog.ExplainColorState",
+		"[ERROR] disk full",
+		"[ERROR] disk still full",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestNoticeSuppressedColorsExplicit checks that no Notice is logged if the colors are turned off on purpose, or
+// if the NoticeSuppressedColors flag is not set.
+func TestNoticeSuppressedColorsExplicit(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   ColorMode
+		notice bool
+	}{
+		{"ColorNever", ColorNever, true},
+		{"flag not set", ColorAuto, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			t.Setenv("NO_COLOR", "")
+			t.Setenv("FORCE_COLOR", "")
+			SetPrependTimestamp(false)
+			SetColorMode(tt.mode)
+			setFlag(&NoticeSuppressedColors, tt.notice)
+			suppressedColorsNoticed.Store(false)
+			t.Cleanup(func() { suppressedColorsNoticed.Store(false) })
+			if got := captureLines(func() { Error("disk full") }); len(got) != 1 || got[0] != "[ERROR] disk full" {
+				t.Errorf("got %q, want only the message", got)
+			}
+		})
+	}
+}
+
+func TestConfigureFromEnvColor(t *testing.T) {
+	tests := []struct {
+		value string
+		want  ColorMode
+	}{
+		{"never", ColorNever},
+		{"always", ColorAlways},
+		{"Auto", ColorAuto},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			setupTest(t)
+			SetColorMode(ColorNever)
+			t.Setenv("CLOG_COLOR", tt.value)
+			if err := ConfigureFromEnv(); err != nil {
+				t.Fatal(err)
+			}
+			if got := GetColorMode(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+	setupTest(t)
+	t.Setenv("CLOG_COLOR", "detect")
+	if err := ConfigureFromEnv(); err == nil || !strings.Contains(err.Error(), "expected never, always or auto") {
+		t.Errorf("got %v, want an error listing the color modes", err)
+	}
+}
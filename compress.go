@@ -0,0 +1,56 @@
+package clog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+/********************************************************************************
+* C O M P R E S S I O N
+*********************************************************************************/
+
+// compression is the gzip compression of a WriterDestination (see WithCompression), whose gz compresses to w.
+type compression struct {
+	level int
+	gz    *gzip.Writer
+	w     io.Writer
+}
+
+// WithCompression compresses the stream written by the destination using gzip at level, e.g. gzip.BestSpeed,
+// for instance to ship the entries over a TCP connection: NewDestination(conn, WithCompression(gzip.BestSpeed)).
+// The whole stream is a single gzip member, so the receiver decompresses it as it arrives. Entries are buffered
+// by the compressor until Flush or Close is called, or its buffer fills; Close terminates the stream, so no entry
+// is lost. It cannot be used with NewFileDestination. An invalid level is reported by NewDestination, which
+// falls back to gzip.DefaultCompression.
+func WithCompression(level int) DestOption {
+	return destOptionFunc(func(d *WriterDestination) { d.compression = &compression{level: level} })
+}
+
+// wrapCompression replaces the writer of d by a gzip writer over it, if d is compressed.
+func (d *WriterDestination) wrapCompression() {
+	c := d.compression
+	if c == nil || d.w == nil {
+		return
+	}
+	gz, err := gzip.NewWriterLevel(d.w, c.level)
+	if err != nil {
+		internalf(LogLevelError, "invalid compression level %d, using the default one", c.level)
+		gz = gzip.NewWriter(d.w)
+	}
+	c.gz, c.w, d.w = gz, d.w, gz
+}
+
+// Flush writes the entries buffered by the compressor of d, if it is compressed (see WithCompression), so that
+// the receiver can decompress them. The stream is not terminated.
+func (d *WriterDestination) Flush() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.compression == nil || d.compression.gz == nil {
+		return nil
+	}
+	if err := d.compression.gz.Flush(); err != nil {
+		return fmt.Errorf("%s: could not flush the compressed stream: %w", PACKAGE_NAME, err)
+	}
+	return nil
+}
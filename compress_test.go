@@ -0,0 +1,115 @@
+package clog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// closeRecorder is a bytes.Buffer that records whether it was closed.
+type closeRecorder struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (w *closeRecorder) Close() error {
+	w.closed = true
+	return nil
+}
+
+// decodeEntries decompresses data, and decodes the JSON lines of the entries it holds. It fails if data is not
+// a complete gzip stream, unless partial is set.
+func decodeEntries(t *testing.T, data []byte, partial bool) []map[string]interface{} {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []map[string]interface{}
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil && !(partial && err == io.ErrUnexpectedEOF) {
+		t.Fatalf("the stream is not terminated: %v", err)
+	}
+	return entries
+}
+
+func TestWithCompressionRoundTrip(t *testing.T) {
+	setupTest(t)
+	var w closeRecorder
+	d := NewDestination(&w, WithCompression(gzip.BestSpeed), WithFormatter(&JSONFormatter{}))
+	cl := GetCloggerByName("Info")
+	write := func(from, to int) {
+		for i := from; i < to; i++ {
+			if err := d.Write(cl.newEntry(fmt.Sprintf("request %d served", i), Fields{{"id", i}})); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	write(0, 3)
+	if err := d.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	// the flushed entries can be decompressed before the stream is terminated
+	if got := decodeEntries(t, w.Bytes(), true); len(got) != 3 {
+		t.Errorf("decoded %d entries after Flush, want 3", len(got))
+	}
+
+	write(3, 500)
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !w.closed {
+		t.Error("Close did not close the writer")
+	}
+	entries := decodeEntries(t, w.Bytes(), false)
+	if len(entries) != 500 {
+		t.Fatalf("decoded %d entries, want 500", len(entries))
+	}
+	for i, entry := range entries {
+		if entry["msg"] != fmt.Sprintf("request %d served", i) || entry["id"] != float64(i) || entry["level"] != "info" {
+			t.Errorf("entry %d: got %v", i, entry)
+		}
+	}
+	if w.Len() >= 500*len(`{"time":"2024-03-05T14:07:09.123456789Z","level":"info","logger":"Info","msg":"request 0 served","id":0}`) {
+		t.Errorf("the stream of %d bytes is not compressed", w.Len())
+	}
+}
+
+func TestWithCompressionInvalidLevel(t *testing.T) {
+	setupTest(t)
+	events := recordInternal(t)
+	var w bytes.Buffer
+	d := NewDestination(&w, WithCompression(42))
+	if !events.contains("invalid compression level 42") {
+		t.Error("the invalid level was not reported")
+	}
+	if err := d.Write(GetCloggerByName("Info").newEntry("disk full", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "2024/03/05 14:07:09 [INFO] disk full\n"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+package clog
+
+import (
+	"fmt"
+	"log/syslog"
+	"os"
+	"sort"
+	"strings"
+)
+
+// syslogFacilityNames maps the syslog facilities to their names.
+var syslogFacilityNames = map[syslog.Priority]string{
+	syslog.LOG_KERN:     "kern",
+	syslog.LOG_USER:     "user",
+	syslog.LOG_MAIL:     "mail",
+	syslog.LOG_DAEMON:   "daemon",
+	syslog.LOG_AUTH:     "auth",
+	syslog.LOG_SYSLOG:   "syslog",
+	syslog.LOG_LPR:      "lpr",
+	syslog.LOG_NEWS:     "news",
+	syslog.LOG_UUCP:     "uucp",
+	syslog.LOG_CRON:     "cron",
+	syslog.LOG_AUTHPRIV: "authpriv",
+	syslog.LOG_FTP:      "ftp",
+	syslog.LOG_LOCAL0:   "local0",
+	syslog.LOG_LOCAL1:   "local1",
+	syslog.LOG_LOCAL2:   "local2",
+	syslog.LOG_LOCAL3:   "local3",
+	syslog.LOG_LOCAL4:   "local4",
+	syslog.LOG_LOCAL5:   "local5",
+	syslog.LOG_LOCAL6:   "local6",
+	syslog.LOG_LOCAL7:   "local7",
+}
+
+// LogConfiguration logs a summary of the effective logging configuration using cl, or the "Info" default
+// clogger if cl is nil, e.g. at startup so that it can be verified: the LogLevel, the format, the color state,
+// the syslog, the Cloggers of the default registry with their levels and decorations, and the destinations.
+// Decorations are shown by name, e.g. "bold,red", and the Cloggers and tags are sorted by name, so that the
+// summary is stable.
+func LogConfiguration(cl *Clogger) {
+	if cl == nil {
+		cl = defaultCloggerForLevel(LogLevelInfo)
+	}
+	cl.Print(configurationSummary())
+}
+
+// configurationSummary returns the lines logged by LogConfiguration.
+func configurationSummary() string {
+	ensureDefaults()
+	var b strings.Builder
+	line := func(indent int, formatString string, args ...interface{}) {
+		b.WriteString("\n" + strings.Repeat("  ", indent) + fmt.Sprintf(formatString, args...))
+	}
+	b.WriteString("logging configuration:")
+	line(1, "level: %s", levelName(GetLogLevel()))
+	line(1, "output: %t, format: %T, timestamp format: %q", getFlag(&LogToStdOut), GetFormatter(), TimestampFormat)
+
+	colorsLock.RLock()
+	c := colors
+	colorsLock.RUnlock()
+	d := decideColors(ToggleInherit, c.outputIsTerminal)
+	line(1, "colors: %s, enabled: %t, because %s", c.mode, d.enabled, d.reason)
+
+	cloggers := defaultRegistry.List()
+	connected := 0
+	for _, cl := range cloggers {
+		cl.lock.RLock()
+		if cl.sysLogger != nil {
+			connected++
+		}
+		cl.lock.RUnlock()
+	}
+	line(1, "syslog: %t, facility: %s, tag: %q, address: local, connected: %d/%d cloggers",
+		getFlag(&LogToSyslog), syslogFacilityNames[DEFAULT_LOG_FACILITY], os.Args[0], connected, len(cloggers))
+
+	line(1, "cloggers:")
+	for _, cl := range cloggers {
+		decorations, _ := cl.decorations()
+		line(2, "%s: %s, %s", cl.Name, levelName(cl.GetLogLevel()), decorationNames(decorations))
+	}
+
+	router := getRouter()
+	if _, ok := router.(defaultRouter); !ok {
+		line(1, "router: %T", router)
+	}
+	dests := getDestinations()
+	if len(dests) > 0 {
+		line(1, "destinations:")
+		for _, dest := range dests {
+			line(2, "%s", destinationSummary(dest))
+		}
+	}
+	tagsLock.RLock()
+	tags := make([]string, 0, len(tagRoutes))
+	for tag := range tagRoutes {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	var routes []string
+	for _, tag := range tags {
+		for _, dest := range tagRoutes[tag] {
+			routes = append(routes, fmt.Sprintf("#%s: %s", tag, destinationSummary(dest)))
+		}
+	}
+	tagsLock.RUnlock()
+	if len(routes) > 0 {
+		line(1, "tag routes:")
+		for _, route := range routes {
+			line(2, "%s", route)
+		}
+	}
+	return b.String()
+}
+
+// destinationSummary describes dest, with the minimum level of the entries it writes.
+func destinationSummary(dest Destination) string {
+	threshold := "all levels"
+	if w, ok := dest.(*WebhookDestination); ok {
+		threshold = levelName(w.minLevel) + " and above"
+	}
+	return fmt.Sprintf("%T, %s", dest, threshold)
+}
@@ -0,0 +1,68 @@
+package clog
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogConfiguration(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetLogLevel(LogLevelWarning)
+	NewClogger("Billing", LogLevelNotice, Style{BRIGHT, FG_RED}, UNDERSCORE)
+	webhook := NewWebhookDestination("http://127.0.0.1:1", LogLevelError, time.Hour, 10)
+	t.Cleanup(func() { webhook.Close() })
+	useDestinations(t, NewDestination(io.Discard), webhook)
+	cl := GetCloggerByName("Warning")
+
+	lines := captureLines(func() { LogConfiguration(cl) })
+	for _, want := range []string{
+		"[WARNING] logging configuration:",
+		"  level: warning",
+		"  colors: never, enabled: false, because the ColorMode is ColorNever",
+		"    Billing: notice, bold,red,underline",
+		"    Print: info, none",
+		"    *clog.WriterDestination, all levels",
+		"    *clog.WebhookDestination, error and above",
+	} {
+		if !containsLine(lines, want) {
+			t.Errorf("no line %q in %q", want, lines)
+		}
+	}
+	// the Cloggers are sorted by name
+	if i, j := indexLine(lines, "    Alert: alert, red,bold"), indexLine(lines, "    Warning: warning, yellow"); i < 0 || j < i {
+		t.Errorf("the Cloggers are not sorted: %q", lines)
+	}
+}
+
+func TestDecorationName(t *testing.T) {
+	tests := []struct {
+		d    Decoration
+		want string
+	}{
+		{FG_RED, "red"},
+		{Combine(BRIGHT, BG_BLUE), "bold,bg-blue"},
+		{"\x1b[38;5;196m", "38;5;196"},
+		{"red", `"red"`},
+	}
+	for _, tt := range tests {
+		if got := DecorationName(tt.d); got != tt.want {
+			t.Errorf("DecorationName(%q) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func containsLine(lines []string, line string) bool {
+	return indexLine(lines, line) >= 0
+}
+
+func indexLine(lines []string, line string) int {
+	for i, l := range lines {
+		if strings.TrimRight(l, " ") == line {
+			return i
+		}
+	}
+	return -1
+}
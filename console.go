@@ -0,0 +1,21 @@
+//go:build !(js && wasm)
+
+package clog
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// consoleBackend is true if the entries are written to the browser console, on js/wasm.
+const consoleBackend = false
+
+// dialSyslog connects to the local syslog, with the name of the program as the tag.
+func dialSyslog(priority syslog.Priority) (io.Writer, error) {
+	return syslog.New(priority, "")
+}
+
+// writeEntryLine writes line, which e was formatted as, to the output.
+func writeEntryLine(e *Entry, line string) error {
+	return writeLine(line)
+}
@@ -0,0 +1,46 @@
+//go:build js && wasm
+
+package clog
+
+import (
+	"io"
+	"log/syslog"
+	"os"
+	"syscall/js"
+)
+
+// consoleBackend is true if the entries are written to the browser console, on js/wasm. The decorations are
+// disabled, since the console shows the escape sequences as they are.
+const consoleBackend = true
+
+// dialSyslog returns a writer that discards the messages, since there is no syslog in the browser: LogToSyslog
+// has no effect, unless a dialer is set using SetSyslogDialer.
+func dialSyslog(priority syslog.Priority) (io.Writer, error) {
+	return io.Discard, nil
+}
+
+// writeEntryLine writes line, which e was formatted as, to the browser console, using console.debug, info, warn
+// or error depending on the level of e, so that the console can filter the messages. If the output was set to
+// another writer than the standard output using SetOutput, line is written to it instead.
+func writeEntryLine(e *Entry, line string) error {
+	if GetOutput() != os.Stdout {
+		return writeLine(line)
+	}
+	outputLock.Lock()
+	defer outputLock.Unlock()
+	js.Global().Get("console").Call(consoleMethod(e.Level), line)
+	return nil
+}
+
+// consoleMethod returns the method of the browser console that the messages logged at level are written with.
+func consoleMethod(level int) string {
+	switch {
+	case IsAtLeast(level, LogLevelError):
+		return "error"
+	case IsAtLeast(level, LogLevelWarning):
+		return "warn"
+	case IsAtLeast(level, LogLevelInfo):
+		return "info"
+	}
+	return "debug"
+}
@@ -0,0 +1,99 @@
+//go:build js && wasm
+
+package clog
+
+import (
+	"io"
+	"log/syslog"
+	"os"
+	"strings"
+	"syscall/js"
+	"testing"
+)
+
+// consoleCall is a call to a method of the browser console.
+type consoleCall struct {
+	method string
+	line   string
+}
+
+// fakeConsole replaces the browser console until tb is done, and returns the calls made to it.
+func fakeConsole(tb testing.TB) *[]consoleCall {
+	var calls []consoleCall
+	console := js.Global().Get("Object").New()
+	for _, method := range []string{"debug", "info", "warn", "error"} {
+		method := method
+		fn := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			calls = append(calls, consoleCall{method, args[0].String()})
+			return nil
+		})
+		tb.Cleanup(fn.Release)
+		console.Set(method, fn)
+	}
+	previous := js.Global().Get("console")
+	js.Global().Set("console", console)
+	tb.Cleanup(func() { js.Global().Set("console", previous) })
+	return &calls
+}
+
+// TestConsoleBackend checks that the entries are written to the console method of their level. The js tests run
+// with: GOOS=js GOARCH=wasm go test -exec="$(go env GOROOT)/lib/wasm/go_js_wasm_exec"
+func TestConsoleBackend(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	previous := GetOutput()
+	SetOutput(os.Stdout)
+	t.Cleanup(func() { SetOutput(previous) })
+	// the decorations are never written to the console, whatever the ColorMode
+	SetColorMode(ColorAlways)
+	calls := fakeConsole(t)
+
+	Debug("a")
+	Info("b")
+	Notice("c")
+	Warning("d")
+	Error("e")
+	Crit("f")
+	want := []consoleCall{
+		{"debug", "[DEBUG] a"},
+		{"info", "[INFO] b"},
+		{"info", "[NOTICE] c"},
+		{"warn", "[WARNING] d"},
+		{"error", "[ERROR] e"},
+		{"error", "[CRIT] f"},
+	}
+	if len(*calls) != len(want) {
+		t.Fatalf("got the calls %v, want %v", *calls, want)
+	}
+	for i := range want {
+		if got := (*calls)[i]; got.method != want[i].method || strings.TrimSuffix(got.line, "\n") != want[i].line {
+			t.Errorf("call %d: got %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+// TestConsoleBackendOutput checks that the output set using SetOutput is written to, rather than the console.
+func TestConsoleBackendOutput(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	calls := fakeConsole(t)
+	if got := CaptureOutput(func() { Warning("disk full") }); got != "[WARNING] disk full\n" {
+		t.Errorf("got %q, want \"[WARNING] disk full\\n\"", got)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("wrote to the console: %v", *calls)
+	}
+}
+
+func TestConsoleBackendSyslog(t *testing.T) {
+	w, err := dialSyslog(syslog.LOG_USER | syslog.LOG_INFO)
+	if err != nil || w != io.Discard {
+		t.Errorf("dialSyslog() = %v, %v, want io.Discard", w, err)
+	}
+	setupTest(t)
+	setFlag(&LogToSyslog, true)
+	ResetForTesting()
+	if got := CaptureOutput(func() { Error("disk full") }); got != "2024/03/05 14:07:09 [ERROR] disk full\n" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,126 @@
+package clog
+
+import (
+	"context"
+)
+
+type debugEnabledKey struct{}
+
+// WithDebugEnabled returns a copy of ctx in which debug logging is enabled. Messages logged with the Ctx
+// functions and methods using the returned context, or a context derived from it, are logged regardless of
+// the LogLevel. This allows, for example, debug logs to be turned on for a single request.
+func WithDebugEnabled(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugEnabledKey{}, true)
+}
+
+// debugEnabled reports whether debug logging is enabled in ctx.
+func debugEnabled(ctx context.Context) bool {
+	enabled, _ := ctx.Value(debugEnabledKey{}).(bool)
+	return enabled
+}
+
+type cloggerKey struct{}
+
+// NewContext returns a copy of ctx that carries l, which can be retrieved using FromContext, e.g. to hand a
+// Clogger with the fields of a request down to the code that serves it.
+func NewContext(ctx context.Context, l *Clogger) context.Context {
+	return context.WithValue(ctx, cloggerKey{}, l)
+}
+
+// FromContext returns the Clogger carried by ctx (see NewContext and Middleware), or the "Info" default clogger
+// if ctx carries none. A Clogger put in the context by Middleware is released once the request has been served,
+// so it must not be kept beyond that.
+func FromContext(ctx context.Context) *Clogger {
+	if l, ok := ctx.Value(cloggerKey{}).(*Clogger); ok && l != nil {
+		return l
+	}
+	return defaultCloggerForLevel(LogLevelInfo)
+}
+
+// PrintCtx logs msg like Print. If debug logging is enabled in ctx using WithDebugEnabled, the message
+// is logged regardless of the LogLevel. It is dropped if the log budget of ctx is exhausted (see
+// WithLogBudget).
+func (l *Clogger) PrintCtx(ctx context.Context, msg string) {
+	bypass := debugEnabled(ctx)
+	if !budgetAllows(ctx, l, bypass) || !l.mayLog(l.GetLogLevel(), bypass) {
+		return
+	}
+	l.writeEntry(l.newEntry(msg, nil), bypass)
+}
+
+// PrintCtxf formats the msg with the provided args and logs it like Printf. If debug logging is enabled
+// in ctx using WithDebugEnabled, the message is logged regardless of the LogLevel. It is dropped if the log
+// budget of ctx is exhausted (see WithLogBudget).
+func (l *Clogger) PrintCtxf(ctx context.Context, formatString string, args ...interface{}) {
+	bypass := debugEnabled(ctx)
+	if !budgetAllows(ctx, l, bypass) || !l.mayLog(l.GetLogLevel(), bypass) {
+		return
+	}
+	l.writeEntry(l.newEntry(sprintf(formatString, args...), nil), bypass)
+}
+
+// DebugCtx logs the msg using the "Debug" default clogger, taking the debug logging setting of ctx into account.
+func DebugCtx(ctx context.Context, msg string) {
+	defaultCloggerForLevel(LogLevelDebug).PrintCtx(ctx, msg)
+}
+
+// DebugCtxf formats the message using the provided args, and logs it using the "Debug" default clogger, taking
+// the debug logging setting of ctx into account.
+func DebugCtxf(ctx context.Context, formatString string, args ...interface{}) {
+	defaultCloggerForLevel(LogLevelDebug).PrintCtxf(ctx, formatString, args...)
+}
+
+// InfoCtx logs the msg using the "Info" default clogger, taking the debug logging setting of ctx into account.
+func InfoCtx(ctx context.Context, msg string) {
+	defaultCloggerForLevel(LogLevelInfo).PrintCtx(ctx, msg)
+}
+
+// InfoCtxf formats the message using the provided args, and logs it using the "Info" default clogger, taking
+// the debug logging setting of ctx into account.
+func InfoCtxf(ctx context.Context, formatString string, args ...interface{}) {
+	defaultCloggerForLevel(LogLevelInfo).PrintCtxf(ctx, formatString, args...)
+}
+
+// NoticeCtx logs the msg using the "Notice" default clogger, taking the debug logging setting of ctx into account.
+func NoticeCtx(ctx context.Context, msg string) {
+	defaultCloggerForLevel(LogLevelNotice).PrintCtx(ctx, msg)
+}
+
+// NoticeCtxf formats the message using the provided args, and logs it using the "Notice" default clogger, taking
+// the debug logging setting of ctx into account.
+func NoticeCtxf(ctx context.Context, formatString string, args ...interface{}) {
+	defaultCloggerForLevel(LogLevelNotice).PrintCtxf(ctx, formatString, args...)
+}
+
+// WarningCtx logs the msg using the "Warning" default clogger, taking the debug logging setting of ctx into account.
+func WarningCtx(ctx context.Context, msg string) {
+	defaultCloggerForLevel(LogLevelWarning).PrintCtx(ctx, msg)
+}
+
+// WarningCtxf formats the message using the provided args, and logs it using the "Warning" default clogger, taking
+// the debug logging setting of ctx into account.
+func WarningCtxf(ctx context.Context, formatString string, args ...interface{}) {
+	defaultCloggerForLevel(LogLevelWarning).PrintCtxf(ctx, formatString, args...)
+}
+
+// ErrorCtx logs the msg using the "Error" default clogger, taking the debug logging setting of ctx into account.
+func ErrorCtx(ctx context.Context, msg string) {
+	defaultCloggerForLevel(LogLevelError).PrintCtx(ctx, msg)
+}
+
+// ErrorCtxf formats the message using the provided args, and logs it using the "Error" default clogger, taking
+// the debug logging setting of ctx into account.
+func ErrorCtxf(ctx context.Context, formatString string, args ...interface{}) {
+	defaultCloggerForLevel(LogLevelError).PrintCtxf(ctx, formatString, args...)
+}
+
+// CritCtx logs the msg using the "Crit" default clogger, taking the debug logging setting of ctx into account.
+func CritCtx(ctx context.Context, msg string) {
+	defaultCloggerForLevel(LogLevelCrit).PrintCtx(ctx, msg)
+}
+
+// CritCtxf formats the message using the provided args, and logs it using the "Crit" default clogger, taking
+// the debug logging setting of ctx into account.
+func CritCtxf(ctx context.Context, formatString string, args ...interface{}) {
+	defaultCloggerForLevel(LogLevelCrit).PrintCtxf(ctx, formatString, args...)
+}
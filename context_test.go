@@ -0,0 +1,68 @@
+package clog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestWithDebugEnabledConcurrentRequests serves two requests concurrently, and only the one whose context has
+// debug logging enabled may log Debug messages.
+func TestWithDebugEnabledConcurrentRequests(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetLogLevel(LogLevelInfo)
+
+	const steps = 50
+	serve := func(ctx context.Context, request string, wg *sync.WaitGroup) {
+		defer wg.Done()
+		for i := 0; i < steps; i++ {
+			DebugCtxf(ctx, "%s debug %d", request, i)
+			InfoCtxf(ctx, "%s info %d", request, i)
+			// the functions without a context keep the LogLevel
+			Debugf("%s plain debug %d", request, i)
+		}
+	}
+	lines := captureLines(func() {
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go serve(WithDebugEnabled(context.Background()), "flagged", &wg)
+		go serve(context.Background(), "normal", &wg)
+		wg.Wait()
+	})
+
+	counts := make(map[string]int)
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			t.Fatalf("unexpected line %q", line)
+		}
+		counts[fields[0]+" "+fields[1]+" "+fields[2]]++
+	}
+	want := map[string]int{
+		"[DEBUG] flagged debug": steps,
+		"[INFO] flagged info":   steps,
+		"[INFO] normal info":    steps,
+	}
+	if fmt.Sprint(counts) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", counts, want)
+	}
+}
+
+func TestWithDebugEnabledDerivedContext(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetLogLevel(LogLevelError)
+	type key struct{}
+	ctx := context.WithValue(WithDebugEnabled(context.Background()), key{}, "derived")
+	got := captureLines(func() {
+		DebugCtx(ctx, "shown")
+		GetCloggerByName("Info").PrintCtx(ctx, "shown too")
+		DebugCtx(context.Background(), "hidden")
+	})
+	if want := []string{"[DEBUG] shown", "[INFO] shown too"}; strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
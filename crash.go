@@ -0,0 +1,207 @@
+package clog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/********************************************************************************
+* C R A S H   R E P O R T S
+*********************************************************************************/
+
+// crashReports holds the configuration of the crash reports, and the recent entries that they include.
+var crashReports = struct {
+	sync.Mutex
+	dir       string
+	retention int
+	// recent is a ring of the last maxRecentCrashEntries entries, rendered as JSON, and next is the index of
+	// the oldest one.
+	recent [][]byte
+	next   int
+}{retention: 10}
+
+// crashReportsEnabled is set if the crash report directory is set, so that the entries are only recorded then.
+var crashReportsEnabled atomic.Bool
+
+// maxRecentCrashEntries is the number of recent entries included in a crash report.
+const maxRecentCrashEntries = 100
+
+// crashFormatter renders the recent entries of the crash reports.
+var crashFormatter = &JSONFormatter{}
+
+// SetCrashReportDir enables crash reports, written to dir, which is created if needed. When Fatal, FatalCode,
+// the Fatal methods of a Clogger or Recover logs a message, a file named crash-YYYYMMDD-HHMMSS.json is written
+// in addition to the normal output. It contains the message, the stack trace, the last 100 entries that passed
+// the LogLevel, the global fields and the build info of the binary. Only the most recent reports are kept, see
+// SetCrashReportRetention. An empty dir disables crash reports, which is the default.
+func SetCrashReportDir(dir string) error {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("%s: cannot create the crash report directory: %v", PACKAGE_NAME, err)
+		}
+	}
+	crashReports.Lock()
+	defer crashReports.Unlock()
+	crashReports.dir = dir
+	crashReportsEnabled.Store(dir != "")
+	if dir == "" {
+		crashReports.recent = nil
+		crashReports.next = 0
+	}
+	return nil
+}
+
+// SetCrashReportRetention sets the number of crash reports that are kept in the crash report directory, 10 by
+// default. The oldest ones are deleted when a new one is written.
+func SetCrashReportRetention(n int) {
+	if n < 1 {
+		n = 1
+	}
+	crashReports.Lock()
+	defer crashReports.Unlock()
+	crashReports.retention = n
+}
+
+// recordRecentEntry remembers e for the crash reports, if they are enabled.
+func recordRecentEntry(e *Entry) {
+	if !crashReportsEnabled.Load() {
+		return
+	}
+	crashReports.Lock()
+	defer crashReports.Unlock()
+	if crashReports.dir == "" {
+		return
+	}
+	line := []byte(formatEntry(crashFormatter, e))
+	if len(crashReports.recent) < maxRecentCrashEntries {
+		crashReports.recent = append(crashReports.recent, line)
+		return
+	}
+	crashReports.recent[crashReports.next] = line
+	crashReports.next = (crashReports.next + 1) % maxRecentCrashEntries
+}
+
+// crashReport is the content of a crash report file.
+type crashReport struct {
+	Kind     string                     `json:"kind"`
+	Time     time.Time                  `json:"time"`
+	Message  string                     `json:"message"`
+	ExitCode *int                       `json:"exit_code,omitempty"`
+	Stack    string                     `json:"stack"`
+	Recent   []json.RawMessage          `json:"recent"`
+	Fields   map[string]json.RawMessage `json:"fields"`
+	Build    map[string]interface{}     `json:"build"`
+}
+
+// writeCrashReport writes a crash report of kind, "fatal" or "panic", if they are enabled. exitCode is nil for
+// the panics, which do not terminate the process. Failures are reported to the internal logger.
+func writeCrashReport(kind string, msg string, exitCode *int, stack []byte) {
+	crashReports.Lock()
+	dir, retention := crashReports.dir, crashReports.retention
+	report := crashReport{
+		Kind:     kind,
+		Time:     now(),
+		Message:  msg,
+		ExitCode: exitCode,
+		Stack:    string(stack),
+		Recent:   make([]json.RawMessage, 0, len(crashReports.recent)),
+		Fields:   make(map[string]json.RawMessage),
+		Build:    make(map[string]interface{}),
+	}
+	for i := range crashReports.recent {
+		report.Recent = append(report.Recent, crashReports.recent[(crashReports.next+i)%len(crashReports.recent)])
+	}
+	crashReports.Unlock()
+	if dir == "" {
+		return
+	}
+	for _, f := range getGlobalFields() {
+		report.Fields[f.Key] = marshalJSONValue(f.Value)
+	}
+	kv := buildInfoFields()
+	for i := 0; i+1 < len(kv); i += 2 {
+		report.Build[kv[i].(string)] = kv[i+1]
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		internalf(LogLevelError, "cannot marshal the crash report: %v", err)
+		return
+	}
+	path, err := createCrashReport(dir, report.Time, data)
+	if err != nil {
+		internalf(LogLevelError, "cannot write the crash report: %v", err)
+		return
+	}
+	pruneCrashReports(dir, retention, path)
+}
+
+// createCrashReport writes data to a new crash report file in dir, named after t, and returns its path. A
+// suffix is added to the name if there is already a report for the same second.
+func createCrashReport(dir string, t time.Time, data []byte) (string, error) {
+	base := "crash-" + t.Format("20060102-150405")
+	for i := 0; ; i++ {
+		name := base + ".json"
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d.json", base, i)
+		}
+		path := filepath.Join(dir, name)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return path, err
+	}
+}
+
+// pruneCrashReports deletes the oldest crash reports in dir, so that only retention of them are kept. The
+// report at current is never deleted.
+func pruneCrashReports(dir string, retention int, current string) {
+	paths, err := filepath.Glob(filepath.Join(dir, "crash-*.json"))
+	if err != nil || len(paths) <= retention {
+		return
+	}
+	// the names sort by time, except for the suffixed ones, so the modification time is used
+	mtimes := make(map[string]time.Time, len(paths))
+	for _, p := range paths {
+		if fi, err := os.Stat(p); err == nil {
+			mtimes[p] = fi.ModTime()
+		}
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		if !mtimes[paths[i]].Equal(mtimes[paths[j]]) {
+			return mtimes[paths[i]].Before(mtimes[paths[j]])
+		}
+		return paths[i] < paths[j]
+	})
+	for _, p := range paths[:len(paths)-retention] {
+		if p == current {
+			continue
+		}
+		if err := os.Remove(p); err != nil {
+			internalf(LogLevelWarning, "cannot delete the old crash report %s: %v", p, err)
+		}
+	}
+}
+
+// fatalExit writes a crash report of the fatal message msg, and then terminates the process with code (see
+// exit).
+func fatalExit(code int, msg string) {
+	writeCrashReport("fatal", msg, &code, debug.Stack())
+	exit(code)
+}
@@ -0,0 +1,167 @@
+package clog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useCrashReportDir enables the crash reports in a temporary directory until tb is done, and returns it.
+func useCrashReportDir(tb testing.TB) string {
+	dir := tb.TempDir()
+	if err := SetCrashReportDir(dir); err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		SetCrashReportDir("")
+		SetCrashReportRetention(10)
+	})
+	return dir
+}
+
+// readCrashReport parses the crash report at path into its top-level keys.
+func readCrashReport(t *testing.T, path string) map[string]json.RawMessage {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report map[string]json.RawMessage
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("the crash report does not parse: %v\n%s", err, data)
+	}
+	return report
+}
+
+// crashReportKeys are the keys of a crash report, except exit_code which only the fatal ones have.
+var crashReportKeys = []string{"kind", "time", "message", "stack", "recent", "fields", "build"}
+
+func TestFatalCrashReport(t *testing.T) {
+	setupTest(t)
+	dir := useCrashReportDir(t)
+	codes := stubExit(t)
+	useGlobalFields(t, map[string]interface{}{"service": "billing"})
+	SetLogLevel(LogLevelInfo)
+	CaptureOutput(func() {
+		Debug("below the log level")
+		Info("booting")
+		Fatal("disk full")
+	})
+	if len(*codes) != 1 || (*codes)[0] != 1 {
+		t.Fatalf("ExitFunc was called with %v, want [1]", *codes)
+	}
+
+	report := readCrashReport(t, filepath.Join(dir, "crash-20240305-140709.json"))
+	for _, key := range append(crashReportKeys, "exit_code") {
+		if _, ok := report[key]; !ok {
+			t.Errorf("the crash report has no %q key", key)
+		}
+	}
+	for key, want := range map[string]string{
+		"kind":      `"fatal"`,
+		"time":      `"2024-03-05T14:07:09.123456789Z"`,
+		"message":   `"disk full"`,
+		"exit_code": `1`,
+		"fields":    `{"service":"billing"}`,
+	} {
+		if got := compactJSON(t, report[key]); got != want {
+			t.Errorf("%s: got %s, want %s", key, got, want)
+		}
+	}
+
+	var recent []struct {
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+	}
+	if err := json.Unmarshal(report["recent"], &recent); err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 2 || recent[0].Msg != "booting" || recent[1].Msg != "disk full" || recent[1].Level != "crit" {
+		t.Errorf("got the recent entries %+v, want booting and disk full", recent)
+	}
+	var stack string
+	json.Unmarshal(report["stack"], &stack)
+	if !strings.Contains(stack, "TestFatalCrashReport") {
+		t.Errorf("the stack does not include the test:\n%s", stack)
+	}
+	var build map[string]string
+	if err := json.Unmarshal(report["build"], &build); err != nil {
+		t.Fatal(err)
+	}
+	if build["go"] != runtime.Version() {
+		t.Errorf("got the build info %v, want go=%s", build, runtime.Version())
+	}
+}
+
+func TestPanicCrashReport(t *testing.T) {
+	setupTest(t)
+	dir := useCrashReportDir(t)
+	CaptureOutput(func() {
+		defer Recover()
+		panic("nil map")
+	})
+	report := readCrashReport(t, filepath.Join(dir, "crash-20240305-140709.json"))
+	for _, key := range crashReportKeys {
+		if _, ok := report[key]; !ok {
+			t.Errorf("the crash report has no %q key", key)
+		}
+	}
+	if _, ok := report["exit_code"]; ok {
+		t.Error("the report of a recovered panic has an exit code")
+	}
+	if got := compactJSON(t, report["kind"]) + " " + compactJSON(t, report["message"]); got != `"panic" "nil map"` {
+		t.Errorf("got the kind and message %s", got)
+	}
+}
+
+func TestCrashReportRetention(t *testing.T) {
+	setupTest(t)
+	dir := useCrashReportDir(t)
+	SetCrashReportRetention(2)
+	stubExit(t)
+	clock := testTime
+	SetClock(func() time.Time { return clock })
+	CaptureOutput(func() {
+		for i := 0; i < 4; i++ {
+			Fatal("disk full")
+			clock = clock.Add(time.Second)
+		}
+	})
+	paths, err := filepath.Glob(filepath.Join(dir, "crash-*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range paths {
+		names = append(names, filepath.Base(p))
+	}
+	if got, want := strings.Join(names, " "), "crash-20240305-140711.json crash-20240305-140712.json"; got != want {
+		t.Errorf("got the reports %s, want %s", got, want)
+	}
+}
+
+func TestCrashReportsDisabled(t *testing.T) {
+	setupTest(t)
+	dir := useCrashReportDir(t)
+	SetCrashReportDir("")
+	stubExit(t)
+	CaptureOutput(func() { Fatal("disk full") })
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("wrote %d files with crash reports disabled", len(entries))
+	}
+}
+
+// compactJSON returns data without insignificant spaces.
+func compactJSON(t *testing.T, data json.RawMessage) string {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
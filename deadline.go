@@ -0,0 +1,76 @@
+package clog
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// errWriteTimeout is the error of a write that did not complete within the write deadline of a destination.
+type errWriteTimeout time.Duration
+
+func (err errWriteTimeout) Error() string {
+	return fmt.Sprintf("%s: write did not complete within %s", PACKAGE_NAME, time.Duration(err))
+}
+
+// Timeout reports true, so that the error satisfies net.Error's Timeout.
+func (err errWriteTimeout) Timeout() bool { return true }
+
+var destinationTimeouts uint64
+
+// WithWriteDeadline bounds the time that a write to the writer of the destination may take, so that a stuck
+// writer, e.g. a stalled network peer, cannot block the log calls. If the writer has a SetWriteDeadline method,
+// like a net.Conn, the deadline is set on it. Otherwise, the write runs in a goroutine, and is given up on after
+// d: it may still complete later, but the next writes fail immediately until it does. A timed out write counts
+// as a failed write, so it is written to the fallback, if there is one, and repeated timeouts switch the
+// destination to it (see WithFallback). By default, or if d is 0, writes block until they complete.
+func WithWriteDeadline(d time.Duration) DestOption {
+	return destOptionFunc(func(dest *WriterDestination) { dest.writeDeadline = d })
+}
+
+// writePrimary writes line to the writer of d, within the write deadline of d if it has one. d.lock must be held.
+func (d *WriterDestination) writePrimary(line string) error {
+	if d.writeDeadline <= 0 {
+		_, err := io.WriteString(d.w, line)
+		return err
+	}
+	if conn, ok := d.w.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		// the deadline is in real time, not in that of the clock set using SetClock
+		if err := conn.SetWriteDeadline(time.Now().Add(d.writeDeadline)); err == nil {
+			_, err := io.WriteString(d.w, line)
+			if err, ok := err.(interface{ Timeout() bool }); ok && err.Timeout() {
+				d.timeouts++
+				atomic.AddUint64(&destinationTimeouts, 1)
+			}
+			return err
+		}
+	}
+	if d.pending != nil {
+		select {
+		case <-d.pending:
+			d.pending = nil
+		default:
+			// the write that timed out last is still stuck
+			d.timeouts++
+			atomic.AddUint64(&destinationTimeouts, 1)
+			return errWriteTimeout(d.writeDeadline)
+		}
+	}
+	done := make(chan error, 1)
+	go func(w io.Writer) {
+		_, err := io.WriteString(w, line)
+		done <- err
+	}(d.w)
+	timer := time.NewTimer(d.writeDeadline)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		d.pending = done
+		d.timeouts++
+		atomic.AddUint64(&destinationTimeouts, 1)
+		return errWriteTimeout(d.writeDeadline)
+	}
+}
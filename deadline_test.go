@@ -0,0 +1,112 @@
+package clog
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stalledWriter blocks every write until release is closed, and records the lines written afterwards.
+type stalledWriter struct {
+	release chan struct{}
+	lines   lockedBuffer
+}
+
+func (w *stalledWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return w.lines.Write(p)
+}
+
+// maxWriteDuration bounds the time that a write with a deadline of 100ms may take, with room for slow machines.
+const maxWriteDuration = time.Second
+
+func TestWriteDeadlineStalledWriter(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	w := &stalledWriter{release: make(chan struct{})}
+	var fallback lockedBuffer
+	d := NewDestination(w, WithWriteDeadline(100*time.Millisecond), WithFallback(&fallback),
+		WithFallbackPolicy(2, time.Hour))
+	useDestinations(t, d)
+	before := GetStats().DestinationTimeouts
+
+	for i, msg := range []string{"first", "second", "third"} {
+		start := time.Now()
+		CaptureOutput(func() { Info(msg) })
+		if elapsed := time.Since(start); elapsed > maxWriteDuration {
+			t.Errorf("Info %d returned after %s, with a deadline of 100ms", i, elapsed)
+		}
+	}
+	// the first write timed out, and the next ones failed right away, since it was still stuck
+	stats := d.Stats()
+	if stats.Timeouts != 2 || stats.Failures != 2 || !stats.OnFallback {
+		t.Errorf("got %+v, want 2 timeouts and the fallback", stats)
+	}
+	if got := GetStats().DestinationTimeouts - before; got != 2 {
+		t.Errorf("GetStats().DestinationTimeouts grew by %d, want 2", got)
+	}
+	// the notice of the switch is written to the destination as well, so it lands in the fallback
+	want := "[INFO] first\n[INFO] second\n[CRIT] Clog: destination *clog.stalledWriter failed 2 consecutive writes " +
+		"(last error: Clog: write did not complete within 100ms), switched to the fallback *clog.lockedBuffer\n" +
+		"[INFO] third\n"
+	if got := fallback.String(); got != want {
+		t.Errorf("fallback: got %q, want %q", got, want)
+	}
+
+	// the stuck write completes once the writer is released
+	close(w.release)
+	deadline := time.Now().Add(maxWriteDuration)
+	for w.lines.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := w.lines.String(); got != "[INFO] first\n" {
+		t.Errorf("writer: got %q, want \"[INFO] first\\n\"", got)
+	}
+}
+
+func TestWriteDeadlineWithoutFallback(t *testing.T) {
+	setupTest(t)
+	w := &stalledWriter{release: make(chan struct{})}
+	defer close(w.release)
+	d := NewDestination(w, WithWriteDeadline(100*time.Millisecond))
+	start := time.Now()
+	err := d.Write(GetCloggerByName("Error").newEntry("disk full", nil))
+	if elapsed := time.Since(start); elapsed > maxWriteDuration {
+		t.Errorf("Write returned after %s, with a deadline of 100ms", elapsed)
+	}
+	var timeout interface{ Timeout() bool }
+	if !errors.As(err, &timeout) || !timeout.Timeout() {
+		t.Errorf("got the error %v, want a timeout", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "within 100ms") {
+		t.Errorf("got the error %q", msg)
+	}
+}
+
+// TestWriteDeadlineConn checks that the deadline is set on a net.Conn, in real time even with a fake clock.
+func TestWriteDeadlineConn(t *testing.T) {
+	setupTest(t)
+	// nobody reads from the other end, so the writes block
+	client, server := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+	d := NewDestination(client, WithWriteDeadline(100*time.Millisecond))
+	start := time.Now()
+	err := d.Write(GetCloggerByName("Error").newEntry("disk full", nil))
+	elapsed := time.Since(start)
+	if elapsed > maxWriteDuration {
+		t.Errorf("Write returned after %s, with a deadline of 100ms", elapsed)
+	}
+	if elapsed < 50*time.Millisecond {
+		t.Errorf("Write returned after %s, before the deadline", elapsed)
+	}
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("got the error %v, want a timeout", err)
+	}
+	if stats := d.Stats(); stats.Timeouts != 1 || stats.Failures != 1 {
+		t.Errorf("got %+v, want one timeout", stats)
+	}
+}
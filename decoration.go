@@ -3,6 +3,9 @@ package clog
 import (
 	"fmt"
 	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 /********************************************************************************
@@ -64,3 +67,164 @@ func NewDecoration(sgrCode string) Decoration {
 	}
 	return Decoration(sgrCode)
 }
+
+// escapeSequenceRegex matches the ANSI escape sequences that are used by Decorations.
+var escapeSequenceRegex = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// StripDecorations returns s without any ANSI escape sequences, e.g. to write a decorated message to a file.
+func StripDecorations(s string) string {
+	return escapeSequenceRegex.ReplaceAllString(s, "")
+}
+
+// VisibleLength returns the number of characters of s that are visible in a terminal, i.e. the number of runes
+// in s, excluding any ANSI escape sequences.
+func VisibleLength(s string) int {
+	return utf8.RuneCountInString(StripDecorations(s))
+}
+
+// TruncateVisible cuts s to at most n characters that are visible in a terminal, counting the ANSI escape
+// sequences and the combining marks as zero-width, and ending with ellipsis, which counts towards n, if any
+// character was cut. It never splits an escape sequence, a multi-byte rune, or a rune from its combining marks.
+// If a decoration is active where s is cut, the result ends with RESET.
+func TruncateVisible(s string, n int, ellipsis string) string {
+	if n < 0 {
+		n = 0
+	}
+	if visibleRunes(s) <= n {
+		return s
+	}
+	keep := n - visibleRunes(ellipsis)
+	if keep < 0 {
+		// the ellipsis does not fit either
+		return TruncateVisible(ellipsis, n, "")
+	}
+
+	var b strings.Builder
+	active := false
+	count := 0
+	for i := 0; i < len(s); {
+		if loc := escapeSequenceRegex.FindStringIndex(s[i:]); loc != nil && loc[0] == 0 {
+			if count == keep {
+				// the sequences after the last character that is kept would only decorate the ellipsis
+				break
+			}
+			seq := s[i : i+loc[1]]
+			if m := sgrRegex.FindStringSubmatch(seq); m != nil {
+				active = !isResetSGR(m[1])
+			}
+			b.WriteString(seq)
+			i += loc[1]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !isZeroWidth(r) {
+			if count == keep {
+				break
+			}
+			count++
+		}
+		b.WriteString(s[i : i+size])
+		i += size
+	}
+	b.WriteString(ellipsis)
+	if active {
+		b.WriteString(string(RESET))
+	}
+	return b.String()
+}
+
+// visibleRunes returns the number of runes of s that take up a column in a terminal.
+func visibleRunes(s string) int {
+	count := 0
+	for _, r := range StripDecorations(s) {
+		if !isZeroWidth(r) {
+			count++
+		}
+	}
+	return count
+}
+
+// isZeroWidth reports whether r is rendered on the column of the rune before it, like a combining mark.
+func isZeroWidth(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me) || r == '\u200d' || (r >= '\ufe00' && r <= '\ufe0f')
+}
+
+// isResetSGR reports whether the parameters of an SGR sequence only reset the decorations.
+func isResetSGR(params string) bool {
+	for _, code := range sgrCodes(params) {
+		if code != "0" {
+			return false
+		}
+	}
+	return true
+}
+
+// sgrRegex matches an SGR escape sequence and captures its parameters.
+var sgrRegex = regexp.MustCompile(`^\x1b\[([0-9;]*)m$`)
+
+// Combine merges the decorations into a single SGR escape sequence, e.g. Combine(BRIGHT, FG_RED) returns
+// "\x1b[1;31m" rather than "\x1b[1m\x1b[31m". Repeated codes are only kept once. It panics if one of the
+// decorations is not an SGR sequence, like NewDecoration.
+func Combine(decorations ...Decoration) Decoration {
+	var codes []string
+	seen := make(map[string]bool)
+	for _, d := range decorations {
+		m := sgrRegex.FindStringSubmatch(string(d))
+		if m == nil {
+			panic(fmt.Sprintf("%s: invalid sgr code '%s' provided", PACKAGE_NAME, d))
+		}
+		for _, code := range sgrCodes(m[1]) {
+			if !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+	if len(codes) == 0 {
+		return ""
+	}
+	return Decoration("\x1b[" + strings.Join(codes, ";") + "m")
+}
+
+// sgrCodes splits the parameters of an SGR sequence into codes, keeping the extended colors, e.g. 38;5;196,
+// together. Empty parameters mean 0.
+func sgrCodes(params string) []string {
+	parts := strings.Split(params, ";")
+	var codes []string
+	for i := 0; i < len(parts); i++ {
+		code := parts[i]
+		if code == "" {
+			code = "0"
+		}
+		if (code == "38" || code == "48" || code == "58") && i+1 < len(parts) {
+			n := 0
+			switch parts[i+1] {
+			case "5":
+				n = 2
+			case "2":
+				n = 4
+			}
+			if i+n < len(parts) {
+				code = strings.Join(parts[i:i+n+1], ";")
+				i += n
+			}
+		}
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// Style is a composite of decorations that is rendered as a single SGR sequence (see Combine). Like a
+// Decoration, it can be passed to NewClogger.
+type Style []Decoration
+
+// String returns the combined escape sequence of s.
+func (s Style) String() string {
+	return string(Combine(s...))
+}
+
+func (s Style) applyTo(cl *Clogger) {
+	if d := Combine(s...); d != "" {
+		cl.Decorations = append(cl.Decorations, d)
+	}
+}
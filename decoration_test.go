@@ -0,0 +1,189 @@
+package clog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// fourDecorations are the decorations of the Clogger in the decoration caching tests and benchmarks.
+var fourDecorations = []Decoration{BG_BLUE, FG_WHITE, BRIGHT, UNDERSCORE}
+
+// fourDecorationOptions are fourDecorations as options of NewClogger.
+var fourDecorationOptions = []CloggerOption{BG_BLUE, FG_WHITE, BRIGHT, UNDERSCORE}
+
+// baselineDecorate is decorate as it was before the joined escape sequences were cached, which the output must
+// stay byte-identical to.
+func baselineDecorate(msg string, decorations ...Decoration) string {
+	var decorationsCode string
+	for _, d := range decorations {
+		decorationsCode += string(d)
+	}
+	return fmt.Sprintf("%s%s%s", decorationsCode, msg, RESET)
+}
+
+func TestCachedDecorationsByteIdentical(t *testing.T) {
+	setupTest(t)
+	SetColorMode(ColorAlways)
+	cl := NewClogger("Deploy", LogLevelInfo, fourDecorationOptions...)
+	timestamp := testTime.Format(TimestampFormat)
+
+	check := func(step string, decorations ...Decoration) {
+		t.Helper()
+		for i := 0; i < 2; i++ {
+			got := CaptureOutput(func() { cl.Printf("step %d", i) })
+			want := timestamp + " " + baselineDecorate(fmt.Sprintf("[DEPLOY] step %d", i), decorations...) + "\n"
+			if got != want {
+				t.Errorf("%s: got %q, want %q", step, got, want)
+			}
+		}
+	}
+	check("initial", fourDecorations...)
+	cl.AddDecoration(BLINK)
+	check("after AddDecoration", BG_BLUE, FG_WHITE, BRIGHT, UNDERSCORE, BLINK)
+	cl.RemoveDecoration(BRIGHT)
+	check("after RemoveDecoration", BG_BLUE, FG_WHITE, UNDERSCORE, BLINK)
+	cl.SetDecorations(FG_RED)
+	check("after SetDecorations", FG_RED)
+	cl.SetDecorations()
+	check("without decorations")
+}
+
+func TestPrintWithDecorationsByteIdentical(t *testing.T) {
+	setupTest(t)
+	SetColorMode(ColorAlways)
+	got := CaptureOutput(func() { PrintWithDecorations("banner", fourDecorations...) })
+	if want := baselineDecorate("banner", fourDecorations...) + "\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTruncateVisible(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		n        int
+		ellipsis string
+		want     string
+	}{
+		{"shorter", "hello", 10, "…", "hello"},
+		{"exact fit", "hello", 5, "…", "hello"},
+		{"cut", "hello world", 5, "…", "hell…"},
+		{"cut without ellipsis", "hello world", 5, "", "hello"},
+		{"multi-character ellipsis", "hello world", 8, "...", "hello..."},
+		{"zero", "hello", 0, "…", ""},
+		{"negative", "hello", -1, "…", ""},
+		{"ellipsis does not fit", "hello", 2, "...", ".."},
+		{"empty", "", 3, "…", ""},
+
+		{"decorated fit", "\x1b[31mhello\x1b[0m", 5, "…", "\x1b[31mhello\x1b[0m"},
+		{"decorated cut", "\x1b[31mhello world\x1b[0m", 5, "…", "\x1b[31mhell…\x1b[0m"},
+		{"cut after the reset", "\x1b[31mhello\x1b[0m world", 8, "…", "\x1b[31mhello\x1b[0m w…"},
+		{"combined parameters", "\x1b[1;31mbold red text", 4, "", "\x1b[1;31mbold\x1b[0m"},
+		{"sequence at the cut", "ab\x1b[31mcd", 2, "", "ab"},
+		{"sequence at the cut while active", "\x1b[31mab\x1b[1mcd", 2, "", "\x1b[31mab\x1b[0m"},
+		{"decorated again", "\x1b[31ma\x1b[0mb\x1b[32mcdef", 3, "", "\x1b[31ma\x1b[0mb\x1b[32mc\x1b[0m"},
+		{"short reset", "\x1b[31mab\x1b[mcdef", 3, "", "\x1b[31mab\x1b[mc"},
+		{"not an SGR sequence", "\x1b[2Kabcdef", 3, "", "\x1b[2Kabc"},
+		{"decorated ellipsis", "hello world", 5, "\x1b[2m…\x1b[0m", "hell\x1b[2m…\x1b[0m"},
+		{"only sequences", "\x1b[31m\x1b[0m", 0, "…", "\x1b[31m\x1b[0m"},
+
+		{"multi-byte", "héllo wörld", 6, "…", "héllo…"},
+		{"multi-byte at the cut", "wörld", 2, "", "wö"},
+		{"combining marks", "e\u0301e\u0301e\u0301", 2, "", "e\u0301e\u0301"},
+		{"combining marks before the ellipsis", "e\u0301e\u0301e\u0301", 2, "…", "e\u0301…"},
+		{"combining marks fit", "e\u0301e\u0301", 2, "…", "e\u0301e\u0301"},
+		{"emoji", "👍👍👍", 2, "", "👍👍"},
+		{"emoji before the ellipsis", "👍👍👍", 2, "…", "👍…"},
+		{"zero width joiner", "👨\u200d👩\u200d👧 family", 3, "", "👨\u200d👩\u200d👧"},
+		{"variation selector", "❤\ufe0f❤\ufe0f", 1, "", "❤\ufe0f"},
+		{"decorated emoji", "\x1b[32m✔\ufe0f done\x1b[0m", 3, "…", "\x1b[32m✔\ufe0f …\x1b[0m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateVisible(tt.s, tt.n, tt.ellipsis)
+			if got != tt.want {
+				t.Errorf("TruncateVisible(%q, %d, %q) = %q, want %q", tt.s, tt.n, tt.ellipsis, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("%q is not valid UTF-8", got)
+			}
+			if strings.Contains(StripDecorations(got), "\x1b") {
+				t.Errorf("%q has a broken escape sequence", got)
+			}
+			if n := visibleRunes(got); n > tt.n && n > 0 {
+				t.Errorf("%q has %d visible characters, more than %d", got, n, tt.n)
+			}
+			if cut := got != tt.s; cut && tt.ellipsis != "" && tt.n >= visibleRunes(tt.ellipsis) &&
+				!strings.Contains(got, tt.ellipsis) {
+				t.Errorf("%q was cut without the ellipsis", got)
+			}
+		})
+	}
+}
+
+// BenchmarkDecorateBaseline measures decorate as it was before the escape sequences were cached, for comparison
+// with BenchmarkDecorateCached.
+func BenchmarkDecorateBaseline(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		baselineDecorate("[DEPLOY] request served", fourDecorations...)
+	}
+}
+
+func BenchmarkDecorateCached(b *testing.B) {
+	setupBenchmark(b)
+	cl := NewClogger("Deploy", LogLevelInfo, fourDecorationOptions...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, code := cl.decorations()
+		decorateWithCode("[DEPLOY] request served", code)
+	}
+}
+
+func BenchmarkPrintFourDecorations(b *testing.B) {
+	setupBenchmark(b)
+	SetColorMode(ColorAlways)
+	cl := NewClogger("Deploy", LogLevelInfo, fourDecorationOptions...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cl.Print("request served")
+	}
+}
+
+func TestCombine(t *testing.T) {
+	tests := []struct {
+		decorations []Decoration
+		want        Decoration
+	}{
+		{[]Decoration{BRIGHT, FG_RED}, "\x1b[1;31m"},
+		{[]Decoration{BRIGHT, FG_RED, BRIGHT}, "\x1b[1;31m"},
+		{[]Decoration{"\x1b[38;5;196;1m", "\x1b[48;2;0;0;255m"}, "\x1b[38;5;196;1;48;2;0;0;255m"},
+		{[]Decoration{"\x1b[m"}, "\x1b[0m"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := Combine(tt.decorations...); got != tt.want {
+			t.Errorf("Combine(%q) = %q, want %q", tt.decorations, got, tt.want)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Combine did not panic on an invalid decoration")
+		}
+	}()
+	Combine(BRIGHT, "red")
+}
+
+// TestStyle checks that a Style decorates the messages of a Clogger with a single escape sequence.
+func TestStyle(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetColorMode(ColorAlways)
+	cl := NewClogger("Alarm", LogLevelError, Style{BRIGHT, FG_WHITE, BG_RED})
+	got := CaptureOutput(func() { cl.Print("disk full") })
+	if want := "\x1b[1;37;41m[ALARM] disk full\x1b[0m\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,185 @@
+package clog
+
+import (
+	"fmt"
+)
+
+/********************************************************************************
+* D E F E R R E D
+*********************************************************************************/
+
+// DefaultDeferredMaxBytes is the number of bytes of entries that a Deferred holds by default.
+const DefaultDeferredMaxBytes = 256 << 10
+
+// Deferred logs speculatively, e.g. for a request: the messages below the Warning level are held until it is
+// known whether they are needed, and then either committed, i.e. logged with their original time, or discarded.
+// The messages at the Warning level and above are logged right away. The held entries are capped in bytes: the
+// oldest ones are dropped first, and a message saying how many were dropped is logged before the others when they
+// are committed. The fields of the held messages are rendered when they are logged, so that changing a slice or a
+// map afterwards does not change what is committed.
+//
+// A Deferred is meant to be used by a single goroutine, e.g. the one handling a request: it is not safe for
+// concurrent use, and sharing it between goroutines is not supported.
+type Deferred struct {
+	base     *Clogger
+	maxBytes int
+	entries  []*Entry
+	size     int
+	// dropped are the levels of the entries dropped to stay within maxBytes.
+	dropped []int
+}
+
+// NewDeferredLogger returns a Deferred that logs using base, with the level of each message, or using the
+// default clogger of the level of each message if base is nil. It holds up to DefaultDeferredMaxBytes of entries
+// (see SetMaxBytes).
+func NewDeferredLogger(base *Clogger) *Deferred {
+	return &Deferred{base: base, maxBytes: DefaultDeferredMaxBytes}
+}
+
+// SetMaxBytes sets the number of bytes of entries that d holds, dropping the oldest ones if needed.
+func (d *Deferred) SetMaxBytes(n int) {
+	d.maxBytes = n
+	d.evict()
+}
+
+// clogger returns the Clogger that d logs the messages at level with.
+func (d *Deferred) clogger(level int) *Clogger {
+	if d.base != nil {
+		return d.base
+	}
+	return defaultCloggerForLevel(level)
+}
+
+// log logs msg at level right away if it is at the Warning level or above, and holds it otherwise.
+func (d *Deferred) log(level int, msg string) {
+	cl := d.clogger(level)
+	e := cl.newEntry(msg, nil)
+	e.Level = level
+	if IsAtLeast(level, LogLevelWarning) {
+		cl.printEntry(e)
+		return
+	}
+	// the entry is written later, so its fields are rendered now, in case the caller changes them in between
+	e.Fields = e.Fields.snapshot()
+	d.entries = append(d.entries, e)
+	d.size += deferredSize(e)
+	d.evict()
+}
+
+// deferredSize approximates the bytes taken by e.
+func deferredSize(e *Entry) int {
+	return memoryEntryOverhead + len(e.Message)
+}
+
+// evict drops the oldest entries until the entries take at most maxBytes.
+func (d *Deferred) evict() {
+	n := 0
+	for n < len(d.entries) && d.size > d.maxBytes {
+		d.size -= deferredSize(d.entries[n])
+		d.dropped = append(d.dropped, d.entries[n].Level)
+		n++
+	}
+	if n > 0 {
+		clear(d.entries[:n])
+		d.entries = d.entries[n:]
+	}
+}
+
+// CommitAll logs all the held entries, with their original time, regardless of the LogLevel, and forgets them.
+func (d *Deferred) CommitAll() {
+	d.CommitAtOrAbove(MinLevel)
+}
+
+// CommitAtOrAbove logs the held entries at level or above, with their original time, regardless of the LogLevel,
+// and forgets all of them.
+func (d *Deferred) CommitAtOrAbove(level int) {
+	dropped, droppedLevel := 0, level
+	for _, l := range d.dropped {
+		if IsAtLeast(l, level) {
+			dropped++
+			if IsAtLeast(l, droppedLevel) {
+				droppedLevel = l
+			}
+		}
+	}
+	if dropped > 0 {
+		cl := d.clogger(droppedLevel)
+		e := cl.newEntry(fmt.Sprintf("%d earlier deferred messages were dropped to stay within %d bytes", dropped, d.maxBytes), nil)
+		e.Level = droppedLevel
+		if len(d.entries) > 0 {
+			e.Time = d.entries[0].Time
+		}
+		cl.writeEntry(e, true)
+	}
+	for _, e := range d.entries {
+		if IsAtLeast(e.Level, level) {
+			d.clogger(e.Level).writeEntry(e, true)
+		}
+	}
+	d.Discard()
+}
+
+// Discard forgets all the held entries, without logging them.
+func (d *Deferred) Discard() {
+	d.entries, d.size, d.dropped = nil, 0, nil
+}
+
+// Debug holds msg at the Debug level.
+func (d *Deferred) Debug(msg string) {
+	d.log(LogLevelDebug, msg)
+}
+
+// Debugf formats the message using the provided args, and holds it at the Debug level.
+func (d *Deferred) Debugf(formatString string, args ...interface{}) {
+	d.log(LogLevelDebug, sprintf(formatString, args...))
+}
+
+// Info holds msg at the Info level.
+func (d *Deferred) Info(msg string) {
+	d.log(LogLevelInfo, msg)
+}
+
+// Infof formats the message using the provided args, and holds it at the Info level.
+func (d *Deferred) Infof(formatString string, args ...interface{}) {
+	d.log(LogLevelInfo, sprintf(formatString, args...))
+}
+
+// Notice holds msg at the Notice level.
+func (d *Deferred) Notice(msg string) {
+	d.log(LogLevelNotice, msg)
+}
+
+// Noticef formats the message using the provided args, and holds it at the Notice level.
+func (d *Deferred) Noticef(formatString string, args ...interface{}) {
+	d.log(LogLevelNotice, sprintf(formatString, args...))
+}
+
+// Warning logs msg at the Warning level right away.
+func (d *Deferred) Warning(msg string) {
+	d.log(LogLevelWarning, msg)
+}
+
+// Warningf formats the message using the provided args, and logs it at the Warning level right away.
+func (d *Deferred) Warningf(formatString string, args ...interface{}) {
+	d.log(LogLevelWarning, sprintf(formatString, args...))
+}
+
+// Error logs msg at the Error level right away.
+func (d *Deferred) Error(msg string) {
+	d.log(LogLevelError, msg)
+}
+
+// Errorf formats the message using the provided args, and logs it at the Error level right away.
+func (d *Deferred) Errorf(formatString string, args ...interface{}) {
+	d.log(LogLevelError, sprintf(formatString, args...))
+}
+
+// Crit logs msg at the Crit level right away.
+func (d *Deferred) Crit(msg string) {
+	d.log(LogLevelCrit, msg)
+}
+
+// Critf formats the message using the provided args, and logs it at the Crit level right away.
+func (d *Deferred) Critf(formatString string, args ...interface{}) {
+	d.log(LogLevelCrit, sprintf(formatString, args...))
+}
@@ -0,0 +1,84 @@
+package clog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDeferredCommitsHeldEntries(t *testing.T) {
+	setupTest(t)
+	d := NewDeferredLogger(nil)
+	got := captureLines(func() {
+		d.Debug("held")
+		d.Warning("right away")
+		d.Info("held too")
+	})
+	if want := []string{"2024/03/05 14:07:09 [WARNING] right away"}; strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("before the commit, got %q, want %q", got, want)
+	}
+	got = captureLines(d.CommitAll)
+	want := []string{"2024/03/05 14:07:09 [DEBUG] held", "2024/03/05 14:07:09 [INFO] held too"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := captureLines(d.CommitAll); len(got) != 0 {
+		t.Errorf("committed again: %q", got)
+	}
+}
+
+// TestDeferredRendersFieldsAtCallTime changes the fields after the messages are held, which must not change how
+// they are rendered when they are committed, with any formatter.
+func TestDeferredRendersFieldsAtCallTime(t *testing.T) {
+	tests := []struct {
+		name      string
+		formatter Formatter
+		want      string
+	}{
+		{"text", &TextFormatter{}, `2024/03/05 14:07:09 [INFO] held ids="[1 2]" user=map[name:ann] n=3` + "\n"},
+		{"flattened text", &TextFormatter{Flatten: true}, `2024/03/05 14:07:09 [INFO] held ids.0=1 ids.1=2 user.name=ann n=3` + "\n"},
+		{"json", &JSONFormatter{}, `{"time":"2024-03-05T14:07:09.123456789Z","level":"info","logger":"Info","msg":"held","ids":[1,2],"user":{"name":"ann"},"n":3}` + "\n"},
+		{"flattened logfmt", &LogfmtFormatter{Flatten: true}, `time=2024-03-05T14:07:09.123456789Z level=info logger=Info msg=held ids.0=1 ids.1=2 user.name=ann n=3` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			SetFormatter(tt.formatter)
+			ids := []int{1, 2}
+			user := map[string]string{"name": "ann"}
+			n := 3
+			base := GetCloggerByName("Info").WithFields("ids", ids, "user", user, "n", n)
+			direct := CaptureOutput(func() { base.Print("held") })
+
+			d := NewDeferredLogger(base)
+			d.Info("held")
+			ids[0], user["name"], n = 9, "bob", 9
+			got := CaptureOutput(d.CommitAll)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if got != direct {
+				t.Errorf("committed %q, but logged %q right away", got, direct)
+			}
+		})
+	}
+}
+
+func TestDeferredDropsOldestEntries(t *testing.T) {
+	setupTest(t)
+	d := NewDeferredLogger(nil)
+	maxBytes := 2 * (memoryEntryOverhead + len("message 0"))
+	d.SetMaxBytes(maxBytes)
+	for i := 0; i < 4; i++ {
+		d.Infof("message %d", i)
+	}
+	got := captureLines(d.CommitAll)
+	want := []string{
+		fmt.Sprintf("2024/03/05 14:07:09 [INFO] 2 earlier deferred messages were dropped to stay within %d bytes", maxBytes),
+		"2024/03/05 14:07:09 [INFO] message 2",
+		"2024/03/05 14:07:09 [INFO] message 3",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
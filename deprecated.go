@@ -0,0 +1,57 @@
+package clog
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+)
+
+// DeprecationCloggerName is the name of the Clogger that Deprecated logs with. It is created at LogLevelWarning
+// the first time it is needed, unless a Clogger with this name has been registered already.
+const DeprecationCloggerName = "Deprecation"
+
+// maxDeprecatedSites is the maximum number of call sites remembered by Deprecated. Above it, the least recently
+// seen ones are forgotten, so their messages may be logged again.
+const maxDeprecatedSites = 4096
+
+// deprecatedSites remembers the features and call sites that Deprecated logged a message for. They are kept
+// apart from the keys of the Once functions, so that ResetOnce and ResetAllOnce do not log them again, and so
+// that many Once keys do not push them out.
+var deprecatedSites = newKeySet(maxDeprecatedSites)
+
+// Deprecated logs a Warning that feature is deprecated and will be removed in removal, with the call site of the
+// function that calls Deprecated, e.g. "DEPRECATED: OldAPI will be removed in v3 (called from svc/foo/bar.go:42)".
+// It is meant to be called at the start of the deprecated function. The message is logged at most once per call
+// site, using the "Deprecation" Clogger, so that these messages can be routed using
+// MatchLogger, or silenced by lowering the LogLevel of that Clogger below the global LogLevel.
+func Deprecated(feature string, removal string) {
+	site := "unknown"
+	// skip Deprecated and the deprecated function
+	if pc, file, line, ok := runtime.Caller(2); ok {
+		dir := "."
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			dir = packageName(fn.Name())
+		}
+		site = fmt.Sprintf("%s/%s:%d", dir, filepath.Base(file), line)
+	}
+	if !deprecatedSites.add(feature + " @ " + site) {
+		return
+	}
+	deprecationClogger().Printf("DEPRECATED: %s will be removed in %s (called from %s)", feature, removal, site)
+}
+
+// deprecationClogger returns the Clogger named DeprecationCloggerName, creating it if needed.
+func deprecationClogger() *Clogger {
+	if cl, ok := DefaultRegistry().Get(DeprecationCloggerName); ok {
+		return cl
+	}
+	cl, err := newClogger(DeprecationCloggerName, LogLevelWarning, FG_YELLOW)
+	if err != nil {
+		panic(err)
+	}
+	if err := registerClogger(cl); err != nil {
+		// registered concurrently
+		cl, _ = DefaultRegistry().Get(DeprecationCloggerName)
+	}
+	return cl
+}
@@ -0,0 +1,71 @@
+package clog
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// oldAPI is a deprecated function, which calls Deprecated.
+func oldAPI() {
+	Deprecated("OldAPI", "v3")
+}
+
+func TestDeprecated(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	deprecatedSites.clear()
+	t.Cleanup(deprecatedSites.clear)
+
+	var lines []int
+	got := captureLines(func() {
+		for i := 0; i < 3; i++ {
+			_, _, line, _ := runtime.Caller(0)
+			lines = append(lines, line+2)
+			oldAPI()
+		}
+		_, _, line, _ := runtime.Caller(0)
+		lines = append(lines, line+2)
+		oldAPI()
+	})
+	want := []string{
+		fmt.Sprintf("[DEPRECATION] DEPRECATED: OldAPI will be removed in v3 (called from github.com/teejays/clog/deprecated_test.go:%d)", lines[0]),
+		fmt.Sprintf("[DEPRECATION] DEPRECATED: OldAPI will be removed in v3 (called from github.com/teejays/clog/deprecated_test.go:%d)", lines[3]),
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// the call sites are not forgotten with the keys of the Once functions, nor pushed out by them
+	ResetAllOnce()
+	for i := 0; i <= maxOnceKeys; i++ {
+		firstOnce(fmt.Sprint("key ", i))
+	}
+	t.Cleanup(ResetAllOnce)
+	if got := captureLines(oldAPI); len(got) != 1 {
+		t.Fatalf("got %q, want a message for the new call site", got)
+	}
+	if got := captureLines(func() {
+		for i := 0; i < 2; i++ {
+			oldAPI()
+		}
+	}); len(got) != 1 {
+		t.Errorf("got %q, want a single message", got)
+	}
+}
+
+func TestDeprecatedSitesBounded(t *testing.T) {
+	deprecatedSites.clear()
+	t.Cleanup(deprecatedSites.clear)
+	for i := 0; i < maxDeprecatedSites+10; i++ {
+		deprecatedSites.add(fmt.Sprint("site ", i))
+	}
+	if n := deprecatedSites.len(); n != maxDeprecatedSites {
+		t.Errorf("%d call sites are remembered, want %d", n, maxDeprecatedSites)
+	}
+	// the oldest ones were forgotten
+	if !deprecatedSites.add("site 0") || deprecatedSites.add(fmt.Sprint("site ", maxDeprecatedSites+9)) {
+		t.Error("the least recently seen call sites were not the ones forgotten")
+	}
+}
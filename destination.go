@@ -0,0 +1,305 @@
+package clog
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/********************************************************************************
+* D E S T I N A T I O N
+*********************************************************************************/
+
+// Destination receives the entries that are routed to it, e.g. using RouteTag, in addition to the output
+// and the syslog. Write must be safe for concurrent use. Write is called synchronously by the log call, and
+// the values of the fields of the entry are rendered when they are written, so a Destination that defers the
+// rendering of an entry, e.g. to a batch, must render the values in Write: the caller may mutate them as
+// soon as the log call returns.
+type Destination interface {
+	Write(e *Entry) error
+}
+
+var destinations []Destination
+var destinationsLock sync.RWMutex
+
+// AddDestination adds dest to the destinations that every message is written to, in addition to the output
+// and the syslog, if it passes the LogLevel.
+func AddDestination(dest Destination) {
+	destinationsLock.Lock()
+	defer destinationsLock.Unlock()
+	destinations = append(destinations, dest)
+}
+
+// getDestinations returns the destinations added using AddDestination.
+func getDestinations() []Destination {
+	destinationsLock.RLock()
+	defer destinationsLock.RUnlock()
+	return destinations
+}
+
+// WriterDestination is a Destination that writes the entries as lines to an io.Writer, formatted using its
+// own Formatter or the global one, and optionally falls back to another io.Writer when writes fail.
+type WriterDestination struct {
+	w         io.Writer
+	formatter Formatter
+	lock      sync.Mutex
+
+	fallback      io.Writer
+	fallbackAfter int
+	probeInterval time.Duration
+	// consecutive is the number of consecutive failed writes to w, and failures the total number.
+	consecutive int
+	failures    uint64
+	// onFallback is set while the entries are written to the fallback, since fallbackSince. lastProbe is the
+	// last time that a write to w was attempted while on the fallback.
+	onFallback    bool
+	fallbackSince time.Time
+	lastProbe     time.Time
+	// audit is the audit chain of d, if it is an audit destination (see WithAudit).
+	audit *auditChain
+	// file is the state of the file of d, if it was created using NewFileDestination.
+	file *fileState
+	// timestampFormat and location override those of the entries, if set (see WithTimestampFormat).
+	timestampFormat string
+	location        *time.Location
+	// compression is the gzip compressor that w writes to, if the stream is compressed (see WithCompression).
+	compression *compression
+	// writeDeadline bounds the writes to w, if set (see WithWriteDeadline). pending receives the result of the
+	// last write that timed out, while it is still stuck, and timeouts is the number of writes that timed out.
+	writeDeadline time.Duration
+	pending       chan error
+	timeouts      uint64
+	// filter are the matchers that the entries must match to be written, if set (see WithFilter).
+	filter []RouteMatcher
+	// syslog is the connection of d to the syslog, if it is a syslog destination (see AddSyslogDestination).
+	syslog *syslogConn
+	// spool stores the lines that could not be written, if set (see WithDiskSpool), and spoolDropped is the
+	// number of spooled lines deleted to stay under its size cap.
+	spool        *segmentSpool
+	spoolDropped uint64
+}
+
+// DestinationStats describes the state of a WriterDestination.
+type DestinationStats struct {
+	// Failures is the total number of failed writes to the writer of the destination.
+	Failures uint64
+	// ConsecutiveFailures is the number of failed writes to the writer since the last successful one.
+	ConsecutiveFailures int
+	// Timeouts is the number of writes that did not complete within the write deadline (see WithWriteDeadline).
+	// They are counted as failures as well.
+	Timeouts uint64
+	// OnFallback is true while the entries are written to the fallback writer, since FallbackSince.
+	OnFallback    bool
+	FallbackSince time.Time
+	// SpoolDropped is the number of spooled lines that were deleted to stay under the size cap of the disk spool
+	// (see WithDiskSpool).
+	SpoolDropped uint64
+}
+
+const (
+	defaultFallbackAfter = 3
+	defaultProbeInterval = 30 * time.Second
+)
+
+var destinationFailures uint64
+var destinationsOnFallback int64
+
+// DestOption configures a WriterDestination created with NewDestination. The SpoolOption returned by
+// WithDiskSpool is a DestOption as well.
+type DestOption interface {
+	applyToDestination(d *WriterDestination)
+}
+
+type destOptionFunc func(d *WriterDestination)
+
+func (f destOptionFunc) applyToDestination(d *WriterDestination) {
+	f(d)
+}
+
+// WithFormatter sets the Formatter of the destination. By default, the global Formatter is used.
+func WithFormatter(f Formatter) DestOption {
+	return destOptionFunc(func(d *WriterDestination) { d.formatter = f })
+}
+
+// WithTimestampFormat renders the timestamps written by the destination using layout and in loc, e.g.
+// WithTimestampFormat(time.RFC3339Nano, time.UTC) for a collector while the console shows the local time. It
+// overrides TimestampFormat, the TimestampFormat of the Formatter and that of the Clogger. The time of an entry
+// is captured once, when it is logged, so all the destinations agree on the instant. An empty layout keeps the
+// format, and a nil loc keeps the location.
+func WithTimestampFormat(layout string, loc *time.Location) DestOption {
+	return destOptionFunc(func(d *WriterDestination) {
+		d.timestampFormat = layout
+		d.location = loc
+	})
+}
+
+// WithFilter makes the destination write only the entries that match all of matchers, e.g.
+// WithFilter(MatchTag("auth"), MatchLevels(LogLevelNotice, MaxLevel)). The other entries are skipped.
+func WithFilter(matchers ...RouteMatcher) DestOption {
+	return destOptionFunc(func(d *WriterDestination) { d.filter = append(d.filter, matchers...) })
+}
+
+// WithFallback sets the writer that the destination switches to after consecutive writes to its own writer
+// fail, e.g. os.Stderr when the disk is full. A Crit message explains the switch. While on the fallback, the
+// writer is probed periodically, and the destination switches back once a write succeeds. See
+// WithFallbackPolicy for the number of failures and the probe interval.
+func WithFallback(w io.Writer) DestOption {
+	return destOptionFunc(func(d *WriterDestination) { d.fallback = w })
+}
+
+// WithFallbackPolicy sets the number of consecutive failed writes after which the destination switches to
+// its fallback, 3 by default, and how often its writer is probed while on the fallback, 30s by default.
+func WithFallbackPolicy(failures int, probeInterval time.Duration) DestOption {
+	return destOptionFunc(func(d *WriterDestination) {
+		d.fallbackAfter = failures
+		d.probeInterval = probeInterval
+	})
+}
+
+// NewDestination returns a Destination that writes the entries as lines to w. Writes are serialized.
+func NewDestination(w io.Writer, opts ...DestOption) *WriterDestination {
+	d := &WriterDestination{w: w, fallbackAfter: defaultFallbackAfter, probeInterval: defaultProbeInterval}
+	for _, opt := range opts {
+		opt.applyToDestination(d)
+	}
+	d.wrapCompression()
+	return d
+}
+
+// Write formats e and writes it as a line to the writer of d.
+func (d *WriterDestination) Write(e *Entry) error {
+	if len(d.filter) > 0 && !(RouteRule{Match: d.filter}).matches(*e) {
+		return nil
+	}
+	f := d.formatter
+	if f == nil {
+		f = GetFormatter()
+	}
+	if d.timestampFormat != "" || d.location != nil {
+		// the entry is copied, since it is shared with the output and the other destinations
+		copied := *e
+		copied.formatted = nil
+		if d.timestampFormat != "" {
+			copied.timestampFormat = d.timestampFormat
+		}
+		if d.location != nil {
+			copied.Time = e.Time.In(d.location)
+		}
+		e = &copied
+	}
+	line := formatEntry(f, e) + "\n"
+	d.lock.Lock()
+	var mac string
+	if d.audit != nil {
+		// the line is sealed under the lock, so that the sequence numbers follow the order of the writes
+		var err error
+		if line, mac, err = d.audit.seal(line); err != nil {
+			d.lock.Unlock()
+			return err
+		}
+	}
+	if d.syslog != nil {
+		severity, _ := syslogPriority(e.Level)
+		d.syslog.severity = severity & 0x07
+	}
+	if err := d.rotateFile(len(line)); err != nil {
+		internalf(LogLevelError, "%v", err)
+	}
+	var notice func()
+	var err error
+	if d.spool != nil {
+		err = d.writeSpooled(line)
+	} else {
+		notice, err = d.writeWithFallback(line)
+	}
+	if d.file != nil && err == nil && !d.onFallback {
+		d.file.size += int64(len(line))
+	}
+	// a line that could not be written does not advance the chain, so that the next line takes its place
+	if d.audit != nil && err == nil {
+		err = d.audit.advance(mac)
+	}
+	d.lock.Unlock()
+	// the notice is logged without the lock, since it is written to d as well
+	if notice != nil {
+		notice()
+	}
+	return err
+}
+
+// writeWithFallback writes line to the writer of d, or to its fallback if it has one, switching between them as needed. It
+// returns a function that logs the switch, if there was one, along with the error of the write. d.lock must be held.
+func (d *WriterDestination) writeWithFallback(line string) (func(), error) {
+	if d.onFallback {
+		if now().Sub(d.lastProbe) < d.probeInterval {
+			_, err := io.WriteString(d.fallback, line)
+			return nil, err
+		}
+		d.lastProbe = now()
+	}
+	err := d.writePrimary(line)
+	if err == nil {
+		d.consecutive = 0
+		if !d.onFallback {
+			return nil, nil
+		}
+		d.onFallback = false
+		atomic.AddInt64(&destinationsOnFallback, -1)
+		since := d.fallbackSince
+		return func() {
+			defaultCloggerForLevel(LogLevelNotice).Printf("Clog: destination %T recovered, switched back from the fallback used since %s", d.w, since.Format(time.RFC3339))
+		}, nil
+	}
+	d.countFailure()
+	var notice func()
+	if d.fallback != nil && !d.onFallback && d.consecutive >= d.fallbackAfter {
+		d.onFallback = true
+		d.fallbackSince = now()
+		d.lastProbe = d.fallbackSince
+		atomic.AddInt64(&destinationsOnFallback, 1)
+		failures := d.consecutive
+		notice = func() {
+			defaultCloggerForLevel(LogLevelCrit).Printf("Clog: destination %T failed %d consecutive writes (last error: %v), switched to the fallback %T", d.w, failures, err, d.fallback)
+		}
+	}
+	if d.fallback == nil {
+		return nil, err
+	}
+	// the entry is not lost: it goes to the fallback, even before the switch
+	_, ferr := io.WriteString(d.fallback, line)
+	return notice, ferr
+}
+
+// countFailure counts a failed write to the writer of d. d.lock must be held.
+func (d *WriterDestination) countFailure() {
+	d.consecutive++
+	d.failures++
+	atomic.AddUint64(&destinationFailures, 1)
+}
+
+// Stats returns the failure counts and the fallback state of d.
+func (d *WriterDestination) Stats() DestinationStats {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return DestinationStats{
+		Failures:            d.failures,
+		ConsecutiveFailures: d.consecutive,
+		Timeouts:            d.timeouts,
+		OnFallback:          d.onFallback,
+		FallbackSince:       d.fallbackSince,
+		SpoolDropped:        d.spoolDropped,
+	}
+}
+
+// Close closes the writer of d, if it is an io.Closer other than the standard output or error. If d is
+// compressed, the compressed stream is terminated first.
+func (d *WriterDestination) Close() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if c := d.compression; c != nil && c.gz != nil {
+		return errors.Join(c.gz.Close(), closeWriter(c.w))
+	}
+	return closeWriter(d.w)
+}
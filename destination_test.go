@@ -0,0 +1,113 @@
+package clog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fullWriter records the lines written to it until limit bytes are written, and fails the writes that would
+// exceed it afterwards, like a full disk.
+type fullWriter struct {
+	limit int
+	b     strings.Builder
+}
+
+func (w *fullWriter) Write(p []byte) (int, error) {
+	if w.b.Len()+len(p) > w.limit {
+		return 0, errors.New("no space left on device")
+	}
+	return w.b.Write(p)
+}
+
+func TestWriterDestinationFallback(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	clock := testTime
+	SetClock(func() time.Time { return clock })
+	primary := &fullWriter{limit: 20}
+	var fallback strings.Builder
+	d := NewDestination(primary, WithFallback(&fallback), WithFallbackPolicy(2, time.Minute))
+	before := GetStats()
+
+	// the third line fails, and goes to the fallback, but the destination switches only after the fourth one
+	var notices string
+	write := func(msgs ...string) {
+		notices += CaptureOutput(func() { writeMessages(t, d, msgs...) })
+	}
+	write("m1", "m2", "m3")
+	if stats := d.Stats(); stats.OnFallback || stats.Failures != 1 || stats.ConsecutiveFailures != 1 {
+		t.Errorf("after one failure: got %+v", stats)
+	}
+	write("m4")
+	want := "[CRIT] Clog: destination *clog.fullWriter failed 2 consecutive writes (last error: no space left on " +
+		"device), switched to the fallback *strings.Builder\n"
+	if notices != want {
+		t.Errorf("got the notices %q, want %q", notices, want)
+	}
+	stats := d.Stats()
+	if !stats.OnFallback || !stats.FallbackSince.Equal(testTime) || stats.Failures != 2 || stats.ConsecutiveFailures != 2 {
+		t.Errorf("after two failures: got %+v", stats)
+	}
+	global := GetStats()
+	if got := global.DestinationFailures - before.DestinationFailures; got != 2 {
+		t.Errorf("GetStats().DestinationFailures grew by %d, want 2", got)
+	}
+	if got := global.DestinationsOnFallback - before.DestinationsOnFallback; got != 1 {
+		t.Errorf("GetStats().DestinationsOnFallback grew by %d, want 1", got)
+	}
+
+	// within the probe interval, the primary writer is not tried at all
+	primary.limit = 1000
+	write("m5")
+	if d.Stats().Failures != 2 || !d.Stats().OnFallback {
+		t.Errorf("the primary writer was probed within the probe interval: %+v", d.Stats())
+	}
+
+	// a failed probe keeps the destination on the fallback
+	primary.limit = 20
+	clock = clock.Add(time.Minute)
+	write("m6")
+	if stats := d.Stats(); !stats.OnFallback || stats.Failures != 3 || stats.ConsecutiveFailures != 3 {
+		t.Errorf("after a failed probe: got %+v", stats)
+	}
+
+	// a successful probe switches back
+	primary.limit = 1000
+	clock = clock.Add(time.Minute)
+	notices = ""
+	write("m7")
+	want = "[NOTICE] Clog: destination *clog.fullWriter recovered, switched back from the fallback used since " +
+		"2024-03-05T14:07:09Z\n"
+	if notices != want {
+		t.Errorf("got the notices %q, want %q", notices, want)
+	}
+	if stats := d.Stats(); stats.OnFallback || stats.Failures != 3 || stats.ConsecutiveFailures != 0 {
+		t.Errorf("after a successful probe: got %+v", stats)
+	}
+	if got := GetStats().DestinationsOnFallback - before.DestinationsOnFallback; got != 0 {
+		t.Errorf("GetStats().DestinationsOnFallback grew by %d, want 0", got)
+	}
+
+	if got, want := primary.b.String(), "[INFO] m1\n[INFO] m2\n[INFO] m7\n"; got != want {
+		t.Errorf("the primary writer got %q, want %q", got, want)
+	}
+	if got, want := fallback.String(), "[INFO] m3\n[INFO] m4\n[INFO] m5\n[INFO] m6\n"; got != want {
+		t.Errorf("the fallback got %q, want %q", got, want)
+	}
+}
+
+func TestWriterDestinationWithoutFallback(t *testing.T) {
+	setupTest(t)
+	d := NewDestination(&fullWriter{limit: 0})
+	cl := GetCloggerByName("Info")
+	for i := 0; i < 5; i++ {
+		if err := d.Write(cl.newEntry("lost", nil)); err == nil {
+			t.Fatal("the failed write returned no error")
+		}
+	}
+	if stats := d.Stats(); stats.OnFallback || stats.Failures != 5 || stats.ConsecutiveFailures != 5 {
+		t.Errorf("got %+v", stats)
+	}
+}
@@ -0,0 +1,146 @@
+package clog
+
+import (
+	"bytes"
+	"log/syslog"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// levelPattern associates a regular expression with the level of the lines it matches.
+type levelPattern struct {
+	re    *regexp.Regexp
+	level int
+}
+
+// defaultLevelPatterns detect the level tokens commonly written by other loggers: "ERROR: ...", "[warn] ...",
+// "level=debug ...".
+var defaultLevelPatterns []levelPattern = func() []levelPattern {
+	names := []struct {
+		alternatives string
+		level        int
+	}{
+		{"debug|trace", LogLevelDebug},
+		{"info", LogLevelInfo},
+		{"notice", LogLevelNotice},
+		{"warn|warning", LogLevelWarning},
+		{"error|err", LogLevelError},
+		{"crit|critical|fatal|panic", LogLevelCrit},
+		{"alert", LogLevelAlert},
+		{"emerg|emergency", LogLevelEmergency},
+	}
+	var patterns []levelPattern
+	for _, n := range names {
+		patterns = append(patterns,
+			levelPattern{regexp.MustCompile(`(?i)^\s*\[(` + n.alternatives + `)\]:?\s*`), n.level},
+			levelPattern{regexp.MustCompile(`(?i)^\s*(` + n.alternatives + `)(:\s*|\s+|$)`), n.level},
+			levelPattern{regexp.MustCompile(`(?i)\blevel=["']?(` + n.alternatives + `)["']?(\s+|$)`), n.level},
+		)
+	}
+	return patterns
+}()
+
+// syslogPriorityPattern matches the <PRI> header of an RFC5424 syslog line.
+var syslogPriorityPattern = regexp.MustCompile(`^\s*<(\d{1,3})>\s*`)
+
+// syslogSeverityLevels maps syslog severities to log levels.
+var syslogSeverityLevels map[syslog.Priority]int = map[syslog.Priority]int{
+	syslog.LOG_EMERG:   LogLevelEmergency,
+	syslog.LOG_ALERT:   LogLevelAlert,
+	syslog.LOG_CRIT:    LogLevelCrit,
+	syslog.LOG_ERR:     LogLevelError,
+	syslog.LOG_WARNING: LogLevelWarning,
+	syslog.LOG_NOTICE:  LogLevelNotice,
+	syslog.LOG_INFO:    LogLevelInfo,
+	syslog.LOG_DEBUG:   LogLevelDebug,
+}
+
+// LevelDetectWriter is an io.Writer that logs every line written to it using the default clogger of the level
+// detected in the line, e.g. a line starting with "ERROR" is logged using the "Error" clogger. It can be used to
+// bridge the output of a subprocess or of another logger into clog. The detected level token is removed from the
+// line, so that it is not labeled twice.
+type LevelDetectWriter struct {
+	defaultLevel int
+	patterns     []levelPattern
+	buf          []byte
+	lock         sync.Mutex
+	// history decorates the replayed lines, if set (see WithHistoricalDecoration).
+	history *historical
+}
+
+// NewLevelDetectWriter creates a new LevelDetectWriter. Lines in which no level is detected are logged at
+// defaultLevel. The levels are detected from common prefixes ("ERROR", "WARN:", "[error]"), logfmt level keys
+// ("level=debug") and RFC5424 <PRI> headers.
+func NewLevelDetectWriter(defaultLevel int, opts ...IngestOption) *LevelDetectWriter {
+	return &LevelDetectWriter{
+		defaultLevel: defaultLevel,
+		patterns:     defaultLevelPatterns,
+		history:      newHistorical(opts),
+	}
+}
+
+// AddLevelPattern makes lines matching re be logged at level. The first match is removed from the line. Added
+// patterns are checked before the built-in ones, the most recently added first.
+func (w *LevelDetectWriter) AddLevelPattern(re *regexp.Regexp, level int) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.patterns = append([]levelPattern{{re, level}}, w.patterns...)
+}
+
+// Write logs every complete line in p. An incomplete last line is buffered until it is completed by a later
+// Write, or until Close is called.
+func (w *LevelDetectWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(bytes.TrimSuffix(w.buf[:i], []byte("\r")))
+		w.buf = w.buf[i+1:]
+		w.logLine(line)
+	}
+	return len(p), nil
+}
+
+// Close logs the buffered incomplete line, if any.
+func (w *LevelDetectWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if len(w.buf) > 0 {
+		w.logLine(string(w.buf))
+		w.buf = nil
+	}
+	return nil
+}
+
+// logLine detects the level of line and logs it.
+func (w *LevelDetectWriter) logLine(line string) {
+	level, msg := w.detectLevel(line)
+	cl := defaultCloggerForLevel(level)
+	e := cl.newEntry(msg, nil)
+	if w.history.active() {
+		w.history.decorate(e, lineTime(line))
+	}
+	cl.printEntry(e)
+}
+
+// detectLevel returns the level detected in line, and the line without the level token.
+func (w *LevelDetectWriter) detectLevel(line string) (int, string) {
+	if m := syslogPriorityPattern.FindStringSubmatchIndex(line); m != nil {
+		if pri, err := strconv.Atoi(line[m[2]:m[3]]); err == nil {
+			if level, ok := syslogSeverityLevels[syslog.Priority(pri)&0x07]; ok {
+				return level, line[:m[0]] + line[m[1]:]
+			}
+		}
+	}
+	for _, p := range w.patterns {
+		if m := p.re.FindStringIndex(line); m != nil {
+			return p.level, line[:m[0]] + line[m[1]:]
+		}
+	}
+	return w.defaultLevel, line
+}
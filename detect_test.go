@@ -0,0 +1,33 @@
+package clog
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestLevelDetectWriter(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	w := NewLevelDetectWriter(LogLevelNotice)
+	w.AddLevelPattern(regexp.MustCompile(`^OOPS `), LogLevelCrit)
+	lines := captureLines(func() {
+		fmt.Fprint(w, "ERROR: disk full\n[warn] slow query\r\nts=1 level=debug msg=cache\n")
+		fmt.Fprint(w, "<11>connection reset\nOOPS out of memory\nstarting up\npartial")
+		fmt.Fprint(w, " line")
+		w.Close()
+	})
+	want := []string{
+		"[ERROR] disk full",
+		"[WARNING] slow query",
+		"[DEBUG] ts=1 msg=cache",
+		"[ERROR] connection reset",
+		"[CRIT] out of memory",
+		"[NOTICE] starting up",
+		"[NOTICE] partial line",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+}
@@ -0,0 +1,80 @@
+//go:build !clognodevmode
+
+package clog
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// devMode is set by DevMode.
+var devMode atomic.Bool
+
+// sourceFiles caches the lines of the source files read for the snippets, by path. The lines of a file that
+// cannot be read are nil.
+var sourceFiles sync.Map // map[string][]string
+
+// DevMode sets whether the messages logged at the Error level or above show the source code around the line
+// that logged them, e.g.
+//
+//	2006/01/02 15:04:05 [ERROR] could not open config
+//	        41 | 	f, err := os.Open(path)
+//	    >   42 | 	if err != nil { clog.Error("could not open config") }
+//	        43 | 	defer f.Close()
+//
+// The snippet is only shown if the source file exists on disk, which is usually not the case for deployed
+// binaries. Source files are read once per process. It is off by default, and it can be removed at compile time
+// using the clognodevmode build tag, in which case DevMode does nothing.
+func DevMode(b bool) {
+	devMode.Store(b)
+}
+
+// attachSourceSnippet appends the snippet of the source code that logged e to its message, if DevMode is on.
+func attachSourceSnippet(e *Entry) {
+	if !devMode.Load() || !IsAtLeast(e.Level, LogLevelError) {
+		return
+	}
+	frame, ok := caller()
+	if !ok {
+		return
+	}
+	if snippet := sourceSnippet(frame.File, frame.Line); snippet != "" {
+		e.Message += "\n" + snippet
+	}
+}
+
+// sourceSnippet returns line of file, and the lines around it, with their numbers, or "" if file cannot be read.
+func sourceSnippet(file string, line int) string {
+	lines := sourceLines(file)
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	var b strings.Builder
+	for n := max(line-1, 1); n <= min(line+1, len(lines)); n++ {
+		marker := " "
+		if n == line {
+			marker = ">"
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "    %s %4d | %s", marker, n, lines[n-1])
+	}
+	return b.String()
+}
+
+// sourceLines returns the lines of file, reading it the first time.
+func sourceLines(file string) []string {
+	if lines, ok := sourceFiles.Load(file); ok {
+		return lines.([]string)
+	}
+	var lines []string
+	if data, err := os.ReadFile(file); err == nil {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	sourceFiles.Store(file, lines)
+	return lines
+}
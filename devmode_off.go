@@ -0,0 +1,9 @@
+//go:build clognodevmode
+
+package clog
+
+// DevMode does nothing, since the source snippets were removed using the clognodevmode build tag.
+func DevMode(b bool) {}
+
+// attachSourceSnippet does nothing, since the source snippets were removed using the clognodevmode build tag.
+func attachSourceSnippet(e *Entry) {}
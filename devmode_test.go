@@ -0,0 +1,52 @@
+//go:build !clognodevmode
+
+package clog_test
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/teejays/clog"
+)
+
+// TestDevMode checks that, in DevMode, the Error messages show the source around their call site, and the Info
+// messages do not. Like the other call site tests, it runs outside of the clog package.
+func TestDevMode(t *testing.T) {
+	t.Cleanup(func() {
+		clog.DevMode(false)
+		clog.SetPrependTimestamp(true)
+		clog.SetColorMode(clog.ColorAuto)
+		clog.ResetForTesting()
+	})
+	clog.ResetForTesting()
+	clog.SetColorMode(clog.ColorNever)
+	clog.SetPrependTimestamp(false)
+	clog.DevMode(true)
+	var line int
+	got := clog.CaptureOutput(func() {
+		clog.Info("loading config")
+		_, _, line, _ = runtime.Caller(0)
+		clog.Error("could not open config")
+	})
+	_, file, _, _ := runtime.Caller(0)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := strings.Split(string(data), "\n")
+	want := "[INFO] loading config\n[ERROR] could not open config\n" +
+		fmt.Sprintf("      %4d | %s\n", line, source[line-1]) +
+		fmt.Sprintf("    > %4d | %s\n", line+1, source[line]) +
+		fmt.Sprintf("      %4d | %s\n", line+2, source[line+1])
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	clog.DevMode(false)
+	if got := clog.CaptureOutput(func() { clog.Error("could not open config") }); got != "[ERROR] could not open config\n" {
+		t.Errorf("without DevMode: got %q", got)
+	}
+}
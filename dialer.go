@@ -0,0 +1,40 @@
+package clog
+
+import (
+	"io"
+	"log"
+	"log/syslog"
+	"sync"
+)
+
+// SyslogDialer connects to the syslog for a Clogger with the given priority, and returns the writer that its
+// messages are written to.
+type SyslogDialer func(priority syslog.Priority) (io.Writer, error)
+
+var syslogDialer SyslogDialer = dialSyslog
+var syslogDialerLock sync.RWMutex
+
+// SetSyslogDialer sets the function that connects the Cloggers to the syslog, e.g. to log to a remote syslog
+// server, or to simulate failures in tests. It applies to the Cloggers created afterwards, and to those whose
+// LogLevel changes. A nil dialer restores the default, which connects to the local syslog.
+func SetSyslogDialer(dialer SyslogDialer) {
+	if dialer == nil {
+		dialer = dialSyslog
+	}
+	syslogDialerLock.Lock()
+	defer syslogDialerLock.Unlock()
+	syslogDialer = dialer
+}
+
+// newSyslogLogger returns a logger that writes to the syslog with priority, using the dialer set with
+// SetSyslogDialer.
+func newSyslogLogger(priority syslog.Priority) (*log.Logger, error) {
+	syslogDialerLock.RLock()
+	dial := syslogDialer
+	syslogDialerLock.RUnlock()
+	w, err := dial(priority)
+	if err != nil {
+		return nil, err
+	}
+	return log.New(w, "", 0), nil
+}
@@ -0,0 +1,165 @@
+package clog
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingFormatter counts the entries formatted by its Formatter.
+type countingFormatter struct {
+	Formatter
+	lock  sync.Mutex
+	calls int
+}
+
+func (f *countingFormatter) Format(e *Entry) string {
+	f.lock.Lock()
+	f.calls++
+	f.lock.Unlock()
+	return f.Formatter.Format(e)
+}
+
+// useDestinations removes the destinations added during tb once it is done.
+func useDestinations(tb testing.TB, dests ...Destination) {
+	destinationsLock.Lock()
+	previous := destinations
+	destinationsLock.Unlock()
+	tb.Cleanup(func() {
+		destinationsLock.Lock()
+		defer destinationsLock.Unlock()
+		destinations = previous
+	})
+	for _, dest := range dests {
+		AddDestination(dest)
+	}
+}
+
+// TestDualWrite logs a single Infof as text to the output and as JSON to a destination, with a clock that
+// moves on every reading, and expects one line of each with the same timestamp and content.
+func TestDualWrite(t *testing.T) {
+	setupTest(t)
+	TimestampFormat = time.RFC3339Nano
+	var clockLock sync.Mutex
+	clock := testTime
+	SetClock(func() time.Time {
+		clockLock.Lock()
+		defer clockLock.Unlock()
+		clock = clock.Add(time.Millisecond)
+		return clock
+	})
+	text := &countingFormatter{Formatter: &TextFormatter{}}
+	SetFormatter(text)
+	jsonFormatter := &countingFormatter{Formatter: &JSONFormatter{}}
+	var jsonFile lockedBuffer
+	useDestinations(t, NewDestination(&jsonFile, WithFormatter(jsonFormatter)))
+
+	textLines := captureLines(func() { Infof("user %d signed in", 42) })
+	jsonLines := strings.Split(strings.TrimSuffix(jsonFile.String(), "\n"), "\n")
+	if len(textLines) != 1 || len(jsonLines) != 1 {
+		t.Fatalf("got the text lines %q and the JSON lines %q, want one of each", textLines, jsonLines)
+	}
+	if text.calls != 1 || jsonFormatter.calls != 1 {
+		t.Errorf("formatted %d times as text and %d times as JSON, want once each", text.calls, jsonFormatter.calls)
+	}
+
+	var decoded struct {
+		Time  string `json:"time"`
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+	}
+	if err := json.Unmarshal([]byte(jsonLines[0]), &decoded); err != nil {
+		t.Fatalf("invalid JSON line %q: %v", jsonLines[0], err)
+	}
+	if want := decoded.Time + " [INFO] " + decoded.Msg; textLines[0] != want {
+		t.Errorf("the text line %q does not match the JSON line %q", textLines[0], jsonLines[0])
+	}
+	if decoded.Time != "2024-03-05T14:07:09.124456789Z" || decoded.Level != "info" || decoded.Msg != "user 42 signed in" {
+		t.Errorf("got the JSON line %q", jsonLines[0])
+	}
+}
+
+// TestDualWriteSharedFormatter checks that destinations sharing a Formatter format the entry once between them.
+// The output formats it on its own, since the highlights (see AddHighlight) only apply to it.
+func TestDualWriteSharedFormatter(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	f := &countingFormatter{Formatter: &TextFormatter{}}
+	SetFormatter(f)
+	var a, b lockedBuffer
+	useDestinations(t, NewDestination(&a), NewDestination(&b, WithFormatter(f)))
+	got := CaptureOutput(func() { Infof("user %d signed in", 42) })
+	for _, out := range []string{got, a.String(), b.String()} {
+		if out != "[INFO] user 42 signed in\n" {
+			t.Errorf("got %q, want \"[INFO] user 42 signed in\\n\"", out)
+		}
+	}
+	if f.calls != 2 {
+		t.Errorf("formatted %d times, want twice", f.calls)
+	}
+}
+
+// TestDualWriteIndependentFailures checks that a failing JSON destination affects neither the text output nor
+// the other destinations.
+func TestDualWriteIndependentFailures(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	recordInternal(t)
+	var jsonFile lockedBuffer
+	failing := NewDestination(&fullWriter{}, WithFormatter(&JSONFormatter{}))
+	useDestinations(t, failing, NewDestination(&jsonFile, WithFormatter(&JSONFormatter{})))
+	got := CaptureOutput(func() { Infof("user %d signed in", 42) })
+	if want := "[INFO] user 42 signed in\n"; got != want {
+		t.Errorf("output: got %q, want %q", got, want)
+	}
+	want := `{"time":"2024-03-05T14:07:09.123456789Z","level":"info","logger":"Info","msg":"user 42 signed in"}` + "\n"
+	if got := jsonFile.String(); got != want {
+		t.Errorf("JSON: got %q, want %q", got, want)
+	}
+	if failing.Stats().Failures != 1 {
+		t.Errorf("got %+v, want one failure", failing.Stats())
+	}
+}
+
+// TestDualWriteFormatsOncePerEntry logs several entries, and expects each Formatter to run once per entry.
+func TestDualWriteFormatsOncePerEntry(t *testing.T) {
+	setupTest(t)
+	text := &countingFormatter{Formatter: &TextFormatter{}}
+	SetFormatter(text)
+	jsonFormatter := &countingFormatter{Formatter: &JSONFormatter{}}
+	var a, b lockedBuffer
+	useDestinations(t, NewDestination(&a, WithFormatter(jsonFormatter)), NewDestination(&b, WithFormatter(jsonFormatter)))
+	lines := captureLines(func() {
+		for i := 0; i < 3; i++ {
+			Infof("attempt %d", i)
+		}
+	})
+	if len(lines) != 3 || a.String() != b.String() || strings.Count(a.String(), "\n") != 3 {
+		t.Fatalf("got the text lines %q and the JSON lines %q and %q, want 3 of each", lines, a.String(), b.String())
+	}
+	if text.calls != 3 || jsonFormatter.calls != 3 {
+		t.Errorf("formatted %d times as text and %d times as JSON, want 3 times each", text.calls, jsonFormatter.calls)
+	}
+}
+
+// TestDualWriteFormatterPanic checks that a destination whose Formatter panics does not keep the entry from the
+// output and from the other destinations.
+func TestDualWriteFormatterPanic(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	events := recordInternal(t)
+	var broken, jsonFile lockedBuffer
+	useDestinations(t, NewDestination(&broken, WithFormatter(panickingFormatter{})), NewDestination(&jsonFile, WithFormatter(&JSONFormatter{})))
+	got := CaptureOutput(func() { Infof("user %d signed in", 42) })
+	if want := "[INFO] user 42 signed in\n"; got != want {
+		t.Errorf("output: got %q, want %q", got, want)
+	}
+	if !strings.Contains(jsonFile.String(), `"msg":"user 42 signed in"`) {
+		t.Errorf("JSON: got %q, want the entry", jsonFile.String())
+	}
+	if !events.contains("panicked") {
+		t.Errorf("the panic was not reported, got %q", events.messages)
+	}
+}
@@ -0,0 +1,134 @@
+package clog
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+/********************************************************************************
+* G O R O U T I N E   D U M P
+*********************************************************************************/
+
+const (
+	// maxDumpedGoroutines is the number of goroutines whose stack DumpGoroutines logs at most.
+	maxDumpedGoroutines = 500
+	// maxGoroutineBlockLength is the length, in bytes, above which the stack of a goroutine is truncated.
+	maxGoroutineBlockLength = 16 << 10
+	// maxGoroutineDumpSize is the size, in bytes, of the largest dump that DumpGoroutines captures.
+	maxGoroutineDumpSize = 64 << 20
+)
+
+// DumpGoroutines logs the stacks of all the goroutines, e.g. to investigate a deadlock without terminating the
+// process like SIGQUIT does. It logs a summary at the Warning level, with the number of goroutines by state, e.g.
+// "goroutine dump: 42 goroutines (chan receive: 30, select: 11, running: 1)", followed by the stack of each
+// goroutine at the Debug level, as a multi-line message, so the stacks are only written if the Debug level
+// passes the LogLevel. The messages are logged using cl, with their own level, or using the default cloggers of
+// their levels if cl is nil. Only the first 500 goroutines are logged, and the stacks longer than 16KiB are
+// truncated.
+func DumpGoroutines(cl *Clogger) {
+	blocks := goroutineBlocks()
+	logDump := func(level int, msg string) {
+		l := cl
+		if l == nil {
+			l = defaultCloggerForLevel(level)
+		}
+		e := l.newEntry(msg, nil)
+		e.Level = level
+		l.printEntry(e)
+	}
+
+	states := make(map[string]int)
+	for _, block := range blocks {
+		states[goroutineState(block)]++
+	}
+	names := make([]string, 0, len(states))
+	for state := range states {
+		names = append(names, state)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if states[names[i]] != states[names[j]] {
+			return states[names[i]] > states[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	counts := make([]string, len(names))
+	for i, state := range names {
+		counts[i] = fmt.Sprintf("%s: %d", state, states[state])
+	}
+	logDump(LogLevelWarning, fmt.Sprintf("goroutine dump: %d goroutines (%s)", len(blocks), strings.Join(counts, ", ")))
+
+	for i, block := range blocks {
+		if i == maxDumpedGoroutines {
+			logDump(LogLevelWarning, fmt.Sprintf("goroutine dump truncated: the stacks of %d more goroutines are not logged", len(blocks)-i))
+			break
+		}
+		if len(block) > maxGoroutineBlockLength {
+			block = block[:maxGoroutineBlockLength] + "\n...truncated"
+		}
+		logDump(LogLevelDebug, block)
+	}
+}
+
+// goroutineBlocks returns the stacks of all the goroutines, as formatted by runtime.Stack, one per goroutine.
+func goroutineBlocks() []string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxGoroutineDumpSize {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	var blocks []string
+	for _, block := range strings.Split(string(buf), "\n\n") {
+		if block = strings.TrimSpace(block); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// goroutineState returns the state of the goroutine whose stack is block, e.g. "chan receive" for a block starting
+// with "goroutine 7 [chan receive, 5 minutes]:".
+func goroutineState(block string) string {
+	start := strings.IndexByte(block, '[')
+	end := strings.IndexByte(block, ']')
+	if start < 0 || end < start {
+		return "unknown"
+	}
+	state, _, _ := strings.Cut(block[start+1:end], ",")
+	return state
+}
+
+// DumpGoroutinesOnSignal logs the stacks of all the goroutines using DumpGoroutines, with the default cloggers,
+// every time the process receives sig, e.g. syscall.SIGUSR2, and keeps it running. Note that
+// EnableSignalLevelControl uses SIGUSR2 as well. Calling the returned function stops listening to sig.
+func DumpGoroutinesOnSignal(sig os.Signal) func() {
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigs, sig)
+	go func() {
+		for {
+			select {
+			case <-sigs:
+				DumpGoroutines(nil)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigs)
+			close(done)
+		})
+	}
+}
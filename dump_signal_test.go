@@ -0,0 +1,33 @@
+//go:build !windows && !js
+
+package clog
+
+import (
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestDumpGoroutinesOnSignal checks that the goroutines are dumped when the process receives the signal, and
+// that it keeps running.
+func TestDumpGoroutinesOnSignal(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetLogLevel(LogLevelInfo)
+	var out lockedBuffer
+	previous := GetOutput()
+	SetOutput(&out)
+	t.Cleanup(func() { SetOutput(previous) })
+	stop := DumpGoroutinesOnSignal(syscall.SIGUSR2)
+	defer stop()
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR2); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); out.String() == "" && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if got := out.String(); !strings.HasPrefix(got, "[WARNING] goroutine dump: ") || strings.Count(got, "\n") != 1 {
+		t.Errorf("got %q, want the summary of the dump", got)
+	}
+}
@@ -0,0 +1,97 @@
+package clog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// dumpEntry is an entry logged by DumpGoroutines, as recorded by recordDump.
+type dumpEntry struct {
+	level   int
+	logger  string
+	message string
+}
+
+// recordDump returns the entries logged while fn runs that pass the LogLevel.
+func recordDump(t *testing.T, fn func()) []dumpEntry {
+	var lock sync.Mutex
+	var entries []dumpEntry
+	addTestHook(t, func(e *Entry) {
+		if !IsAtLeast(e.Level, GetLogLevel()) {
+			return
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		entries = append(entries, dumpEntry{e.Level, e.Logger, e.Message})
+	})
+	CaptureOutput(fn)
+	lock.Lock()
+	defer lock.Unlock()
+	return append([]dumpEntry(nil), entries...)
+}
+
+// parkedInDumpTest blocks until release is closed, so that it shows in the goroutine dumps.
+func parkedInDumpTest(release chan struct{}, wg *sync.WaitGroup) {
+	wg.Done()
+	<-release
+}
+
+// TestDumpGoroutines checks that the summary counts the goroutines by state, and that the stack of each
+// goroutine follows it at the Debug level.
+func TestDumpGoroutines(t *testing.T) {
+	setupTest(t)
+	release := make(chan struct{})
+	defer close(release)
+	var started sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		started.Add(1)
+		go parkedInDumpTest(release, &started)
+	}
+	started.Wait()
+
+	entries := recordDump(t, func() { DumpGoroutines(NewClogger("dump", LogLevelWarning)) })
+	if len(entries) < 7 {
+		t.Fatalf("got %d entries, want a summary and at least 6 stacks", len(entries))
+	}
+	summary := regexp.MustCompile(`^goroutine dump: (\d+) goroutines \(.*chan receive: (\d+)`).FindStringSubmatch(entries[0].message)
+	if entries[0].level != LogLevelWarning || entries[0].logger != "dump" || summary == nil {
+		t.Fatalf("got the summary %+v", entries[0])
+	}
+	if total := fmt.Sprint(len(entries) - 1); summary[1] != total {
+		t.Errorf("the summary counts %s goroutines, want %s", summary[1], total)
+	}
+	var parked int
+	for _, e := range entries[1:] {
+		if e.level != LogLevelDebug || !strings.HasPrefix(e.message, "goroutine ") {
+			t.Errorf("got the stack %+v", e)
+		}
+		if strings.Contains(e.message, "parkedInDumpTest") {
+			parked++
+		}
+	}
+	if parked != 5 {
+		t.Errorf("got %d stacks of the parked goroutines, want 5", parked)
+	}
+
+	SetLogLevel(LogLevelInfo)
+	if entries := recordDump(t, func() { DumpGoroutines(nil) }); len(entries) != 1 || entries[0].logger != "Warning" {
+		t.Errorf("above the Debug level: got %+v, want only the summary", entries)
+	}
+}
+
+// TestGoroutineState checks the state read from the header of a stack.
+func TestGoroutineState(t *testing.T) {
+	tests := map[string]string{
+		"goroutine 7 [chan receive, 5 minutes]:\nmain.main()": "chan receive",
+		"goroutine 1 [running]:\nmain.main()":                 "running",
+		"not a stack":                                         "unknown",
+	}
+	for block, want := range tests {
+		if got := goroutineState(block); got != want {
+			t.Errorf("goroutineState(%q) = %q, want %q", block, got, want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package clog
+
+// WouldLog reports whether a message logged at level using l would be written anywhere, i.e. to the syslog, to
+// the output or to a destination, given the current flags, LogLevel and Router, as routed for l and level
+// regardless of the text of the message. The LogLevel is ignored for the Cloggers of the packages that match
+// CLOG_DEBUG (see ForPackage).
+// It does not format anything, so it can be used to guard building expensive messages. It does not take sampling
+// or WithDebugEnabled contexts into account.
+func (l *Clogger) WouldLog(level int) bool {
+	if tagsSuppressed(l.tags) {
+		return false
+	}
+	if l.verbose || IsAtLeast(level, GetLogLevel()) {
+		if getFlag(&LogToStdOut) || len(getRouter().Route(Entry{Level: level, Logger: l.Name, Tags: l.tags})) > 0 {
+			return true
+		}
+	}
+	if getFlag(&LogToSyslog) {
+		l.lock.RLock()
+		defer l.lock.RUnlock()
+		return l.sysLogger != nil
+	}
+	return false
+}
+
+// enabled reports whether a message logged using the default clogger of level would be written.
+func enabled(level int) bool {
+	ensureDefaults()
+	cl := defaultsByLevel[level].Load()
+	return cl != nil && cl.WouldLog(level)
+}
+
+// DebugEnabled reports whether messages logged using Debug would be written, e.g.
+//
+//	if clog.DebugEnabled() {
+//		clog.Debug(dump(state))
+//	}
+func DebugEnabled() bool {
+	return enabled(LogLevelDebug)
+}
+
+// InfoEnabled reports whether messages logged using Info would be written.
+func InfoEnabled() bool {
+	return enabled(LogLevelInfo)
+}
+
+// NoticeEnabled reports whether messages logged using Notice would be written.
+func NoticeEnabled() bool {
+	return enabled(LogLevelNotice)
+}
+
+// WarningEnabled reports whether messages logged using Warning would be written.
+func WarningEnabled() bool {
+	return enabled(LogLevelWarning)
+}
+
+// ErrorEnabled reports whether messages logged using Error would be written.
+func ErrorEnabled() bool {
+	return enabled(LogLevelError)
+}
+
+// CritEnabled reports whether messages logged using Crit would be written.
+func CritEnabled() bool {
+	return enabled(LogLevelCrit)
+}
@@ -0,0 +1,106 @@
+package clog
+
+import (
+	"io"
+	"testing"
+)
+
+func TestWouldLogOutput(t *testing.T) {
+	setupTest(t)
+	SetLogLevel(LogLevelWarning)
+	cl := GetCloggerByName("Info")
+	if cl.WouldLog(LogLevelInfo) {
+		t.Error("a level below the LogLevel would be logged")
+	}
+	if !cl.WouldLog(LogLevelError) {
+		t.Error("a level above the LogLevel would not be logged")
+	}
+	SetLogToStdOut(false)
+	if cl.WouldLog(LogLevelError) {
+		t.Error("a message would be logged without the output, the syslog and any destination")
+	}
+}
+
+func TestWouldLogDestination(t *testing.T) {
+	setupTest(t)
+	SetLogToStdOut(false)
+	SetLogLevel(LogLevelWarning)
+	useDestinations(t, NewDestination(io.Discard))
+	cl := GetCloggerByName("Info")
+	if !cl.WouldLog(LogLevelError) {
+		t.Error("a message for a destination would not be logged")
+	}
+	// the destinations only get the messages that pass the LogLevel
+	if cl.WouldLog(LogLevelInfo) {
+		t.Error("a level below the LogLevel would be logged")
+	}
+	if ErrorEnabled() != true || InfoEnabled() != false {
+		t.Errorf("ErrorEnabled() = %t and InfoEnabled() = %t, want true and false", ErrorEnabled(), InfoEnabled())
+	}
+}
+
+func TestWouldLogRouterRule(t *testing.T) {
+	setupTest(t)
+	SetLogToStdOut(false)
+	SetRouter(NewRuleRouter(
+		RouteRule{Match: []RouteMatcher{MatchLevels(LogLevelError, MaxLevel)}, Destinations: []Destination{NewDestination(io.Discard)}},
+		RouteRule{Match: []RouteMatcher{MatchTag("audit")}, Destinations: []Destination{NewDestination(io.Discard)}},
+	))
+	t.Cleanup(func() { SetRouter(nil) })
+	cl := GetCloggerByName("Info")
+	if !cl.WouldLog(LogLevelError) {
+		t.Error("a level matched by a rule would not be logged")
+	}
+	if cl.WouldLog(LogLevelWarning) {
+		t.Error("a level matched by no rule would be logged")
+	}
+	// the tags of the Clogger are routed as well
+	if !cl.Tagged("audit").WouldLog(LogLevelDebug) {
+		t.Error("a tag matched by a rule would not be logged")
+	}
+}
+
+func TestWouldLogVerbose(t *testing.T) {
+	setupTest(t)
+	SetLogLevel(LogLevelError)
+	cl, err := newClogger("svc/payments", LogLevelDebug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cl.WouldLog(LogLevelDebug) {
+		t.Error("a level below the LogLevel would be logged")
+	}
+	// the Cloggers of the packages that match CLOG_DEBUG ignore the LogLevel
+	cl.verbose = true
+	if !cl.WouldLog(LogLevelDebug) {
+		t.Error("a verbose Clogger would not log below the LogLevel")
+	}
+	if got := captureLines(func() { cl.Print("ledger posted") }); len(got) != 1 {
+		t.Errorf("got %q, want the message", got)
+	}
+}
+
+func TestWouldLogSyslog(t *testing.T) {
+	setupTest(t)
+	recordSyslog(t)
+	SetLogToStdOut(false)
+	SetLogLevel(LogLevelCrit)
+	// the syslog gets every message, whatever the LogLevel
+	if !GetCloggerByName("Debug").WouldLog(LogLevelDebug) {
+		t.Error("a message for the syslog would not be logged")
+	}
+}
+
+func TestWouldLogSuppressedTag(t *testing.T) {
+	setupTest(t)
+	useDestinations(t, NewDestination(io.Discard))
+	SuppressTags("noisy")
+	t.Cleanup(func() { UnsuppressTags("noisy") })
+	cl := GetCloggerByName("Error")
+	if cl.Tagged("noisy").WouldLog(LogLevelError) {
+		t.Error("a message with a suppressed tag would be logged")
+	}
+	if !cl.Tagged("quiet").WouldLog(LogLevelError) {
+		t.Error("a message without a suppressed tag would not be logged")
+	}
+}
@@ -0,0 +1,114 @@
+package clog
+
+import (
+	"time"
+)
+
+/********************************************************************************
+* E N T R Y
+*********************************************************************************/
+
+// Entry represents a single message logged by a Clogger. It is created when the message is logged and
+// is handed to a Formatter to be rendered for the standard output, and to the syslog.
+type Entry struct {
+	// Time is the time at which the message was logged.
+	Time time.Time
+	// Level is the LogLevel of the Clogger that logged the message.
+	Level int
+	// Logger is the name of the Clogger that logged the message.
+	Logger string
+	// Message is the logged message, with any formatting args already applied.
+	Message string
+	// Fields are the key-value pairs attached to the message: the global fields, followed by the fields of
+	// the Clogger, followed by the fields passed to the log call.
+	Fields Fields
+	// Decorations are the decorations of the Clogger that logged the message.
+	Decorations []Decoration
+	// Host is the hostname of the machine, set if PrependHostname is true.
+	Host string
+	// PID is the process id, set if PrependPID is true.
+	PID int
+	// GoroutineID is the id of the goroutine that logged the message, set if PrependGoroutineID is true.
+	GoroutineID int
+	// Caller is the file:line of the code that logged the message, set if PrependCaller is true.
+	Caller string
+	// Scope are the titles of the groups that the message is part of, outermost first (see Group).
+	Scope []string
+	// Tags are the tags of the Clogger that logged the message (see Tagged).
+	Tags []string
+
+	// decorationCode is the cached, joined escape sequences of Decorations.
+	decorationCode string
+	// toggles are the overrides of the global flags of the Clogger that logged the message.
+	toggles toggles
+	// timestampFormat is the timestamp format of the Clogger that logged the message, if it has its own.
+	timestampFormat string
+	// highlight is set while e is formatted for the standard output, which is where AddHighlight applies.
+	highlight bool
+	// formatted are the lines that e has been rendered as, by Formatter (see formatEntry).
+	formatted []formattedLine
+}
+
+// newEntry creates a new Entry for msg logged by l. The global fields, the fields pushed by the goroutine
+// using PushFields, the fields of l and fields are merged in that order, the latter taking precedence if a key
+// exists in more than one.
+func (l *Clogger) newEntry(msg string, fields Fields) *Entry {
+	decorations, code := l.decorations()
+	e := &Entry{
+		Time:           now(),
+		Level:          l.GetLogLevel(),
+		Logger:         l.Name,
+		Message:        msg,
+		Fields:         getGlobalFields().merge(scopedFields()).merge(l.fields).merge(fields),
+		Decorations:    decorations,
+		decorationCode: code,
+		toggles:        l.getToggles(),
+
+		timestampFormat: l.getTimestampFormat(),
+		Scope:           l.scopeOf(),
+		Tags:            l.tags,
+	}
+	if getFlag(&PrependHostname) {
+		e.Host = hostname
+	}
+	if getFlag(&PrependPID) {
+		e.PID = pid
+	}
+	if getFlag(&PrependGoroutineID) {
+		e.GoroutineID = goroutineID()
+	}
+	if getFlag(&PrependCaller) {
+		e.Caller = callerOf(getFlag(&FullCallerPath))
+	}
+	if getFlag(&UTCTimestamps) {
+		e.Time = e.Time.UTC()
+	}
+	return e
+}
+
+// decorationPrefix returns the joined escape sequences of the decorations of e.
+func (e *Entry) decorationPrefix() string {
+	if e.decorationCode != "" {
+		return e.decorationCode
+	}
+	return joinDecorations(e.Decorations)
+}
+
+// addDecorations applies the extra decorations to e, after the decorations of its Clogger.
+func (e *Entry) addDecorations(extra []Decoration) {
+	if len(extra) == 0 {
+		return
+	}
+	code := e.decorationPrefix()
+	// the decorations of the Clogger are shared with it, so they are copied rather than appended to
+	e.Decorations = append(append([]Decoration{}, e.Decorations...), extra...)
+	e.decorationCode = code + joinDecorations(extra)
+}
+
+// timestampFormatOr returns the timestamp format of the Clogger that logged e, or format if it has none.
+func (e *Entry) timestampFormatOr(format string) string {
+	if e.timestampFormat != "" {
+		return e.timestampFormat
+	}
+	return format
+}
@@ -0,0 +1,118 @@
+package clog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ConfigureFromEnv configures the package using the following environment variables, if they are set:
+//
+//	CLOG_LEVEL      the global log level, e.g. debug or warning (see ParseLogLevel)
+//	CLOG_COLOR      never, always or auto (see ColorMode)
+//	CLOG_TIMESTAMP  on or off, whether timestamps are prepended to the messages
+//	CLOG_FORMAT     text, json or logfmt
+//	CLOG_OUTPUT     stdout, stderr, or the path of a file that messages are appended to
+//
+// It is not called automatically; call it at the start of the program to opt in. The settings are applied
+// using the same setters as the rest of the package, so the last write wins: settings made in code after
+// ConfigureFromEnv override the environment, and settings made before are overridden by it. If any of the
+// variables has an invalid value, it returns an error describing all the invalid values and changes nothing.
+func ConfigureFromEnv() error {
+	var errs []error
+	var apply []func()
+
+	if v, ok := os.LookupEnv("CLOG_LEVEL"); ok {
+		level, err := ParseLogLevel(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("CLOG_LEVEL: %w", err))
+		} else {
+			apply = append(apply, func() { SetLogLevel(level) })
+		}
+	}
+	if v, ok := os.LookupEnv("CLOG_TIMESTAMP"); ok {
+		on, err := parseOnOff(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("CLOG_TIMESTAMP: %w", err))
+		} else {
+			apply = append(apply, func() { SetPrependTimestamp(on) })
+		}
+	}
+	if v, ok := os.LookupEnv("CLOG_FORMAT"); ok {
+		var f Formatter
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "text":
+			f = &TextFormatter{}
+		case "json":
+			f = &JSONFormatter{}
+		case "logfmt":
+			f = &LogfmtFormatter{}
+		default:
+			errs = append(errs, fmt.Errorf("CLOG_FORMAT: %s: invalid format '%s', expected text, json or logfmt", PACKAGE_NAME, v))
+		}
+		if f != nil {
+			apply = append(apply, func() { SetFormatter(f) })
+		}
+	}
+	out := GetOutput()
+	if v, ok := os.LookupEnv("CLOG_OUTPUT"); ok {
+		w, err := openOutput(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("CLOG_OUTPUT: %w", err))
+		} else {
+			out = w
+			apply = append(apply, func() { SetOutput(w) })
+		}
+	}
+	if v, ok := os.LookupEnv("CLOG_COLOR"); ok {
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "never":
+			apply = append(apply, func() { SetColorMode(ColorNever) })
+		case "always":
+			apply = append(apply, func() { SetColorMode(ColorAlways) })
+		case "auto":
+			apply = append(apply, func() { SetColorMode(ColorAuto) })
+		default:
+			errs = append(errs, fmt.Errorf("CLOG_COLOR: %s: invalid color mode '%s', expected never, always or auto", PACKAGE_NAME, v))
+		}
+	}
+
+	if len(errs) > 0 {
+		if f, ok := out.(*os.File); ok && f != os.Stdout && f != os.Stderr && f != GetOutput() {
+			f.Close()
+		}
+		return errors.Join(errs...)
+	}
+	for _, fn := range apply {
+		fn()
+	}
+	return nil
+}
+
+// parseOnOff parses the value of a boolean environment variable.
+func parseOnOff(v string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "on", "true", "1", "yes":
+		return true, nil
+	case "off", "false", "0", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("%s: invalid value '%s', expected on or off", PACKAGE_NAME, v)
+}
+
+// openOutput returns the writer for the value of CLOG_OUTPUT.
+func openOutput(v string) (io.Writer, error) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "stdout", "":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+	f, err := os.OpenFile(v, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("%s: could not open the output file: %w", PACKAGE_NAME, err)
+	}
+	return f, nil
+}
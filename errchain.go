@@ -0,0 +1,98 @@
+package clog
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// maxErrChainDepth is the depth below which the causes of an error are not logged by ErrChain, and
+// maxErrChainCauses the number of causes above which they are not.
+const (
+	maxErrChainDepth  = 16
+	maxErrChainCauses = 100
+)
+
+// errCause is a cause of an error, as logged by ErrChain.
+type errCause struct {
+	Depth int    `json:"depth"`
+	Type  string `json:"type"`
+	Msg   string `json:"msg"`
+}
+
+// ErrChain logs err using the "Error" default clogger, followed by its causes: the errors that it wraps, found
+// using errors.Unwrap and the Unwrap() []error method of the errors created using errors.Join, recursively. With
+// the TextFormatter, each cause is an indented line with its depth, its type and its message, e.g.
+//
+//	2006/01/02 15:04:05 [ERROR] load config: open /etc/app.yml: no such file or directory
+//	  1 *fs.PathError: open /etc/app.yml: no such file or directory
+//	    2 syscall.Errno: no such file or directory
+//
+// With a structured formatter (see SchemaFormatter), the causes are a "causes" field instead, an array of
+// {"depth", "type", "msg"} objects. An error that is found again, e.g. because of a cycle, is not unwrapped
+// again, and the causes deeper than 16 levels, or beyond the 100th, are left out. It does nothing if err is nil.
+func ErrChain(err error) {
+	defaultCloggerForLevel(LogLevelError).errChain(err)
+}
+
+// errChain logs err and its causes using l, like ErrChain.
+func (l *Clogger) errChain(err error) {
+	if err == nil {
+		return
+	}
+	seen := map[interface{}]bool{}
+	markSeen(err, seen)
+	causes := errCauses(err, 0, seen, nil)
+	if len(causes) == 0 {
+		l.Print(err.Error())
+		return
+	}
+	if _, structured := GetFormatter().(SchemaFormatter); structured {
+		l.printEntry(l.newEntry(err.Error(), Fields{{"causes", causes}}))
+		return
+	}
+	var b strings.Builder
+	b.WriteString(err.Error())
+	for _, c := range causes {
+		fmt.Fprintf(&b, "\n%s%d %s: %s", strings.Repeat("  ", c.Depth), c.Depth, c.Type, c.Msg)
+	}
+	l.Print(b.String())
+}
+
+// errCauses appends the causes of err, which is at depth, to causes, depth first. seen holds the errors that
+// have been found already.
+func errCauses(err error, depth int, seen map[interface{}]bool, causes []errCause) []errCause {
+	var wrapped []error
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		wrapped = u.Unwrap()
+	case interface{ Unwrap() error }:
+		wrapped = []error{u.Unwrap()}
+	}
+	for _, cause := range wrapped {
+		if cause == nil || depth+1 > maxErrChainDepth || len(causes) >= maxErrChainCauses || !markSeen(cause, seen) {
+			continue
+		}
+		causes = append(causes, errCause{Depth: depth + 1, Type: fmt.Sprintf("%T", cause), Msg: cause.Error()})
+		causes = errCauses(cause, depth+1, seen, causes)
+	}
+	return causes
+}
+
+// markSeen adds err to seen, and reports whether it was not in it already. Errors that are not comparable,
+// e.g. a struct holding a slice in an interface field, are never in it.
+func markSeen(err error, seen map[interface{}]bool) (unseen bool) {
+	if !reflect.TypeOf(err).Comparable() {
+		return true
+	}
+	defer func() {
+		if recover() != nil {
+			unseen = true
+		}
+	}()
+	if seen[err] {
+		return false
+	}
+	seen[err] = true
+	return true
+}
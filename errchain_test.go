@@ -0,0 +1,149 @@
+package clog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+// queryError is a custom error that wraps the errors of several attempts.
+type queryError struct {
+	query    string
+	attempts []error
+}
+
+func (e *queryError) Error() string   { return "query " + e.query + " failed" }
+func (e *queryError) Unwrap() []error { return e.attempts }
+
+// retryError is a custom error that wraps a single error.
+type retryError struct {
+	attempt int
+	err     error
+}
+
+func (e retryError) Error() string { return fmt.Sprintf("attempt %d: %v", e.attempt, e.err) }
+func (e retryError) Unwrap() error { return e.err }
+
+// loopError wraps itself.
+type loopError struct{}
+
+func (e *loopError) Error() string { return "loop" }
+func (e *loopError) Unwrap() error { return e }
+
+func TestErrChainText(t *testing.T) {
+	pathErr := &fs.PathError{Op: "open", Path: "/etc/app.yml", Err: fs.ErrNotExist}
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"wrapped", fmt.Errorf("start: %w", fmt.Errorf("load config: %w", pathErr)),
+			"[ERROR] start: load config: open /etc/app.yml: file does not exist\n" +
+				"  1 *fmt.wrapError: load config: open /etc/app.yml: file does not exist\n" +
+				"    2 *fs.PathError: open /etc/app.yml: file does not exist\n" +
+				"      3 *errors.errorString: file does not exist\n"},
+		{"joined", errors.Join(errors.New("a"), fmt.Errorf("b: %w", errors.New("c")), errors.New("d")),
+			"[ERROR] a\nb: c\nd\n" +
+				"  1 *errors.errorString: a\n" +
+				"  1 *fmt.wrapError: b: c\n" +
+				"    2 *errors.errorString: c\n" +
+				"  1 *errors.errorString: d\n"},
+		{"custom", &queryError{"users", []error{
+			retryError{1, errors.New("timeout")},
+			retryError{2, fmt.Errorf("reset: %w", errors.New("EOF"))},
+		}},
+			"[ERROR] query users failed\n" +
+				"  1 clog.retryError: attempt 1: timeout\n" +
+				"    2 *errors.errorString: timeout\n" +
+				"  1 clog.retryError: attempt 2: reset: EOF\n" +
+				"    2 *fmt.wrapError: reset: EOF\n" +
+				"      3 *errors.errorString: EOF\n"},
+		{"cycle", fmt.Errorf("outer: %w", &loopError{}),
+			"[ERROR] outer: loop\n" +
+				"  1 *clog.loopError: loop\n"},
+		{"no causes", errors.New("disk full"), "[ERROR] disk full\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			SetPrependTimestamp(false)
+			if got := CaptureOutput(func() { ErrChain(tt.err) }); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrChainNil(t *testing.T) {
+	setupTest(t)
+	if got := CaptureOutput(func() { ErrChain(nil) }); got != "" {
+		t.Errorf("logged %q for a nil error", got)
+	}
+}
+
+func TestErrChainStructured(t *testing.T) {
+	setupTest(t)
+	SetFormatter(&JSONFormatter{})
+	err := &queryError{"users", []error{retryError{1, errors.New("timeout")}, errors.New("EOF")}}
+	got := CaptureOutput(func() { ErrChain(err) })
+	want := `{"time":"2024-03-05T14:07:09.123456789Z","level":"error","logger":"Error","msg":"query users failed",` +
+		`"causes":[{"depth":1,"type":"clog.retryError","msg":"attempt 1: timeout"},` +
+		`{"depth":2,"type":"*errors.errorString","msg":"timeout"},` +
+		`{"depth":1,"type":"*errors.errorString","msg":"EOF"}]}` + "\n"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	var decoded struct{ Causes []errCause }
+	if err := json.Unmarshal([]byte(got), &decoded); err != nil || len(decoded.Causes) != 3 {
+		t.Errorf("got the causes %+v, %v", decoded.Causes, err)
+	}
+}
+
+// deepTail returns the message of the error wrapped by level n of the deep error of TestErrChainLimits.
+func deepTail(n int) string {
+	var b strings.Builder
+	for i := n - 1; i >= 0; i-- {
+		fmt.Fprintf(&b, "level %d: ", i)
+	}
+	return b.String() + "root"
+}
+
+func TestErrChainLimits(t *testing.T) {
+	setupTest(t)
+	SetFormatter(&JSONFormatter{})
+	deep := errors.New("root")
+	for i := 0; i < 30; i++ {
+		deep = fmt.Errorf("level %d: %w", i, deep)
+	}
+	wide := make([]error, 150)
+	for i := range wide {
+		wide[i] = fmt.Errorf("child %d", i)
+	}
+	tests := []struct {
+		name string
+		err  error
+		want int
+		last errCause
+	}{
+		{"deep", deep, maxErrChainDepth, errCause{maxErrChainDepth, "*fmt.wrapError", "level 13: " + deepTail(13)}},
+		{"wide", errors.Join(wide...), maxErrChainCauses, errCause{1, "*errors.errorString", "child 99"}},
+	}
+	for _, tt := range tests {
+		var decoded struct{ Causes []errCause }
+		got := CaptureOutput(func() { ErrChain(tt.err) })
+		if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if len(decoded.Causes) != tt.want {
+			t.Errorf("%s: got %d causes, want %d", tt.name, len(decoded.Causes), tt.want)
+			continue
+		}
+		last := decoded.Causes[len(decoded.Causes)-1]
+		if last != tt.last {
+			t.Errorf("%s: got the last cause %+v, want %+v", tt.name, last, tt.last)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package clog
+
+import (
+	"regexp"
+	"sync"
+	"sync/atomic"
+)
+
+// escalationRule replaces the level of the messages that match pattern with level.
+type escalationRule struct {
+	pattern *regexp.Regexp
+	level   int
+}
+
+// escalationRules holds the escalation rules in registration order. It is replaced, never modified, so that
+// it can be read without a lock.
+var escalationRules atomic.Pointer[[]*escalationRule]
+var escalationRulesLock sync.Mutex
+
+// AddEscalationRule replaces the level of the messages that match pattern with newLevel, e.g. to escalate a
+// fatal condition that a third-party library logs at the Info level. The message is then filtered, decorated
+// and logged to the syslog like one logged at newLevel, and gets an escalated=true field. Rules are checked in
+// the order they were added, and the first one that matches wins. It returns a function that removes the rule.
+func AddEscalationRule(pattern *regexp.Regexp, newLevel int) (remove func()) {
+	rule := &escalationRule{pattern: pattern, level: newLevel}
+	escalationRulesLock.Lock()
+	defer escalationRulesLock.Unlock()
+	var rules []*escalationRule
+	if p := escalationRules.Load(); p != nil {
+		rules = append(rules, *p...)
+	}
+	rules = append(rules, rule)
+	escalationRules.Store(&rules)
+	return func() {
+		escalationRulesLock.Lock()
+		defer escalationRulesLock.Unlock()
+		var rules []*escalationRule
+		for _, r := range *escalationRules.Load() {
+			if r != rule {
+				rules = append(rules, r)
+			}
+		}
+		escalationRules.Store(&rules)
+	}
+}
+
+// hasEscalationRules reports whether an escalation rule is set.
+func hasEscalationRules() bool {
+	p := escalationRules.Load()
+	return p != nil && len(*p) > 0
+}
+
+// escalate applies the first escalation rule that matches the message of e, if any. It returns the Clogger
+// whose syslog writer e should be logged with: the default clogger of the new level if e was escalated, or l.
+func (l *Clogger) escalate(e *Entry) *Clogger {
+	p := escalationRules.Load()
+	if p == nil || len(*p) == 0 {
+		return l
+	}
+	for _, rule := range *p {
+		if !rule.pattern.MatchString(e.Message) {
+			continue
+		}
+		cl := defaultCloggerForLevel(rule.level)
+		e.Level = rule.level
+		e.Decorations, e.decorationCode = cl.decorations()
+		e.Fields = e.Fields.merge(Fields{{Key: "escalated", Value: true}})
+		return cl
+	}
+	return l
+}
@@ -0,0 +1,33 @@
+package clog
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestEscalationRule(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetLogLevel(LogLevelWarning)
+	remove := AddEscalationRule(regexp.MustCompile(`out of memory`), LogLevelCrit)
+	t.Cleanup(remove)
+	AddEscalationRule(regexp.MustCompile(`memory`), LogLevelError)()
+	levels := make(map[string]int)
+	addTestHook(t, func(e *Entry) { levels[e.Message] = e.Level })
+
+	lines := captureLines(func() {
+		// an escalated message passes the LogLevel of its new level
+		Info("worker out of memory")
+		Info("worker restarted")
+		remove()
+		Info("worker out of memory again")
+	})
+	if want := []string{"[INFO] worker out of memory escalated=true"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+	want := map[string]int{"worker out of memory": LogLevelCrit, "worker restarted": LogLevelInfo, "worker out of memory again": LogLevelInfo}
+	if !reflect.DeepEqual(levels, want) {
+		t.Errorf("got the levels %v, want %v", levels, want)
+	}
+}
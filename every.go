@@ -0,0 +1,81 @@
+package clog
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// throttler lets through at most one message per interval, and counts the messages it suppresses in between.
+type throttler struct {
+	interval time.Duration
+	// start is the time the throttler was created. next is the time, since start, at which the next message
+	// may be logged. Durations since start use the monotonic clock when the clock provides one.
+	start      time.Time
+	next       atomic.Int64
+	suppressed atomic.Uint64
+}
+
+// allow reports whether the next message should be logged and, if so, how many were suppressed since the
+// previous one.
+func (t *throttler) allow() (bool, uint64) {
+	elapsed := int64(now().Sub(t.start))
+	for {
+		next := t.next.Load()
+		if elapsed < next {
+			t.suppressed.Add(1)
+			return false, 0
+		}
+		if t.next.CompareAndSwap(next, elapsed+int64(t.interval)) {
+			return true, t.suppressed.Swap(0)
+		}
+	}
+}
+
+// Every returns a new Clogger, with the same configuration as l, that logs at most one message per interval
+// d, e.g. for a loop that keeps reporting that it is waiting. The other messages are dropped, and the next
+// logged message is annotated with "(suppressed N similar)". The messages that would not be written anywhere,
+// e.g. because they are below the LogLevel, do not count. Like Sampled, the interval is per returned Clogger,
+// so it should be created once and reused, and Cloggers derived from it share it. Other Cloggers are not
+// affected, so real errors are never dropped because of it. If d is not positive, every message is logged.
+func (l *Clogger) Every(d time.Duration) *Clogger {
+	child := l.pooledClone()
+	child.throttler = nil
+	if d > 0 {
+		child.throttler = &throttler{interval: d, start: now()}
+	}
+	return child
+}
+
+// DebugEvery returns the "Debug" default clogger, limited to one message per interval d (see Every).
+func DebugEvery(d time.Duration) *Clogger {
+	return defaultCloggerForLevel(LogLevelDebug).Every(d)
+}
+
+// InfoEvery returns the "Info" default clogger, limited to one message per interval d (see Every).
+func InfoEvery(d time.Duration) *Clogger {
+	return defaultCloggerForLevel(LogLevelInfo).Every(d)
+}
+
+// WarningEvery returns the "Warning" default clogger, limited to one message per interval d (see Every).
+func WarningEvery(d time.Duration) *Clogger {
+	return defaultCloggerForLevel(LogLevelWarning).Every(d)
+}
+
+// ErrorEvery returns the "Error" default clogger, limited to one message per interval d (see Every).
+func ErrorEvery(d time.Duration) *Clogger {
+	return defaultCloggerForLevel(LogLevelError).Every(d)
+}
+
+// throttle reports whether e should be logged by l, and annotates its message with the number of messages
+// suppressed before it if l is limited by Every.
+func (l *Clogger) throttle(e *Entry) bool {
+	if l.throttler == nil {
+		return true
+	}
+	ok, suppressed := l.throttler.allow()
+	if ok && suppressed > 0 {
+		e.Message += fmt.Sprintf(" (suppressed %d similar)", suppressed)
+	}
+	return ok
+}
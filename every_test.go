@@ -0,0 +1,91 @@
+package clog
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestInfoEvery logs every 50ms for 20s, with the clock advanced by the test, and expects one line every 10s.
+func TestInfoEvery(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	var clockLock sync.Mutex
+	clock := testTime
+	SetClock(func() time.Time {
+		clockLock.Lock()
+		defer clockLock.Unlock()
+		return clock
+	})
+	dbWait := InfoEvery(10 * time.Second)
+	got := captureLines(func() {
+		for i := 0; i <= 400; i++ {
+			dbWait.Printf("still waiting for DB (attempt %d)", i)
+			clockLock.Lock()
+			clock = clock.Add(50 * time.Millisecond)
+			clockLock.Unlock()
+		}
+	})
+	want := []string{
+		"[INFO] still waiting for DB (attempt 0)",
+		"[INFO] still waiting for DB (attempt 200) (suppressed 199 similar)",
+		"[INFO] still waiting for DB (attempt 400) (suppressed 199 similar)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestEveryIsPerInstance checks that the interval of a Clogger returned by Every does not limit the Clogger it
+// was derived from, nor another one returned by Every.
+func TestEveryIsPerInstance(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	a, b := InfoEvery(time.Hour), InfoEvery(time.Hour)
+	got := captureLines(func() {
+		a.Print("a1")
+		a.Print("a2")
+		b.Print("b1")
+		Info("info1")
+		Info("info2")
+	})
+	want := []string{"[INFO] a1", "[INFO] b1", "[INFO] info1", "[INFO] info2"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// a non positive interval logs every message
+	all := InfoEvery(0)
+	if got := captureLines(func() { all.Print("m"); all.Print("m") }); len(got) != 2 {
+		t.Errorf("got %q, want two lines", got)
+	}
+}
+
+// TestEveryConcurrent logs from many goroutines at the same instant, of which exactly one must pass.
+func TestEveryConcurrent(t *testing.T) {
+	setupTest(t)
+	const goroutines, messages = 50, 20
+	var out lockedBuffer
+	previous := GetOutput()
+	SetOutput(&out)
+	t.Cleanup(func() { SetOutput(previous) })
+	cl := WarningEvery(time.Minute)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < messages; i++ {
+				cl.Print("message")
+			}
+		}()
+	}
+	wg.Wait()
+	if got := strings.Count(out.String(), "\n"); got != 1 {
+		t.Errorf("got %d lines, want 1", got)
+	}
+	if s := cl.throttler.suppressed.Load(); s != goroutines*messages-1 {
+		t.Errorf("suppressed %d messages, want %d", s, goroutines*messages-1)
+	}
+}
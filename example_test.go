@@ -0,0 +1,68 @@
+package clog_test
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/teejays/clog"
+)
+
+// labelFormatter renders an entry as "label: [Logger] message", so that the example shows which destination
+// got which entry.
+type labelFormatter struct {
+	label string
+}
+
+func (f labelFormatter) Format(e *clog.Entry) string {
+	return fmt.Sprintf("%s: [%s] %s", f.label, e.Logger, e.Message)
+}
+
+func ExampleNewRuleRouter() {
+	clog.SetLogToStdOut(false)
+	defer clog.SetLogToStdOut(true)
+
+	errors := clog.NewDestination(os.Stdout, clog.WithFormatter(labelFormatter{"errors"}))
+	billing := clog.NewDestination(os.Stdout, clog.WithFormatter(labelFormatter{"billing"}))
+	archive := clog.NewDestination(os.Stdout, clog.WithFormatter(labelFormatter{"archive"}))
+	clog.SetRouter(clog.NewRuleRouter(
+		// the errors go to the errors destination, and on to the following rules
+		clog.RouteRule{Match: []clog.RouteMatcher{clog.MatchLevels(clog.LogLevelError, clog.MaxLevel)}, Destinations: []clog.Destination{errors}},
+		// the billing entries only go to the billing destination, and to errors if they are errors
+		clog.RouteRule{Match: []clog.RouteMatcher{clog.MatchTag("billing")}, Destinations: []clog.Destination{billing}, Final: true},
+		// everything else is archived
+		clog.RouteRule{Destinations: []clog.Destination{archive}},
+	))
+	defer clog.SetRouter(nil)
+
+	clog.Warn("cache miss")
+	clog.Error("disk full")
+	clog.GetCloggerByName("Info").Tagged("billing").Print("invoice sent")
+	clog.GetCloggerByName("Error").Tagged("billing").Print("card declined")
+	// Output:
+	// archive: [Warning] cache miss
+	// errors: [Error] disk full
+	// archive: [Error] disk full
+	// billing: [Info] invoice sent
+	// errors: [Error] card declined
+	// billing: [Error] card declined
+}
+
+func ExampleWithFormatter() {
+	defer clog.SetOutput(clog.GetOutput())
+	clog.SetOutput(os.Stdout)
+	clog.SetPrependTimestamp(false)
+	defer clog.SetPrependTimestamp(true)
+	clog.SetClock(func() time.Time { return time.Date(2024, 3, 5, 14, 7, 9, 0, time.UTC) })
+	defer clog.SetClock(nil)
+
+	// the entry is written as text to the output, and as JSON to the destination
+	jsonFile := clog.NewDestination(os.Stdout, clog.WithFormatter(&clog.JSONFormatter{}))
+	clog.SetRouter(clog.NewRuleRouter(clog.RouteRule{Destinations: []clog.Destination{jsonFile}}))
+	defer clog.SetRouter(nil)
+
+	clog.Infof("user %d signed in", 42)
+	// Output:
+	// [INFO] user 42 signed in
+	// {"time":"2024-03-05T14:07:09Z","level":"info","logger":"Info","msg":"user 42 signed in"}
+}
@@ -0,0 +1,95 @@
+package clog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ExitFunc is called by Fatal and its variants to terminate the process, with the exit code. It defaults to
+// os.Exit, and can be replaced e.g. in tests, to record the exit code instead of exiting.
+var ExitFunc func(code int) = os.Exit
+
+// ExitFlushTimeout bounds the time that Fatal and its variants wait for the destinations to be flushed and closed
+// before the process exits, so that an unreachable destination cannot keep it alive.
+var ExitFlushTimeout = 5 * time.Second
+
+var exitHooks []func()
+var exitHooksLock sync.Mutex
+
+// AddExitHook registers fn to be called by Fatal and its variants before the process exits, e.g. to flush
+// buffered data or close connections. Hooks are called in reverse order of registration, like deferred calls.
+func AddExitHook(fn func()) {
+	exitHooksLock.Lock()
+	defer exitHooksLock.Unlock()
+	exitHooks = append(exitHooks, fn)
+}
+
+// exit runs the exit hooks, flushes the output, flushes and closes the destinations for at most ExitFlushTimeout,
+// and calls ExitFunc with code. A panicking hook does not stop the others from running.
+func exit(code int) {
+	exitHooksLock.Lock()
+	hooks := append([]func(){}, exitHooks...)
+	exitHooksLock.Unlock()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		runExitHook(hooks[i])
+	}
+	flushOutput()
+	closeDestinations(ExitFlushTimeout)
+	ExitFunc(code)
+}
+
+// closeDestinations closes all the destinations concurrently, or flushes those that cannot be closed, so that the
+// entries they buffer, e.g. the last batch of a WebhookDestination or the end of a compressed stream, are not
+// lost. It returns once they are all done, or after timeout.
+func closeDestinations(timeout time.Duration) {
+	var wg sync.WaitGroup
+	for _, dest := range allDestinations() {
+		wg.Add(1)
+		go func(dest Destination) {
+			defer wg.Done()
+			if err := closeDestination(dest); err != nil {
+				internalf(LogLevelError, "failed to close destination before exiting: %v", err)
+			}
+		}(dest)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		internalf(LogLevelError, "destinations not closed after %s, exiting anyway", timeout)
+	}
+}
+
+// closeDestination closes dest if it is an io.Closer, or flushes it if it buffers data. A panic is returned as an
+// error.
+func closeDestination(dest Destination) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s: destination panicked: %v", PACKAGE_NAME, r)
+		}
+	}()
+	switch d := dest.(type) {
+	case io.Closer:
+		return d.Close()
+	case interface{ Flush() error }:
+		return d.Flush()
+	}
+	return nil
+}
+
+// runExitHook runs fn, reporting a panic to the internal logger, so that the other hooks still run.
+func runExitHook(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			internalf(LogLevelError, "exit hook panicked: %v", r)
+		}
+	}()
+	fn()
+}
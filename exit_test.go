@@ -0,0 +1,117 @@
+package clog
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestFatalFlushesDestinations logs with Fatal to a WebhookDestination whose batch window would never end, and
+// to a compressed destination, which both must have the last entry before ExitFunc is called.
+func TestFatalFlushesDestinations(t *testing.T) {
+	setupTest(t)
+	var lock sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lock.Lock()
+		defer lock.Unlock()
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+	var compressed closeRecorder
+	useDestinations(t,
+		NewWebhookDestination(server.URL, LogLevelDebug, time.Hour, 10),
+		NewDestination(&compressed, WithCompression(gzip.BestSpeed), WithFormatter(&JSONFormatter{})),
+	)
+
+	var atExit []string
+	previous := ExitFunc
+	ExitFunc = func(code int) {
+		lock.Lock()
+		defer lock.Unlock()
+		atExit = append([]string{}, bodies...)
+	}
+	t.Cleanup(func() { ExitFunc = previous })
+	CaptureOutput(func() { Fatal("disk full") })
+
+	if len(atExit) != 1 || !strings.Contains(atExit[0], `"msg":"disk full"`) {
+		t.Errorf("webhook: got %q at exit, want the last entry", atExit)
+	}
+	if !compressed.closed {
+		t.Error("the compressed destination was not closed")
+	}
+	entries := decodeEntries(t, compressed.Bytes(), false)
+	if len(entries) != 1 || entries[0]["msg"] != "disk full" {
+		t.Errorf("compressed: got %v, want the last entry", entries)
+	}
+}
+
+// blockingCloser is a Destination whose Close blocks until release is closed.
+type blockingCloser struct {
+	release chan struct{}
+}
+
+func (d *blockingCloser) Write(e *Entry) error { return nil }
+
+func (d *blockingCloser) Close() error {
+	<-d.release
+	return nil
+}
+
+// TestExitFlushTimeout checks that a destination that cannot be closed does not keep the process from exiting.
+func TestExitFlushTimeout(t *testing.T) {
+	setupTest(t)
+	events := recordInternal(t)
+	codes := stubExit(t)
+	d := &blockingCloser{release: make(chan struct{})}
+	defer close(d.release)
+	useDestinations(t, d)
+	previous := ExitFlushTimeout
+	ExitFlushTimeout = 10 * time.Millisecond
+	t.Cleanup(func() { ExitFlushTimeout = previous })
+
+	CaptureOutput(func() { Fatal("disk full") })
+	if len(*codes) != 1 || (*codes)[0] != 1 {
+		t.Errorf("got exit codes %v, want [1]", *codes)
+	}
+	if !events.contains("destinations not closed after 10ms") {
+		t.Errorf("the timeout was not reported, got %q", events.messages)
+	}
+}
+
+// TestExitHookPanic checks that a panicking exit hook is reported to the internal logger, not to the output, and
+// does not keep the other hooks from running.
+func TestExitHookPanic(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	events := recordInternal(t)
+	codes := stubExit(t)
+	exitHooksLock.Lock()
+	previous := exitHooks
+	exitHooksLock.Unlock()
+	t.Cleanup(func() {
+		exitHooksLock.Lock()
+		defer exitHooksLock.Unlock()
+		exitHooks = previous
+	})
+	var ran bool
+	AddExitHook(func() { ran = true })
+	AddExitHook(func() { panic("boom") })
+
+	lines := captureLines(func() { Fatal("disk full") })
+	if len(lines) != 1 || lines[0] != "[FATAL] disk full" {
+		t.Errorf("got %q, want only the fatal message", lines)
+	}
+	if !ran || len(*codes) != 1 {
+		t.Errorf("the other hook ran: %t, exit codes %v", ran, *codes)
+	}
+	if !events.contains("exit hook panicked: boom") {
+		t.Errorf("the panic was not reported, got %q", events.messages)
+	}
+}
@@ -0,0 +1,83 @@
+package clog
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+)
+
+/********************************************************************************
+* E X P E C T
+*********************************************************************************/
+
+// StrictAsserts flag determines whether a failed Assert terminates the process like Fatal, rather than only
+// logging a Crit message.
+var StrictAsserts bool = false
+
+// SetStrictAsserts sets the StrictAsserts flag. It is safe to call while other goroutines are logging.
+func SetStrictAsserts(b bool) {
+	setFlag(&StrictAsserts, b)
+}
+
+var failedExpectations uint64
+var failedAsserts uint64
+
+// Expect is a sanity check that does not abort: if cond is false, it logs msg as a Warning using the "Warning"
+// default clogger, with the location of the call, e.g. "expectation failed: rows sum to total (at
+// etl/load.go:42)". It returns cond. The message is logged once per call site, so that a check in a loop does
+// not flood the logs, but every failure is counted (see Stats.FailedExpectations).
+func Expect(cond bool, msg string) bool {
+	if !cond {
+		atomic.AddUint64(&failedExpectations, 1)
+		logFailedCheck(LogLevelWarning, "expectation failed", msg)
+	}
+	return cond
+}
+
+// Expectf is like Expect, with the message formatted using the provided args. The args are only formatted if
+// cond is false.
+func Expectf(cond bool, formatString string, args ...interface{}) bool {
+	if !cond {
+		atomic.AddUint64(&failedExpectations, 1)
+		logFailedCheck(LogLevelWarning, "expectation failed", sprintf(formatString, args...))
+	}
+	return cond
+}
+
+// Assert is like Expect, but logs the message at the Crit level, and counts it in Stats.FailedAsserts. If the
+// StrictAsserts flag is set, the failure is logged using the "Fatal" default clogger instead, and the process
+// is terminated with exit code 1, like Fatal.
+func Assert(cond bool, msg string) bool {
+	if !cond {
+		atomic.AddUint64(&failedAsserts, 1)
+		logFailedCheck(LogLevelCrit, "assertion failed", msg)
+	}
+	return cond
+}
+
+// Assertf is like Assert, with the message formatted using the provided args.
+func Assertf(cond bool, formatString string, args ...interface{}) bool {
+	if !cond {
+		atomic.AddUint64(&failedAsserts, 1)
+		logFailedCheck(LogLevelCrit, "assertion failed", sprintf(formatString, args...))
+	}
+	return cond
+}
+
+// logFailedCheck logs msg, prefixed with what failed and followed by the location of the check, at level, once per
+// call site. A failed assertion is fatal if the StrictAsserts flag is set.
+func logFailedCheck(level int, what string, msg string) {
+	site := "unknown"
+	if frame, ok := caller(); ok {
+		site = fmt.Sprintf("%s/%s:%d", packageName(frame.Function), filepath.Base(frame.File), frame.Line)
+	}
+	msg = fmt.Sprintf("%s: %s (at %s)", what, msg, site)
+	if level == LogLevelCrit && getFlag(&StrictAsserts) {
+		Fatal(msg)
+		return
+	}
+	if !firstOnce(what + " @ " + site) {
+		return
+	}
+	defaultCloggerForLevel(level).Print(msg)
+}
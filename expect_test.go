@@ -0,0 +1,83 @@
+package clog_test
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/teejays/clog"
+)
+
+// The failed checks report their call site, so they are tested from another package.
+
+// TestExpect checks that a failed expectation is logged once per call site, with its location, and that every
+// failure is counted.
+func TestExpect(t *testing.T) {
+	t.Cleanup(func() {
+		clog.SetPrependTimestamp(true)
+		clog.ResetAllOnce()
+		clog.ResetForTesting()
+	})
+	clog.ResetForTesting()
+	clog.ResetAllOnce()
+	clog.SetPrependTimestamp(false)
+	before := clog.GetStats()
+	var line int
+	got := clog.CaptureOutput(func() {
+		for i := 0; i < 3; i++ {
+			_, _, line, _ = runtime.Caller(0)
+			clog.Expectf(i == 1, "row %d is valid", i)
+		}
+		if !clog.Expect(true, "never logged") {
+			t.Error("Expect returned false for a true condition")
+		}
+	})
+	want := "[WARNING] expectation failed: row 0 is valid (at github.com/teejays/clog_test/expect_test.go:" +
+		strconv.Itoa(line+1) + ")\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if n := clog.GetStats().FailedExpectations - before.FailedExpectations; n != 2 {
+		t.Errorf("got %d failed expectations, want 2", n)
+	}
+}
+
+// TestStrictAsserts checks that a failed assertion is logged at the Crit level, or terminates the process if the
+// StrictAsserts flag is set.
+func TestStrictAsserts(t *testing.T) {
+	previous := clog.ExitFunc
+	var codes []int
+	clog.ExitFunc = func(code int) { codes = append(codes, code) }
+	t.Cleanup(func() {
+		clog.ExitFunc = previous
+		clog.SetStrictAsserts(false)
+		clog.SetPrependTimestamp(true)
+		clog.ResetAllOnce()
+		clog.ResetForTesting()
+	})
+	clog.ResetForTesting()
+	clog.ResetAllOnce()
+	clog.SetPrependTimestamp(false)
+	before := clog.GetStats()
+	check := func() bool { return clog.Assert(false, "balance is positive") }
+	_, _, line, _ := runtime.Caller(0)
+	site := "(at github.com/teejays/clog_test/expect_test.go:" + strconv.Itoa(line-1) + ")"
+	got := clog.CaptureOutput(func() {
+		if check() {
+			t.Error("Assert returned true for a false condition")
+		}
+		clog.SetStrictAsserts(true)
+		check()
+	})
+	want := "[CRIT] assertion failed: balance is positive " + site + "\n" +
+		"[FATAL] assertion failed: balance is positive " + site + "\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if len(codes) != 1 || codes[0] != 1 {
+		t.Errorf("got exit codes %v, want [1]", codes)
+	}
+	if n := clog.GetStats().FailedAsserts - before.FailedAsserts; n != 2 {
+		t.Errorf("got %d failed asserts, want 2", n)
+	}
+}
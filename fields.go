@@ -0,0 +1,256 @@
+package clog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/********************************************************************************
+* F I E L D S
+*********************************************************************************/
+
+// Field is a key-value pair attached to a logged message.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Fields is an ordered list of key-value pairs. Keys are unique: adding a key that already exists
+// replaces its value, but keeps its position.
+type Fields []Field
+
+// SortFields flag determines whether the fields of a message are rendered sorted by key. By default, they are
+// rendered in the order they were added: global fields first, then the fields of the Clogger (see WithFields),
+// then the fields passed to the log call.
+var SortFields bool = false
+
+// SetSortFields sets the SortFields flag. It is safe to call while other goroutines are logging.
+func SetSortFields(b bool) {
+	setFlag(&SortFields, b)
+}
+
+// Get returns the value of key, and whether it exists.
+func (f Fields) Get(key string) (interface{}, bool) {
+	for _, field := range f {
+		if field.Key == key {
+			return field.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Map returns the fields as a map.
+func (f Fields) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(f))
+	for _, field := range f {
+		m[field.Key] = field.Value
+	}
+	return m
+}
+
+// merge returns the fields of f followed by the fields of other. If a key exists in both, the value from
+// other is used at the position of the key in f. f is never modified.
+func (f Fields) merge(other Fields) Fields {
+	if len(other) == 0 {
+		return f
+	}
+	if len(f) == 0 {
+		return other
+	}
+	merged := make(Fields, len(f), len(f)+len(other))
+	copy(merged, f)
+	for _, field := range other {
+		merged = merged.set(field.Key, field.Value)
+	}
+	return merged
+}
+
+// set sets the value of key, appending it if it does not exist yet. It modifies f in place.
+func (f Fields) set(key string, value interface{}) Fields {
+	for i := range f {
+		if f[i].Key == key {
+			f[i].Value = value
+			return f
+		}
+	}
+	return append(f, Field{key, value})
+}
+
+// ordered returns the fields in the order they should be rendered, i.e. sorted by key if SortFields is set.
+func (f Fields) ordered() Fields {
+	if !getFlag(&SortFields) || len(f) < 2 {
+		return f
+	}
+	sorted := make(Fields, len(f))
+	copy(sorted, f)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	return sorted
+}
+
+var globalFields Fields
+var globalFieldsLock sync.RWMutex
+
+// SetGlobalFields replaces the global fields with fields, sorted by key. Global fields are attached to every
+// message logged by any Clogger. Fields attached to a message take precedence over the global fields.
+func SetGlobalFields(fields map[string]interface{}) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	global := make(Fields, 0, len(fields))
+	for _, k := range keys {
+		global = append(global, Field{k, fields[k]})
+	}
+
+	globalFieldsLock.Lock()
+	defer globalFieldsLock.Unlock()
+	globalFields = global
+}
+
+// AddGlobalField adds the key-value pair to the global fields, replacing any previous value of key.
+func AddGlobalField(key string, value interface{}) {
+	globalFieldsLock.Lock()
+	defer globalFieldsLock.Unlock()
+	// copied, since entries may still be holding the previous global fields
+	globalFields = append(Fields(nil), globalFields...).set(key, value)
+}
+
+// WithHostField adds the hostname of the machine as the "host" global field.
+func WithHostField() {
+	host, err := os.Hostname()
+	if err != nil {
+		internalf(LogLevelWarning, "could not add the host global field: %v", err)
+		return
+	}
+	AddGlobalField("host", host)
+}
+
+// WithPIDField adds the process id as the "pid" global field.
+func WithPIDField() {
+	AddGlobalField("pid", os.Getpid())
+}
+
+func getGlobalFields() Fields {
+	globalFieldsLock.RLock()
+	defer globalFieldsLock.RUnlock()
+	return globalFields
+}
+
+// WithFields returns a new Clogger, with the same configuration as l, that attaches keysAndValues as
+// fields to every message it logs, after the fields of l. keysAndValues should alternate between keys and
+// values, or be Fields. The returned Clogger is not registered, and later changes to the configuration of l do not
+// affect it. Short-lived children, e.g. one per request, can be given back using Release.
+func (l *Clogger) WithFields(keysAndValues ...interface{}) *Clogger {
+	child := l.pooledClone()
+	child.fields = l.fields.merge(fieldsFromKeysAndValues(keysAndValues))
+	return child
+}
+
+// fieldsFromKeysAndValues converts alternating keys and values into Fields. A Field, e.g. one created
+// using Dur or Bytes, can be given in place of a key and its value. A trailing key without a value gets a
+// (MISSING) value, and keys that are not strings are formatted using fmt.Sprint.
+func fieldsFromKeysAndValues(keysAndValues []interface{}) Fields {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+	fields := make(Fields, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if f, ok := keysAndValues[i].(Field); ok {
+			fields = fields.set(f.Key, f.Value)
+			i--
+			continue
+		}
+		key := fmt.Sprint(keysAndValues[i])
+		if i+1 >= len(keysAndValues) {
+			fields = fields.set(key, "(MISSING)")
+			continue
+		}
+		fields = fields.set(key, keysAndValues[i+1])
+	}
+	return fields
+}
+
+// formatFields renders fields as space separated key=value pairs, e.g. "user=42 path=/login".
+func formatFields(fields Fields) string {
+	var b strings.Builder
+	for i, f := range fields.ordered() {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		b.WriteString(formatFieldValue(f.Value))
+	}
+	return b.String()
+}
+
+// formatFieldValue formats a single field value, quoting it if it is empty or contains
+// spaces, quotes or an equal sign so that the key=value pairs remain unambiguous.
+func formatFieldValue(v interface{}) string {
+	s := sprint(v)
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// renderedValue is a field value rendered when the message was logged, see Fields.snapshot. It formats as text,
+// as JSON and flattened like the value did at that time.
+type renderedValue struct {
+	text string
+	json json.RawMessage
+	// flat are the flattened key-value pairs of the field, see flattenFields.
+	flat Fields
+}
+
+func (v renderedValue) String() string {
+	return v.text
+}
+
+func (v renderedValue) MarshalJSON() ([]byte, error) {
+	return v.json, nil
+}
+
+// snapshot returns a copy of f with the values that the caller could still change, e.g. slices, maps or
+// pointers, rendered as they are now. Entries that are held before they are written, e.g. by a Deferred, are
+// snapshotted so that they are rendered as they were when the message was logged. f is never modified.
+func (f Fields) snapshot() Fields {
+	if len(f) == 0 {
+		return f
+	}
+	snapshot := make(Fields, len(f))
+	for i, field := range f {
+		snapshot[i] = field
+		if !isImmutableValue(field.Value) {
+			snapshot[i].Value = renderedValue{
+				text: sprint(field.Value),
+				json: marshalJSONValue(field.Value),
+				flat: flattenValue(nil, field.Key, field.Value, 0, make(map[uintptr]bool)),
+			}
+		}
+	}
+	return snapshot
+}
+
+// isImmutableValue reports whether v cannot be changed once it is passed to a log call, i.e. whether it is nil,
+// a string, a bool or a number.
+func isImmutableValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+	return false
+}
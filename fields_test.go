@@ -0,0 +1,97 @@
+package clog
+
+import (
+	"strings"
+	"testing"
+)
+
+// captureOutput returns the output logged while fn runs, without the trailing newline.
+func captureOutput(fn func()) string {
+	return strings.Join(captureLines(fn), "\n")
+}
+
+// useGlobalFields sets the global fields until tb is done.
+func useGlobalFields(tb testing.TB, fields map[string]interface{}) {
+	previous := getGlobalFields()
+	SetGlobalFields(fields)
+	tb.Cleanup(func() {
+		globalFieldsLock.Lock()
+		defer globalFieldsLock.Unlock()
+		globalFields = previous
+	})
+}
+
+func TestFieldOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		formatter Formatter
+		sorted    bool
+		want      string
+	}{
+		{"text", &TextFormatter{}, false,
+			"[INFO] served app=api env=prod job=7 user=42 path=/login status=200 bytes=512"},
+		{"text sorted", &TextFormatter{}, true,
+			"[INFO] served app=api bytes=512 env=prod job=7 path=/login status=200 user=42"},
+		{"logfmt", &LogfmtFormatter{TimestampFormat: "T"}, false,
+			"time=T level=info logger=Info msg=served app=api env=prod job=7 user=42 path=/login status=200 bytes=512"},
+		{"logfmt sorted", &LogfmtFormatter{TimestampFormat: "T"}, true,
+			"time=T level=info logger=Info msg=served app=api bytes=512 env=prod job=7 path=/login status=200 user=42"},
+		{"json", &JSONFormatter{TimestampFormat: "T"}, false,
+			`{"time":"T","level":"info","logger":"Info","msg":"served","app":"api","env":"prod","job":7,"user":42,"path":"/login","status":200,"bytes":512}` + ""},
+		{"json sorted", &JSONFormatter{TimestampFormat: "T"}, true,
+			`{"time":"T","level":"info","logger":"Info","msg":"served","app":"api","bytes":512,"env":"prod","job":7,"path":"/login","status":200,"user":42}` + ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			SetPrependTimestamp(false)
+			SetFormatter(tt.formatter)
+			SetSortFields(tt.sorted)
+			// the global fields come first, sorted by key, then the scoped fields, then those of the Clogger in
+			// the order they were added, then those of the call
+			useGlobalFields(t, map[string]interface{}{"env": "prod", "app": "api"})
+			pop := PushFields(map[string]interface{}{"job": 7})
+			defer pop()
+			cl := GetCloggerByName("Info").WithFields("user", 42).WithFields("path", "/login")
+			got := captureOutput(func() { cl.PrintFields("served", "status", 200, "bytes", 512) })
+			if got != tt.want {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFieldOverrides replaces fields at every level, which keeps the position of the first occurrence of the key
+// with the value of the last one.
+func TestFieldOverrides(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	useGlobalFields(t, map[string]interface{}{"env": "prod", "region": "eu"})
+	cl := GetCloggerByName("Info").WithFields("user", 42, "region", "us").WithFields("user", 43)
+	got := captureOutput(func() { cl.PrintFields("served", "env", "staging", "status", 200) })
+	if want := "[INFO] served env=staging region=us user=43 status=200"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// the parent is not affected by the fields of its children
+	got = captureOutput(func() { GetCloggerByName("Info").WithFields("user", 42).Print("parent") })
+	if want := "[INFO] parent env=prod region=eu user=42"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestFieldOrderDeterministic logs the same fields many times, which must always render the same way.
+func TestFieldOrderDeterministic(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	useGlobalFields(t, map[string]interface{}{"e": 5, "d": 4, "c": 3, "b": 2, "a": 1})
+	cl := GetCloggerByName("Info").WithFields("z", 26, "y", 25, "x", 24)
+	first := captureOutput(func() { cl.Print("served") })
+	for i := 0; i < 100; i++ {
+		if got := captureOutput(func() { cl.Print("served") }); got != first {
+			t.Fatalf("got %q, then %q", first, got)
+		}
+	}
+	if want := "[INFO] served a=1 b=2 c=3 d=4 e=5 z=26 y=25 x=24"; first != want {
+		t.Errorf("got %q, want %q", first, want)
+	}
+}
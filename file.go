@@ -0,0 +1,223 @@
+package clog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+/********************************************************************************
+* F I L E
+*********************************************************************************/
+
+// fileState is the state of a WriterDestination that writes to a file, created using NewFileDestination.
+type fileState struct {
+	path    string
+	file    *os.File
+	size    int64
+	maxSize int64
+	header  func() string
+}
+
+// NewFileDestination returns a Destination that writes the entries as lines to the file at path, which is
+// created if needed and appended to otherwise. It accepts the options of NewDestination, and WithFileHeader,
+// WithHeaderFunc and WithMaxSize.
+func NewFileDestination(path string, opts ...DestOption) (*WriterDestination, error) {
+	d := NewDestination(nil, opts...)
+	if d.compression != nil {
+		return nil, fmt.Errorf("%s: a file destination cannot be compressed", PACKAGE_NAME)
+	}
+	if d.file == nil {
+		d.file = &fileState{}
+	}
+	d.file.path = path
+	if err := d.openFile(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// WithFileHeader sets whether a header is written at the top of every new file of a destination created using
+// NewFileDestination, including the files created by rotation: the time the file was opened, the hostname, the
+// PID, the path of the binary, its version and commit if they are available, and the LogLevel. The header is
+// written regardless of the LogLevel.
+func WithFileHeader(b bool) DestOption {
+	return destOptionFunc(func(d *WriterDestination) {
+		if d.file == nil {
+			d.file = &fileState{}
+		}
+		d.file.header = nil
+		if b {
+			d.file.header = defaultFileHeader
+		}
+	})
+}
+
+// WithHeaderFunc is like WithFileHeader, with fn returning the header instead of the default one.
+func WithHeaderFunc(fn func() string) DestOption {
+	return destOptionFunc(func(d *WriterDestination) {
+		if d.file == nil {
+			d.file = &fileState{}
+		}
+		d.file.header = fn
+	})
+}
+
+// WithMaxSize rotates the file of a destination created using NewFileDestination when writing a line would make
+// it larger than maxBytes: the file is renamed with the time of the rotation as a suffix, e.g.
+// app.log.20060102-150405.000000000, and a new file is created.
+func WithMaxSize(maxBytes int64) DestOption {
+	return destOptionFunc(func(d *WriterDestination) {
+		if d.file == nil {
+			d.file = &fileState{}
+		}
+		d.file.maxSize = maxBytes
+	})
+}
+
+// Reopen closes and reopens the file of d, e.g. after it was moved by an external log rotation tool. If the file
+// is new, the header is written to it. It returns an error if d was not created using NewFileDestination.
+func (d *WriterDestination) Reopen() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.file == nil || d.file.file == nil {
+		return fmt.Errorf("%s: the destination does not write to a file", PACKAGE_NAME)
+	}
+	d.file.file.Close()
+	return d.openFile()
+}
+
+// openFile opens the file of d, and writes the header if the file is empty. d.lock must be held, unless d is
+// being created.
+func (d *WriterDestination) openFile() error {
+	f, err := os.OpenFile(d.file.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("%s: could not open the log file: %w", PACKAGE_NAME, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("%s: could not open the log file: %w", PACKAGE_NAME, err)
+	}
+	d.w, d.file.file, d.file.size = f, f, info.Size()
+	if d.file.size == 0 && d.file.header != nil {
+		header := strings.TrimRight(d.file.header(), "\n") + "\n"
+		n, err := io.WriteString(f, header)
+		d.file.size += int64(n)
+		if err != nil {
+			return fmt.Errorf("%s: could not write the log file header: %w", PACKAGE_NAME, err)
+		}
+	}
+	return nil
+}
+
+// rotateFile rotates the file of d if writing n more bytes would make it larger than its maximum size. d.lock
+// must be held.
+func (d *WriterDestination) rotateFile(n int) error {
+	fs := d.file
+	if fs == nil || fs.file == nil || fs.maxSize <= 0 || fs.size == 0 || fs.size+int64(n) <= fs.maxSize {
+		return nil
+	}
+	fs.file.Close()
+	if err := os.Rename(fs.path, fs.path+"."+now().Format("20060102-150405.000000000")); err != nil {
+		internalf(LogLevelError, "could not rotate the log file %s: %v", fs.path, err)
+	}
+	return d.openFile()
+}
+
+// defaultFileHeader returns the header written by WithFileHeader.
+func defaultFileHeader() string {
+	binary, err := os.Executable()
+	if err != nil {
+		binary = os.Args[0]
+	}
+	version, commit := "unknown", "unknown"
+	info := buildInfoFields()
+	for i := 0; i+1 < len(info); i += 2 {
+		switch info[i] {
+		case "version":
+			version = fmt.Sprint(info[i+1])
+		case "vcs.revision":
+			commit = fmt.Sprint(info[i+1])
+		}
+	}
+	lines := []string{
+		fmt.Sprintf("# log file opened at %s", now().Format(time.RFC3339)),
+		fmt.Sprintf("# host: %s, pid: %d", hostname, pid),
+		fmt.Sprintf("# binary: %s, version: %s, commit: %s", binary, version, commit),
+		fmt.Sprintf("# level: %s", levelName(GetLogLevel())),
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tailChunkSize is the number of bytes read at a time by Tail, backwards from the end of the file.
+const tailChunkSize = 4096
+
+// Tail returns the last n lines of the file of d, without reading the whole file, and without the decorations.
+// Lines written while it reads are not included. It returns an error if d was not created using
+// NewFileDestination.
+func (d *WriterDestination) Tail(n int) ([]string, error) {
+	d.lock.Lock()
+	if d.file == nil || d.file.file == nil {
+		d.lock.Unlock()
+		return nil, fmt.Errorf("%s: the destination does not write to a file", PACKAGE_NAME)
+	}
+	// the lines are written under the lock, so the file only holds whole lines up to size
+	path, size := d.file.path, d.file.size
+	d.lock.Unlock()
+	if n <= 0 {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: could not read the log file: %w", PACKAGE_NAME, err)
+	}
+	defer f.Close()
+
+	var data []byte
+	var breaks int
+	offset := size
+	// n lines are complete once n+1 line breaks are found, or the start of the file is reached
+	for offset > 0 && breaks <= n {
+		chunk := int64(tailChunkSize)
+		if chunk > offset {
+			chunk = offset
+		}
+		offset -= chunk
+		buf := make([]byte, chunk)
+		if _, err := f.ReadAt(buf, offset); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("%s: could not read the log file: %w", PACKAGE_NAME, err)
+		}
+		breaks += bytes.Count(buf, []byte("\n"))
+		data = append(buf, data...)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if offset > 0 {
+		// the first line is incomplete
+		lines = lines[1:]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	if len(lines) == 1 && lines[0] == "" {
+		return nil, nil
+	}
+	for i := range lines {
+		lines[i] = StripDecorations(lines[i])
+	}
+	return lines, nil
+}
+
+// activeFileDestination returns the first destination added using AddDestination that writes to a file, or nil
+// if there is none.
+func activeFileDestination() *WriterDestination {
+	for _, dest := range getDestinations() {
+		if d, ok := dest.(*WriterDestination); ok && d.file != nil {
+			return d
+		}
+	}
+	return nil
+}
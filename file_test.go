@@ -0,0 +1,110 @@
+package clog
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readLines returns the lines of the file at path.
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestFileHeader(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetLogLevel(LogLevelWarning)
+	path := filepath.Join(t.TempDir(), "app.log")
+	d, err := NewFileDestination(path, WithFileHeader(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	useDestinations(t, d)
+	CaptureOutput(func() { Error("disk full") })
+
+	lines := readLines(t, path)
+	if len(lines) != 5 || lines[0] != "# log file opened at "+testTime.Format(time.RFC3339) ||
+		!strings.HasPrefix(lines[2], "# binary: ") || lines[3] != "# level: warning" || lines[4] != "[ERROR] disk full" {
+		t.Errorf("got %q, want the header followed by the entry", lines)
+	}
+	// a file that is not empty keeps its header
+	if err := d.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	CaptureOutput(func() { Error("disk still full") })
+	if lines := readLines(t, path); len(lines) != 6 || lines[5] != "[ERROR] disk still full" {
+		t.Errorf("got %q after reopening, want a single header", lines)
+	}
+}
+
+// TestFileHeaderRotation checks that every file created by the rotation starts with the header.
+func TestFileHeaderRotation(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	clock := testTime
+	SetClock(func() time.Time { return clock })
+	d, err := NewFileDestination(path, WithHeaderFunc(func() string { return "# opened " + now().Format(time.TimeOnly) }), WithMaxSize(40))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	useDestinations(t, d)
+	CaptureOutput(func() {
+		Info("first entry")
+		clock = clock.Add(time.Second)
+		Info("second entry, which does not fit")
+	})
+
+	rotated := path + "." + clock.Format("20060102-150405.000000000")
+	if got, want := readLines(t, rotated), []string{"# opened 14:07:09", "[INFO] first entry"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rotated file: got %q, want %q", got, want)
+	}
+	if got, want := readLines(t, path), []string{"# opened 14:07:10", "[INFO] second entry, which does not fit"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("new file: got %q, want %q", got, want)
+	}
+	// the headers are not entries
+	if entries, err := MergeEntries(strings.NewReader("# opened 14:07:09\n")); err != nil || len(entries) != 0 {
+		t.Errorf("MergeEntries read the header as %v, %v", entries, err)
+	}
+}
+
+// TestTail writes more than a chunk of lines, so that Tail reads the file backwards in several chunks.
+func TestTail(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetColorMode(ColorAlways)
+	d, err := NewFileDestination(filepath.Join(t.TempDir(), "app.log"), WithFileHeader(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	useDestinations(t, d)
+	CaptureOutput(func() {
+		for i := 0; i < 500; i++ {
+			Infof("entry %d", i)
+		}
+	})
+	// the decorations are stripped
+	got, err := d.Tail(3)
+	if want := []string{"[INFO] entry 497", "[INFO] entry 498", "[INFO] entry 499"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Tail(3) = %q, %v, want %q", got, err, want)
+	}
+	if got, err := d.Tail(1000); err != nil || len(got) != 504 || got[0] != "# log file opened at "+testTime.Format(time.RFC3339) {
+		t.Errorf("Tail(1000) = %d lines, %v, want the header and the 500 entries", len(got), err)
+	}
+	if _, err := NewDestination(&lockedBuffer{}).Tail(3); err == nil {
+		t.Error("Tail of a destination that does not write to a file did not fail")
+	}
+}
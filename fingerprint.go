@@ -0,0 +1,85 @@
+package clog
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sync"
+	"sync/atomic"
+)
+
+/********************************************************************************
+* F I N G E R P R I N T
+*********************************************************************************/
+
+// normalizeRule replaces the matches of pattern with placeholder.
+type normalizeRule struct {
+	pattern     *regexp.Regexp
+	placeholder string
+}
+
+// builtinNormalizeRules are applied by NormalizeMessage after the rules added using AddNormalizeRule, in order:
+// the more specific patterns come first, so that e.g. the digits of a UUID are not replaced on their own.
+var builtinNormalizeRules = []normalizeRule{
+	{regexp.MustCompile(`"(?:[^"\\]|\\.)*"`), "<str>"},
+	{regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`\b(?:0[xX][0-9a-fA-F]+|[0-9a-fA-F]*[0-9][0-9a-fA-F]*[a-fA-F][0-9a-fA-F]*|[0-9a-fA-F]*[a-fA-F][0-9a-fA-F]*[0-9][0-9a-fA-F]*)\b`), "<hex>"},
+	{regexp.MustCompile(`\d+`), "<n>"},
+}
+
+// normalizeRules holds the rules added using AddNormalizeRule. Like escalationRules, it is replaced, never
+// modified, so that it can be read without a lock.
+var normalizeRules atomic.Pointer[[]normalizeRule]
+var normalizeRulesLock sync.Mutex
+
+// AddNormalizeRule adds a rule to NormalizeMessage, which replaces the matches of pattern with placeholder,
+// e.g. to replace e-mail addresses with "<email>". The added rules are applied in the order they were added,
+// before the built-in ones.
+func AddNormalizeRule(pattern *regexp.Regexp, placeholder string) {
+	normalizeRulesLock.Lock()
+	defer normalizeRulesLock.Unlock()
+	var rules []normalizeRule
+	if p := normalizeRules.Load(); p != nil {
+		rules = append(rules, *p...)
+	}
+	rules = append(rules, normalizeRule{pattern, placeholder})
+	normalizeRules.Store(&rules)
+}
+
+// NormalizeMessage replaces the variable parts of msg with placeholders, so that messages that only differ in
+// them are the same, e.g. `order 12345 of "bob" failed` becomes `order <n> of <str> failed`. Quoted strings,
+// UUIDs, hex IDs and runs of digits are replaced, after the rules added using AddNormalizeRule.
+func NormalizeMessage(msg string) string {
+	if p := normalizeRules.Load(); p != nil {
+		for _, rule := range *p {
+			msg = rule.pattern.ReplaceAllLiteralString(msg, rule.placeholder)
+		}
+	}
+	for _, rule := range builtinNormalizeRules {
+		msg = rule.pattern.ReplaceAllLiteralString(msg, rule.placeholder)
+	}
+	return msg
+}
+
+// Fingerprint returns the fingerprint of msg: the FNV-64a hash of NormalizeMessage(msg), in hex.
+func Fingerprint(msg string) string {
+	h := fnv.New64a()
+	h.Write([]byte(NormalizeMessage(msg)))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+var fingerprinting atomic.Bool
+
+// EnableFingerprinting attaches a fingerprint field to the messages logged at the Warning level or above, e.g.
+// fingerprint=5d1f0c6f9a3e2b71, so that error trackers can group messages that only differ in IDs (see
+// Fingerprint). Calling it more than once does no harm.
+func EnableFingerprinting() {
+	if !fingerprinting.CompareAndSwap(false, true) {
+		return
+	}
+	AddHook(HookFunc(func(e *Entry) {
+		if IsAtLeast(e.Level, LogLevelWarning) {
+			e.Fields = e.Fields.merge(Fields{{"fingerprint", Fingerprint(e.Message)}})
+		}
+	}))
+}
@@ -0,0 +1,89 @@
+package clog
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNormalizeMessage(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"order 12345 failed", "order <n> failed"},
+		{`order 12345 of "bob" failed`, `order <n> of <str> failed`},
+		{`user "a \"quoted\" name" not found`, "user <str> not found"},
+		{"request 0x1f3a timed out", "request <hex> timed out"},
+		{"commit 9fceb02d0ae598e95dc970b74767f19372d61af8 not found", "commit <hex> not found"},
+		{"session 123e4567-e89b-12d3-a456-426614174000 expired", "session <uuid> expired"},
+		{"retrying in 3s after 2 attempts", "retrying in <n>s after <n> attempts"},
+		{"cache miss", "cache miss"},
+		// words made of hex letters only are not IDs
+		{"bad cafe", "bad cafe"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeMessage(tt.msg); got != tt.want {
+			t.Errorf("NormalizeMessage(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestAddNormalizeRule(t *testing.T) {
+	t.Cleanup(func() { normalizeRules.Store(nil) })
+	AddNormalizeRule(regexp.MustCompile(`[\w.]+@[\w.]+`), "<email>")
+	if got, want := NormalizeMessage("mail to bob42@example.com bounced 3 times"), "mail to <email> bounced <n> times"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if Fingerprint("mail to bob@example.com bounced") != Fingerprint("mail to alice@example.org bounced") {
+		t.Error("messages that only differ in e-mail addresses have different fingerprints")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a, b := Fingerprint(`order 12345 of "bob" failed`), Fingerprint(`order 67890 of "alice" failed`)
+	if a != b {
+		t.Errorf("messages that only differ in IDs have different fingerprints: %s and %s", a, b)
+	}
+	if len(a) != 16 {
+		t.Errorf("got the fingerprint %q, want 16 hex digits", a)
+	}
+	if c := Fingerprint("order 12345 shipped"); c == a {
+		t.Errorf("different messages have the same fingerprint %s", c)
+	}
+}
+
+func TestEnableFingerprinting(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	hooksLock.Lock()
+	previous := hooks
+	hooksLock.Unlock()
+	t.Cleanup(func() {
+		hooksLock.Lock()
+		defer hooksLock.Unlock()
+		hooks = previous
+		fingerprinting.Store(false)
+	})
+	EnableFingerprinting()
+	EnableFingerprinting()
+
+	fingerprint := Fingerprint("order 1 failed")
+	got := captureLines(func() {
+		Warning("order 12345 failed")
+		Error("order 67890 failed")
+		Info("order 12345 shipped")
+	})
+	want := []string{
+		"[WARNING] order 12345 failed fingerprint=" + fingerprint,
+		"[ERROR] order 67890 failed fingerprint=" + fingerprint,
+		"[INFO] order 12345 shipped",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package clog
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// maxFlattenDepth is the maximum depth that nested values are flattened to. Deeper values are rendered as is.
+const maxFlattenDepth = 8
+
+// flattenFields returns fields with nested maps, structs, slices and arrays flattened into dotted keys, e.g. a
+// field "http" with the value map[string]int{"status": 500} becomes "http.status" with the value 500, and
+// slices become "key.0", "key.1"... Values implementing fmt.Stringer or error are not flattened, unexported
+// struct fields are skipped, and nil values are rendered as null.
+func flattenFields(fields Fields) Fields {
+	flat := make(Fields, 0, len(fields))
+	for _, f := range fields {
+		flat = flattenValue(flat, f.Key, f.Value, 0, make(map[uintptr]bool))
+	}
+	return flat
+}
+
+// flattenValue appends the flattened key-value pairs of v to flat. seen holds the pointers that are being
+// flattened on the current path, so that cycles are detected.
+func flattenValue(flat Fields, key string, v interface{}, depth int, seen map[uintptr]bool) Fields {
+	if v == nil {
+		return flat.set(key, "null")
+	}
+	switch v := v.(type) {
+	case renderedValue:
+		for _, f := range v.flat {
+			flat = flat.set(f.Key, f.Value)
+		}
+		return flat
+	case fmt.Stringer, error:
+		return flat.set(key, v)
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return flat.set(key, "null")
+		}
+		if rv.Kind() == reflect.Ptr {
+			if seen[rv.Pointer()] {
+				return flat.set(key, "<cycle>")
+			}
+			seen[rv.Pointer()] = true
+			defer delete(seen, rv.Pointer())
+		}
+		rv = rv.Elem()
+		if rv.CanInterface() {
+			switch rv.Interface().(type) {
+			case fmt.Stringer, error:
+				return flat.set(key, rv.Interface())
+			}
+		}
+	}
+	if depth >= maxFlattenDepth {
+		return flat.set(key, v)
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.IsNil() {
+			return flat.set(key, "null")
+		}
+		if seen[rv.Pointer()] {
+			return flat.set(key, "<cycle>")
+		}
+		seen[rv.Pointer()] = true
+		defer delete(seen, rv.Pointer())
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, k := range keys {
+			flat = flattenValue(flat, key+"."+fmt.Sprint(k), rv.MapIndex(k).Interface(), depth+1, seen)
+		}
+		return flat
+	case reflect.Struct:
+		t := rv.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			flat = flattenValue(flat, key+"."+t.Field(i).Name, rv.Field(i).Interface(), depth+1, seen)
+		}
+		return flat
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice {
+			if rv.IsNil() {
+				return flat.set(key, "null")
+			}
+			if rv.Type().Elem().Kind() == reflect.Uint8 {
+				// byte slices are rendered as a single value
+				return flat.set(key, v)
+			}
+		}
+		for i := 0; i < rv.Len(); i++ {
+			flat = flattenValue(flat, key+"."+strconv.Itoa(i), rv.Index(i).Interface(), depth+1, seen)
+		}
+		return flat
+	}
+	if rv.CanInterface() {
+		return flat.set(key, rv.Interface())
+	}
+	return flat.set(key, v)
+}
@@ -0,0 +1,103 @@
+package clog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type flattenRequest struct {
+	Method  string
+	Status  int
+	secret  string
+	Headers map[string]string
+	Next    *flattenRequest
+	Took    time.Duration
+}
+
+// flattenNode points to itself, to test cycles.
+type flattenNode struct {
+	Name string
+	Next *flattenNode
+}
+
+func TestFlattenFields(t *testing.T) {
+	node := &flattenNode{Name: "a"}
+	node.Next = node
+	var nilRequest *flattenRequest
+	var nilMap map[string]int
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"scalar", 500, "v=500"},
+		{"map", map[string]interface{}{"status": 500, "method": "GET"}, "v.method=GET v.status=500"},
+		{"nested map", map[string]interface{}{"http": map[string]int{"status": 500}}, "v.http.status=500"},
+		{"slice", []string{"a", "b"}, "v.0=a v.1=b"},
+		{"array", [2]int{1, 2}, "v.0=1 v.1=2"},
+		{"bytes", []byte("raw"), "v=\"[114 97 119]\""},
+		// the unexported fields are skipped, and the nil pointer is rendered as null
+		{"struct", flattenRequest{Method: "GET", Status: 200, secret: "hidden", Headers: map[string]string{"Accept": "*/*"}},
+			"v.Method=GET v.Status=200 v.Headers.Accept=*/* v.Next=null v.Took=0s"},
+		{"pointer to struct", &flattenRequest{Method: "POST", Next: &flattenRequest{Method: "GET"}},
+			"v.Method=POST v.Status=0 v.Headers=null v.Next.Method=GET v.Next.Status=0 v.Next.Headers=null v.Next.Next=null v.Next.Took=0s v.Took=0s"},
+		{"nil pointer", nilRequest, "v=null"},
+		{"nil map", nilMap, "v=null"},
+		{"nil slice", []int(nil), "v=null"},
+		{"nil", nil, "v=null"},
+		{"stringer", time.Second, "v=1s"},
+		{"error", errors.New("disk full"), "v=\"disk full\""},
+		{"stringer in a map", map[string]interface{}{"took": 2 * time.Second}, "v.took=2s"},
+		{"cycle", node, "v.Name=a v.Next=<cycle>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatFields(flattenFields(Fields{{"v", tt.value}}))
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlattenDepthLimit(t *testing.T) {
+	var v interface{} = "leaf"
+	for i := 0; i < maxFlattenDepth+2; i++ {
+		v = map[string]interface{}{"n": v}
+	}
+	got := formatFields(flattenFields(Fields{{"v", v}}))
+	key := "v" + strings.Repeat(".n", maxFlattenDepth)
+	if !strings.HasPrefix(got, key+"=") || strings.Contains(got, key+".n=") {
+		t.Errorf("got %q, want the value under %s rendered as is", got, key)
+	}
+}
+
+func TestFlattenFormatters(t *testing.T) {
+	fields := []interface{}{"http", map[string]interface{}{"status": 500, "path": "/login"}, "ids", []int{1, 2}}
+	tests := []struct {
+		name      string
+		formatter Formatter
+		want      string
+	}{
+		{"text", &TextFormatter{Flatten: true}, "[ERROR] failed http.path=/login http.status=500 ids.0=1 ids.1=2\n"},
+		{"text not flattened", &TextFormatter{}, "[ERROR] failed http=\"map[path:/login status:500]\" ids=\"[1 2]\"\n"},
+		{"logfmt", &LogfmtFormatter{Flatten: true},
+			"time=2024-03-05T14:07:09.123456789Z level=error logger=Error msg=failed http.path=/login http.status=500 ids.0=1 ids.1=2\n"},
+		// the JSON formatter nests the values instead
+		{"json", &JSONFormatter{},
+			`{"time":"2024-03-05T14:07:09.123456789Z","level":"error","logger":"Error","msg":"failed","http":{"path":"/login","status":500},"ids":[1,2]}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			SetPrependTimestamp(false)
+			SetFormatter(tt.formatter)
+			got := CaptureOutput(func() { GetCloggerByName("Error").PrintFields("failed", fields...) })
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package clog
+
+import (
+	"strings"
+	"sync"
+)
+
+// WarnOnFormatErrors flag determines whether formatted log calls (Printf, Infof...) are checked for mistakes
+// in their format string and args, such as a missing or an extra arg, which fmt silently renders as
+// %!s(MISSING) or %!(EXTRA ...). The first time a call site makes such a mistake, a Warning is logged with
+// its location. The check is a simple scan of the formatted message, but it is off by default.
+var WarnOnFormatErrors bool = false
+
+// formatErrorSites holds the file:line of the call sites that have already been warned about.
+var formatErrorSites sync.Map
+
+// hasFormatError reports whether msg contains one of the markers that fmt writes when the verbs and the args
+// of a format string do not match. Markers of recovered panics, "%!v(PANIC=", are not format errors.
+func hasFormatError(msg string) bool {
+	for {
+		i := strings.Index(msg, "%!")
+		if i < 0 {
+			return false
+		}
+		msg = msg[i+2:]
+		if j := strings.IndexByte(msg, '('); j >= 0 && !strings.HasPrefix(msg[j:], panicMarker) {
+			return true
+		}
+	}
+}
+
+// warnFormatError logs a Warning about the formatString, unless the call site that logged it has already been
+// warned about.
+func warnFormatError(formatString string) {
+	location := callerLocation()
+	if _, warned := formatErrorSites.LoadOrStore(location, true); warned {
+		return
+	}
+	defaultCloggerForLevel(LogLevelWarning).PrintFields("log call with mismatched format string and args", "format", formatString, "caller", location)
+}
@@ -0,0 +1,397 @@
+package clog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+/********************************************************************************
+* F O R M A T T E R
+*********************************************************************************/
+
+// Formatter renders an Entry as a single line for the standard output.
+type Formatter interface {
+	Format(e *Entry) string
+}
+
+var formatter Formatter = &TextFormatter{}
+var formatterLock sync.RWMutex
+
+// SetFormatter sets the Formatter used to render messages logged to the standard output. The
+// default Formatter is a TextFormatter.
+func SetFormatter(f Formatter) {
+	formatterLock.Lock()
+	defer formatterLock.Unlock()
+	formatter = f
+}
+
+// formatEntry renders e using f. If f panics, the panic is recovered and the message is rendered with a
+// placeholder describing the panic instead. The line is cached in e, so that an entry written to several
+// destinations, e.g. text to the output and JSON to a file, is only formatted once per Formatter.
+func formatEntry(f Formatter, e *Entry) (line string) {
+	key := formattedKey{f, e.highlight}
+	// Formatters of uncomparable types cannot be looked up, and are not cached
+	cacheable := f != nil && reflect.TypeOf(f).Comparable()
+	if cacheable {
+		for _, c := range e.formatted {
+			if c.key == key {
+				return c.line
+			}
+		}
+		defer func() { e.formatted = append(e.formatted, formattedLine{key, line}) }()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			line = recoveredPanic("Format", r) + " " + e.Message
+			internalf(LogLevelError, "Formatter %T panicked: %v", f, r)
+		}
+	}()
+	return f.Format(e)
+}
+
+// formattedKey identifies the rendering of an entry: its Formatter, and whether it is highlighted (see
+// AddHighlight).
+type formattedKey struct {
+	f         Formatter
+	highlight bool
+}
+
+// formattedLine is a rendering of an entry, cached in the entry.
+type formattedLine struct {
+	key  formattedKey
+	line string
+}
+
+// GetFormatter returns the Formatter used to render messages logged to the standard output.
+func GetFormatter() Formatter {
+	formatterLock.RLock()
+	defer formatterLock.RUnlock()
+	return formatter
+}
+
+// TextFormatter is the default, human friendly, Formatter. It renders an entry as
+// "[TIMESTAMP ][[HOST] ][[PID] ][[gID] ][FILE:LINE: ][[NAME] ]message key=value...", honoring the Prepend flags
+// the ColorMode and the BadgeStyle flag, or their overrides for the Clogger that logged the message.
+type TextFormatter struct {
+	// Flatten determines whether nested maps, structs and slices in the fields are flattened into
+	// dotted keys, e.g. http.status=500.
+	Flatten bool
+	// MetadataDecorations, if set, decorate the timestamp and the name of the logger separately from the
+	// message, e.g. DIM to let the eye land on the message, which keeps the decorations of the Clogger. By
+	// default, the name is decorated along with the message and the timestamp is not decorated.
+	MetadataDecorations []Decoration
+}
+
+// Format renders e as a line of text. If WrapMessages is set, it may render it as multiple lines.
+func (f *TextFormatter) Format(e *Entry) string {
+	var prefix string
+	if e.toggles.timestamp.resolve(&PrependTimestamp) {
+		prefix += e.Time.Format(e.timestampFormatOr(TimestampFormat)) + " "
+	}
+	if e.Host != "" {
+		prefix += fmt.Sprintf("[%s] ", e.Host)
+	}
+	if e.PID != 0 {
+		prefix += fmt.Sprintf("[%d] ", e.PID)
+	}
+	if e.GoroutineID != 0 {
+		prefix += fmt.Sprintf("[g%d] ", e.GoroutineID)
+	}
+	if e.Caller != "" {
+		prefix += e.Caller + ": "
+	}
+	decorated := entryColorsEnabled(e)
+	badge := decorated && e.toggles.badge.resolve(&BadgeStyle)
+	var name string
+	// a badge already shows the level, so the name is only repeated if it differs
+	if e.toggles.loggerName.resolve(&PrependLoggerName) && !(badge && strings.EqualFold(e.Logger, levelName(e.Level))) {
+		name = namePrefixFor(e.Logger, e.Level)
+	}
+	// the messages of groups are indented after the name, so that the timestamps stay aligned
+	name += scopeIndent(e.Scope)
+	var badgeToken string
+	if badge {
+		badgeToken = e.badge()
+	}
+	msg := e.Message
+	if len(e.Fields) > 0 {
+		fields := e.Fields
+		if f.Flatten {
+			fields = flattenFields(fields)
+		}
+		msg += " " + formatFields(fields)
+	}
+	for _, tag := range e.Tags {
+		msg += " #" + tag
+	}
+	if decorated && e.highlight {
+		var base string
+		if !badge {
+			base = e.decorationPrefix()
+		}
+		msg = highlight(msg, base)
+	}
+	lines := []string{msg}
+	indent := VisibleLength(prefix) + VisibleLength(badgeToken) + VisibleLength(name)
+	if width := wrapWidth(); width > 0 && width-indent >= minWrapWidth {
+		lines = wrapText(msg, width-indent)
+	}
+	// each line is decorated separately, so that the indentation of the continuation lines is not decorated
+	colored := func(s string) string {
+		if !decorated || badge {
+			return s
+		}
+		return decorateWithCode(s, e.decorationPrefix())
+	}
+	var metaCode string
+	if decorated {
+		metaCode = joinDecorations(f.MetadataDecorations)
+	}
+	metadata := func(s string) string {
+		if metaCode == "" || s == "" {
+			return s
+		}
+		return decorateWithCode(s, metaCode)
+	}
+	var b strings.Builder
+	switch {
+	case badge:
+		b.WriteString(metadata(prefix) + badgeToken + metadata(name) + lines[0])
+	case metaCode != "":
+		b.WriteString(metadata(prefix+name) + colored(lines[0]))
+	default:
+		// by default, the name is decorated along with the message
+		b.WriteString(prefix + colored(name+lines[0]))
+	}
+	for _, line := range lines[1:] {
+		b.WriteString("\n" + strings.Repeat(" ", indent) + colored(line))
+	}
+	return b.String()
+}
+
+// JSONFormatter renders an entry as a JSON object with the keys time, level, logger and msg, the
+// host, pid and goroutine keys if they are set, followed by the fields as top-level keys. Fields
+// named after one of those keys are prefixed with "fields.".
+type JSONFormatter struct {
+	// TimestampFormat is the format of the time key. It defaults to time.RFC3339Nano.
+	TimestampFormat string
+	// TimestampKey is the key of the time, "time" by default, e.g. "ts".
+	TimestampKey string
+	// UTC renders the time in UTC, whatever the location of the entries.
+	UTC bool
+	// UnitKeys appends the unit of the fields created using Dur and Bytes to their keys, e.g. latency_ms and
+	// size_bytes.
+	UnitKeys bool
+}
+
+// NewJSONFormatter returns a JSONFormatter configured with opts, e.g.
+//
+//	NewJSONFormatter(WithTimestampKey("ts"), WithTimestampLayout(time.RFC3339Nano, true))
+func NewJSONFormatter(opts ...FormatterOption) *JSONFormatter {
+	f := &JSONFormatter{}
+	for _, opt := range opts {
+		opt(&structuredOptions{&f.TimestampKey, &f.TimestampFormat, &f.UTC})
+	}
+	return f
+}
+
+// Format renders e as a JSON object.
+func (f *JSONFormatter) Format(e *Entry) string {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	timeKey := timestampKeyOrDefault(f.TimestampKey)
+	writeJSONPair(&b, timeKey, structuredTimestamp(e, f.TimestampFormat, f.UTC))
+	b.WriteByte(',')
+	writeJSONPair(&b, "level", levelName(e.Level))
+	b.WriteByte(',')
+	writeJSONPair(&b, "logger", e.Logger)
+	b.WriteByte(',')
+	writeJSONPair(&b, "msg", e.Message)
+	for _, p := range processPairs(e) {
+		b.WriteByte(',')
+		writeJSONPair(&b, p.key, p.value)
+	}
+	for _, field := range e.Fields.ordered() {
+		key := structuredFieldKey(field.Key, timeKey)
+		if u, ok := field.Value.(unitValue); ok && f.UnitKeys {
+			key += "_" + u.unit()
+		}
+		b.WriteByte(',')
+		writeJSONPair(&b, key, field.Value)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// writeJSONPair writes "key":value to b. Values that cannot be marshaled to JSON are written
+// as strings, formatted using fmt.Sprint.
+func writeJSONPair(b *bytes.Buffer, key string, value interface{}) {
+	k, _ := json.Marshal(key)
+	b.Write(k)
+	b.WriteByte(':')
+	b.Write(marshalJSONValue(value))
+}
+
+// marshalJSONValue marshals value to JSON. Values that cannot be marshaled are marshaled as strings, formatted
+// using fmt.Sprint, and a panic in a MarshalJSON method is recovered and replaced by a placeholder string.
+func marshalJSONValue(value interface{}) (v []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			v, _ = json.Marshal(recoveredPanic("MarshalJSON", r))
+		}
+	}()
+	v, err := json.Marshal(value)
+	if err != nil {
+		v, _ = json.Marshal(sprint(value))
+	}
+	return v
+}
+
+// LogfmtFormatter renders an entry as a line of logfmt key=value pairs with the keys time, level,
+// logger and msg, the host, pid and goroutine keys if they are set, followed by the fields. Fields
+// named after one of those keys are prefixed with "fields.".
+type LogfmtFormatter struct {
+	// TimestampFormat is the format of the time key. It defaults to time.RFC3339Nano.
+	TimestampFormat string
+	// TimestampKey is the key of the time, "time" by default, e.g. "ts".
+	TimestampKey string
+	// UTC renders the time in UTC, whatever the location of the entries.
+	UTC bool
+	// Flatten determines whether nested maps, structs and slices in the fields are flattened into
+	// dotted keys, e.g. http.status=500.
+	Flatten bool
+}
+
+// NewLogfmtFormatter returns a LogfmtFormatter configured with opts (see NewJSONFormatter).
+func NewLogfmtFormatter(opts ...FormatterOption) *LogfmtFormatter {
+	f := &LogfmtFormatter{}
+	for _, opt := range opts {
+		opt(&structuredOptions{&f.TimestampKey, &f.TimestampFormat, &f.UTC})
+	}
+	return f
+}
+
+// Format renders e as a logfmt line.
+func (f *LogfmtFormatter) Format(e *Entry) string {
+	var b strings.Builder
+	timeKey := timestampKeyOrDefault(f.TimestampKey)
+	fmt.Fprintf(&b, "%s=%s level=%s logger=%s msg=%s",
+		timeKey,
+		formatFieldValue(structuredTimestamp(e, f.TimestampFormat, f.UTC)),
+		formatFieldValue(levelName(e.Level)),
+		formatFieldValue(e.Logger),
+		formatFieldValue(e.Message),
+	)
+	for _, p := range processPairs(e) {
+		fmt.Fprintf(&b, " %s=%s", p.key, formatFieldValue(p.value))
+	}
+	fields := e.Fields
+	if f.Flatten {
+		fields = flattenFields(fields)
+	}
+	for _, field := range fields.ordered() {
+		fmt.Fprintf(&b, " %s=%s", structuredFieldKey(field.Key, timeKey), formatFieldValue(field.Value))
+	}
+	return b.String()
+}
+
+// FormatterOption configures a JSONFormatter or a LogfmtFormatter created with NewJSONFormatter or
+// NewLogfmtFormatter.
+type FormatterOption func(o *structuredOptions)
+
+// structuredOptions points to the settings of the structured formatter being configured.
+type structuredOptions struct {
+	timestampKey    *string
+	timestampFormat *string
+	utc             *bool
+}
+
+// WithTimestampKey sets the key of the time, e.g. "ts". A field with the same key is prefixed with "fields.".
+func WithTimestampKey(name string) FormatterOption {
+	return func(o *structuredOptions) { *o.timestampKey = name }
+}
+
+// WithTimestampLayout sets the layout of the time, e.g. time.RFC3339Nano, and renders it in UTC if utc is true,
+// independently of TimestampFormat and of the location of the console. The timestamp format of a Clogger (see
+// Clogger.SetTimestampFormat) still takes precedence over the layout.
+func WithTimestampLayout(layout string, utc bool) FormatterOption {
+	return func(o *structuredOptions) {
+		*o.timestampFormat = layout
+		*o.utc = utc
+	}
+}
+
+type pair struct {
+	key   string
+	value interface{}
+}
+
+// processPairs returns the host, pid, goroutine, caller, scope and tags keys of e that are set, in that order.
+func processPairs(e *Entry) []pair {
+	var pairs []pair
+	if e.Host != "" {
+		pairs = append(pairs, pair{"host", e.Host})
+	}
+	if e.PID != 0 {
+		pairs = append(pairs, pair{"pid", e.PID})
+	}
+	if e.GoroutineID != 0 {
+		pairs = append(pairs, pair{"goroutine", e.GoroutineID})
+	}
+	if e.Caller != "" {
+		pairs = append(pairs, pair{"caller", e.Caller})
+	}
+	if len(e.Scope) > 0 {
+		pairs = append(pairs, pair{"scope", strings.Join(e.Scope, " > ")})
+	}
+	if len(e.Tags) > 0 {
+		pairs = append(pairs, pair{"tags", strings.Join(e.Tags, ",")})
+	}
+	return pairs
+}
+
+// structuredKey returns the key under which a field is emitted by the structured formatters,
+// prefixing it with "fields." if it clashes with one of the keys of the entry itself.
+func structuredKey(key string) string {
+	if isReservedKey(key) {
+		return fieldClashPrefix + key
+	}
+	return key
+}
+
+// structuredFieldKey is like structuredKey, for a formatter whose time key is timeKey.
+func structuredFieldKey(key, timeKey string) string {
+	if key == timeKey {
+		return fieldClashPrefix + key
+	}
+	return structuredKey(key)
+}
+
+// structuredTimestamp renders the time of e for a structured formatter, using format unless the Clogger that
+// logged e has its own, and in UTC if utc is true.
+func structuredTimestamp(e *Entry, format string, utc bool) string {
+	t := e.Time
+	if utc {
+		t = t.UTC()
+	}
+	return t.Format(e.timestampFormatOr(timestampFormatOrDefault(format)))
+}
+
+func timestampKeyOrDefault(key string) string {
+	if key == "" {
+		return "time"
+	}
+	return key
+}
+
+func timestampFormatOrDefault(format string) string {
+	if format == "" {
+		return time.RFC3339Nano
+	}
+	return format
+}
@@ -0,0 +1,48 @@
+package clog
+
+import (
+	"testing"
+)
+
+func TestMetadataDecorations(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      ColorMode
+		metadata  []Decoration
+		timestamp bool
+		want      string
+	}{
+		// by default, the whole line but the timestamp is decorated with the decorations of the Clogger
+		{"default", ColorAlways, nil, true, "2024/03/05 14:07:09 \x1b[31m[ERROR] disk full\x1b[0m\n"},
+		{"dimmed", ColorAlways, []Decoration{DIM}, true,
+			"\x1b[2m2024/03/05 14:07:09 [ERROR] \x1b[0m\x1b[31mdisk full\x1b[0m\n"},
+		{"dimmed without timestamp", ColorAlways, []Decoration{DIM}, false,
+			"\x1b[2m[ERROR] \x1b[0m\x1b[31mdisk full\x1b[0m\n"},
+		{"several decorations", ColorAlways, []Decoration{DIM, UNDERSCORE}, true,
+			"\x1b[2m\x1b[4m2024/03/05 14:07:09 [ERROR] \x1b[0m\x1b[31mdisk full\x1b[0m\n"},
+		{"without colors", ColorNever, []Decoration{DIM}, true, "2024/03/05 14:07:09 [ERROR] disk full\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			SetColorMode(tt.mode)
+			SetPrependTimestamp(tt.timestamp)
+			SetFormatter(&TextFormatter{MetadataDecorations: tt.metadata})
+			if got := CaptureOutput(func() { Error("disk full") }); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataDecorationsCaller(t *testing.T) {
+	setupTest(t)
+	SetColorMode(ColorAlways)
+	SetPrependTimestamp(false)
+	SetFormatter(&TextFormatter{MetadataDecorations: []Decoration{DIM}})
+	e := GetCloggerByName("Error").newEntry("disk full", nil)
+	e.Caller = "main.go:42"
+	if got, want := formatEntry(GetFormatter(), e), "\x1b[2mmain.go:42: [ERROR] \x1b[0m\x1b[31mdisk full\x1b[0m"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
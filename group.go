@@ -0,0 +1,96 @@
+package clog
+
+import (
+	"strings"
+	"sync"
+)
+
+// LogGroup groups related messages: the messages logged through it are indented by two spaces per level of
+// nesting in the text output, after the timestamp and the name, and have a "scope" key with the titles of
+// the groups in the structured outputs. It embeds the Clogger that logs the messages of the group, so that
+// it can be used like one, and groups nest by calling Group on it.
+type LogGroup struct {
+	*Clogger
+	title  string
+	global bool
+}
+
+// Group logs title using the "Info" default clogger and returns a LogGroup, whose messages are logged using
+// the "Info" default clogger as well.
+func Group(title string) *LogGroup {
+	return defaultCloggerForLevel(LogLevelInfo).Group(title)
+}
+
+// Group logs title using l and returns a LogGroup, whose messages are logged using l.
+func (l *Clogger) Group(title string) *LogGroup {
+	l.Print(title)
+	child := l.clone()
+	child.scope = append(append([]string{}, l.scope...), title)
+	return &LogGroup{Clogger: child, title: title}
+}
+
+// BeginGroup is like Group, but every message logged while the group is open, e.g. using the package
+// functions, is part of the group, until the group is closed.
+func BeginGroup(title string) *LogGroup {
+	defaultCloggerForLevel(LogLevelInfo).Print(title)
+	globalScope.Lock()
+	defer globalScope.Unlock()
+	globalScope.titles = append(append([]string{}, globalScope.titles...), title)
+	// the messages of the group are already part of the global scope
+	return &LogGroup{Clogger: defaultCloggerForLevel(LogLevelInfo), title: title, global: true}
+}
+
+// Close closes the group. If the group was created using BeginGroup, messages logged afterwards are no
+// longer part of it. Close does not log anything, see CloseWith.
+func (g *LogGroup) Close() {
+	if !g.global {
+		return
+	}
+	globalScope.Lock()
+	defer globalScope.Unlock()
+	for i := len(globalScope.titles) - 1; i >= 0; i-- {
+		if globalScope.titles[i] == g.title {
+			globalScope.titles = append(append([]string{}, globalScope.titles[:i]...), globalScope.titles[i+1:]...)
+			break
+		}
+	}
+}
+
+// CloseWith logs summary as the last message of the group, and closes it.
+func (g *LogGroup) CloseWith(summary string) {
+	g.Print(summary)
+	g.Close()
+}
+
+// WithGroup calls fn with a Clogger whose messages are part of a group with the given title, logged using the
+// "Info" default clogger.
+func WithGroup(title string, fn func(*Clogger)) {
+	g := Group(title)
+	defer g.Close()
+	fn(g.Clogger)
+}
+
+// globalScope holds the titles of the groups created using BeginGroup that are open, outermost first.
+var globalScope struct {
+	sync.Mutex
+	titles []string
+}
+
+// scopeOf returns the titles of the groups that a message logged by l is part of, outermost first.
+func (l *Clogger) scopeOf() []string {
+	globalScope.Lock()
+	titles := globalScope.titles
+	globalScope.Unlock()
+	if len(titles) == 0 {
+		return l.scope
+	}
+	if len(l.scope) == 0 {
+		return titles
+	}
+	return append(append([]string{}, titles...), l.scope...)
+}
+
+// scopeIndent returns the indentation of the messages that are part of the groups in scope.
+func scopeIndent(scope []string) string {
+	return strings.Repeat("  ", len(scope))
+}
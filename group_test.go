@@ -0,0 +1,54 @@
+package clog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	lines := captureLines(func() {
+		g := Group("deploy api")
+		g.Print("pulled image")
+		inner := g.Group("migrate")
+		inner.Printf("applied %d migrations", 3)
+		g.CloseWith("deployed")
+		Info("idle")
+	})
+	want := []string{
+		"[INFO] deploy api",
+		"[INFO]   pulled image",
+		"[INFO]   migrate",
+		"[INFO]     applied 3 migrations",
+		"[INFO]   deployed",
+		"[INFO] idle",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+}
+
+// TestBeginGroup checks that every message logged while a global group is open is part of it, and that the
+// structured outputs get the titles as a scope key.
+func TestBeginGroup(t *testing.T) {
+	setupTest(t)
+	SetFormatter(&JSONFormatter{})
+	lines := captureLines(func() {
+		g := BeginGroup("backup")
+		WithGroup("upload", func(cl *Clogger) { cl.Print("sent 3 files") })
+		Error("disk full")
+		g.Close()
+		Info("idle")
+	})
+	wantScopes := []string{"", `"scope":"backup"`, `"scope":"backup \u003e upload"`, `"scope":"backup"`, ""}
+	if len(lines) != len(wantScopes) {
+		t.Fatalf("got %q, want %d lines", lines, len(wantScopes))
+	}
+	for i, scope := range wantScopes {
+		if has := strings.Contains(lines[i], `"scope"`); has != (scope != "") || !strings.Contains(lines[i], scope) {
+			t.Errorf("line %d: got %q, want the scope %q", i, lines[i], scope)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package clog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GRPCLogger is an adapter that satisfies grpclog.LoggerV2 and routes grpc's internal logs to the
+// default cloggers, using the LevelSourceGRPC level mapping. It can be installed by calling
+// grpclog.SetLoggerV2(clog.NewGRPCLogger(0)).
+type GRPCLogger struct {
+	verbosity int
+}
+
+// NewGRPCLogger creates a new GRPCLogger. verbosity is the grpc verbosity level: V(l) reports
+// true only for l less than or equal to verbosity.
+func NewGRPCLogger(verbosity int) *GRPCLogger {
+	return &GRPCLogger{verbosity: verbosity}
+}
+
+// Info logs the args using the default clogger of the level that grpc's INFO severity maps to (see
+// LevelMapping), "Info" by default.
+func (g *GRPCLogger) Info(args ...interface{}) {
+	mappedClogger(LevelSourceGRPC, grpcInfo).Print(fmt.Sprint(args...))
+}
+
+// Infoln logs the args like Info.
+func (g *GRPCLogger) Infoln(args ...interface{}) {
+	mappedClogger(LevelSourceGRPC, grpcInfo).Print(sprintln(args...))
+}
+
+// Infof formats the message using the provided args, and logs it like Info.
+func (g *GRPCLogger) Infof(format string, args ...interface{}) {
+	mappedClogger(LevelSourceGRPC, grpcInfo).Printf(format, args...)
+}
+
+// Warning logs the args using the default clogger of the level that grpc's WARNING severity maps to (see
+// LevelMapping), "Warning" by default.
+func (g *GRPCLogger) Warning(args ...interface{}) {
+	mappedClogger(LevelSourceGRPC, grpcWarning).Print(fmt.Sprint(args...))
+}
+
+// Warningln logs the args like Warning.
+func (g *GRPCLogger) Warningln(args ...interface{}) {
+	mappedClogger(LevelSourceGRPC, grpcWarning).Print(sprintln(args...))
+}
+
+// Warningf formats the message using the provided args, and logs it like Warning.
+func (g *GRPCLogger) Warningf(format string, args ...interface{}) {
+	mappedClogger(LevelSourceGRPC, grpcWarning).Printf(format, args...)
+}
+
+// Error logs the args using the default clogger of the level that grpc's ERROR severity maps to (see
+// LevelMapping), "Error" by default.
+func (g *GRPCLogger) Error(args ...interface{}) {
+	mappedClogger(LevelSourceGRPC, grpcError).Print(fmt.Sprint(args...))
+}
+
+// Errorln logs the args like Error.
+func (g *GRPCLogger) Errorln(args ...interface{}) {
+	mappedClogger(LevelSourceGRPC, grpcError).Print(sprintln(args...))
+}
+
+// Errorf formats the message using the provided args, and logs it like Error.
+func (g *GRPCLogger) Errorf(format string, args ...interface{}) {
+	mappedClogger(LevelSourceGRPC, grpcError).Printf(format, args...)
+}
+
+// Fatal logs the args using clog's Fatal, which terminates the process.
+func (g *GRPCLogger) Fatal(args ...interface{}) {
+	Fatal(fmt.Sprint(args...))
+}
+
+// Fatalln logs the args using clog's Fatal, which terminates the process.
+func (g *GRPCLogger) Fatalln(args ...interface{}) {
+	Fatal(sprintln(args...))
+}
+
+// Fatalf formats the message using the provided args, and logs it using clog's Fatalf, which
+// terminates the process.
+func (g *GRPCLogger) Fatalf(format string, args ...interface{}) {
+	Fatalf(format, args...)
+}
+
+// V reports whether the grpc verbosity level l is enabled.
+func (g *GRPCLogger) V(l int) bool {
+	return l <= g.verbosity
+}
+
+// sprintln formats args the way fmt.Sprintln does, i.e. always separated by spaces, but
+// without the trailing newline.
+func sprintln(args ...interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
@@ -0,0 +1,68 @@
+package clog
+
+import (
+	"strings"
+	"testing"
+)
+
+// grpcLoggerV2 mirrors the grpclog.LoggerV2 interface of google.golang.org/grpc, so that the compiler verifies
+// that GRPCLogger keeps satisfying it without importing grpc.
+type grpcLoggerV2 interface {
+	Info(args ...interface{})
+	Infoln(args ...interface{})
+	Infof(format string, args ...interface{})
+	Warning(args ...interface{})
+	Warningln(args ...interface{})
+	Warningf(format string, args ...interface{})
+	Error(args ...interface{})
+	Errorln(args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatal(args ...interface{})
+	Fatalln(args ...interface{})
+	Fatalf(format string, args ...interface{})
+	V(l int) bool
+}
+
+var _ grpcLoggerV2 = (*GRPCLogger)(nil)
+
+func TestGRPCLogger(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	var g grpcLoggerV2 = NewGRPCLogger(1)
+	got := captureLines(func() {
+		g.Info("dialing", 1)
+		g.Infoln("dialing", 2)
+		g.Warningf("retry in %ds", 3)
+		g.Error("connection reset")
+	})
+	want := []string{
+		"[INFO] dialing1", // like fmt.Sprint, which grpclog uses as well
+		"[INFO] dialing 2",
+		"[WARNING] retry in 3s",
+		"[ERROR] connection reset",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if !g.V(0) || !g.V(1) || g.V(2) {
+		t.Errorf("V(0), V(1), V(2) = %t, %t, %t, want true, true, false", g.V(0), g.V(1), g.V(2))
+	}
+}
+
+func TestGRPCLoggerFatalUsesClogExit(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	codes := stubExit(t)
+	hooked := false
+	AddExitHook(func() { hooked = true })
+	got := captureLines(func() { NewGRPCLogger(0).Fatalf("listen: %s", "address in use") })
+	if len(got) != 1 || got[0] != "[FATAL] listen: address in use" {
+		t.Errorf("got %q, want the message logged by the Fatal clogger", got)
+	}
+	if len(*codes) != 1 || (*codes)[0] != 1 {
+		t.Errorf("exit codes %v, want [1]", *codes)
+	}
+	if !hooked {
+		t.Error("the exit hooks did not run")
+	}
+}
@@ -0,0 +1,161 @@
+package clog
+
+import (
+	"io"
+	"log/syslog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testTime is the time returned by the clock of the tests, see setupTest.
+var testTime = time.Date(2024, time.March, 5, 14, 7, 9, 123456789, time.UTC)
+
+// testFlags are the global flags that the tests may change, which setupTest restores afterwards.
+var testFlags = []*bool{
+	&LogToStdOut, &LogToSyslog, &UseDecoration, &PrependTimestamp, &PrependLoggerName, &ShowSyslogPriority,
+	&BadgeStyle, &NoticeSuppressedColors, &StrictAsserts, &SortFields, &WarnOnFormatErrors, &PrependHostname,
+	&PrependPID, &PrependGoroutineID, &PrependCaller, &FullCallerPath, &UTCTimestamps, &WrapMessages,
+}
+
+// setupTest resets the package for a test: the default cloggers are re-created, the clock always returns
+// testTime, messages are only logged to the output, without colors, and the log level is LogLevelDebug. The
+// flags, the clock, the ColorMode, the log level and the Formatter are restored once tb is done.
+func setupTest(tb testing.TB) {
+	tb.Helper()
+	saved := make([]bool, len(testFlags))
+	for i, flag := range testFlags {
+		saved[i] = getFlag(flag)
+	}
+	timestampFormat, wrapWidth := TimestampFormat, WrapWidth
+	level, mode, f := GetLogLevel(), GetColorMode(), GetFormatter()
+	tb.Cleanup(func() {
+		for i, flag := range testFlags {
+			setFlag(flag, saved[i])
+		}
+		TimestampFormat, WrapWidth = timestampFormat, wrapWidth
+		SetLogLevel(level)
+		SetColorMode(mode)
+		SetFormatter(f)
+		SetClock(nil)
+		ResetForTesting()
+	})
+	ResetForTesting()
+	SetClock(func() time.Time { return testTime })
+	SetColorMode(ColorNever)
+	SetLogLevel(LogLevelDebug)
+	SetFormatter(&TextFormatter{})
+	setFlag(&LogToStdOut, true)
+	setFlag(&LogToSyslog, false)
+}
+
+// captureLines returns the lines logged to the output while fn runs.
+func captureLines(fn func()) []string {
+	out := CaptureOutput(fn)
+	if out == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+}
+
+// stubExit replaces ExitFunc until tb is done, and returns the exit codes it is called with.
+func stubExit(tb testing.TB) *[]int {
+	var codes []int
+	previous := ExitFunc
+	ExitFunc = func(code int) { codes = append(codes, code) }
+	tb.Cleanup(func() { ExitFunc = previous })
+	return &codes
+}
+
+// addTestHook adds fn as a Hook until tb is done.
+func addTestHook(tb testing.TB, fn func(e *Entry)) {
+	hooksLock.Lock()
+	previous := hooks
+	hooksLock.Unlock()
+	AddHook(HookFunc(fn))
+	tb.Cleanup(func() {
+		hooksLock.Lock()
+		defer hooksLock.Unlock()
+		hooks = previous
+	})
+}
+
+// internalEvents records the messages of the internal logger, see recordInternal.
+type internalEvents struct {
+	lock     sync.Mutex
+	messages []string
+}
+
+// recordInternal records the messages of the internal logger until tb is done.
+func recordInternal(tb testing.TB) *internalEvents {
+	events := &internalEvents{}
+	SetInternalLogger(func(level int, msg string) {
+		events.lock.Lock()
+		defer events.lock.Unlock()
+		events.messages = append(events.messages, msg)
+	})
+	tb.Cleanup(func() { SetInternalLogger(nil) })
+	return events
+}
+
+// contains reports whether one of the recorded messages contains substr.
+func (events *internalEvents) contains(substr string) bool {
+	events.lock.Lock()
+	defer events.lock.Unlock()
+	for _, msg := range events.messages {
+		if strings.Contains(msg, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// syslogRecord is a message written to the syslog, with the priority of the Clogger that wrote it.
+type syslogRecord struct {
+	priority syslog.Priority
+	message  string
+}
+
+// syslogRecorder records the messages written to the syslog, see recordSyslog.
+type syslogRecorder struct {
+	lock    sync.Mutex
+	records []syslogRecord
+}
+
+// syslogRecorderWriter writes the messages of a Clogger to a syslogRecorder.
+type syslogRecorderWriter struct {
+	r        *syslogRecorder
+	priority syslog.Priority
+}
+
+func (w syslogRecorderWriter) Write(p []byte) (int, error) {
+	w.r.lock.Lock()
+	defer w.r.lock.Unlock()
+	w.r.records = append(w.r.records, syslogRecord{w.priority, strings.TrimSuffix(string(p), "\n")})
+	return len(p), nil
+}
+
+// recordSyslog connects the default cloggers, and those created until tb is done, to a syslogRecorder, and
+// turns LogToSyslog on.
+func recordSyslog(tb testing.TB) *syslogRecorder {
+	r := &syslogRecorder{}
+	SetSyslogDialer(func(priority syslog.Priority) (io.Writer, error) {
+		return syslogRecorderWriter{r, priority}, nil
+	})
+	tb.Cleanup(func() { SetSyslogDialer(nil) })
+	ResetForTesting()
+	setFlag(&LogToSyslog, true)
+	return r
+}
+
+// messages returns the messages written to the syslog.
+func (r *syslogRecorder) messages() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	messages := make([]string, len(r.records))
+	for i, record := range r.records {
+		messages[i] = record.message
+	}
+	return messages
+}
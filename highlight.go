@@ -0,0 +1,93 @@
+package clog
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+/********************************************************************************
+* H I G H L I G H T
+*********************************************************************************/
+
+// highlightRule decorates the matches of pattern with code, the joined escape sequences of its decorations.
+type highlightRule struct {
+	pattern *regexp.Regexp
+	code    string
+}
+
+// highlightRules holds the highlight rules in registration order. Like escalationRules, it is replaced, never
+// modified, so that it can be read without a lock.
+var highlightRules atomic.Pointer[[]*highlightRule]
+var highlightRulesLock sync.Mutex
+
+// AddHighlight decorates the substrings of the messages that match re, e.g. IP addresses in FG_CYAN or
+// "DEPRECATED" in REVERSE. After a match, the decorations of the rest of the line are restored. Highlights
+// only apply to the standard output, when it is formatted by the TextFormatter with colors enabled. When
+// matches of several rules overlap, the rule that was added first wins. It returns a function that removes
+// the rule.
+func AddHighlight(re *regexp.Regexp, decorations ...Decoration) (remove func()) {
+	rule := &highlightRule{pattern: re, code: joinDecorations(decorations)}
+	highlightRulesLock.Lock()
+	defer highlightRulesLock.Unlock()
+	var rules []*highlightRule
+	if p := highlightRules.Load(); p != nil {
+		rules = append(rules, *p...)
+	}
+	rules = append(rules, rule)
+	highlightRules.Store(&rules)
+	return func() {
+		highlightRulesLock.Lock()
+		defer highlightRulesLock.Unlock()
+		var rules []*highlightRule
+		for _, r := range *highlightRules.Load() {
+			if r != rule {
+				rules = append(rules, r)
+			}
+		}
+		highlightRules.Store(&rules)
+	}
+}
+
+// highlight decorates the matches of the highlight rules in s. base is the escape sequence that s is decorated
+// with, which is restored after each match.
+func highlight(s string, base string) string {
+	p := highlightRules.Load()
+	if p == nil || len(*p) == 0 {
+		return s
+	}
+	type match struct {
+		start, end int
+		code       string
+	}
+	var matches []match
+	for _, rule := range *p {
+	next:
+		for _, loc := range rule.pattern.FindAllStringIndex(s, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			for _, m := range matches {
+				if loc[0] < m.end && m.start < loc[1] {
+					continue next
+				}
+			}
+			matches = append(matches, match{loc[0], loc[1], rule.code})
+		}
+	}
+	if len(matches) == 0 {
+		return s
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(s[last:m.start])
+		b.WriteString(m.code + s[m.start:m.end] + string(RESET) + base)
+		last = m.end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
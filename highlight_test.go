@@ -0,0 +1,46 @@
+package clog
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAddHighlight(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetColorMode(ColorAlways)
+	remove := AddHighlight(regexp.MustCompile(`\d+\.\d+\.\d+\.\d+`), FG_CYAN)
+	t.Cleanup(remove)
+	// an overlapping match of a later rule is left out
+	t.Cleanup(AddHighlight(regexp.MustCompile(`10\.0`), REVERSE))
+
+	got := CaptureOutput(func() { Error("blocked 10.0.0.1 and 10.0.0.2") })
+	want := "\x1b[31m[ERROR] blocked \x1b[36m10.0.0.1\x1b[0m\x1b[31m and \x1b[36m10.0.0.2\x1b[0m\x1b[31m\x1b[0m\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	remove()
+	got = CaptureOutput(func() { Error("blocked 10.0.0.1") })
+	if want := "\x1b[31m[ERROR] blocked \x1b[7m10.0\x1b[0m\x1b[31m.0.1\x1b[0m\n"; got != want {
+		t.Errorf("after remove: got %q, want %q", got, want)
+	}
+}
+
+// TestAddHighlightOutputOnly checks that the highlights only apply to the output, with colors enabled.
+func TestAddHighlightOutputOnly(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	t.Cleanup(AddHighlight(regexp.MustCompile(`\d+\.\d+\.\d+\.\d+`), FG_CYAN))
+	var file lockedBuffer
+	useDestinations(t, NewDestination(&file))
+	if got := CaptureOutput(func() { Error("blocked 10.0.0.1") }); got != "[ERROR] blocked 10.0.0.1\n" {
+		t.Errorf("without colors: got %q", got)
+	}
+	SetColorMode(ColorAlways)
+	CaptureOutput(func() { Error("blocked 10.0.0.2") })
+	if strings.Contains(file.String(), string(FG_CYAN)) {
+		t.Errorf("the destination got the highlights: %q", file.String())
+	}
+}
@@ -0,0 +1,78 @@
+package clog
+
+import (
+	"time"
+)
+
+// defaultHistoricalThreshold is how much older than the start of an ingest writer an entry must be to be
+// historical, by default.
+const defaultHistoricalThreshold = 2 * time.Second
+
+// IngestOption configures the writers that log the lines of other loggers, the JSONIngestWriter and the
+// LevelDetectWriter.
+type IngestOption func(h *historical)
+
+// historical decorates the historical entries of an ingest writer, i.e. the lines replayed from before it
+// started, e.g. when following a log file from its beginning.
+type historical struct {
+	decorations []Decoration
+	threshold   time.Duration
+	start       time.Time
+	// caughtUp is set once an entry that is not historical has been logged: the later ones are not either.
+	caughtUp bool
+}
+
+// WithHistoricalDecoration renders the entries whose time is older than the start of the writer, by more than 2s
+// by default (see WithHistoricalThreshold), with the extra decorations d, e.g. DIM, so that the replayed lines
+// stand out from the live ones. It stops as soon as an entry is recent: the stream has caught up to real time.
+// Lines without a time keep the rendering of the previous line. The decorations are only shown where colors are
+// (see ColorsEnabled), so they have no effect if the output is not a terminal. The time of the lines that are
+// not parsed by the writer itself is read using ParseEntry.
+func WithHistoricalDecoration(d ...Decoration) IngestOption {
+	return func(h *historical) { h.decorations = d }
+}
+
+// WithHistoricalThreshold sets how much older than the start of the writer an entry must be to be decorated
+// with the decorations set using WithHistoricalDecoration.
+func WithHistoricalThreshold(threshold time.Duration) IngestOption {
+	return func(h *historical) { h.threshold = threshold }
+}
+
+// newHistorical returns the historical decoration configured by opts, or nil if there is none.
+func newHistorical(opts []IngestOption) *historical {
+	h := &historical{threshold: defaultHistoricalThreshold, start: now()}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if len(h.decorations) == 0 {
+		return nil
+	}
+	return h
+}
+
+// active reports whether the entries may still be historical.
+func (h *historical) active() bool {
+	return h != nil && !h.caughtUp
+}
+
+// decorate adds the decorations to e if t, the time of e as logged originally, is historical. The zero time
+// is historical if the previous entry was. It is not safe for concurrent use.
+func (h *historical) decorate(e *Entry, t time.Time) {
+	if !h.active() {
+		return
+	}
+	if !t.IsZero() && !t.Before(h.start.Add(-h.threshold)) {
+		h.caughtUp = true
+		return
+	}
+	e.addDecorations(h.decorations)
+}
+
+// lineTime returns the time of line, a line logged by clog, or the zero time if it cannot be parsed.
+func lineTime(line string) time.Time {
+	e, err := ParseEntry(line)
+	if err != nil {
+		return time.Time{}
+	}
+	return e.Time
+}
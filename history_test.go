@@ -0,0 +1,58 @@
+package clog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHistoricalDecoration checks that the replayed lines are dimmed, including those without a time that
+// follow them, until a recent line shows that the stream caught up to real time.
+func TestHistoricalDecoration(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetColorMode(ColorAlways)
+	hourAgo, recent := testTime.Add(-time.Hour).Format(time.RFC3339), testTime.Add(-time.Second).Format(time.RFC3339)
+	w := NewJSONIngestWriter(map[string]string{"time": "time"}, WithHistoricalDecoration(DIM))
+	lines := captureLines(func() {
+		w.Write([]byte(`{"time":"` + hourAgo + `","msg":"replayed"}` + "\n"))
+		w.Write([]byte("not JSON, after a replayed line\n"))
+		w.Write([]byte(`{"time":"` + recent + `","msg":"live"}` + "\n"))
+		w.Write([]byte(`{"time":"` + hourAgo + `","msg":"late"}` + "\n"))
+	})
+	var text []string
+	var dimmed []bool
+	for _, line := range lines {
+		text = append(text, StripDecorations(line))
+		dimmed = append(dimmed, strings.Contains(line, string(DIM)))
+	}
+	if want := []string{"[INFO] replayed", "[INFO] not JSON, after a replayed line", "[INFO] live", "[INFO] late"}; !reflect.DeepEqual(text, want) {
+		t.Errorf("got %q, want %q", text, want)
+	}
+	if want := []bool{true, true, false, false}; !reflect.DeepEqual(dimmed, want) {
+		t.Errorf("got dimmed %v, want %v in %q", dimmed, want, lines)
+	}
+
+	SetColorMode(ColorNever)
+	w = NewJSONIngestWriter(map[string]string{"time": "time"}, WithHistoricalDecoration(DIM))
+	lines = captureLines(func() { w.Write([]byte(`{"time":"` + hourAgo + `","msg":"replayed"}` + "\n")) })
+	if len(lines) != 1 || lines[0] != "[INFO] replayed" {
+		t.Errorf("without colors: got %q", lines)
+	}
+}
+
+// TestHistoricalDecorationDetect checks that the LevelDetectWriter reads the time of the lines logged by clog.
+func TestHistoricalDecorationDetect(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetColorMode(ColorAlways)
+	w := NewLevelDetectWriter(LogLevelInfo, WithHistoricalDecoration(DIM), WithHistoricalThreshold(time.Minute))
+	lines := captureLines(func() {
+		w.Write([]byte(testTime.Add(-2*time.Minute).Format(TimestampFormat) + " [WARNING] replayed\n"))
+		w.Write([]byte(testTime.Add(-30*time.Second).Format(TimestampFormat) + " [WARNING] live\n"))
+	})
+	if len(lines) != 2 || !strings.Contains(lines[0], string(DIM)) || strings.Contains(lines[1], string(DIM)) {
+		t.Errorf("got %q, want only the first line dimmed", lines)
+	}
+}
@@ -0,0 +1,61 @@
+package clog
+
+import (
+	"sync"
+)
+
+/********************************************************************************
+* H O O K S
+*********************************************************************************/
+
+// Hook is called with every Entry logged by any Clogger, before it is written to the syslog and the standard
+// output. A Hook may modify the Entry. A panic in a Hook is recovered, and the message is logged regardless.
+type Hook interface {
+	Fire(e *Entry)
+}
+
+// HookFunc adapts a function to the Hook interface.
+type HookFunc func(e *Entry)
+
+// Fire calls f(e).
+func (f HookFunc) Fire(e *Entry) {
+	f(e)
+}
+
+var hooks []Hook
+var hooksLock sync.RWMutex
+
+// AddHook adds h to the hooks that are called with every logged Entry, in the order they were added.
+func AddHook(h Hook) {
+	hooksLock.Lock()
+	defer hooksLock.Unlock()
+	hooks = append(hooks, h)
+}
+
+// hasHooks reports whether a hook was added.
+func hasHooks() bool {
+	hooksLock.RLock()
+	defer hooksLock.RUnlock()
+	return len(hooks) > 0
+}
+
+// fireHooks calls the hooks with e.
+func fireHooks(e *Entry) {
+	hooksLock.RLock()
+	hs := hooks
+	hooksLock.RUnlock()
+	for _, h := range hs {
+		fireHook(h, e)
+	}
+}
+
+// fireHook calls h with e. If h panics, the panic is recovered and the placeholder is attached to e as the
+// hook_panic field.
+func fireHook(h Hook, e *Entry) {
+	defer func() {
+		if r := recover(); r != nil {
+			e.Fields = e.Fields.merge(Fields{{"hook_panic", recoveredPanic("Fire", r)}})
+		}
+	}()
+	h.Fire(e)
+}
@@ -0,0 +1,170 @@
+package clog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ingestLevels maps the levels of other loggers that ParseLogLevel does not know to clog levels.
+var ingestLevels = map[string]int{
+	"trace":  LogLevelDebug,
+	"dpanic": LogLevelCrit,
+	"panic":  LogLevelCrit,
+	"fatal":  LogLevelCrit,
+}
+
+// JSONIngestWriter is an io.Writer that parses every line written to it as a JSON object logged by another
+// logger, e.g. zap's {"level":"info","ts":1700000000.123,"msg":"started","port":8080}, and logs it again using
+// the default clogger of its level, with its time and the other keys as fields. It can be used to migrate the
+// output of legacy services to clog, through its destinations and formatting, without changing their code.
+type JSONIngestWriter struct {
+	levelKey string
+	timeKey  string
+	msgKey   string
+	buf      []byte
+	lock     sync.Mutex
+	// history decorates the replayed entries, if set (see WithHistoricalDecoration).
+	history *historical
+}
+
+// NewJSONIngestWriter creates a new JSONIngestWriter. fieldMap maps "level", "time" and "msg" to the keys of the
+// incoming objects that hold them, and defaults to the keys of zap: {"level": "level", "time": "ts", "msg":
+// "msg"}. The time is either a number of seconds since the epoch, or a string in the RFC 3339 format or in
+// TimestampFormat; the time of the Write is used otherwise. Levels that are not known are logged at the Info
+// level. Lines that are not JSON objects are logged as they are, at the Info level.
+func NewJSONIngestWriter(fieldMap map[string]string, opts ...IngestOption) *JSONIngestWriter {
+	w := &JSONIngestWriter{levelKey: "level", timeKey: "ts", msgKey: "msg", history: newHistorical(opts)}
+	for role, key := range fieldMap {
+		switch role {
+		case "level":
+			w.levelKey = key
+		case "time":
+			w.timeKey = key
+		case "msg":
+			w.msgKey = key
+		}
+	}
+	return w
+}
+
+// Write logs every complete line in p. An incomplete last line is buffered until it is completed by a later
+// Write, or until Close is called.
+func (w *JSONIngestWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(bytes.TrimSuffix(w.buf[:i], []byte("\r")))
+		w.buf = w.buf[i+1:]
+		w.logLine(line)
+	}
+	return len(p), nil
+}
+
+// Close logs the buffered incomplete line, if any.
+func (w *JSONIngestWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if len(w.buf) > 0 {
+		w.logLine(string(w.buf))
+		w.buf = nil
+	}
+	return nil
+}
+
+// logLine parses line and logs it, or logs it as is if it cannot be parsed.
+func (w *JSONIngestWriter) logLine(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	level, t, msg, fields, err := w.parse(line)
+	if err != nil {
+		cl := defaultCloggerForLevel(LogLevelInfo)
+		e := cl.newEntry(line, nil)
+		if w.history.active() {
+			w.history.decorate(e, lineTime(line))
+		}
+		cl.printEntry(e)
+		return
+	}
+	cl := defaultCloggerForLevel(level)
+	e := cl.newEntry(msg, fields)
+	w.history.decorate(e, t)
+	if !t.IsZero() {
+		e.Time = t
+	}
+	cl.printEntry(e)
+}
+
+// parse returns the level, time, message and fields of the JSON object line. The fields keep the order of the
+// line.
+func (w *JSONIngestWriter) parse(line string) (int, time.Time, string, Fields, error) {
+	level, t, msg := LogLevelInfo, time.Time{}, ""
+	var fields Fields
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return level, t, msg, nil, fmt.Errorf("%s: not a JSON object", PACKAGE_NAME)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return level, t, msg, nil, err
+		}
+		key, _ := tok.(string)
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return level, t, msg, nil, err
+		}
+		switch key {
+		case w.levelKey:
+			level = ingestLevel(fmt.Sprint(value))
+		case w.timeKey:
+			t = ingestTime(value)
+		case w.msgKey:
+			msg = fmt.Sprint(value)
+		default:
+			fields = append(fields, Field{key, value})
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return level, t, msg, nil, err
+	}
+	return level, t, msg, fields, nil
+}
+
+// ingestLevel returns the clog level of the level named name by another logger, or LogLevelInfo.
+func ingestLevel(name string) int {
+	if level, err := ParseLogLevel(name); err == nil {
+		return level
+	}
+	if level, ok := ingestLevels[strings.ToLower(name)]; ok {
+		return level
+	}
+	return LogLevelInfo
+}
+
+// ingestTime returns the time of value, a number of seconds since the epoch or a timestamp, or the zero time.
+func ingestTime(value interface{}) time.Time {
+	switch v := value.(type) {
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			sec, frac := math.Modf(f)
+			return time.Unix(int64(sec), int64(frac*1e9))
+		}
+	case string:
+		if t, err := parseTimestamp(v, time.RFC3339Nano, TimestampFormat); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
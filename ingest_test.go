@@ -0,0 +1,56 @@
+package clog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestJSONIngestWriter checks that the lines of another logger are logged again at their level, with their time
+// and their other keys as fields, and that the lines that are not JSON objects are logged as they are.
+func TestJSONIngestWriter(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	var times []time.Time
+	addTestHook(t, func(e *Entry) { times = append(times, e.Time) })
+	w := NewJSONIngestWriter(nil)
+	lines := captureLines(func() {
+		w.Write([]byte(`{"level":"warn","ts":1700000000.5,"msg":"slow query","table":"users","ms":812}` + "\n"))
+		w.Write([]byte("panic: runtime error\n{\"level\":\"dpanic\","))
+		w.Write([]byte(`"msg":"invariant broken"}`))
+		w.Close()
+	})
+	want := []string{
+		"[WARNING] slow query table=users ms=812",
+		"[INFO] panic: runtime error",
+		"[CRIT] invariant broken",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+	wantTimes := []time.Time{time.Unix(1700000000, 5e8), testTime, testTime}
+	for i := range wantTimes {
+		if i >= len(times) || !times[i].Equal(wantTimes[i]) {
+			t.Errorf("got times %v, want %v", times, wantTimes)
+			break
+		}
+	}
+}
+
+// TestJSONIngestWriterFieldMap checks that the keys of the level, time and message are taken from fieldMap.
+func TestJSONIngestWriterFieldMap(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	var times []time.Time
+	addTestHook(t, func(e *Entry) { times = append(times, e.Time) })
+	w := NewJSONIngestWriter(map[string]string{"level": "severity", "time": "timestamp", "msg": "message"})
+	lines := captureLines(func() {
+		w.Write([]byte(`{"severity":"ERROR","timestamp":"2024-03-05T14:00:00Z","message":"disk full","msg":"kept"}` + "\n"))
+	})
+	if len(lines) != 1 || lines[0] != "[ERROR] disk full msg=kept" {
+		t.Errorf("got %q", lines)
+	}
+	if want := time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC); len(times) != 1 || !times[0].Equal(want) {
+		t.Errorf("got times %v, want %v", times, want)
+	}
+}
@@ -0,0 +1,50 @@
+package clog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+/********************************************************************************
+* I N T E R N A L   D I A G N O S T I C S
+*********************************************************************************/
+
+var internalLogger func(level int, msg string) = defaultInternalLogger
+var internalLoggerLock sync.RWMutex
+
+// maxInternalMessages is the maximum number of messages remembered by the default internal logger. Above it, the
+// least recently seen messages are forgotten, so they may be written again.
+const maxInternalMessages = 1000
+
+// internalMessages remembers the messages already written by the default internal logger.
+var internalMessages = newKeySet(maxInternalMessages)
+
+// SetInternalLogger sets the function that receives the diagnostics of clog itself, e.g. a syslog writer that
+// could not be initialized, a Formatter that panicked or entries dropped by a destination, with the LogLevel of
+// each. It lets them be captured, e.g. by tests, routed or silenced. A nil fn restores the default, which writes
+// each distinct message once to the standard error.
+func SetInternalLogger(fn func(level int, msg string)) {
+	if fn == nil {
+		fn = defaultInternalLogger
+	}
+	internalLoggerLock.Lock()
+	defer internalLoggerLock.Unlock()
+	internalLogger = fn
+}
+
+// defaultInternalLogger writes msg as plain text to the standard error, unless it has already written it.
+func defaultInternalLogger(level int, msg string) {
+	if !internalMessages.add(msg) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s: %s\n", PACKAGE_NAME, levelName(level), msg)
+}
+
+// internalf formats a diagnostic message and hands it to the internal logger.
+func internalf(level int, formatString string, args ...interface{}) {
+	internalLoggerLock.RLock()
+	fn := internalLogger
+	internalLoggerLock.RUnlock()
+	fn(level, fmt.Sprintf(formatString, args...))
+}
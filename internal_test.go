@@ -0,0 +1,131 @@
+package clog
+
+import (
+	"errors"
+	"io"
+	"log/syslog"
+	"os"
+	"sync"
+	"testing"
+)
+
+// internalEvent is a diagnostic received by the internal logger.
+type internalEvent struct {
+	level int
+	msg   string
+}
+
+// captureInternal installs an internal logger that records the diagnostics with their level until tb is done.
+func captureInternal(tb testing.TB) func() []internalEvent {
+	var lock sync.Mutex
+	var events []internalEvent
+	SetInternalLogger(func(level int, msg string) {
+		lock.Lock()
+		defer lock.Unlock()
+		events = append(events, internalEvent{level, msg})
+	})
+	tb.Cleanup(func() { SetInternalLogger(nil) })
+	return func() []internalEvent {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]internalEvent(nil), events...)
+	}
+}
+
+// panickingFormatter is a Formatter that always panics.
+type panickingFormatter struct{}
+
+func (panickingFormatter) Format(e *Entry) string { panic("boom") }
+
+func TestInternalLoggerEvents(t *testing.T) {
+	tests := []struct {
+		name  string
+		fn    func(t *testing.T)
+		level int
+		msg   string
+	}{
+		{"syslog failure", func(t *testing.T) {
+			SetSyslogDialer(func(priority syslog.Priority) (io.Writer, error) {
+				return nil, errors.New("no syslog daemon")
+			})
+			t.Cleanup(func() { SetSyslogDialer(nil) })
+			setFlag(&LogToSyslog, true)
+			NewClogger("Payments", LogLevelInfo)
+		}, LogLevelWarning, "Clogger profile 'Payments' will not log to syslog as it failed to initialize " +
+			"syslog.Logger(): no syslog daemon"},
+		{"formatter panic", func(t *testing.T) {
+			SetFormatter(panickingFormatter{})
+			CaptureOutput(func() { Info("disk full") })
+		}, LogLevelError, "Formatter clog.panickingFormatter panicked: boom"},
+		{"destination failure", func(t *testing.T) {
+			useDestinations(t, NewDestination(&fullWriter{}))
+			CaptureOutput(func() { Info("disk full") })
+		}, LogLevelError, "destination *clog.WriterDestination could not write an entry: no space left on device"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			events := captureInternal(t)
+			tt.fn(t)
+			want := internalEvent{tt.level, tt.msg}
+			if got := events(); len(got) != 1 || got[0] != want {
+				t.Errorf("got the events %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// TestDefaultInternalLogger checks that the default internal logger writes each distinct message once to the
+// standard error.
+func TestDefaultInternalLogger(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	stderr := os.Stderr
+	os.Stderr = f
+	t.Cleanup(func() { os.Stderr = stderr })
+	SetInternalLogger(nil)
+
+	// the messages are unique to the test, since the default logger remembers them for the process
+	internalf(LogLevelError, "%s could not write: %v", t.Name(), "disk full")
+	internalf(LogLevelError, "%s could not write: %v", t.Name(), "disk full")
+	internalf(LogLevelWarning, "%s is slow", t.Name())
+	internalf(LogLevelError, "%s could not write: %v", t.Name(), "disk full")
+
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Clog: error: TestDefaultInternalLogger could not write: disk full\n" +
+		"Clog: warning: TestDefaultInternalLogger is slow\n"
+	if got := string(b); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestDefaultInternalLoggerBounded checks that the default internal logger only remembers the most recent
+// messages, so that a stream of distinct messages does not grow its memory.
+func TestDefaultInternalLoggerBounded(t *testing.T) {
+	stderr := os.Stderr
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	os.Stderr = devNull
+	t.Cleanup(func() { os.Stderr = stderr })
+	SetInternalLogger(nil)
+
+	for i := 0; i < 2*maxInternalMessages; i++ {
+		internalf(LogLevelWarning, "%s: entry %d dropped", t.Name(), i)
+	}
+	if n := internalMessages.len(); n != maxInternalMessages {
+		t.Errorf("got %d messages remembered, want %d", n, maxInternalMessages)
+	}
+	// the first message was forgotten, so it is written again
+	if !internalMessages.add(t.Name() + ": entry 0 dropped") {
+		t.Error("the least recently seen message was not forgotten")
+	}
+}
@@ -0,0 +1,78 @@
+package clog
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+/********************************************************************************
+* L E V E L   C H A N G E S
+*********************************************************************************/
+
+// levelChangeFunc is a callback registered with OnLevelChange.
+type levelChangeFunc struct {
+	fn func(old, new int)
+}
+
+// levelChangeFuncs holds the callbacks in registration order. Like escalationRules, it is replaced, never
+// modified, so that it can be read without a lock.
+var levelChangeFuncs atomic.Pointer[[]*levelChangeFunc]
+var levelChangeFuncsLock sync.Mutex
+
+// OnLevelChange registers fn to be called after the LogLevel changes, whether by SetLogLevel,
+// SetLevelTemporarily, a signal (see EnableSignalLevelControl), the admin handler or the environment, e.g. to enable
+// verbose dumps only at LogLevelDebug. fn is called synchronously, in the goroutine that changed the level, and
+// is not called if the level is set to its current value. A panic in fn is recovered and reported to the
+// internal logger. It returns a function that removes the callback.
+func OnLevelChange(fn func(old, new int)) (remove func()) {
+	f := &levelChangeFunc{fn}
+	levelChangeFuncsLock.Lock()
+	defer levelChangeFuncsLock.Unlock()
+	var fns []*levelChangeFunc
+	if p := levelChangeFuncs.Load(); p != nil {
+		fns = append(fns, *p...)
+	}
+	fns = append(fns, f)
+	levelChangeFuncs.Store(&fns)
+	return func() {
+		levelChangeFuncsLock.Lock()
+		defer levelChangeFuncsLock.Unlock()
+		var fns []*levelChangeFunc
+		for _, other := range *levelChangeFuncs.Load() {
+			if other != f {
+				fns = append(fns, other)
+			}
+		}
+		levelChangeFuncs.Store(&fns)
+	}
+}
+
+// CurrentLevel returns the LogLevel. Unlike reading LogLevel directly, it is safe to call while other goroutines
+// change the level.
+func CurrentLevel() int {
+	return GetLogLevel()
+}
+
+// notifyLevelChange calls the callbacks registered with OnLevelChange, if the level changed from old to new.
+// It must be called without holding logLevelLock, so that the callbacks can read the level.
+func notifyLevelChange(old, new int) {
+	if old == new {
+		return
+	}
+	p := levelChangeFuncs.Load()
+	if p == nil {
+		return
+	}
+	for _, f := range *p {
+		callLevelChangeFunc(f.fn, old, new)
+	}
+}
+
+func callLevelChangeFunc(fn func(old, new int), old, new int) {
+	defer func() {
+		if r := recover(); r != nil {
+			internalf(LogLevelError, "OnLevelChange callback panicked: %v", r)
+		}
+	}()
+	fn(old, new)
+}
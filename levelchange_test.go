@@ -0,0 +1,34 @@
+package clog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOnLevelChange(t *testing.T) {
+	setupTest(t)
+	events := recordInternal(t)
+	type change struct{ old, new, current int }
+	var changes []change
+	remove := OnLevelChange(func(old, new int) {
+		// the callbacks can read the level
+		changes = append(changes, change{old, new, CurrentLevel()})
+	})
+	t.Cleanup(remove)
+	t.Cleanup(OnLevelChange(func(old, new int) { panic("boom") }))
+
+	SetLogLevel(LogLevelWarning)
+	SetLogLevel(LogLevelWarning)
+	SetLogLevel(LogLevelError)
+	remove()
+	SetLogLevel(LogLevelInfo)
+
+	want := []change{{LogLevelDebug, LogLevelWarning, LogLevelWarning}, {LogLevelWarning, LogLevelError, LogLevelError}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("got %+v, want %+v", changes, want)
+	}
+	// the panicking callback is still called after the first one is removed
+	if !events.contains("OnLevelChange callback panicked: boom") || len(events.messages) != 3 {
+		t.Errorf("got the diagnostics %q, want a panic per change", events.messages)
+	}
+}
@@ -0,0 +1,35 @@
+package clog
+
+// Leveled is an adapter that routes the messages of libraries expecting a leveled logger
+// (Error/Info/Debug/Warn with key-value pairs) to the default cloggers.
+type Leveled struct{}
+
+// LeveledAdapter returns a Leveled adapter that can be passed to any library that accepts
+// a logger with Error, Info, Debug and Warn(msg string, keysAndValues ...interface{}) methods.
+func LeveledAdapter() Leveled {
+	return Leveled{}
+}
+
+// Error logs msg and keysAndValues using the default clogger of the level that Error maps to (see
+// LevelSourceLeveled), "Error" by default.
+func (Leveled) Error(msg string, keysAndValues ...interface{}) {
+	mappedClogger(LevelSourceLeveled, leveledError).PrintFields(msg, keysAndValues...)
+}
+
+// Info logs msg and keysAndValues using the default clogger of the level that Info maps to (see
+// LevelSourceLeveled), "Info" by default.
+func (Leveled) Info(msg string, keysAndValues ...interface{}) {
+	mappedClogger(LevelSourceLeveled, leveledInfo).PrintFields(msg, keysAndValues...)
+}
+
+// Debug logs msg and keysAndValues using the default clogger of the level that Debug maps to (see
+// LevelSourceLeveled), "Debug" by default.
+func (Leveled) Debug(msg string, keysAndValues ...interface{}) {
+	mappedClogger(LevelSourceLeveled, leveledDebug).PrintFields(msg, keysAndValues...)
+}
+
+// Warn logs msg and keysAndValues using the default clogger of the level that Warn maps to (see
+// LevelSourceLeveled), "Warning" by default.
+func (Leveled) Warn(msg string, keysAndValues ...interface{}) {
+	mappedClogger(LevelSourceLeveled, leveledWarn).PrintFields(msg, keysAndValues...)
+}
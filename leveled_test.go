@@ -0,0 +1,38 @@
+package clog
+
+import (
+	"strings"
+	"testing"
+)
+
+// leveledLogger mirrors the structural leveled logger interface accepted by libraries such as
+// hashicorp/go-retryablehttp, so that the compiler verifies that Leveled keeps satisfying it.
+type leveledLogger interface {
+	Error(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Debug(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+}
+
+var _ leveledLogger = Leveled{}
+
+func TestLeveledAdapter(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	var l leveledLogger = LeveledAdapter()
+	got := captureLines(func() {
+		l.Debug("retrying", "attempt", 2)
+		l.Info("request", "method", "GET", "url", "/health")
+		l.Warn("slow response", "ms", 1500)
+		l.Error("giving up", "err", "timeout")
+	})
+	want := []string{
+		"[DEBUG] retrying attempt=2",
+		"[INFO] request method=GET url=/health",
+		"[WARNING] slow response ms=1500",
+		"[ERROR] giving up err=timeout",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+package clog
+
+import (
+	"sort"
+	"sync"
+)
+
+/********************************************************************************
+* L E V E L   M A P P I N G
+*********************************************************************************/
+
+// The sources of the level mappings, i.e. the logging libraries whose levels are mapped to clog levels.
+const (
+	// LevelSourceSlog maps the levels of log/slog: slog.LevelDebug (-4), LevelInfo (0), LevelWarn (4) and
+	// LevelError (8).
+	LevelSourceSlog = "slog"
+	// LevelSourceLogr maps the verbosity of logr: V(0) and V(1) are Info, and V(2) and above are Debug. Errors
+	// logged using logr's Error are not mapped.
+	LevelSourceLogr = "logr"
+	// LevelSourceGRPC maps the severities of grpclog, which GRPCLogger uses: INFO (0), WARNING (1) and ERROR (2).
+	// FATAL always terminates the process, like Fatal.
+	LevelSourceGRPC = "grpc"
+	// LevelSourceGorm maps the levels of gorm, which SQLLogger uses: SQLError (2), SQLWarn (3) and SQLInfo (4).
+	LevelSourceGorm = "gorm"
+	// LevelSourceLeveled maps the methods of Leveled, numbered like the levels of slog: Debug (-4), Info (0),
+	// Warn (4) and Error (8).
+	LevelSourceLeveled = "leveled"
+)
+
+// The grpclog severities.
+const (
+	grpcInfo = iota
+	grpcWarning
+	grpcError
+)
+
+// The levels of the methods of Leveled.
+const (
+	leveledDebug = -4
+	leveledInfo  = 0
+	leveledWarn  = 4
+	leveledError = 8
+)
+
+// defaultLevelMappings returns the default level mappings, by source.
+func defaultLevelMappings() map[string]map[int]int {
+	return map[string]map[int]int{
+		LevelSourceSlog: {-4: LogLevelDebug, 0: LogLevelInfo, 4: LogLevelWarning, 8: LogLevelError},
+		LevelSourceLogr: {0: LogLevelInfo, 2: LogLevelDebug},
+		LevelSourceGRPC: {grpcInfo: LogLevelInfo, grpcWarning: LogLevelWarning, grpcError: LogLevelError},
+		LevelSourceGorm: {SQLError: LogLevelError, SQLWarn: LogLevelWarning, SQLInfo: LogLevelInfo},
+		LevelSourceLeveled: {
+			leveledDebug: LogLevelDebug, leveledInfo: LogLevelInfo, leveledWarn: LogLevelWarning, leveledError: LogLevelError,
+		},
+	}
+}
+
+var levelMappings = defaultLevelMappings()
+var levelMappingsLock sync.RWMutex
+
+// LevelMapping returns the clog level that the level theirs of source maps to, e.g.
+// LevelMapping(LevelSourceSlog, 4) is LogLevelWarning. The adapters consult it for every message, so that a
+// mapping changed using SetLevelMapping applies to the next messages. A level that is not mapped takes the
+// mapping of the closest lower level that is, e.g. slog's level 6 maps like LevelWarn, and a level below all the
+// mapped ones maps like the lowest one. It returns LogLevelInfo if source has no mappings.
+func LevelMapping(source string, theirs int) int {
+	levelMappingsLock.RLock()
+	defer levelMappingsLock.RUnlock()
+	mapping := levelMappings[source]
+	if ours, ok := mapping[theirs]; ok {
+		return ours
+	}
+	if len(mapping) == 0 {
+		return LogLevelInfo
+	}
+	keys := make([]int, 0, len(mapping))
+	for k := range mapping {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	closest := keys[0]
+	for _, k := range keys {
+		if k <= theirs {
+			closest = k
+		}
+	}
+	return mapping[closest]
+}
+
+// SetLevelMapping maps the level theirs of source to the clog level ours, e.g. to log the warnings of grpc, which
+// are noisy, as Info:
+//
+//	clog.SetLevelMapping(clog.LevelSourceGRPC, 1, clog.LogLevelInfo)
+//
+// source can be one of the LevelSource constants, or the name of a library that an adapter outside of clog
+// consults LevelMapping for.
+func SetLevelMapping(source string, theirs int, ours int) {
+	levelMappingsLock.Lock()
+	defer levelMappingsLock.Unlock()
+	if levelMappings[source] == nil {
+		levelMappings[source] = make(map[int]int)
+	}
+	levelMappings[source][theirs] = ours
+}
+
+// ResetLevelMappings restores the default level mappings. It is meant for tests.
+func ResetLevelMappings() {
+	levelMappingsLock.Lock()
+	defer levelMappingsLock.Unlock()
+	levelMappings = defaultLevelMappings()
+}
+
+// mappedClogger returns the default clogger of the level that the level theirs of source maps to.
+func mappedClogger(source string, theirs int) *Clogger {
+	return defaultCloggerForLevel(LevelMapping(source, theirs))
+}
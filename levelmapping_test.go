@@ -0,0 +1,109 @@
+package clog
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDefaultLevelMappings(t *testing.T) {
+	tests := []struct {
+		source string
+		theirs int
+		want   int
+	}{
+		{LevelSourceSlog, -8, LogLevelDebug},
+		{LevelSourceSlog, -4, LogLevelDebug},
+		{LevelSourceSlog, 0, LogLevelInfo},
+		{LevelSourceSlog, 2, LogLevelInfo},
+		{LevelSourceSlog, 4, LogLevelWarning},
+		{LevelSourceSlog, 6, LogLevelWarning},
+		{LevelSourceSlog, 8, LogLevelError},
+		{LevelSourceSlog, 12, LogLevelError},
+
+		{LevelSourceLogr, 0, LogLevelInfo},
+		{LevelSourceLogr, 1, LogLevelInfo},
+		{LevelSourceLogr, 2, LogLevelDebug},
+		{LevelSourceLogr, 5, LogLevelDebug},
+
+		{LevelSourceGRPC, grpcInfo, LogLevelInfo},
+		{LevelSourceGRPC, grpcWarning, LogLevelWarning},
+		{LevelSourceGRPC, grpcError, LogLevelError},
+
+		{LevelSourceGorm, SQLError, LogLevelError},
+		{LevelSourceGorm, SQLWarn, LogLevelWarning},
+		{LevelSourceGorm, SQLInfo, LogLevelInfo},
+
+		{LevelSourceLeveled, leveledDebug, LogLevelDebug},
+		{LevelSourceLeveled, leveledInfo, LogLevelInfo},
+		{LevelSourceLeveled, leveledWarn, LogLevelWarning},
+		{LevelSourceLeveled, leveledError, LogLevelError},
+
+		{"unknown", 3, LogLevelInfo},
+	}
+	ResetLevelMappings()
+	for _, tt := range tests {
+		if got := LevelMapping(tt.source, tt.theirs); got != tt.want {
+			t.Errorf("LevelMapping(%q, %d) = %s, want %s", tt.source, tt.theirs, levelName(got), levelName(tt.want))
+		}
+	}
+}
+
+// TestAdaptersUseLevelMapping logs through every adapter, with the default mappings and after re-mapping all
+// the levels of its source to Notice, which must apply to the next messages.
+func TestAdaptersUseLevelMapping(t *testing.T) {
+	sql := &SQLLogger{Mode: SQLInfo}
+	tests := []struct {
+		source string
+		log    func()
+		want   []string
+	}{
+		{LevelSourceLeveled, func() {
+			l := LeveledAdapter()
+			l.Debug("d")
+			l.Info("i")
+			l.Warn("w")
+			l.Error("e")
+		}, []string{"[DEBUG] d", "[INFO] i", "[WARNING] w", "[ERROR] e"}},
+		{LevelSourceGRPC, func() {
+			g := NewGRPCLogger(0)
+			g.Info("i")
+			g.Warning("w")
+			g.Error("e")
+		}, []string{"[INFO] i", "[WARNING] w", "[ERROR] e"}},
+		{LevelSourceGorm, func() {
+			ctx := context.Background()
+			sql.Info(ctx, "i")
+			sql.Warn(ctx, "w")
+			sql.Error(ctx, "e")
+		}, []string{"[INFO] i", "[WARNING] w", "[ERROR] e"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			setupTest(t)
+			SetPrependTimestamp(false)
+			t.Cleanup(ResetLevelMappings)
+			ResetLevelMappings()
+			if got := captureLines(tt.log); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+
+			levelMappingsLock.RLock()
+			var theirs []int
+			for level := range levelMappings[tt.source] {
+				theirs = append(theirs, level)
+			}
+			levelMappingsLock.RUnlock()
+			for _, level := range theirs {
+				SetLevelMapping(tt.source, level, LogLevelNotice)
+			}
+			want := make([]string, len(tt.want))
+			for i, line := range tt.want {
+				want[i] = "[NOTICE]" + line[strings.Index(line, "]")+1:]
+			}
+			if got := captureLines(tt.log); strings.Join(got, "\n") != strings.Join(want, "\n") {
+				t.Errorf("after SetLevelMapping, got %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package clog
+
+import (
+	"fmt"
+	"log/syslog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// levelNames holds the lowercase names of the log levels, as used by the structured formatters. It is guarded
+// by levelsLock, since RegisterLevel adds to it.
+var levelNames map[int]string = map[int]string{
+	LogLevelDebug:     "debug",
+	LogLevelInfo:      "info",
+	LogLevelNotice:    "notice",
+	LogLevelWarning:   "warning",
+	LogLevelError:     "error",
+	LogLevelCrit:      "crit",
+	LogLevelAlert:     "alert",
+	LogLevelEmergency: "emerg",
+}
+
+// MinLevel and MaxLevel are the bounds of the log levels: MinLevel is the most verbose, least severe, level and
+// MaxLevel is the most severe one.
+const (
+	MinLevel = LogLevelDebug
+	MaxLevel = LogLevelEmergency
+)
+
+// Levels returns all the log levels in ascending order of severity, i.e. from the most verbose to the most
+// severe.
+func Levels() []int {
+	levelsLock.RLock()
+	levels := make([]int, 0, len(levelNames))
+	for level := range levelNames {
+		levels = append(levels, level)
+	}
+	levelsLock.RUnlock()
+	sort.Slice(levels, func(i, j int) bool { return !IsAtLeast(levels[i], levels[j]) })
+	return levels
+}
+
+// IsAtLeast reports whether level is at least as severe as threshold, i.e. whether a message logged at level
+// passes a LogLevel of threshold. A higher level is more severe: LogLevelDebug is the most verbose level, and
+// LogLevelEmergency the most severe one. Levels registered using RegisterLevel are ordered by their rank. It
+// defines the ordering of the levels that all the filtering uses.
+func IsAtLeast(level, threshold int) bool {
+	if !customLevels.Load() {
+		return level >= threshold
+	}
+	levelsLock.RLock()
+	defer levelsLock.RUnlock()
+	return levelRank(level) >= levelRank(threshold)
+}
+
+// levelsLock guards levelNames, levelRanks and levelPriorities, which RegisterLevel adds to.
+var levelsLock sync.RWMutex
+
+// levelRanks holds the ranks of the levels registered using RegisterLevel.
+var levelRanks map[int]int = make(map[int]int)
+
+// levelPriorities holds the syslog priorities of the levels registered using RegisterLevel. They are kept apart
+// from LogLevelSysLogPriorityMap, which is exported and so cannot be written to safely while it may be read.
+var levelPriorities map[int]syslog.Priority = make(map[int]syslog.Priority)
+
+// customLevels is set once a level is registered using RegisterLevel, so that the built-in levels can be
+// compared without taking levelsLock.
+var customLevels atomic.Bool
+
+// levelRank returns the rank of level, which orders it among the other levels. The rank of a built-in level
+// is ten times the level, e.g. 30 for LogLevelWarning. levelsLock must be held.
+func levelRank(level int) int {
+	if rank, ok := levelRanks[level]; ok {
+		return rank
+	}
+	return level * 10
+}
+
+// RegisterLevel registers a custom log level with the given name and rank, whose messages are logged to the
+// syslog with syslogPriority, and returns it. The rank orders the level among the others: the rank of a
+// built-in level is ten times the level, e.g. a rank of 35 orders it above LogLevelWarning and below
+// LogLevelError. It also registers a Clogger with the given name and defaultDecorations that logs at the level.
+// The level can be used like the built-in ones, e.g. with NewClogger, SetLogLevel and ParseLogLevel. It returns
+// an error if the name or the rank is already used by another level, or the name by a Clogger.
+func RegisterLevel(name string, rank int, syslogPriority syslog.Priority, defaultDecorations ...Decoration) (int, error) {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	if lower == "" {
+		return 0, fmt.Errorf("%s: the name of a log level must not be empty", PACKAGE_NAME)
+	}
+	// this also catches the aliases, and names that are numbers
+	if _, err := ParseLogLevel(lower); err == nil {
+		return 0, fmt.Errorf("%s: log level name '%s' is already used", PACKAGE_NAME, name)
+	}
+	ensureDefaults()
+	if _, exists := defaultRegistry.Get(name); exists {
+		return 0, fmt.Errorf("%s: a clogger named '%s' already exists", PACKAGE_NAME, name)
+	}
+
+	levelsLock.Lock()
+	level := MaxLevel + 1
+	for l, n := range levelNames {
+		if n == lower {
+			levelsLock.Unlock()
+			return 0, fmt.Errorf("%s: log level name '%s' is already used", PACKAGE_NAME, name)
+		}
+		if levelRank(l) == rank {
+			levelsLock.Unlock()
+			return 0, fmt.Errorf("%s: log level rank %d is already used by level '%s'", PACKAGE_NAME, rank, n)
+		}
+		if l >= level {
+			level = l + 1
+		}
+	}
+	levelNames[level] = lower
+	levelRanks[level] = rank
+	levelPriorities[level] = syslogPriority
+	customLevels.Store(true)
+	levelsLock.Unlock()
+
+	options := make([]CloggerOption, len(defaultDecorations))
+	for i, d := range defaultDecorations {
+		options[i] = d
+	}
+	cl, err := newClogger(name, level, options...)
+	if err != nil {
+		return level, err
+	}
+	return level, registerClogger(cl)
+}
+
+// LevelSyslogPriority returns the syslog priority that the messages logged at level are logged with, and
+// whether level has one: the priority given to RegisterLevel for a custom level, and the one in the deprecated
+// LogLevelSysLogPriorityMap otherwise.
+func LevelSyslogPriority(level int) (syslog.Priority, bool) {
+	return syslogPriority(level)
+}
+
+// syslogPriority returns the syslog priority of level, and whether it has one.
+func syslogPriority(level int) (syslog.Priority, bool) {
+	levelsLock.RLock()
+	priority, ok := levelPriorities[level]
+	levelsLock.RUnlock()
+	if ok {
+		return priority, true
+	}
+	priority, ok = LogLevelSysLogPriorityMap[level]
+	return priority, ok
+}
+
+// maxLevelNameLength returns the length of the longest level name.
+func maxLevelNameLength() int {
+	levelsLock.RLock()
+	defer levelsLock.RUnlock()
+	var width int
+	for _, name := range levelNames {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	return width
+}
+
+// LevelName returns the lowercase name of level, e.g. "warning", as used by the structured formatters, or the
+// level number if it has no name.
+func LevelName(level int) string {
+	return levelName(level)
+}
+
+// LevelFromName returns the level with the given name, as returned by LevelName. The name is case insensitive.
+// It returns false if there is no such level. See ParseLogLevel to accept aliases and numbers as well.
+func LevelFromName(name string) (int, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	levelsLock.RLock()
+	defer levelsLock.RUnlock()
+	for level, n := range levelNames {
+		if n == name {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
+// levelName returns the name of level, or the level number if it has no name.
+func levelName(level int) string {
+	levelsLock.RLock()
+	defer levelsLock.RUnlock()
+	if name, ok := levelNames[level]; ok {
+		return name
+	}
+	return fmt.Sprint(level)
+}
+
+// ParseLogLevel returns the log level with the given name, e.g. "debug" or "WARNING". The name is
+// case insensitive, "warn" and "err" are accepted as aliases, and the number of a log level is accepted
+// as well. It returns an error if there is no such level.
+func ParseLogLevel(name string) (int, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch name {
+	case "warn":
+		name = "warning"
+	case "err":
+		name = "error"
+	case "critical":
+		name = "crit"
+	case "emergency":
+		name = "emerg"
+	}
+	if level, ok := LevelFromName(name); ok {
+		return level, nil
+	}
+	if level, err := strconv.Atoi(name); err == nil {
+		levelsLock.RLock()
+		_, ok := levelNames[level]
+		levelsLock.RUnlock()
+		if ok {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("%s: invalid log level '%s'", PACKAGE_NAME, name)
+}
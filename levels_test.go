@@ -0,0 +1,179 @@
+package clog
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+	"testing"
+)
+
+// registerTestLevel registers a custom level using RegisterLevel, and unregisters it once tb is done.
+func registerTestLevel(tb testing.TB, name string, rank int, priority syslog.Priority, decorations ...Decoration) int {
+	tb.Helper()
+	level, err := RegisterLevel(name, rank, priority, decorations...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		levelsLock.Lock()
+		defer levelsLock.Unlock()
+		delete(levelNames, level)
+		delete(levelRanks, level)
+		delete(levelPriorities, level)
+	})
+	return level
+}
+
+func TestLevels(t *testing.T) {
+	want := []int{
+		LogLevelDebug, LogLevelInfo, LogLevelNotice, LogLevelWarning,
+		LogLevelError, LogLevelCrit, LogLevelAlert, LogLevelEmergency,
+	}
+	if got := Levels(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Levels() = %v, want %v", got, want)
+	}
+	if MinLevel != LogLevelDebug || MaxLevel != LogLevelEmergency {
+		t.Errorf("MinLevel, MaxLevel = %d, %d, want %d, %d", MinLevel, MaxLevel, LogLevelDebug, LogLevelEmergency)
+	}
+	for _, level := range Levels() {
+		if level < MinLevel || level > MaxLevel {
+			t.Errorf("level %d is out of the bounds [%d, %d]", level, MinLevel, MaxLevel)
+		}
+	}
+
+	setupTest(t)
+	audit := registerTestLevel(t, "AUDIT", 35, syslog.LOG_NOTICE)
+	want = []int{
+		LogLevelDebug, LogLevelInfo, LogLevelNotice, LogLevelWarning, audit,
+		LogLevelError, LogLevelCrit, LogLevelAlert, LogLevelEmergency,
+	}
+	if got := Levels(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Levels() = %v, want %v", got, want)
+	}
+}
+
+func TestLevelNames(t *testing.T) {
+	names := map[int]string{
+		LogLevelDebug:     "debug",
+		LogLevelInfo:      "info",
+		LogLevelNotice:    "notice",
+		LogLevelWarning:   "warning",
+		LogLevelError:     "error",
+		LogLevelCrit:      "crit",
+		LogLevelAlert:     "alert",
+		LogLevelEmergency: "emerg",
+	}
+	for _, level := range Levels() {
+		name := LevelName(level)
+		if name != names[level] {
+			t.Errorf("LevelName(%d) = %q, want %q", level, name, names[level])
+		}
+		for _, n := range []string{name, strings.ToUpper(name)} {
+			if got, ok := LevelFromName(n); !ok || got != level {
+				t.Errorf("LevelFromName(%q) = %d, %t, want %d", n, got, ok, level)
+			}
+		}
+	}
+	if got := LevelName(42); got != "42" {
+		t.Errorf("LevelName(42) = %q, want \"42\"", got)
+	}
+	// unlike ParseLogLevel, LevelFromName does not accept aliases nor numbers
+	for _, name := range []string{"", "warn", "err", "3", "verbose"} {
+		if got, ok := LevelFromName(name); ok {
+			t.Errorf("LevelFromName(%q) = %d, want no level", name, got)
+		}
+	}
+}
+
+// TestIsAtLeast checks that IsAtLeast orders the levels as Levels does, and that the LogLevel filters the
+// messages accordingly.
+func TestIsAtLeast(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	levels := Levels()
+	for i, level := range levels {
+		cl := NewClogger("Test "+LevelName(level), level)
+		for j, threshold := range levels {
+			if got, want := IsAtLeast(level, threshold), i >= j; got != want {
+				t.Errorf("IsAtLeast(%s, %s) = %t, want %t", LevelName(level), LevelName(threshold), got, want)
+			}
+			SetLogLevel(threshold)
+			logged := CaptureOutput(func() { cl.Print("m") }) != ""
+			if logged != (i >= j) {
+				t.Errorf("a %s message logged: %t, with the %s LogLevel", LevelName(level), logged, LevelName(threshold))
+			}
+		}
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	builtins := len(LogLevelSysLogPriorityMap)
+	audit := registerTestLevel(t, "AUDIT", 35, syslog.LOG_NOTICE, FG_CYAN)
+
+	// the deprecated map only holds the built-in levels, LevelSyslogPriority holds them all
+	if _, ok := LogLevelSysLogPriorityMap[audit]; ok || len(LogLevelSysLogPriorityMap) != builtins {
+		t.Errorf("LogLevelSysLogPriorityMap was changed: %v", LogLevelSysLogPriorityMap)
+	}
+	for level, want := range LogLevelSysLogPriorityMap {
+		if priority, ok := LevelSyslogPriority(level); !ok || priority != want {
+			t.Errorf("LevelSyslogPriority(%d) = %v, %t, want %v", level, priority, ok, want)
+		}
+	}
+	if priority, ok := LevelSyslogPriority(audit); !ok || priority != syslog.LOG_NOTICE {
+		t.Errorf("LevelSyslogPriority(audit) = %v, %t, want LOG_NOTICE", priority, ok)
+	}
+	if priority, ok := LevelSyslogPriority(LogLevelError); !ok || priority != syslog.LOG_ERR {
+		t.Errorf("LevelSyslogPriority(LogLevelError) = %v, %t, want LOG_ERR", priority, ok)
+	}
+	if got := LevelName(audit); got != "audit" {
+		t.Errorf("LevelName(audit) = %q, want \"audit\"", got)
+	}
+	if got, err := ParseLogLevel("Audit"); err != nil || got != audit {
+		t.Errorf("ParseLogLevel(\"Audit\") = %d, %v, want %d", got, err, audit)
+	}
+	if !IsAtLeast(audit, LogLevelWarning) || IsAtLeast(audit, LogLevelError) {
+		t.Error("audit is not ordered between Warning and Error")
+	}
+
+	SetLogLevel(LogLevelWarning)
+	got := CaptureOutput(func() { GetCloggerByName("AUDIT").Print("login") })
+	if want := "[AUDIT] login\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	SetLogLevel(LogLevelError)
+	if got := CaptureOutput(func() { GetCloggerByName("AUDIT").Print("login") }); got != "" {
+		t.Errorf("logged %q below the log level", got)
+	}
+
+	SetLogLevel(LogLevelDebug)
+	SetFormatter(&JSONFormatter{})
+	got = CaptureOutput(func() { NewClogger("Security", audit).Print("login") })
+	if want := `{"time":"2024-03-05T14:07:09.123456789Z","level":"audit","logger":"Security","msg":"login"}` + "\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRegisterLevelCollisions(t *testing.T) {
+	setupTest(t)
+	registerTestLevel(t, "AUDIT", 35, syslog.LOG_NOTICE)
+	tests := []struct {
+		name string
+		rank int
+	}{
+		{"", 36},
+		{"warning", 36},
+		{"warn", 36},
+		{"3", 36},
+		{"audit", 36},
+		{"Debug", 36}, // the name of a default clogger
+		{"security", 30},
+		{"security", 35},
+	}
+	for _, tt := range tests {
+		if level, err := RegisterLevel(tt.name, tt.rank, syslog.LOG_INFO); err == nil {
+			t.Errorf("RegisterLevel(%q, %d) = %d, want an error", tt.name, tt.rank, level)
+		}
+	}
+}
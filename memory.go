@@ -0,0 +1,132 @@
+package clog
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// memoryEntryOverhead approximates the bytes taken by an entry held by a MemoryDestination, besides its
+// strings.
+const memoryEntryOverhead = 128
+
+// MemoryDestination is a Destination that holds the last entries in memory, up to a number of bytes, so that
+// they can be queried, e.g. by an embedded debug UI or the AdminHandler. The oldest entries are evicted first.
+// The entries keep their structure: the values of their fields are rendered as JSON when they are written, as
+// json.RawMessage values. It is safe for concurrent use.
+type MemoryDestination struct {
+	lock     sync.Mutex
+	maxBytes int
+	// entries are held from head on, oldest first, and take size bytes.
+	entries []memoryEntry
+	head    int
+	size    int
+}
+
+type memoryEntry struct {
+	e    Entry
+	size int
+}
+
+// NewMemoryDestination returns a MemoryDestination that holds up to about maxBytes of entries, e.g. 5<<20.
+func NewMemoryDestination(maxBytes int) *MemoryDestination {
+	return &MemoryDestination{maxBytes: maxBytes}
+}
+
+// Write stores a copy of e, evicting the oldest entries as needed. An entry larger than the maximum number of
+// bytes on its own is not stored.
+func (d *MemoryDestination) Write(e *Entry) error {
+	me := memoryEntry{e: Entry{
+		Time:        e.Time,
+		Level:       e.Level,
+		Logger:      e.Logger,
+		Message:     e.Message,
+		Host:        e.Host,
+		PID:         e.PID,
+		GoroutineID: e.GoroutineID,
+		Caller:      e.Caller,
+		Scope:       append([]string(nil), e.Scope...),
+		Tags:        append([]string(nil), e.Tags...),
+	}}
+	me.size = memoryEntryOverhead + len(e.Logger) + len(e.Message) + len(e.Host) + len(e.Caller)
+	// the values are rendered now, since the caller may mutate them after the log call
+	for _, f := range e.Fields.ordered() {
+		v := json.RawMessage(marshalJSONValue(f.Value))
+		me.e.Fields = append(me.e.Fields, Field{f.Key, v})
+		me.size += len(f.Key) + len(v)
+	}
+	for _, s := range append(me.e.Scope, me.e.Tags...) {
+		me.size += len(s)
+	}
+	if me.size > d.maxBytes {
+		return nil
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for d.size+me.size > d.maxBytes {
+		d.size -= d.entries[d.head].size
+		d.entries[d.head] = memoryEntry{}
+		d.head++
+	}
+	// the evicted entries are dropped from the slice once they make up half of it
+	if d.head > len(d.entries)/2 {
+		d.entries = append(d.entries[:0], d.entries[d.head:]...)
+		d.head = 0
+	}
+	d.entries = append(d.entries, me)
+	d.size += me.size
+	return nil
+}
+
+// Query returns the last limit entries at minLevel or above whose message, logger or fields contain substr, oldest
+// first. A field matches if its key, its value rendered as JSON, or key=value does. An empty substr matches all
+// the entries, and a limit of 0 or less returns all the matching entries.
+func (d *MemoryDestination) Query(minLevel int, substr string, limit int) []Entry {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var matches []Entry
+	for i := len(d.entries) - 1; i >= d.head; i-- {
+		if limit > 0 && len(matches) == limit {
+			break
+		}
+		e := &d.entries[i].e
+		if IsAtLeast(e.Level, minLevel) && memoryEntryMatches(e, substr) {
+			matches = append(matches, *e)
+		}
+	}
+	for i, j := 0, len(matches)-1; i < j; i, j = i+1, j-1 {
+		matches[i], matches[j] = matches[j], matches[i]
+	}
+	return matches
+}
+
+// memoryEntryMatches reports whether the message, the logger or the fields of e contain substr.
+func memoryEntryMatches(e *Entry, substr string) bool {
+	if strings.Contains(e.Message, substr) || strings.Contains(e.Logger, substr) {
+		return true
+	}
+	for _, f := range e.Fields {
+		if strings.Contains(f.Key+"="+string(f.Value.(json.RawMessage)), substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Clear removes all the entries.
+func (d *MemoryDestination) Clear() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.entries, d.head, d.size = nil, 0, 0
+}
+
+// activeMemoryDestination returns the first MemoryDestination added using AddDestination, if any.
+func activeMemoryDestination() *MemoryDestination {
+	for _, dest := range getDestinations() {
+		if d, ok := dest.(*MemoryDestination); ok {
+			return d
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package clog
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// messages returns the messages of entries.
+func messages(entries []Entry) []string {
+	var msgs []string
+	for _, e := range entries {
+		msgs = append(msgs, e.Message)
+	}
+	return msgs
+}
+
+// TestMemoryDestinationQuery checks that the entries are filtered on their level, and on their message or
+// fields, and that the last matching entries are returned oldest first.
+func TestMemoryDestinationQuery(t *testing.T) {
+	setupTest(t)
+	d := NewMemoryDestination(1 << 20)
+	useDestinations(t, d)
+	CaptureOutput(func() {
+		GetCloggerByName("Error").PrintFields("request failed", "cause", "timeout", "attempt", 1)
+		Info("request served in 3s, close to the timeout")
+		Error("disk full")
+		GetCloggerByName("Error").PrintFields("request failed", "cause", "timeout", "attempt", 2)
+		Crit("query timeout")
+	})
+	tests := []struct {
+		minLevel int
+		substr   string
+		limit    int
+		want     []string
+	}{
+		{MinLevel, "", 0, []string{"request failed", "request served in 3s, close to the timeout", "disk full", "request failed", "query timeout"}},
+		{LogLevelError, "timeout", 0, []string{"request failed", "request failed", "query timeout"}},
+		{LogLevelError, "cause=\"timeout\"", 0, []string{"request failed", "request failed"}},
+		{LogLevelError, "timeout", 2, []string{"request failed", "query timeout"}},
+		{LogLevelCrit, "disk", 0, nil},
+	}
+	for _, tt := range tests {
+		if got := messages(d.Query(tt.minLevel, tt.substr, tt.limit)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Query(%d, %q, %d) = %q, want %q", tt.minLevel, tt.substr, tt.limit, got, tt.want)
+		}
+	}
+	last := d.Query(LogLevelError, "attempt", 1)
+	if len(last) != 1 || !reflect.DeepEqual(last[0].Fields, Fields{{"cause", json.RawMessage(`"timeout"`)}, {"attempt", json.RawMessage("2")}}) {
+		t.Errorf("got %+v, want the fields rendered as JSON", last)
+	}
+	d.Clear()
+	if got := d.Query(MinLevel, "", 0); len(got) != 0 {
+		t.Errorf("got %d entries after Clear", len(got))
+	}
+}
+
+// TestMemoryDestinationEviction checks that the oldest entries are evicted once the entries exceed the maximum
+// number of bytes, and that an entry that exceeds it on its own is not stored.
+func TestMemoryDestinationEviction(t *testing.T) {
+	setupTest(t)
+	entrySize := memoryEntryOverhead + len("Info") + len("m0")
+	d := NewMemoryDestination(3 * entrySize)
+	cl := GetCloggerByName("Info")
+	for i := 0; i < 50; i++ {
+		d.Write(cl.newEntry(fmt.Sprintf("m%d", i%10), nil))
+	}
+	if got, want := messages(d.Query(MinLevel, "", 0)), []string{"m7", "m8", "m9"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if d.size != 3*entrySize || len(d.entries)-d.head != 3 || len(d.entries) > 6 {
+		t.Errorf("got %d bytes in %d entries from %d, want %d bytes in 3 entries", d.size, len(d.entries), d.head, 3*entrySize)
+	}
+	d.Write(cl.newEntry(string(make([]byte, 3*entrySize)), nil))
+	if got := len(d.Query(MinLevel, "", 0)); got != 3 {
+		t.Errorf("got %d entries after writing an entry too large to be stored, want 3", got)
+	}
+}
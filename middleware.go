@@ -0,0 +1,18 @@
+package clog
+
+import (
+	"net/http"
+)
+
+// Middleware returns an http.Handler that serves the requests using next, with a child of l that has the method
+// and the path of the request as fields put in the request context, so that the handlers can log using
+// FromContext(r.Context()). The child is taken from the pool and released once next returns, so that serving a
+// request does not allocate a Clogger. Neither the child nor the Cloggers derived from it may be used after the
+// request has been served, e.g. by a goroutine started by the handler.
+func Middleware(l *Clogger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		child := l.WithFields("method", r.Method, "path", r.URL.Path)
+		defer child.Release()
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), child)))
+	})
+}
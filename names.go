@@ -0,0 +1,85 @@
+package clog
+
+import (
+	"strconv"
+	"strings"
+)
+
+/********************************************************************************
+* N A M E S
+*********************************************************************************/
+
+// sgrNames maps the SGR codes of the provided Decorations to their names.
+var sgrNames = map[string]string{
+	"0": "reset",
+	"1": "bold",
+	"2": "dim",
+	"4": "underline",
+	"5": "blink",
+	"7": "reverse",
+	"8": "hidden",
+
+	"30": "black",
+	"31": "red",
+	"32": "green",
+	"33": "yellow",
+	"34": "blue",
+	"35": "magenta",
+	"36": "cyan",
+	"37": "white",
+	"90": "gray",
+	"91": "light-red",
+	"92": "light-green",
+	"93": "light-yellow",
+	"94": "light-blue",
+	"95": "light-magenta",
+	"96": "light-cyan",
+	"97": "light-white",
+
+	"40":  "bg-black",
+	"41":  "bg-red",
+	"42":  "bg-green",
+	"43":  "bg-yellow",
+	"44":  "bg-blue",
+	"45":  "bg-magenta",
+	"46":  "bg-cyan",
+	"47":  "bg-white",
+	"100": "bg-gray",
+	"101": "bg-light-red",
+	"102": "bg-light-green",
+	"103": "bg-light-yellow",
+	"104": "bg-light-blue",
+	"105": "bg-light-magenta",
+	"106": "bg-light-cyan",
+	"107": "bg-light-white",
+}
+
+// DecorationName returns the name of d, e.g. "red" for FG_RED or "bold,red" for Combine(BRIGHT, FG_RED). Codes
+// without a name are returned as numbers, e.g. "38;5;196", and sequences that are not SGR sequences are quoted.
+func DecorationName(d Decoration) string {
+	m := sgrRegex.FindStringSubmatch(string(d))
+	if m == nil {
+		return strconv.Quote(string(d))
+	}
+	var names []string
+	for _, code := range sgrCodes(m[1]) {
+		if name, ok := sgrNames[code]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, code)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// decorationNames returns the names of decorations, separated by commas, or "none".
+func decorationNames(decorations []Decoration) string {
+	if len(decorations) == 0 {
+		return "none"
+	}
+	names := make([]string, len(decorations))
+	for i, d := range decorations {
+		names[i] = DecorationName(d)
+	}
+	return strings.Join(names, ",")
+}
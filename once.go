@@ -0,0 +1,132 @@
+package clog
+
+import (
+	"container/list"
+	"sync"
+)
+
+// maxOnceKeys is the maximum number of keys remembered by the Once functions. Above it, the least recently
+// seen keys are forgotten, so their messages may be logged again.
+const maxOnceKeys = 10000
+
+// onceKeys remembers the keys of the messages logged by the Once functions.
+var onceKeys = newKeySet(maxOnceKeys)
+
+// keySet remembers up to max keys, most recently seen first. It is safe for concurrent use.
+type keySet struct {
+	sync.Mutex
+	max   int
+	order *list.List
+	byKey map[string]*list.Element
+}
+
+func newKeySet(max int) *keySet {
+	return &keySet{max: max, order: list.New(), byKey: make(map[string]*list.Element)}
+}
+
+// add reports whether key is seen for the first time, and remembers it. Above max keys, the least recently seen
+// key is forgotten.
+func (s *keySet) add(key string) bool {
+	s.Lock()
+	defer s.Unlock()
+	if el, ok := s.byKey[key]; ok {
+		s.order.MoveToFront(el)
+		return false
+	}
+	s.byKey[key] = s.order.PushFront(key)
+	if s.order.Len() > s.max {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.byKey, oldest.Value.(string))
+	}
+	return true
+}
+
+// remove forgets key.
+func (s *keySet) remove(key string) {
+	s.Lock()
+	defer s.Unlock()
+	if el, ok := s.byKey[key]; ok {
+		s.order.Remove(el)
+		delete(s.byKey, key)
+	}
+}
+
+// clear forgets all the keys.
+func (s *keySet) clear() {
+	s.Lock()
+	defer s.Unlock()
+	s.order.Init()
+	s.byKey = make(map[string]*list.Element)
+}
+
+// len returns the number of keys remembered.
+func (s *keySet) len() int {
+	s.Lock()
+	defer s.Unlock()
+	return s.order.Len()
+}
+
+// firstOnce reports whether key is seen for the first time, and remembers it. If key is empty, the file:line
+// of the code that called into clog is used as the key.
+func firstOnce(key string) bool {
+	if key == "" {
+		key = callerLocation()
+	}
+	return onceKeys.add(key)
+}
+
+// ResetOnce forgets key, so that the next message logged with it by one of the Once functions is logged.
+func ResetOnce(key string) {
+	onceKeys.remove(key)
+}
+
+// ResetAllOnce forgets all the keys seen by the Once functions. It is meant for tests.
+func ResetAllOnce() {
+	onceKeys.clear()
+}
+
+// InfoOnce logs the msg using the "Info" default clogger, the first time it is called with key. Later calls
+// with the same key are dropped. If key is empty, the file:line of the call is used as the key.
+func InfoOnce(key string, msg string) {
+	if firstOnce(key) {
+		defaultCloggerForLevel(LogLevelInfo).Print(msg)
+	}
+}
+
+// InfoOncef is like InfoOnce, but formats the message using the provided args.
+func InfoOncef(key string, formatString string, args ...interface{}) {
+	if firstOnce(key) {
+		defaultCloggerForLevel(LogLevelInfo).Printf(formatString, args...)
+	}
+}
+
+// WarnOnce logs the msg using the "Warning" default clogger, the first time it is called with key. Later calls
+// with the same key are dropped. If key is empty, the file:line of the call is used as the key.
+func WarnOnce(key string, msg string) {
+	if firstOnce(key) {
+		defaultCloggerForLevel(LogLevelWarning).Print(msg)
+	}
+}
+
+// WarnOncef is like WarnOnce, but formats the message using the provided args.
+func WarnOncef(key string, formatString string, args ...interface{}) {
+	if firstOnce(key) {
+		defaultCloggerForLevel(LogLevelWarning).Printf(formatString, args...)
+	}
+}
+
+// ErrorOnce logs the msg using the "Error" default clogger, the first time it is called with key. Later calls
+// with the same key are dropped. If key is empty, the file:line of the call is used as the key.
+func ErrorOnce(key string, msg string) {
+	if firstOnce(key) {
+		defaultCloggerForLevel(LogLevelError).Print(msg)
+	}
+}
+
+// ErrorOncef is like ErrorOnce, but formats the message using the provided args.
+func ErrorOncef(key string, formatString string, args ...interface{}) {
+	if firstOnce(key) {
+		defaultCloggerForLevel(LogLevelError).Printf(formatString, args...)
+	}
+}
@@ -0,0 +1,48 @@
+package clog
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestOnce(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	ResetAllOnce()
+	t.Cleanup(ResetAllOnce)
+	lines := captureLines(func() {
+		for i := 0; i < 3; i++ {
+			WarnOncef("config", "config %d is deprecated", i)
+			ErrorOnce("db", "database unreachable")
+		}
+		ResetOnce("config")
+		WarnOncef("config", "config %d is deprecated", 3)
+		// the keys are shared between the levels
+		InfoOnce("db", "database reachable")
+	})
+	want := []string{
+		"[WARNING] config 0 is deprecated",
+		"[ERROR] database unreachable",
+		"[WARNING] config 3 is deprecated",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+}
+
+// TestOnceBounded checks that the least recently seen keys are forgotten above maxOnceKeys.
+func TestOnceBounded(t *testing.T) {
+	setupTest(t)
+	ResetAllOnce()
+	t.Cleanup(ResetAllOnce)
+	for i := 0; i <= maxOnceKeys; i++ {
+		firstOnce(fmt.Sprint("key ", i))
+	}
+	if n := onceKeys.len(); n != maxOnceKeys {
+		t.Errorf("got %d keys remembered, want %d", n, maxOnceKeys)
+	}
+	if !firstOnce("key 0") || firstOnce(fmt.Sprint("key ", maxOnceKeys)) {
+		t.Error("the least recently seen key was not the one forgotten")
+	}
+}
@@ -0,0 +1,73 @@
+package clog
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// OpLogger logs the steps of a multi-step operation, prefixing every message with the name of the operation
+// and the time elapsed since it began, e.g. "[INFO] sync (t+0.312s) fetched manifest". It is safe for concurrent
+// use by the goroutines of the operation.
+type OpLogger struct {
+	name    string
+	begin   time.Time
+	clogger *Clogger
+	errors  atomic.Int64
+}
+
+// BeginOperation starts an operation with the given name, whose messages are logged using the "Info" default
+// clogger.
+func BeginOperation(name string) *OpLogger {
+	return defaultCloggerForLevel(LogLevelInfo).BeginOperation(name)
+}
+
+// BeginOperation starts an operation with the given name, whose messages are logged using l.
+func (l *Clogger) BeginOperation(name string) *OpLogger {
+	return &OpLogger{name: name, begin: now(), clogger: l}
+}
+
+// prefix returns the prefix of the messages of the operation, i.e. its name and the elapsed time.
+func (o *OpLogger) prefix() string {
+	return fmt.Sprintf("%s (t+%.3fs) ", o.name, now().Sub(o.begin).Seconds())
+}
+
+// print logs msg using cl, counting it as an error if cl logs at the Error level or above.
+func (o *OpLogger) print(cl *Clogger, msg string) {
+	if IsAtLeast(cl.GetLogLevel(), LogLevelError) {
+		o.errors.Add(1)
+	}
+	cl.Print(o.prefix() + msg)
+}
+
+// Print logs msg using the Clogger of the operation.
+func (o *OpLogger) Print(msg string) {
+	o.print(o.clogger, msg)
+}
+
+// Printf formats msg using the provided args, and logs it using the Clogger of the operation.
+func (o *OpLogger) Printf(formatString string, args ...interface{}) {
+	o.print(o.clogger, sprintf(formatString, args...))
+}
+
+// Error logs msg using the "Error" default clogger, and counts it as an error of the operation.
+func (o *OpLogger) Error(msg string) {
+	o.print(defaultCloggerForLevel(LogLevelError), msg)
+}
+
+// Errorf formats msg using the provided args, and logs it like Error.
+func (o *OpLogger) Errorf(formatString string, args ...interface{}) {
+	o.print(defaultCloggerForLevel(LogLevelError), sprintf(formatString, args...))
+}
+
+// End logs the completion of the operation with its total duration, and the number of errors logged through
+// o if there were any. It returns the total duration.
+func (o *OpLogger) End() time.Duration {
+	elapsed := now().Sub(o.begin)
+	msg := fmt.Sprintf("%s done in %s", o.name, elapsed.Round(time.Millisecond))
+	if n := o.errors.Load(); n > 0 {
+		msg += fmt.Sprintf(" with %d error(s)", n)
+	}
+	o.clogger.Print(msg)
+	return elapsed
+}
@@ -0,0 +1,34 @@
+package clog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOperation(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	clock := testTime
+	SetClock(func() time.Time { return clock })
+	var elapsed time.Duration
+	lines := captureLines(func() {
+		op := BeginOperation("sync")
+		clock = clock.Add(312 * time.Millisecond)
+		op.Printf("fetched %d manifests", 2)
+		clock = clock.Add(1500 * time.Millisecond)
+		op.Error("checksum mismatch")
+		elapsed = op.End()
+	})
+	want := []string{
+		"[INFO] sync (t+0.312s) fetched 2 manifests",
+		"[ERROR] sync (t+1.812s) checksum mismatch",
+		"[INFO] sync done in 1.812s with 1 error(s)",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+	if elapsed != 1812*time.Millisecond {
+		t.Errorf("End() = %s, want 1.812s", elapsed)
+	}
+}
@@ -0,0 +1,127 @@
+package clog
+
+/********************************************************************************
+* O P T I O N S
+*********************************************************************************/
+
+// Toggle overrides one of the global flags for a single Clogger. The zero value, ToggleInherit,
+// uses the global flag.
+type Toggle int
+
+const (
+	// ToggleInherit uses the value of the global flag.
+	ToggleInherit Toggle = iota
+	// ToggleOn turns the behavior on, regardless of the global flag.
+	ToggleOn
+	// ToggleOff turns the behavior off, regardless of the global flag.
+	ToggleOff
+)
+
+// resolve returns the effective value of the toggle, given the global flag.
+func (t Toggle) resolve(global *bool) bool {
+	switch t {
+	case ToggleOn:
+		return true
+	case ToggleOff:
+		return false
+	}
+	return getFlag(global)
+}
+
+func toggleOf(b bool) Toggle {
+	if b {
+		return ToggleOn
+	}
+	return ToggleOff
+}
+
+// toggles holds the overrides of the global flags of a Clogger.
+type toggles struct {
+	timestamp  Toggle
+	loggerName Toggle
+	decoration Toggle
+	badge      Toggle
+}
+
+// CloggerOption configures a Clogger created with NewClogger. A Decoration or a Style is a CloggerOption as
+// well, which adds the decoration to the Clogger.
+type CloggerOption interface {
+	applyTo(cl *Clogger)
+}
+
+type cloggerOptionFunc func(cl *Clogger)
+
+func (f cloggerOptionFunc) applyTo(cl *Clogger) {
+	f(cl)
+}
+
+// applyTo adds the decoration to the Clogger.
+func (d Decoration) applyTo(cl *Clogger) {
+	cl.Decorations = append(cl.Decorations, d)
+}
+
+// OptTimestamp overrides the PrependTimestamp flag for the Clogger.
+func OptTimestamp(b bool) CloggerOption {
+	return cloggerOptionFunc(func(cl *Clogger) { cl.toggles.timestamp = toggleOf(b) })
+}
+
+// OptLoggerName overrides the PrependLoggerName flag for the Clogger.
+func OptLoggerName(b bool) CloggerOption {
+	return cloggerOptionFunc(func(cl *Clogger) { cl.toggles.loggerName = toggleOf(b) })
+}
+
+// OptDecoration overrides the UseDecoration flag for the Clogger.
+func OptDecoration(b bool) CloggerOption {
+	return cloggerOptionFunc(func(cl *Clogger) { cl.toggles.decoration = toggleOf(b) })
+}
+
+// OptTimestampFormat overrides TimestampFormat, and the timestamp format of the structured formatters, for
+// the Clogger.
+func OptTimestampFormat(format string) CloggerOption {
+	return cloggerOptionFunc(func(cl *Clogger) { cl.timestampFormat = format })
+}
+
+// SetPrependTimestamp overrides the PrependTimestamp flag for the Clogger. It is safe to call while other
+// goroutines are logging using the Clogger.
+func (l *Clogger) SetPrependTimestamp(t Toggle) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.toggles.timestamp = t
+}
+
+// SetPrependLoggerName overrides the PrependLoggerName flag for the Clogger. It is safe to call while other
+// goroutines are logging using the Clogger.
+func (l *Clogger) SetPrependLoggerName(t Toggle) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.toggles.loggerName = t
+}
+
+// SetUseDecoration overrides the UseDecoration flag for the Clogger. It is safe to call while other
+// goroutines are logging using the Clogger.
+func (l *Clogger) SetUseDecoration(t Toggle) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.toggles.decoration = t
+}
+
+// SetTimestampFormat overrides TimestampFormat, and the timestamp format of the structured formatters, for
+// the Clogger. An empty format removes the override. It is safe to call while other goroutines are logging
+// using the Clogger.
+func (l *Clogger) SetTimestampFormat(format string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.timestampFormat = format
+}
+
+func (l *Clogger) getTimestampFormat() string {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	return l.timestampFormat
+}
+
+func (l *Clogger) getToggles() toggles {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	return l.toggles
+}
@@ -0,0 +1,81 @@
+package clog
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPerCloggerTimestamp(t *testing.T) {
+	tests := []struct {
+		global bool
+		toggle Toggle
+		want   string
+	}{
+		{true, ToggleInherit, "2024/03/05 14:07:09 [AUDIT] login\n"},
+		{false, ToggleInherit, "[AUDIT] login\n"},
+		{true, ToggleOff, "[AUDIT] login\n"},
+		{false, ToggleOn, "2024/03/05 14:07:09 [AUDIT] login\n"},
+		{true, ToggleOn, "2024/03/05 14:07:09 [AUDIT] login\n"},
+		{false, ToggleOff, "[AUDIT] login\n"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("global=%t/clogger=%d", tt.global, tt.toggle), func(t *testing.T) {
+			setupTest(t)
+			SetPrependTimestamp(tt.global)
+			cl := NewClogger("Audit", LogLevelInfo)
+			cl.SetPrependTimestamp(tt.toggle)
+			if got := CaptureOutput(func() { cl.Print("login") }); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPerCloggerTimestampFormat(t *testing.T) {
+	setupTest(t)
+	TimestampFormat = time.Kitchen
+	audit := NewClogger("Audit", LogLevelInfo, OptTimestampFormat(time.RFC3339Nano))
+	plain := NewClogger("Plain", LogLevelInfo, OptTimestamp(false))
+	console := NewClogger("Console", LogLevelInfo)
+	got := captureLines(func() {
+		audit.Print("login")
+		plain.Print("login")
+		console.Print("login")
+		// the children keep the overrides of their parent
+		audit.WithFields("user", 42).Print("login")
+	})
+	want := []string{
+		"2024-03-05T14:07:09.123456789Z [AUDIT] login",
+		"[PLAIN] login",
+		"2:07PM [CONSOLE] login",
+		"2024-03-05T14:07:09.123456789Z [AUDIT] login user=42",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	audit.SetTimestampFormat("")
+	if got, want := CaptureOutput(func() { audit.Print("login") }), "2:07PM [AUDIT] login\n"; got != want {
+		t.Errorf("without the override, got %q, want %q", got, want)
+	}
+}
+
+// TestPerCloggerTimestampFormatStructured checks that the format of the Clogger also beats the layout of the
+// structured formatters.
+func TestPerCloggerTimestampFormatStructured(t *testing.T) {
+	setupTest(t)
+	SetFormatter(NewJSONFormatter(WithTimestampLayout(time.RFC1123, false)))
+	audit := NewClogger("Audit", LogLevelInfo, OptTimestampFormat(time.DateOnly))
+	got := captureLines(func() {
+		audit.Print("login")
+		NewClogger("Console", LogLevelInfo).Print("login")
+	})
+	want := []string{
+		`{"time":"2024-03-05","level":"info","logger":"Audit","msg":"login"}`,
+		`{"time":"Tue, 05 Mar 2024 14:07:09 UTC","level":"info","logger":"Console","msg":"login"}`,
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package clog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+)
+
+var output io.Writer = os.Stdout
+var outputLock sync.Mutex
+
+// SetOutput sets the writer that the Cloggers log to when LogToStdOut is set to true. By default, they log to
+// the standard output (os.Stdout). Writes are serialized, so that lines logged by concurrent goroutines never
+// interleave.
+func SetOutput(w io.Writer) {
+	setOutputIsTerminal(isTerminal(w))
+	outputLock.Lock()
+	defer outputLock.Unlock()
+	output = w
+}
+
+// GetOutput returns the writer that the Cloggers log to when LogToStdOut is set to true.
+func GetOutput() io.Writer {
+	outputLock.Lock()
+	defer outputLock.Unlock()
+	return output
+}
+
+// writeLine writes line, followed by a newline, to the output. The error is only of interest to SelfTest.
+// If a progress line is shown (see NewProgress), it is erased first, and redrawn below line.
+func writeLine(line string) error {
+	outputLock.Lock()
+	defer outputLock.Unlock()
+	if progressLine != "" {
+		line = clearLine + line
+	}
+	_, err := io.WriteString(output, line+"\n")
+	if progressLine != "" {
+		io.WriteString(output, progressLine)
+	}
+	return err
+}
+
+// isTerminal reports whether w is a terminal (character device).
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// flushOutput flushes the output if it buffers data, i.e. has a Flush method, and syncs it to the disk if it is
+// a file.
+func flushOutput() {
+	outputLock.Lock()
+	defer outputLock.Unlock()
+	if f, ok := output.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := output.(interface{ Sync() error }); ok {
+		f.Sync()
+	}
+}
+
+// CaptureOutput calls fn with the output set to a buffer, and returns what was logged to the output while fn
+// ran. The previous output is restored afterwards, even if fn panics. It is meant for tests, together with
+// SetClock, and must not be called concurrently with itself or SetOutput.
+func CaptureOutput(fn func()) string {
+	var buf bytes.Buffer
+	previous := GetOutput()
+	SetOutput(&buf)
+	defer SetOutput(previous)
+	fn()
+	return buf.String()
+}
@@ -0,0 +1,198 @@
+package clog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/********************************************************************************
+* C O L O R S
+*********************************************************************************/
+
+// Color is a terminal color, from which the Decorations that use it as the foreground or the background color
+// are derived, e.g. ColorRed.Background() is BG_RED and ColorRed.Bright().Foreground() is
+// FG_RED_LIGHT. It is one of the
+// 8 basic colors, their bright variants, a color of the 256-color palette (see From256) or a 24-bit color (see
+// FromRGB).
+type Color uint32
+
+// The basic colors. They are prefixed with Color, since Red, Green, Yellow and Blue print messages.
+const (
+	ColorBlack Color = iota
+	ColorRed
+	ColorGreen
+	ColorYellow
+	ColorBlue
+	ColorMagenta
+	ColorCyan
+	ColorWhite
+)
+
+const (
+	// brightColor marks the bright variants of the basic colors.
+	brightColor Color = 1 << 3
+	// paletteColor and rgbColor mark the colors of the 256-color palette and the 24-bit colors, whose value is in
+	// the lower 8 and 24 bits.
+	paletteColor Color = 1 << 24
+	rgbColor     Color = 1 << 25
+)
+
+// colorNames are the names of the basic colors, by value.
+var colorNames = [...]string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// From256 returns the color n of the 256-color palette, which is rendered as a 38;5;n or 48;5;n SGR code.
+func From256(n uint8) Color {
+	return paletteColor | Color(n)
+}
+
+// FromRGB returns the 24-bit color r, g, b, which is rendered as a 38;2;r;g;b or 48;2;r;g;b SGR code.
+func FromRGB(r, g, b uint8) Color {
+	return rgbColor | Color(r)<<16 | Color(g)<<8 | Color(b)
+}
+
+// Bright returns the bright variant of c, e.g. FG_RED_LIGHT is ColorRed.Bright().Foreground(). The bright variant of a
+// color of the 256-color palette is only defined for the first 8 of them, which are the basic colors. Other
+// colors are returned as they are.
+func (c Color) Bright() Color {
+	switch {
+	case c < brightColor:
+		return c | brightColor
+	case c&paletteColor != 0 && c&0xff < 8:
+		return c + 8
+	}
+	return c
+}
+
+// Foreground returns the Decoration that sets c as the color of the text, e.g. FG_RED for ColorRed.
+func (c Color) Foreground() Decoration {
+	return c.decoration(30, 90, "38")
+}
+
+// Background returns the Decoration that sets c as the background color of the text, e.g. BG_RED for ColorRed.
+func (c Color) Background() Decoration {
+	return c.decoration(40, 100, "48")
+}
+
+// decoration renders c using the SGR code base of the basic colors, brightBase of their bright variants, or
+// extended for the other colors.
+func (c Color) decoration(base, brightBase int, extended string) Decoration {
+	var code string
+	switch {
+	case c < brightColor:
+		code = strconv.Itoa(base + int(c))
+	case c < 2*brightColor:
+		code = strconv.Itoa(brightBase + int(c-brightColor))
+	case c&paletteColor != 0:
+		code = fmt.Sprintf("%s;5;%d", extended, c&0xff)
+	default:
+		code = fmt.Sprintf("%s;2;%d;%d;%d", extended, c>>16&0xff, c>>8&0xff, c&0xff)
+	}
+	return Decoration("\x1b[" + code + "m")
+}
+
+// String returns the name of c, e.g. "red", "light-red", "256:196" or "#ff8700".
+func (c Color) String() string {
+	switch {
+	case c < brightColor:
+		return colorNames[c]
+	case c < 2*brightColor:
+		return "light-" + colorNames[c-brightColor]
+	case c&paletteColor != 0:
+		return fmt.Sprintf("256:%d", c&0xff)
+	default:
+		return fmt.Sprintf("#%06x", uint32(c&0xffffff))
+	}
+}
+
+// ColorOf returns the color set by d, and whether it sets the foreground or the background color. ok is false if
+// d is not a single SGR code that sets a color. It is the inverse of Foreground and Background.
+func ColorOf(d Decoration) (c Color, background bool, ok bool) {
+	m := sgrRegex.FindStringSubmatch(string(d))
+	if m == nil {
+		return 0, false, false
+	}
+	codes := sgrCodes(m[1])
+	if len(codes) != 1 {
+		return 0, false, false
+	}
+	parts := strings.Split(codes[0], ";")
+	n, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, false, false
+	}
+	switch {
+	case len(parts) == 1 && n >= 30 && n <= 37:
+		return Color(n - 30), false, true
+	case len(parts) == 1 && n >= 40 && n <= 47:
+		return Color(n - 40), true, true
+	case len(parts) == 1 && n >= 90 && n <= 97:
+		return Color(n - 90).Bright(), false, true
+	case len(parts) == 1 && n >= 100 && n <= 107:
+		return Color(n - 100).Bright(), true, true
+	case n != 38 && n != 48:
+		return 0, false, false
+	}
+	background = n == 48
+	var values []uint8
+	for _, p := range parts[2:] {
+		v, err := strconv.ParseUint(p, 10, 8)
+		if err != nil {
+			return 0, false, false
+		}
+		values = append(values, uint8(v))
+	}
+	switch {
+	case parts[1] == "5" && len(values) == 1:
+		return From256(values[0]), background, true
+	case parts[1] == "2" && len(values) == 3:
+		return FromRGB(values[0], values[1], values[2]), background, true
+	}
+	return 0, false, false
+}
+
+// DecorationByName returns the Decoration with the given name, the inverse of DecorationName: a color, e.g.
+// "red", "light-red" or "gray", optionally prefixed with "bg-" for the background, or one of "bold", "dim",
+// "underline", "blink", "reverse", "hidden" and "reset". Several names separated by commas are combined into a
+// single Decoration (see Combine). It returns false if one of the names is not known.
+func DecorationByName(name string) (Decoration, bool) {
+	var decorations []Decoration
+	for _, n := range strings.Split(name, ",") {
+		d, ok := decorationByName(strings.ToLower(strings.TrimSpace(n)))
+		if !ok {
+			return "", false
+		}
+		decorations = append(decorations, d)
+	}
+	return Combine(decorations...), true
+}
+
+// decorationByName returns the Decoration with a single name.
+func decorationByName(name string) (Decoration, bool) {
+	background := strings.HasPrefix(name, "bg-")
+	colorName := strings.TrimPrefix(name, "bg-")
+	bright := strings.HasPrefix(colorName, "light-")
+	colorName = strings.TrimPrefix(colorName, "light-")
+	if colorName == "gray" && !bright {
+		colorName, bright = "black", true
+	}
+	for i, n := range colorNames {
+		if n != colorName {
+			continue
+		}
+		c := Color(i)
+		if bright {
+			c = c.Bright()
+		}
+		if background {
+			return c.Background(), true
+		}
+		return c.Foreground(), true
+	}
+	for code, n := range sgrNames {
+		if n == name {
+			return Decoration("\x1b[" + code + "m"), true
+		}
+	}
+	return "", false
+}
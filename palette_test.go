@@ -0,0 +1,120 @@
+package clog
+
+import (
+	"testing"
+)
+
+// testColors are basic, bright, palette and 24-bit colors.
+var testColors = []Color{
+	ColorBlack, ColorRed, ColorGreen, ColorYellow, ColorBlue, ColorMagenta, ColorCyan, ColorWhite,
+	ColorBlack.Bright(), ColorRed.Bright(), ColorCyan.Bright(), ColorWhite.Bright(),
+	From256(0), From256(7), From256(8), From256(196), From256(255),
+	FromRGB(0, 0, 0), FromRGB(255, 135, 0), FromRGB(255, 255, 255), FromRGB(1, 2, 3),
+}
+
+func TestColorDecorationRoundTrip(t *testing.T) {
+	for _, c := range testColors {
+		if got, background, ok := ColorOf(c.Foreground()); !ok || background || got != c {
+			t.Errorf("ColorOf(%s.Foreground()) = %s, %t, %t", c, got, background, ok)
+		}
+		if got, background, ok := ColorOf(c.Background()); !ok || !background || got != c {
+			t.Errorf("ColorOf(%s.Background()) = %s, %t, %t", c, got, background, ok)
+		}
+		// the basic colors and their bright variants round-trip through their names as well, as used by the
+		// themes and the configuration
+		if c >= 2*brightColor {
+			continue
+		}
+		for _, d := range []Decoration{c.Foreground(), c.Background()} {
+			if got, ok := DecorationByName(DecorationName(d)); !ok || got != d {
+				t.Errorf("DecorationByName(DecorationName(%q)) = %q, %t", d, got, ok)
+			}
+		}
+	}
+}
+
+func TestColorDecorationConstants(t *testing.T) {
+	tests := []struct {
+		got  Decoration
+		want Decoration
+	}{
+		{ColorBlack.Foreground(), FG_BLACK},
+		{ColorRed.Foreground(), FG_RED},
+		{ColorGreen.Foreground(), FG_GREEN},
+		{ColorYellow.Foreground(), FG_YELLOW},
+		{ColorBlue.Foreground(), FG_BLUE},
+		{ColorMagenta.Foreground(), FG_MAGENTA},
+		{ColorCyan.Foreground(), FG_CYAN},
+		{ColorWhite.Foreground(), FG_WHITE},
+		{ColorBlack.Bright().Foreground(), FG_GRAY_LIGHT},
+		{ColorRed.Bright().Foreground(), FG_RED_LIGHT},
+		{ColorGreen.Bright().Foreground(), FG_GREEN_LIGHT},
+		{ColorYellow.Bright().Foreground(), FG_YELLOW_LIGHT},
+		{ColorBlue.Bright().Foreground(), FG_BLUE_LIGHT},
+		{ColorMagenta.Bright().Foreground(), FG_MAGENTA_LIGHT},
+		{ColorCyan.Bright().Foreground(), FG_CYAN_LIGHT},
+		{ColorWhite.Bright().Foreground(), FG_WHITE_LIGHT},
+		{ColorBlack.Background(), BG_BLACK},
+		{ColorRed.Background(), BG_RED},
+		{ColorGreen.Background(), BG_GREEN},
+		{ColorYellow.Background(), BG_YELLOW},
+		{ColorBlue.Background(), BG_BLUE},
+		{ColorMagenta.Background(), BG_MAGENTA},
+		{ColorCyan.Background(), BG_CYAN},
+		{ColorWhite.Background(), BG_WHITE},
+		{From256(196).Foreground(), "\x1b[38;5;196m"},
+		{From256(196).Background(), "\x1b[48;5;196m"},
+		{FromRGB(255, 135, 0).Foreground(), "\x1b[38;2;255;135;0m"},
+		{FromRGB(255, 135, 0).Background(), "\x1b[48;2;255;135;0m"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestColorBright(t *testing.T) {
+	tests := []struct {
+		c    Color
+		want Color
+	}{
+		{ColorRed, ColorRed.Bright()},
+		{ColorRed.Bright(), ColorRed.Bright()},
+		// the first 8 colors of the palette are the basic ones
+		{From256(1), From256(9)},
+		{From256(9), From256(9)},
+		{From256(196), From256(196)},
+		{FromRGB(255, 0, 0), FromRGB(255, 0, 0)},
+	}
+	for _, tt := range tests {
+		if got := tt.c.Bright(); got != tt.want {
+			t.Errorf("%s.Bright() = %s, want %s", tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestColorString(t *testing.T) {
+	tests := []struct {
+		c    Color
+		want string
+	}{
+		{ColorRed, "red"},
+		{ColorWhite.Bright(), "light-white"},
+		{From256(196), "256:196"},
+		{FromRGB(255, 135, 0), "#ff8700"},
+	}
+	for _, tt := range tests {
+		if got := tt.c.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestColorOfOtherDecorations(t *testing.T) {
+	for _, d := range []Decoration{BRIGHT, RESET, Combine(BRIGHT, FG_RED), "\x1b[38;5m", "\x1b[38;2;1;2m", "red"} {
+		if c, background, ok := ColorOf(d); ok {
+			t.Errorf("ColorOf(%q) = %s, %t, want no color", d, c, background)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package clog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+/********************************************************************************
+* P A R S E
+*********************************************************************************/
+
+// ParseEntry parses a line logged by clog back into an Entry. It accepts the output of the JSONFormatter and
+// of the LogfmtFormatter with the default time key, and of the TextFormatter when the timestamp is prepended in
+// the current TimestampFormat. Decorations are ignored. The fields of a logfmt line have string values. The text format is not fully unambiguous: trailing key=value words are read as fields, trailing
+// #words as tags, and all the fields have string values. The level of a text line is that of the registered
+// Clogger with its name, or the level with its name, defaulting to LogLevelInfo. Wrapped lines need to be
+// joined before they are parsed, which MergeEntries does.
+func ParseEntry(line string) (Entry, error) {
+	line = strings.TrimRight(StripDecorations(line), "\r\n")
+	if strings.HasPrefix(strings.TrimSpace(line), "{") {
+		return parseJSONEntry(line)
+	}
+	if strings.HasPrefix(strings.TrimSpace(line), "time=") {
+		return parseLogfmtEntry(line)
+	}
+	return parseTextEntry(line)
+}
+
+// MergeEntries parses the lines logged by clog in each of readers (see ParseEntry) and returns them as a
+// single list sorted by time, e.g. to assert on the interleaved output of several processes. Entries with
+// equal times keep the order of readers, and their order within a reader. Empty lines and the lines of file
+// headers, which start with #, are skipped, and indented lines are read as the continuation of a wrapped line.
+func MergeEntries(readers ...io.Reader) ([]Entry, error) {
+	var entries []Entry
+	for i, r := range readers {
+		var pending string
+		var pendingLine int
+		flush := func() error {
+			if pending == "" {
+				return nil
+			}
+			e, err := ParseEntry(pending)
+			if err != nil {
+				return fmt.Errorf("%s: reader %d, line %d: %v", PACKAGE_NAME, i, pendingLine, err)
+			}
+			entries = append(entries, e)
+			pending = ""
+			return nil
+		}
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for n := 1; scanner.Scan(); n++ {
+			line := StripDecorations(scanner.Text())
+			// the headers of the log files (see WithFileHeader) are not entries
+			if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if pending != "" && !strings.HasPrefix(pending, "{") && (line[0] == ' ' || line[0] == '\t') {
+				pending += " " + strings.TrimSpace(line)
+				continue
+			}
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			pending, pendingLine = line, n
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("%s: reader %d: %v", PACKAGE_NAME, i, err)
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	return entries, nil
+}
+
+// parseJSONEntry parses a line of the JSONFormatter. The fields keep the order of the line.
+func parseJSONEntry(line string) (Entry, error) {
+	var e Entry
+	dec := json.NewDecoder(strings.NewReader(line))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return e, fmt.Errorf("%s: not a JSON object: %q", PACKAGE_NAME, line)
+	}
+	var level string
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return e, fmt.Errorf("%s: invalid JSON: %v", PACKAGE_NAME, err)
+		}
+		key, _ := t.(string)
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return e, fmt.Errorf("%s: invalid JSON value of %s: %v", PACKAGE_NAME, key, err)
+		}
+		if err := setStructuredKey(&e, &level, key, value); err != nil {
+			return e, err
+		}
+	}
+	return finishStructuredEntry(e, level, line)
+}
+
+// parseLogfmtEntry parses a line of the LogfmtFormatter. The fields keep the order of the line.
+func parseLogfmtEntry(line string) (Entry, error) {
+	var e Entry
+	var level string
+	for rest := strings.TrimSpace(line); rest != ""; rest = strings.TrimLeft(rest, " ") {
+		key, after, ok := strings.Cut(rest, "=")
+		if !ok || key == "" || strings.ContainsAny(key, " \"") {
+			return e, fmt.Errorf("%s: not a logfmt pair: %q", PACKAGE_NAME, rest)
+		}
+		var value string
+		if strings.HasPrefix(after, "\"") {
+			q, err := strconv.QuotedPrefix(after)
+			if err != nil {
+				return e, fmt.Errorf("%s: invalid logfmt value of %s: %v", PACKAGE_NAME, key, err)
+			}
+			value, _ = strconv.Unquote(q)
+			rest = after[len(q):]
+		} else {
+			value, rest, _ = strings.Cut(after, " ")
+		}
+		if err := setStructuredKey(&e, &level, key, value); err != nil {
+			return e, err
+		}
+	}
+	return finishStructuredEntry(e, level, line)
+}
+
+// setStructuredKey sets the part of e named key by a structured formatter to value, or appends it to the fields
+// of e. The level is only set in level, since it is resolved once the entry is complete.
+func setStructuredKey(e *Entry, level *string, key string, value interface{}) error {
+	s, _ := value.(string)
+	var err error
+	switch key {
+	case "time":
+		if e.Time, err = parseTimestamp(s, time.RFC3339Nano, TimestampFormat); err != nil {
+			return err
+		}
+	case "level":
+		*level = s
+	case "logger":
+		e.Logger = s
+	case "msg":
+		e.Message = s
+	case "host":
+		e.Host = s
+	case "pid":
+		e.PID = structuredInt(value)
+	case "goroutine":
+		e.GoroutineID = structuredInt(value)
+	case "caller":
+		e.Caller = s
+	case "scope":
+		e.Scope = strings.Split(s, " > ")
+	case "tags":
+		e.Tags = strings.Split(s, ",")
+	default:
+		e.Fields = append(e.Fields, Field{strings.TrimPrefix(key, fieldClashPrefix), value})
+	}
+	return nil
+}
+
+// finishStructuredEntry checks that the entry e parsed from line has a time, and sets its level.
+func finishStructuredEntry(e Entry, level string, line string) (Entry, error) {
+	if e.Time.IsZero() {
+		return e, fmt.Errorf("%s: no time key: %q", PACKAGE_NAME, line)
+	}
+	e.Level = LogLevelInfo
+	if l, err := ParseLogLevel(level); err == nil {
+		e.Level = l
+	}
+	return e, nil
+}
+
+// structuredInt returns value, a JSON number or a logfmt string, as an int, or 0.
+func structuredInt(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	return 0
+}
+
+// parseTextEntry parses a line of the TextFormatter:
+// "TIMESTAMP [[HOST] ][[PID] ][[gID] ][FILE:LINE: ][[NAME] ]message key=value... #tag...".
+func parseTextEntry(line string) (Entry, error) {
+	var e Entry
+	// a timestamp has as many words as its format
+	words := len(strings.Fields(TimestampFormat))
+	rest := strings.TrimLeft(line, " ")
+	var stamp []string
+	for i := 0; i < words; i++ {
+		word, after, _ := strings.Cut(rest, " ")
+		stamp = append(stamp, word)
+		rest = strings.TrimLeft(after, " ")
+	}
+	t, err := parseTimestamp(strings.Join(stamp, " "), TimestampFormat)
+	if err != nil {
+		return e, err
+	}
+	e.Time = t
+
+	var brackets []string
+	for strings.HasPrefix(rest, "[") || (e.Caller == "" && isCallerToken(rest)) {
+		// the caller is followed by the name of the logger
+		if !strings.HasPrefix(rest, "[") {
+			word, after, _ := strings.Cut(rest, " ")
+			e.Caller, rest = strings.TrimSuffix(word, ":"), after
+			continue
+		}
+		end := strings.Index(rest, "] ")
+		if end < 0 {
+			if strings.HasSuffix(rest, "]") {
+				end = len(rest) - 1
+			} else {
+				break
+			}
+		}
+		brackets = append(brackets, rest[1:end])
+		rest = strings.TrimPrefix(rest[end+1:], " ")
+	}
+	for i, b := range brackets {
+		switch {
+		case i == len(brackets)-1 && isLoggerName(b):
+			e.Logger, e.Level = parseLoggerName(b)
+		case isDigits(b):
+			e.PID, _ = strconv.Atoi(b)
+		case strings.HasPrefix(b, "g") && isDigits(b[1:]):
+			e.GoroutineID, _ = strconv.Atoi(b[1:])
+		default:
+			e.Host = b
+		}
+	}
+	if e.Logger == "" {
+		e.Level = LogLevelInfo
+	}
+	// the messages of groups are indented after the name
+	rest = strings.TrimLeft(rest, " ")
+
+	e.Message, e.Fields, e.Tags = splitTextMessage(rest)
+	return e, nil
+}
+
+// splitTextMessage splits the trailing #tags and key=value fields off s.
+func splitTextMessage(s string) (string, Fields, []string) {
+	type token struct {
+		start int
+		text  string
+	}
+	var tokens []token
+	for i := 0; i < len(s); {
+		if s[i] == ' ' {
+			i++
+			continue
+		}
+		start := i
+		for i < len(s) && s[i] != ' ' {
+			// quoted values of fields may contain spaces
+			if s[i] == '"' && i > start && s[i-1] == '=' {
+				if q, err := strconv.QuotedPrefix(s[i:]); err == nil {
+					i += len(q)
+					continue
+				}
+			}
+			i++
+		}
+		tokens = append(tokens, token{start, s[start:i]})
+	}
+
+	end := len(tokens)
+	var tags []string
+	for end > 1 && len(tokens[end-1].text) > 1 && tokens[end-1].text[0] == '#' {
+		end--
+	}
+	for _, t := range tokens[end:] {
+		tags = append(tags, t.text[1:])
+	}
+	var fields Fields
+	first := end
+	for first > 1 {
+		key, value, ok := strings.Cut(tokens[first-1].text, "=")
+		if !ok || key == "" || strings.ContainsAny(key, "\"") {
+			break
+		}
+		if strings.HasPrefix(value, "\"") {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				break
+			}
+			value = unquoted
+		}
+		fields = append(Fields{{key, value}}, fields...)
+		first--
+	}
+	if first == len(tokens) {
+		return strings.TrimRight(s, " "), fields, tags
+	}
+	return strings.TrimRight(s[:tokens[first].start], " "), fields, tags
+}
+
+// parseTimestamp parses s using the first of formats that matches it, in the local time zone.
+func parseTimestamp(s string, formats ...string) (time.Time, error) {
+	for _, format := range formats {
+		if format == "" {
+			continue
+		}
+		if t, err := time.ParseInLocation(format, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%s: %q is not a timestamp in the format %q", PACKAGE_NAME, s, formats[len(formats)-1])
+}
+
+// isLoggerName reports whether s looks like a name rendered by namePrefix.
+func isLoggerName(s string) bool {
+	return s != "" && strings.ToUpper(s) == s && strings.IndexFunc(s, unicode.IsLetter) >= 0
+}
+
+// parseLoggerName returns the name and level of the registered Clogger rendered as name, or the
+// level with that name. name may include a syslog severity, e.g. ERROR/3.
+func parseLoggerName(name string) (string, int) {
+	// the syslog severity shown by ShowSyslogPriority
+	if i := strings.LastIndex(name, "/"); i > 0 && isDigits(name[i+1:]) {
+		name = name[:i]
+	}
+	for _, cl := range DefaultRegistry().List() {
+		if strings.EqualFold(cl.Name, name) {
+			return cl.Name, cl.GetLogLevel()
+		}
+	}
+	if level, err := ParseLogLevel(name); err == nil {
+		return name, level
+	}
+	return name, LogLevelInfo
+}
+
+// isCallerToken reports whether s starts with a FILE:LINE: token, as prepended if PrependCaller is set.
+func isCallerToken(s string) bool {
+	word, _, _ := strings.Cut(s, " ")
+	file, line, ok := strings.Cut(strings.TrimSuffix(word, ":"), ":")
+	return ok && strings.HasSuffix(word, ":") && file != "" && isDigits(line)
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
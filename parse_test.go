@@ -0,0 +1,95 @@
+package clog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseEntryRoundTrip formats entries with each Formatter, parses the lines back and expects the same entries.
+func TestParseEntryRoundTrip(t *testing.T) {
+	setupTest(t)
+	TimestampFormat = time.RFC3339Nano
+	SetPrependHostname(true)
+	SetPrependPID(true)
+	entries := []Entry{
+		{Time: testTime, Level: LogLevelInfo, Logger: "Info", Message: "user signed in"},
+		{Time: testTime, Level: LogLevelError, Logger: "Error", Message: "payment failed",
+			Fields: Fields{{"order", "A-17"}, {"reason", "card declined"}}},
+		{Time: testTime.Add(time.Second), Level: LogLevelWarning, Logger: "Warning", Message: "disk at 91%",
+			Host: "web-1", PID: 4242, Tags: []string{"ops", "disk"}},
+	}
+	formatters := []struct {
+		name string
+		f    Formatter
+	}{
+		{"text", &TextFormatter{}},
+		{"JSON", &JSONFormatter{}},
+		{"logfmt", &LogfmtFormatter{}},
+	}
+	for _, ft := range formatters {
+		for _, want := range entries {
+			t.Run(ft.name+"/"+want.Message, func(t *testing.T) {
+				line := ft.f.Format(&want)
+				got, err := ParseEntry(line)
+				if err != nil {
+					t.Fatalf("ParseEntry(%q): %v", line, err)
+				}
+				if !got.Time.Equal(want.Time) {
+					t.Errorf("%q: got the time %s, want %s", line, got.Time, want.Time)
+				}
+				got.Time = want.Time
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%q:\ngot  %+v\nwant %+v", line, got, want)
+				}
+			})
+		}
+	}
+}
+
+// TestMergeEntriesOrder merges the outputs of two processes, and expects the entries sorted by time, with the
+// entries of equal times in the order of the readers, and of the lines.
+func TestMergeEntriesOrder(t *testing.T) {
+	setupTest(t)
+	TimestampFormat = time.RFC3339Nano
+	at := func(seconds int) string {
+		return testTime.Add(time.Duration(seconds) * time.Second).Format(time.RFC3339Nano)
+	}
+	api := strings.Join([]string{
+		"# api started",
+		at(1) + " [INFO] api 1",
+		at(3) + " [INFO] api 3",
+		at(3) + " [ERROR] api 3 again",
+		"",
+	}, "\n")
+	worker := strings.Join([]string{
+		`{"time":"` + at(0) + `","level":"info","logger":"Info","msg":"worker 0"}`,
+		`{"time":"` + at(3) + `","level":"info","logger":"Info","msg":"worker 3"}`,
+		"time=" + at(2) + " level=warning logger=Warning msg=\"worker 2\"",
+	}, "\n")
+	entries, err := MergeEntries(strings.NewReader(api), strings.NewReader(worker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Message)
+	}
+	want := []string{"worker 0", "api 1", "worker 2", "api 3", "api 3 again", "worker 3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if entries[4].Level != LogLevelError || entries[2].Level != LogLevelWarning {
+		t.Errorf("got the levels %d and %d, want %d and %d", entries[4].Level, entries[2].Level, LogLevelError, LogLevelWarning)
+	}
+}
+
+func TestParseEntryInvalid(t *testing.T) {
+	setupTest(t)
+	for _, line := range []string{"not a log line", `{"level":"info","msg":"no time"}`, `time=2024-03-05T14:07:09Z msg="unterminated`} {
+		if _, err := ParseEntry(line); err == nil {
+			t.Errorf("ParseEntry(%q) did not fail", line)
+		}
+	}
+}
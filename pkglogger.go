@@ -0,0 +1,110 @@
+package clog
+
+import (
+	"net/url"
+	"os"
+	"path"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+/********************************************************************************
+* P A C K A G E   L O G G E R S
+*********************************************************************************/
+
+// packageCloggers caches the Clogger of ForPackage by the program counter of its caller.
+var packageCloggers sync.Map // map[uintptr]*Clogger
+
+// packageCloggersLock serializes the creation of the Cloggers of ForPackage, so that the callers in the same
+// package get the same Clogger.
+var packageCloggersLock sync.Mutex
+
+// ForPackage returns the Clogger of the package that calls it, at LogLevelInfo, creating and registering it in
+// the default registry the first time. Its name is the import path of the package, without the path of the
+// main module, e.g. "svc/payments/ledger", so that messages can be attributed to the package that logged them
+// without creating Cloggers by hand. The lookup is cached by caller, so that it is cheap enough to call
+// ForPackage().Printf(...) directly.
+//
+// The messages of the packages whose name matches one of the comma-separated patterns of the CLOG_DEBUG
+// environment variable, e.g. CLOG_DEBUG=svc/payments/*,svc/auth, are logged at LogLevelDebug, and are logged
+// whatever the LogLevel. Patterns use the syntax of path.Match, and a pattern also matches the packages below
+// it, e.g. svc/payments matches svc/payments/ledger. CLOG_DEBUG is read when the Clogger is created.
+func ForPackage() *Clogger {
+	pc, _, _, ok := runtime.Caller(1)
+	if !ok {
+		return defaultCloggerForLevel(LogLevelInfo)
+	}
+	if cl, ok := packageCloggers.Load(pc); ok {
+		return cl.(*Clogger)
+	}
+
+	name := "unknown"
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		name = packageName(fn.Name())
+	}
+	packageCloggersLock.Lock()
+	defer packageCloggersLock.Unlock()
+	cl, exists := DefaultRegistry().Get(name)
+	if !exists {
+		level, verbose := LogLevelInfo, matchesDebugPatterns(name, os.Getenv("CLOG_DEBUG"))
+		if verbose {
+			level = LogLevelDebug
+		}
+		var err error
+		if cl, err = newClogger(name, level); err != nil {
+			panic(err)
+		}
+		cl.verbose = verbose
+		if err := registerClogger(cl); err != nil {
+			panic(err)
+		}
+	}
+	packageCloggers.Store(pc, cl)
+	return cl
+}
+
+// mainModulePath is the path of the main module, which is trimmed from the names of the package Cloggers.
+var mainModulePath = sync.OnceValue(func() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Path
+	}
+	return ""
+})
+
+// packageName returns the name of the package Clogger of the function with the given fully qualified name,
+// e.g. "svc/payments/ledger" for "example.com/app/svc/payments/ledger.(*Ledger).Post" in the module
+// example.com/app.
+func packageName(function string) string {
+	// the dots of the last element of the import path are escaped by the linker, e.g. gopkg.in/yaml%2ev3
+	slash := strings.LastIndex(function, "/")
+	pkg := function
+	if i := strings.Index(function[slash+1:], "."); i >= 0 {
+		pkg = function[:slash+1+i]
+	}
+	if unescaped, err := url.PathUnescape(pkg); err == nil {
+		pkg = unescaped
+	}
+	if module := mainModulePath(); module != "" && strings.HasPrefix(pkg, module+"/") {
+		pkg = strings.TrimPrefix(pkg, module+"/")
+	}
+	return pkg
+}
+
+// matchesDebugPatterns reports whether name matches one of the comma-separated patterns, or is below a
+// package that matches one of them.
+func matchesDebugPatterns(name string, patterns string) bool {
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		for p := name; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package clog
+
+import (
+	"testing"
+)
+
+func TestPackageName(t *testing.T) {
+	module := mainModulePath()
+	tests := []struct {
+		function string
+		want     string
+	}{
+		{module + "/svc/payments/ledger.(*Ledger).Post", "svc/payments/ledger"},
+		{module + "/svc/auth.Login.func1", "svc/auth"},
+		{"gopkg.in/yaml%2ev3.Unmarshal", "gopkg.in/yaml.v3"},
+		{"main.main", "main"},
+	}
+	for _, tt := range tests {
+		if got := packageName(tt.function); got != tt.want {
+			t.Errorf("packageName(%q) = %q, want %q", tt.function, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesDebugPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns string
+		want     bool
+	}{
+		{"svc/payments/ledger", "svc/payments/*", true},
+		{"svc/payments/ledger/internal", "svc/payments", true},
+		{"svc/auth", "svc/payments/*, svc/auth", true},
+		{"svc/authz", "svc/auth", false},
+		{"svc/payments", "svc/payments/*", false},
+		{"svc/auth", "", false},
+	}
+	for _, tt := range tests {
+		if got := matchesDebugPatterns(tt.name, tt.patterns); got != tt.want {
+			t.Errorf("matchesDebugPatterns(%q, %q) = %t, want %t", tt.name, tt.patterns, got, tt.want)
+		}
+	}
+}
+
+func TestForPackage(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetLogLevel(LogLevelWarning)
+	t.Setenv("CLOG_DEBUG", packageName(packagePrefix+"F"))
+	cl := ForPackage()
+	if again := ForPackage(); again != cl {
+		t.Error("ForPackage returned another Clogger for the same package")
+	}
+	if registered, ok := DefaultRegistry().Get(cl.Name); !ok || registered != cl {
+		t.Errorf("the Clogger %q is not registered", cl.Name)
+	}
+	// the package matches CLOG_DEBUG, so its messages are logged at the Debug level, whatever the LogLevel
+	if cl.GetLogLevel() != LogLevelDebug {
+		t.Errorf("got the level %d, want %d", cl.GetLogLevel(), LogLevelDebug)
+	}
+	if got := captureLines(func() { cl.Print("posted") }); len(got) != 1 {
+		t.Errorf("got %q, want the message", got)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build race || clogdebug
+
+package clog
+
+// poisonReleased reports whether released Cloggers are poisoned instead of being reused.
+const poisonReleased = true
@@ -0,0 +1,80 @@
+package clog
+
+import (
+	"fmt"
+	"sync"
+)
+
+/********************************************************************************
+* P O O L
+*********************************************************************************/
+
+// cloggerPool recycles the child Cloggers created by WithFields, Tagged, Sampled and Every, so that a
+// per-request child logger does not allocate a new Clogger every time.
+var cloggerPool = sync.Pool{
+	New: func() interface{} { return new(Clogger) },
+}
+
+// pooledClone behaves like clone, except that the returned Clogger is taken from the pool and can be
+// given back using Release.
+func (l *Clogger) pooledClone() *Clogger {
+	child := cloggerPool.Get().(*Clogger)
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	child.Name = l.Name
+	child.Priority = l.Priority
+	child.Decorations = l.Decorations
+	child.sysLogger = l.sysLogger
+	child.LogLevel = l.LogLevel
+	child.toggles = l.toggles
+	child.fields = l.fields
+	child.sampler = l.sampler
+	child.sampleNote = l.sampleNote
+	child.throttler = l.throttler
+	child.timestampFormat = l.timestampFormat
+	child.scope = l.scope
+	child.tags = l.tags
+	child.verbose = l.verbose
+	child.pooled = true
+	child.released.Store(false)
+	return child
+}
+
+// Release gives a child Clogger obtained from WithFields, Tagged, Sampled or Every back to the pool once it
+// is no longer needed, e.g. at the end of the request it was created for. Middleware does it for the child
+// Cloggers it creates. Neither the Clogger nor anything derived from it
+// may be used after Release. In builds with the race detector or the clogdebug build tag, released
+// Cloggers are poisoned rather than reused, and logging with one panics. Release does nothing for
+// Cloggers that did not come from the pool.
+func (l *Clogger) Release() {
+	if l == nil || !l.pooled || l.released.Swap(true) {
+		return
+	}
+	if poisonReleased {
+		return
+	}
+	l.lock.Lock()
+	l.Name = ""
+	l.Decorations = nil
+	l.sysLogger = nil
+	l.decorationCode = ""
+	l.decorationCodeFor = nil
+	l.toggles = toggles{}
+	l.fields = nil
+	l.sampler = nil
+	l.sampleNote = 0
+	l.throttler = nil
+	l.timestampFormat = ""
+	l.scope = nil
+	l.tags = nil
+	l.verbose = false
+	l.lock.Unlock()
+	cloggerPool.Put(l)
+}
+
+// checkReleased panics if l has been released and poisoning is enabled for this build.
+func (l *Clogger) checkReleased() {
+	if poisonReleased && l.released.Load() {
+		panic(fmt.Errorf("%s: Clogger %s used after Release", PACKAGE_NAME, l.Name))
+	}
+}
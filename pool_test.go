@@ -0,0 +1,124 @@
+package clog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareFromContext(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	var child *Clogger
+	h := Middleware(GetCloggerByName("Info"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		child = FromContext(r.Context())
+		child.Print("serving")
+	}))
+	got := CaptureOutput(func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/orders/42", nil))
+	})
+	if want := "[INFO] serving method=GET path=/orders/42\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if !child.released.Load() {
+		t.Error("the child Clogger was not released once the request was served")
+	}
+}
+
+func TestFromContextDefault(t *testing.T) {
+	setupTest(t)
+	if got := FromContext(context.Background()); got != GetCloggerByName("Info") {
+		t.Errorf("got %p, want the Info default clogger", got)
+	}
+}
+
+func TestDerivedCloggersArePooled(t *testing.T) {
+	setupTest(t)
+	base := GetCloggerByName("Info")
+	children := map[string]*Clogger{
+		"WithFields": base.WithFields("k", "v"),
+		"Tagged":     base.Tagged("billing"),
+		"Sampled":    base.Sampled(10),
+		"Every":      base.Every(time.Second),
+	}
+	for name, child := range children {
+		if !child.pooled {
+			t.Errorf("%s: the child Clogger is not taken from the pool", name)
+		}
+		child.Release()
+		if !child.released.Load() {
+			t.Errorf("%s: Release did not release the child Clogger", name)
+		}
+	}
+}
+
+func TestLoggingAfterReleasePanics(t *testing.T) {
+	if !poisonReleased {
+		t.Skip("released Cloggers are only poisoned with the race detector or the clogdebug build tag")
+	}
+	setupTest(t)
+	child := GetCloggerByName("Info").WithFields("k", "v")
+	child.Release()
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(error).Error(), "used after Release") {
+			t.Errorf("got %v, want a panic about the use after Release", r)
+		}
+	}()
+	CaptureOutput(func() { child.Print("too late") })
+}
+
+func TestReleaseAvoidsAllocations(t *testing.T) {
+	if poisonReleased {
+		t.Skip("released Cloggers are not reused with the race detector or the clogdebug build tag")
+	}
+	setupTest(t)
+	base := GetCloggerByName("Info")
+	// unpooled is how WithFields created a child before the pool
+	var child *Clogger
+	unpooled := testing.AllocsPerRun(1000, func() {
+		child = base.clone()
+		child.fields = base.fields.merge(fieldsFromKeysAndValues([]interface{}{"request_id", "abc"}))
+	})
+	released := testing.AllocsPerRun(1000, func() { base.WithFields("request_id", "abc").Release() })
+	if released >= unpooled {
+		t.Errorf("%v allocations per released child, want fewer than the %v of an unpooled child", released, unpooled)
+	}
+}
+
+// BenchmarkWithFieldsNotReleased measures the allocations of a child Clogger that is not given back to the pool,
+// for comparison with BenchmarkWithFieldsReleased.
+func BenchmarkWithFieldsNotReleased(b *testing.B) {
+	setupBenchmark(b)
+	base := GetCloggerByName("Info")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		base.WithFields("request_id", "abc")
+	}
+}
+
+func BenchmarkWithFieldsReleased(b *testing.B) {
+	setupBenchmark(b)
+	base := GetCloggerByName("Info")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		base.WithFields("request_id", "abc").Release()
+	}
+}
+
+// BenchmarkMiddlewareFromContext measures a request served through Middleware by a handler that logs using
+// FromContext, with the LogLevel filtering the message out so that only the per-request work is measured.
+func BenchmarkMiddlewareFromContext(b *testing.B) {
+	setupBenchmark(b)
+	SetLogLevel(LogLevelInfo)
+	h := Middleware(GetCloggerByName("Debug"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Print("serving")
+	}))
+	w, r := httptest.NewRecorder(), httptest.NewRequest("GET", "/orders/42", nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,108 @@
+package clog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+)
+
+// PrependHostname flag determines whether standard output logs should prepend the hostname of the machine.
+// The hostname is read once, when the package is initialized.
+var PrependHostname bool = false
+
+// PrependPID flag determines whether standard output logs should prepend the process id.
+var PrependPID bool = false
+
+// PrependGoroutineID flag determines whether standard output logs should prepend the id of the goroutine
+// that logged the message. The id is parsed from the output of runtime.Stack for every message, which is
+// slow, so it should only be turned on while debugging.
+//
+// When more than one of the Prepend flags is set, the tokens are prepended in the order: timestamp,
+// hostname, PID, goroutine id, caller, e.g. "2006/01/02 15:04:05 [web-07] [4242] [g17] main.go:42: [INFO]
+// message". Structured formatters emit them as the host, pid, goroutine and caller keys.
+var PrependGoroutineID bool = false
+
+// PrependCaller flag determines whether standard output logs should prepend the file name and line number of
+// the code that logged the message, e.g. "main.go:42: ", like the log.Lshortfile flag of the standard library.
+var PrependCaller bool = false
+
+// FullCallerPath flag determines whether the caller prepended if PrependCaller is set includes the full path of
+// the file, like the log.Llongfile flag of the standard library.
+var FullCallerPath bool = false
+
+// UTCTimestamps flag determines whether the times of the messages are in UTC rather than in the local time
+// zone, like the log.LUTC flag of the standard library.
+var UTCTimestamps bool = false
+
+// SetPrependHostname sets the PrependHostname flag. It is safe to call while other goroutines are logging.
+func SetPrependHostname(b bool) {
+	setFlag(&PrependHostname, b)
+}
+
+// SetPrependPID sets the PrependPID flag. It is safe to call while other goroutines are logging.
+func SetPrependPID(b bool) {
+	setFlag(&PrependPID, b)
+}
+
+// SetPrependGoroutineID sets the PrependGoroutineID flag. It is safe to call while other goroutines are logging.
+func SetPrependGoroutineID(b bool) {
+	setFlag(&PrependGoroutineID, b)
+}
+
+// SetPrependCaller sets the PrependCaller flag. It is safe to call while other goroutines are logging.
+func SetPrependCaller(b bool) {
+	setFlag(&PrependCaller, b)
+}
+
+// SetFullCallerPath sets the FullCallerPath flag. It is safe to call while other goroutines are logging.
+func SetFullCallerPath(b bool) {
+	setFlag(&FullCallerPath, b)
+}
+
+// SetUTCTimestamps sets the UTCTimestamps flag. It is safe to call while other goroutines are logging.
+func SetUTCTimestamps(b bool) {
+	setFlag(&UTCTimestamps, b)
+}
+
+// callerOf returns the file:line of the code that called into clog, with the full path of the file if full is
+// set, or "???:1" if it cannot be found, like the standard library.
+func callerOf(full bool) string {
+	frame, ok := caller()
+	if !ok {
+		return "???:1"
+	}
+	file := frame.File
+	if !full {
+		file = filepath.Base(file)
+	}
+	return file + ":" + strconv.Itoa(frame.Line)
+}
+
+var hostname string = readHostname()
+var pid int = os.Getpid()
+
+func readHostname() string {
+	h, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return h
+}
+
+// goroutineID returns the id of the current goroutine, parsed from the "goroutine N [running]:" header
+// of runtime.Stack. It returns 0 if the id can not be parsed.
+func goroutineID() int {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, err := strconv.Atoi(string(b))
+	if err != nil {
+		return 0
+	}
+	return id
+}
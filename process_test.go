@@ -0,0 +1,58 @@
+package clog
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func TestProcessTokensOrder(t *testing.T) {
+	setupTest(t)
+	SetPrependHostname(true)
+	SetPrependPID(true)
+	SetPrependGoroutineID(true)
+	got := CaptureOutput(func() { Info("ready") })
+	want := regexp.MustCompile(fmt.Sprintf(`^2024/03/05 14:07:09 \[%s\] \[%d\] \[g[1-9][0-9]*\] \[INFO\] ready\n$`,
+		regexp.QuoteMeta(hostname), pid))
+	if !want.MatchString(got) {
+		t.Errorf("got %q, want it to match %s", got, want)
+	}
+}
+
+func TestProcessTokensAsFields(t *testing.T) {
+	setupTest(t)
+	SetPrependHostname(true)
+	SetPrependPID(true)
+	SetFormatter(&JSONFormatter{})
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(CaptureOutput(func() { Info("ready") })), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["host"] != hostname || got["pid"] != float64(pid) {
+		t.Errorf("got %v, want the host and pid keys", got)
+	}
+}
+
+// TestProcessFlagsConcurrently toggles the process flags while other goroutines log, for the race detector.
+func TestProcessFlagsConcurrently(t *testing.T) {
+	setupTest(t)
+	var wg sync.WaitGroup
+	CaptureOutput(func() {
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				Info("message")
+			}()
+			go func(on bool) {
+				defer wg.Done()
+				SetPrependHostname(on)
+				SetPrependPID(on)
+				SetPrependGoroutineID(on)
+			}(i%2 == 0)
+		}
+		wg.Wait()
+	})
+}
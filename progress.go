@@ -0,0 +1,194 @@
+package clog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/********************************************************************************
+* P R O G R E S S
+*********************************************************************************/
+
+// clearLine moves the cursor to the start of the line, and erases the line.
+const clearLine = "\r\x1b[K"
+
+// progressRedrawInterval is the minimum interval between two redraws of a progress shown in place, unless its
+// percentage changed.
+const progressRedrawInterval = 100 * time.Millisecond
+
+// progressLine is the line of the progresses shown in place at the bottom of the output, without a newline, or
+// "" if there are none. It is guarded by outputLock.
+var progressLine string
+
+// activeProgresses are the progresses shown in place, in order of creation. They are guarded by outputLock.
+var activeProgresses []*Progress
+
+// Progress reports the progress of a long-running job (see NewProgress). Its methods are safe for concurrent
+// use.
+type Progress struct {
+	label   string
+	total   int64
+	done    atomic.Int64
+	begin   time.Time
+	inPlace bool
+
+	lock sync.Mutex
+	// text is the in-place rendering of the progress, and drawnPercent and drawnAt the percent and time it was
+	// rendered at.
+	text         string
+	drawnPercent int
+	drawnAt      time.Time
+	// percentStep and interval are the thresholds of the Info lines, and reportedPercent and reportedAt are
+	// the percent and time of the last one.
+	percentStep     int
+	interval        time.Duration
+	reportedPercent int
+	reportedAt      time.Time
+	finished        bool
+}
+
+// NewProgress starts reporting the progress of a job with total steps, e.g. "import 45% (450/1000)". If the
+// output is a terminal, the progress is shown in place, on a line that stays below the messages logged in the
+// meantime. Otherwise, e.g. in CI, it is logged as an Info line every 10 percent or 10 seconds, whichever comes
+// first (see SetReportInterval). If total is not positive, only the number of steps done is shown. Done must be
+// called once the job ends.
+func NewProgress(label string, total int) *Progress {
+	colorsLock.RLock()
+	outputIsTerminal := colors.outputIsTerminal
+	colorsLock.RUnlock()
+	p := &Progress{
+		label:       label,
+		total:       int64(total),
+		begin:       now(),
+		inPlace:     outputIsTerminal && getFlag(&LogToStdOut),
+		percentStep: 10,
+		interval:    10 * time.Second,
+	}
+	p.reportedAt = p.begin
+	if p.inPlace {
+		outputLock.Lock()
+		activeProgresses = append(activeProgresses, p)
+		outputLock.Unlock()
+		p.report(0)
+	}
+	return p
+}
+
+// SetReportInterval sets how often the progress is logged when the output is not a terminal: whenever it
+// advanced by at least percent percent, or every interval. A zero value disables that threshold.
+func (p *Progress) SetReportInterval(percent int, interval time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.percentStep = percent
+	p.interval = interval
+}
+
+// Increment adds n steps to the steps done.
+func (p *Progress) Increment(n int) {
+	p.report(p.done.Add(int64(n)))
+}
+
+// Done ends the progress and logs a summary with the elapsed time, e.g. "import done: 1000/1000 in 1.5s".
+// Calls after the first one do nothing.
+func (p *Progress) Done() {
+	p.lock.Lock()
+	if p.finished {
+		p.lock.Unlock()
+		return
+	}
+	p.finished = true
+	p.lock.Unlock()
+	if p.inPlace {
+		outputLock.Lock()
+		for i, other := range activeProgresses {
+			if other == p {
+				activeProgresses = append(activeProgresses[:i:i], activeProgresses[i+1:]...)
+				break
+			}
+		}
+		redrawProgressLine()
+		outputLock.Unlock()
+	}
+	elapsed := now().Sub(p.begin).Round(time.Millisecond)
+	defaultCloggerForLevel(LogLevelInfo).Printf("%s done: %s in %s", p.label, p.counts(p.done.Load()), elapsed)
+}
+
+// counts renders done, and the total if there is one.
+func (p *Progress) counts(done int64) string {
+	if p.total <= 0 {
+		return fmt.Sprint(done)
+	}
+	return fmt.Sprintf("%d/%d", done, p.total)
+}
+
+// percent returns the percentage of done, or -1 if there is no total.
+func (p *Progress) percent(done int64) int {
+	if p.total <= 0 {
+		return -1
+	}
+	return int(done * 100 / p.total)
+}
+
+// render renders the progress at done, e.g. "import 45% (450/1000)".
+func (p *Progress) render(done int64) string {
+	if percent := p.percent(done); percent >= 0 {
+		return fmt.Sprintf("%s %d%% (%s)", p.label, percent, p.counts(done))
+	}
+	return fmt.Sprintf("%s (%s)", p.label, p.counts(done))
+}
+
+// report redraws the progress in place, or logs it if it reached one of the thresholds.
+func (p *Progress) report(done int64) {
+	p.lock.Lock()
+	if p.finished {
+		p.lock.Unlock()
+		return
+	}
+	if p.inPlace {
+		// the line is redrawn when the percentage changes, and at most every progressRedrawInterval otherwise
+		t, percent := now(), p.percent(done)
+		changed := p.text == "" || percent != p.drawnPercent || t.Sub(p.drawnAt) >= progressRedrawInterval
+		if changed {
+			p.text, p.drawnPercent, p.drawnAt = p.render(done), percent, t
+		}
+		p.lock.Unlock()
+		if changed {
+			outputLock.Lock()
+			redrawProgressLine()
+			outputLock.Unlock()
+		}
+		return
+	}
+	percent := p.percent(done)
+	t := now()
+	due := (p.percentStep > 0 && percent >= p.reportedPercent+p.percentStep) ||
+		(p.interval > 0 && t.Sub(p.reportedAt) >= p.interval)
+	if due {
+		p.reportedPercent, p.reportedAt = percent, t
+	}
+	p.lock.Unlock()
+	if due {
+		defaultCloggerForLevel(LogLevelInfo).Print(p.render(done))
+	}
+}
+
+// redrawProgressLine replaces the progress line with the current one of activeProgresses. It must be called
+// with outputLock held.
+func redrawProgressLine() {
+	texts := make([]string, 0, len(activeProgresses))
+	for _, p := range activeProgresses {
+		p.lock.Lock()
+		texts = append(texts, p.text)
+		p.lock.Unlock()
+	}
+	line := strings.Join(texts, "  ")
+	if line == progressLine {
+		return
+	}
+	progressLine = line
+	io.WriteString(output, clearLine+line)
+}
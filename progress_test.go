@@ -0,0 +1,79 @@
+package clog
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestProgressNotTerminal checks that, if the output is not a terminal, the progress is logged as Info lines
+// whenever it advanced by the percent step, followed by a summary once it is done.
+func TestProgressNotTerminal(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	lines := captureLines(func() {
+		p := NewProgress("import", 100)
+		p.SetReportInterval(25, 0)
+		for i := 0; i < 10; i++ {
+			p.Increment(10)
+		}
+		p.Done()
+		p.Done()
+	})
+	want := []string{
+		"[INFO] import 30% (30/100)",
+		"[INFO] import 60% (60/100)",
+		"[INFO] import 90% (90/100)",
+		"[INFO] import done: 100/100 in 0s",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+}
+
+// TestProgressInPlace checks that, on a terminal, the progress is redrawn in place, below the messages logged in
+// the meantime, and erased once it is done.
+func TestProgressInPlace(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	out := CaptureOutput(func() {
+		setOutputIsTerminal(true)
+		p := NewProgress("import", 4)
+		p.Increment(1)
+		Info("user signed in")
+		p.Increment(1)
+		p.Done()
+	})
+	want := clearLine + "import 0% (0/4)" +
+		clearLine + "import 25% (1/4)" +
+		clearLine + "[INFO] user signed in\n" + "import 25% (1/4)" +
+		clearLine + "import 50% (2/4)" +
+		clearLine +
+		"[INFO] import done: 2/4 in 0s\n"
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+// TestProgressConcurrentIncrements checks that no step is lost when Increment is called concurrently.
+func TestProgressConcurrentIncrements(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	lines := captureLines(func() {
+		p := NewProgress("import", 0)
+		p.SetReportInterval(0, 0)
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.Increment(2)
+			}()
+		}
+		wg.Wait()
+		p.Done()
+	})
+	if len(lines) != 1 || lines[0] != "[INFO] import done: 100 in 0s" {
+		t.Errorf("got %q, want only the summary with 100 steps", lines)
+	}
+}
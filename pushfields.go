@@ -0,0 +1,83 @@
+package clog
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+/********************************************************************************
+* S C O P E D   F I E L D S
+*********************************************************************************/
+
+// fieldScopes holds the stacks of fields pushed using PushFields, by goroutine id, outermost first.
+var fieldScopes = make(map[int][]*Fields)
+var fieldScopesLock sync.RWMutex
+
+// activeFieldScopes is the number of scopes that have not been popped, so that the goroutine id is only
+// looked up while there are some.
+var activeFieldScopes atomic.Int64
+
+// PushFields attaches fields to every message logged by the calling goroutine, until the returned pop function
+// is called, e.g. for code that does not pass a context.Context or a Clogger around:
+//
+//	defer clog.PushFields(map[string]interface{}{"job": id})()
+//
+// Scopes can be nested: the fields of the inner scopes take precedence. The scoped fields come after the global
+// fields and before those of the Clogger. The pop function must be called, typically deferred, and the scopes
+// are not inherited by the goroutines started within them. Calling pop more than once does no harm. Logging is
+// only slowed down while a scope is active.
+func PushFields(fields map[string]interface{}) (pop func()) {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	frame := make(Fields, 0, len(keys))
+	for _, key := range keys {
+		frame = append(frame, Field{key, fields[key]})
+	}
+
+	id := goroutineID()
+	fieldScopesLock.Lock()
+	fieldScopes[id] = append(fieldScopes[id], &frame)
+	fieldScopesLock.Unlock()
+	activeFieldScopes.Add(1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			fieldScopesLock.Lock()
+			defer fieldScopesLock.Unlock()
+			stack := fieldScopes[id]
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i] == &frame {
+					stack = append(stack[:i:i], stack[i+1:]...)
+					break
+				}
+			}
+			if len(stack) == 0 {
+				delete(fieldScopes, id)
+			} else {
+				fieldScopes[id] = stack
+			}
+			activeFieldScopes.Add(-1)
+		})
+	}
+}
+
+// scopedFields returns the fields pushed by the calling goroutine using PushFields, merged with the inner scopes
+// taking precedence.
+func scopedFields() Fields {
+	if activeFieldScopes.Load() == 0 {
+		return nil
+	}
+	id := goroutineID()
+	fieldScopesLock.RLock()
+	defer fieldScopesLock.RUnlock()
+	var merged Fields
+	for _, frame := range fieldScopes[id] {
+		merged = merged.merge(*frame)
+	}
+	return merged
+}
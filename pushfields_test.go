@@ -0,0 +1,45 @@
+package clog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPushFields(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	var other []string
+	lines := captureLines(func() {
+		popJob := PushFields(map[string]interface{}{"job": 7, "step": "fetch"})
+		Info("started")
+		popStep := PushFields(map[string]interface{}{"step": "build"})
+		Info("building")
+		// the scopes are not inherited by other goroutines
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			other = captureLines(func() { Info("unrelated") })
+		}()
+		<-done
+		popStep()
+		popStep()
+		Info("built")
+		popJob()
+		Info("idle")
+	})
+	want := []string{
+		"[INFO] started job=7 step=fetch",
+		"[INFO] building job=7 step=build",
+		"[INFO] built job=7 step=fetch",
+		"[INFO] idle",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+	if want := []string{"[INFO] unrelated"}; !reflect.DeepEqual(other, want) {
+		t.Errorf("the other goroutine got %q, want %q", other, want)
+	}
+	if n := activeFieldScopes.Load(); n != 0 {
+		t.Errorf("%d scopes are still active", n)
+	}
+}
@@ -0,0 +1,93 @@
+package clog
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+/********************************************************************************
+* R E G I S T R Y
+*********************************************************************************/
+
+// Registry holds Cloggers by name. The package functions, GetCloggerByName and NewClogger use the default
+// registry (see DefaultRegistry). A library can create its own Registry, so that the names of its Cloggers
+// never collide with the ones of the application or of other libraries.
+type Registry struct {
+	cloggers map[string]*Clogger
+	lock     sync.RWMutex
+}
+
+// NewRegistry returns a new, empty, Registry.
+func NewRegistry() *Registry {
+	return &Registry{cloggers: make(map[string]*Clogger)}
+}
+
+var defaultRegistry *Registry = NewRegistry()
+
+// DefaultRegistry returns the Registry used by the package functions, which holds the default cloggers.
+func DefaultRegistry() *Registry {
+	ensureDefaults()
+	return defaultRegistry
+}
+
+// NewClogger creates a new Clogger like the package function NewClogger, and registers it in r. It panics if
+// it encounters an error, e.g. if r already has a Clogger with the same name.
+func (r *Registry) NewClogger(name string, logLevel int, options ...CloggerOption) *Clogger {
+	clogger, err := newClogger(name, logLevel, options...)
+	if err != nil {
+		panic(err)
+	}
+	if err := r.Register(clogger); err != nil {
+		panic(err)
+	}
+	return clogger
+}
+
+// Register adds cl to r. It returns an error if r already has a Clogger with the same name.
+func (r *Registry) Register(cl *Clogger) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, exists := r.cloggers[cl.Name]; exists {
+		return fmt.Errorf("%s: a logger with the name %s already exists", PACKAGE_NAME, cl.Name)
+	}
+	r.cloggers[cl.Name] = cl
+	return nil
+}
+
+// Replace adds cl to r in place of the Clogger with the same name. It returns the replaced Clogger, or nil if
+// there was none.
+func (r *Registry) Replace(cl *Clogger) *Clogger {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	old := r.cloggers[cl.Name]
+	r.cloggers[cl.Name] = cl
+	return old
+}
+
+// Get returns the Clogger with the given name, and whether it exists.
+func (r *Registry) Get(name string) (*Clogger, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	cl, ok := r.cloggers[name]
+	return cl, ok
+}
+
+// List returns the Cloggers of r, sorted by name.
+func (r *Registry) List() []*Clogger {
+	r.lock.RLock()
+	list := make([]*Clogger, 0, len(r.cloggers))
+	for _, cl := range r.cloggers {
+		list = append(list, cl)
+	}
+	r.lock.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// clear removes all the Cloggers from r.
+func (r *Registry) clear() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.cloggers = make(map[string]*Clogger)
+}
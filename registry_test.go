@@ -0,0 +1,82 @@
+package clog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistriesWithSameNames(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	a, b := NewRegistry(), NewRegistry()
+	httpA := a.NewClogger("HTTP", LogLevelInfo, FG_GREEN)
+	httpB := b.NewClogger("HTTP", LogLevelError, FG_RED)
+	// the default registry has its own "HTTP" as well
+	httpDefault := NewClogger("HTTP", LogLevelWarning)
+
+	if got, ok := a.Get("HTTP"); !ok || got != httpA {
+		t.Errorf("a.Get(\"HTTP\") = %p, %t, want %p", got, ok, httpA)
+	}
+	if got, ok := b.Get("HTTP"); !ok || got != httpB {
+		t.Errorf("b.Get(\"HTTP\") = %p, %t, want %p", got, ok, httpB)
+	}
+	if got := GetCloggerByName("HTTP"); got != httpDefault {
+		t.Errorf("GetCloggerByName(\"HTTP\") = %p, want %p", got, httpDefault)
+	}
+	if got, ok := DefaultRegistry().Get("HTTP"); !ok || got != httpDefault {
+		t.Errorf("DefaultRegistry().Get(\"HTTP\") = %p, %t, want %p", got, ok, httpDefault)
+	}
+
+	got := captureLines(func() {
+		httpA.Print("from a")
+		httpB.Print("from b")
+		httpDefault.Print("from the application")
+	})
+	want := []string{"[HTTP] from a", "[HTTP] from b", "[HTTP] from the application"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	setupTest(t)
+	r := NewRegistry()
+	r.NewClogger("HTTP", LogLevelInfo)
+	if err := r.Register(NewClogger("HTTP", LogLevelInfo)); err == nil {
+		t.Error("registered a second Clogger named HTTP")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Registry.NewClogger did not panic on a second Clogger named HTTP")
+			}
+		}()
+		r.NewClogger("HTTP", LogLevelDebug)
+	}()
+
+	r.NewClogger("DB", LogLevelInfo)
+	r.NewClogger("Cache", LogLevelInfo)
+	var names []string
+	for _, cl := range r.List() {
+		names = append(names, cl.Name)
+	}
+	if got, want := strings.Join(names, ","), "Cache,DB,HTTP"; got != want {
+		t.Errorf("List() = %s, want %s", got, want)
+	}
+	if _, ok := r.Get("Info"); ok {
+		t.Error("a new Registry has the default cloggers")
+	}
+
+	replacement := NewRegistry().NewClogger("DB", LogLevelError)
+	if old := r.Replace(replacement); old == nil || old.Name != "DB" || old == replacement {
+		t.Errorf("Replace returned %v, want the previous DB Clogger", old)
+	}
+	if got, _ := r.Get("DB"); got != replacement {
+		t.Error("Replace did not replace the DB Clogger")
+	}
+}
@@ -0,0 +1,44 @@
+package clog
+
+import (
+	"fmt"
+)
+
+// PrintfR formats the message using the provided args, logs it like Printf, and returns it, e.g. to also use it
+// in an HTTP response.
+func (l *Clogger) PrintfR(formatString string, args ...interface{}) string {
+	msg := sprintf(formatString, args...)
+	l.printEntry(l.newEntry(msg, nil))
+	return msg
+}
+
+// LogAndReturn formats the message using the provided args, logs it using the default clogger of level, and
+// returns it.
+func LogAndReturn(level int, formatString string, args ...interface{}) string {
+	return defaultCloggerForLevel(level).PrintfR(formatString, args...)
+}
+
+// InfofR is like Infof, and returns the formatted message.
+func InfofR(formatString string, args ...interface{}) string {
+	return defaultCloggerForLevel(LogLevelInfo).PrintfR(formatString, args...)
+}
+
+// WarningfR is like Warningf, and returns the formatted message.
+func WarningfR(formatString string, args ...interface{}) string {
+	return defaultCloggerForLevel(LogLevelWarning).PrintfR(formatString, args...)
+}
+
+// ErrorfR is like Errorf, and returns the formatted message.
+func ErrorfR(formatString string, args ...interface{}) string {
+	return defaultCloggerForLevel(LogLevelError).PrintfR(formatString, args...)
+}
+
+// ErrorfE is like Errorf, and returns the message as an error, which collapses the common
+// "clog.Errorf(...); return fmt.Errorf(...)". The error is built using fmt.Errorf, so that %w wraps an error
+// like it does there, and the logged message is its text.
+func ErrorfE(formatString string, args ...interface{}) error {
+	err := fmt.Errorf(formatString, args...)
+	cl := defaultCloggerForLevel(LogLevelError)
+	cl.printEntry(cl.newEntry(err.Error(), nil))
+	return err
+}
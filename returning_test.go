@@ -0,0 +1,42 @@
+package clog
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestLogAndReturn(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetLogLevel(LogLevelWarning)
+	var returned []string
+	lines := captureLines(func() {
+		returned = append(returned,
+			LogAndReturn(LogLevelError, "quota of %s exceeded", "alice"),
+			WarningfR("retry %d of %d", 2, 3),
+			// a filtered message is still returned
+			InfofR("user %d signed in", 42),
+		)
+	})
+	if want := []string{"quota of alice exceeded", "retry 2 of 3", "user 42 signed in"}; !reflect.DeepEqual(returned, want) {
+		t.Errorf("returned %q, want %q", returned, want)
+	}
+	if want := []string{"[ERROR] quota of alice exceeded", "[WARNING] retry 2 of 3"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("logged %q, want %q", lines, want)
+	}
+}
+
+func TestErrorfE(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	var err error
+	lines := captureLines(func() { err = ErrorfE("reading %s: %w", "config.yaml", io.ErrUnexpectedEOF) })
+	if !errors.Is(err, io.ErrUnexpectedEOF) || err.Error() != "reading config.yaml: unexpected EOF" {
+		t.Errorf("got the error %v, want it to wrap io.ErrUnexpectedEOF", err)
+	}
+	if want := []string{"[ERROR] reading config.yaml: unexpected EOF"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("logged %q, want %q", lines, want)
+	}
+}
@@ -0,0 +1,184 @@
+package clog
+
+import (
+	"path"
+	"regexp"
+	"sync"
+)
+
+/********************************************************************************
+* R O U T E R
+*********************************************************************************/
+
+// Router decides which destinations an entry is written to, in addition to the output and the syslog. Route
+// is only called for the entries that pass the LogLevel, and must be safe for concurrent use.
+type Router interface {
+	Route(e Entry) []Destination
+}
+
+// defaultRouter routes the entries to the destinations added using AddDestination, followed by those that
+// their tags are routed to using RouteTag.
+type defaultRouter struct{}
+
+func (defaultRouter) Route(e Entry) []Destination {
+	tagged := tagDestinations(e.Tags)
+	if len(tagged) == 0 {
+		return getDestinations()
+	}
+	dests := append([]Destination{}, getDestinations()...)
+	for _, dest := range tagged {
+		if !containsDestination(dests, dest) {
+			dests = append(dests, dest)
+		}
+	}
+	return dests
+}
+
+var router Router = defaultRouter{}
+var routerLock sync.RWMutex
+
+// SetRouter sets the Router that decides which destinations the entries are written to, e.g. a RuleRouter for
+// topologies that AddDestination and RouteTag do not cover. A nil r restores the default Router, which writes
+// to the destinations added using AddDestination and RouteTag.
+func SetRouter(r Router) {
+	if r == nil {
+		r = defaultRouter{}
+	}
+	routerLock.Lock()
+	defer routerLock.Unlock()
+	router = r
+}
+
+// getRouter returns the Router set using SetRouter.
+func getRouter() Router {
+	routerLock.RLock()
+	defer routerLock.RUnlock()
+	return router
+}
+
+// RouteMatcher reports whether an entry matches a RouteRule.
+type RouteMatcher func(e Entry) bool
+
+// MatchLevels matches the entries with a level between min and max, inclusive, in the order of the levels
+// (see IsAtLeast).
+func MatchLevels(min, max int) RouteMatcher {
+	return func(e Entry) bool { return IsAtLeast(e.Level, min) && IsAtLeast(max, e.Level) }
+}
+
+// MatchLogger matches the entries logged by a Clogger whose name matches pattern, a glob as used by
+// path.Match, e.g. "db*".
+func MatchLogger(pattern string) RouteMatcher {
+	return func(e Entry) bool {
+		ok, _ := path.Match(pattern, e.Logger)
+		return ok
+	}
+}
+
+// MatchTag matches the entries tagged with tag.
+func MatchTag(tag string) RouteMatcher {
+	return func(e Entry) bool { return containsTag(e.Tags, tag) }
+}
+
+// MatchMessage matches the entries whose message matches re.
+func MatchMessage(re *regexp.Regexp) RouteMatcher {
+	return func(e Entry) bool { return re.MatchString(e.Message) }
+}
+
+// MatchNot matches the entries that m does not match, e.g. MatchNot(MatchTag("auth")).
+func MatchNot(m RouteMatcher) RouteMatcher {
+	return func(e Entry) bool { return !m(e) }
+}
+
+// RouteRule routes the entries that match all of its matchers to its destinations. A rule without matchers
+// matches every entry.
+type RouteRule struct {
+	Match        []RouteMatcher
+	Destinations []Destination
+	// Final stops the evaluation of the following rules when the rule matches.
+	Final bool
+}
+
+// matches reports whether e matches all the matchers of r.
+func (r RouteRule) matches(e Entry) bool {
+	for _, m := range r.Match {
+		if !m(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// RuleRouter is a Router that evaluates ordered rules. An entry is written to the destinations of every rule
+// that it matches, each only once, up to the first matching Final rule.
+type RuleRouter struct {
+	rules []RouteRule
+	lock  sync.RWMutex
+}
+
+// NewRuleRouter returns a RuleRouter with the given rules, e.g.
+//
+//	clog.SetRouter(clog.NewRuleRouter(
+//		clog.RouteRule{Match: []clog.RouteMatcher{clog.MatchLevels(clog.LogLevelError, clog.MaxLevel)}, Destinations: []clog.Destination{pager}},
+//		clog.RouteRule{Match: []clog.RouteMatcher{clog.MatchTag("billing")}, Destinations: []clog.Destination{billing}, Final: true},
+//		clog.RouteRule{Destinations: []clog.Destination{archive}},
+//	))
+func NewRuleRouter(rules ...RouteRule) *RuleRouter {
+	return &RuleRouter{rules: rules}
+}
+
+// AddRule appends rule to the rules of r.
+func (r *RuleRouter) AddRule(rule RouteRule) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.rules = append(r.rules, rule)
+}
+
+// Route returns the destinations of the rules that e matches.
+func (r *RuleRouter) Route(e Entry) []Destination {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	var dests []Destination
+	for _, rule := range r.rules {
+		if !rule.matches(e) {
+			continue
+		}
+		for _, dest := range rule.Destinations {
+			if !containsDestination(dests, dest) {
+				dests = append(dests, dest)
+			}
+		}
+		if rule.Final {
+			break
+		}
+	}
+	return dests
+}
+
+// Destinations returns all the destinations of the rules of r, each only once.
+func (r *RuleRouter) Destinations() []Destination {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	var dests []Destination
+	for _, rule := range r.rules {
+		for _, dest := range rule.Destinations {
+			if !containsDestination(dests, dest) {
+				dests = append(dests, dest)
+			}
+		}
+	}
+	return dests
+}
+
+// outputDestination writes the entries to the output, like the output itself.
+type outputDestination struct{}
+
+func (outputDestination) Write(e *Entry) error {
+	writeEntryLine(e, formatEntry(GetFormatter(), e))
+	return nil
+}
+
+// OutputDestination returns a Destination that writes to the output (see SetOutput) using the global
+// Formatter, e.g. to route entries to the output using a RuleRouter while LogToStdOut is false.
+func OutputDestination() Destination {
+	return outputDestination{}
+}
@@ -0,0 +1,153 @@
+package clog
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// useRouter sets r as the Router until tb is done.
+func useRouter(tb testing.TB, r Router) {
+	SetRouter(r)
+	tb.Cleanup(func() { SetRouter(nil) })
+}
+
+// TestRuleRouter routes errors to a pager, the billing messages to their own destination only, and everything
+// else to an archive, and checks where each message lands.
+func TestRuleRouter(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	pager, billing, archive := &recordingDestination{}, &recordingDestination{}, &recordingDestination{}
+	useRouter(t, NewRuleRouter(
+		RouteRule{Match: []RouteMatcher{MatchLevels(LogLevelError, MaxLevel)}, Destinations: []Destination{pager}},
+		RouteRule{Match: []RouteMatcher{MatchTag("billing")}, Destinations: []Destination{billing}, Final: true},
+		RouteRule{Destinations: []Destination{archive}},
+	))
+	SetLogLevel(LogLevelInfo)
+	out := captureLines(func() {
+		Debug("below the level")
+		Info("request served")
+		Error("disk full")
+		GetCloggerByName("Info").Tagged("billing").Print("invoice sent")
+		GetCloggerByName("Crit").Tagged("billing").Print("payment failed")
+	})
+
+	tests := []struct {
+		name string
+		dest *recordingDestination
+		want []string
+	}{
+		{"pager", pager, []string{"disk full", "payment failed"}},
+		{"billing", billing, []string{"invoice sent", "payment failed"}},
+		{"archive", archive, []string{"request served", "disk full"}},
+	}
+	for _, tt := range tests {
+		if strings.Join(tt.dest.messages, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.dest.messages, tt.want)
+		}
+	}
+	// the output is not affected by the router
+	want := []string{"[INFO] request served", "[ERROR] disk full", "[INFO] invoice sent #billing", "[CRIT] payment failed #billing"}
+	if strings.Join(out, "\n") != strings.Join(want, "\n") {
+		t.Errorf("output: got %q, want %q", out, want)
+	}
+}
+
+func TestRouteMatchers(t *testing.T) {
+	e := Entry{Level: LogLevelWarning, Logger: "dbpool", Message: "connection reset", Tags: []string{"db"}}
+	tests := []struct {
+		name    string
+		matcher RouteMatcher
+		want    bool
+	}{
+		{"levels including", MatchLevels(LogLevelNotice, LogLevelError), true},
+		{"levels at min", MatchLevels(LogLevelWarning, LogLevelWarning), true},
+		{"levels above", MatchLevels(LogLevelError, MaxLevel), false},
+		{"levels below", MatchLevels(MinLevel, LogLevelNotice), false},
+		{"logger glob", MatchLogger("db*"), true},
+		{"logger exact", MatchLogger("dbpool"), true},
+		{"other logger", MatchLogger("http*"), false},
+		{"tag", MatchTag("db"), true},
+		{"other tag", MatchTag("billing"), false},
+		{"message", MatchMessage(regexp.MustCompile(`^connection (reset|refused)$`)), true},
+		{"other message", MatchMessage(regexp.MustCompile(`timeout`)), false},
+		{"not", MatchNot(MatchTag("billing")), true},
+		{"not matching", MatchNot(MatchTag("db")), false},
+	}
+	for _, tt := range tests {
+		if got := tt.matcher(e); got != tt.want {
+			t.Errorf("%s: got %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRuleRouterRoute(t *testing.T) {
+	a, b, c := &recordingDestination{}, &recordingDestination{}, &recordingDestination{}
+	var messages []string
+	u := uncomparableDestination{messages: &messages, extra: []string{"x"}}
+	r := NewRuleRouter(
+		RouteRule{Match: []RouteMatcher{MatchLogger("db*")}, Destinations: []Destination{a, u}},
+		RouteRule{Match: []RouteMatcher{MatchLevels(LogLevelError, MaxLevel)}, Destinations: []Destination{a, b}},
+		RouteRule{Match: []RouteMatcher{MatchTag("audit")}, Destinations: []Destination{c, u}, Final: true},
+	)
+	r.AddRule(RouteRule{Destinations: []Destination{b}})
+
+	tests := []struct {
+		name string
+		e    Entry
+		want []Destination
+	}{
+		{"last rule only", Entry{Level: LogLevelInfo, Logger: "http"}, []Destination{b}},
+		// a is only written to once
+		{"several rules", Entry{Level: LogLevelError, Logger: "db"}, []Destination{a, u, b}},
+		{"final rule", Entry{Level: LogLevelInfo, Logger: "http", Tags: []string{"audit"}}, []Destination{c, u}},
+		// the uncomparable destination cannot be told apart from itself, so it is written to once per rule
+		{"uncomparable in two rules", Entry{Level: LogLevelInfo, Logger: "db", Tags: []string{"audit"}}, []Destination{a, u, c, u}},
+	}
+	for _, tt := range tests {
+		got := r.Route(tt.e)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %d destinations, want %d", tt.name, len(got), len(tt.want))
+			continue
+		}
+		for i := range got {
+			_, gotUncomparable := got[i].(uncomparableDestination)
+			_, wantUncomparable := tt.want[i].(uncomparableDestination)
+			if !sameDestination(got[i], tt.want[i]) && !(gotUncomparable && wantUncomparable) {
+				t.Errorf("%s: destination %d is %T, want %T", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+	if got := r.Destinations(); len(got) != 5 {
+		t.Errorf("Destinations: got %d, want a, b, c and the uncomparable destination twice", len(got))
+	}
+}
+
+func TestDefaultRouter(t *testing.T) {
+	setupTest(t)
+	resetTags(t)
+	destinationsLock.Lock()
+	previous := destinations
+	destinations = nil
+	destinationsLock.Unlock()
+	t.Cleanup(func() {
+		destinationsLock.Lock()
+		defer destinationsLock.Unlock()
+		destinations = previous
+	})
+	all, billing := &recordingDestination{}, &recordingDestination{}
+	AddDestination(all)
+	RouteTag("billing", billing)
+	RouteTag("audit", all)
+	CaptureOutput(func() {
+		Info("untagged")
+		GetCloggerByName("Info").Tagged("billing").Print("invoice sent")
+		GetCloggerByName("Info").Tagged("audit").Print("login")
+	})
+	if want := []string{"untagged", "invoice sent", "login"}; strings.Join(all.messages, "\n") != strings.Join(want, "\n") {
+		t.Errorf("all: got %q, want %q", all.messages, want)
+	}
+	if want := []string{"invoice sent"}; strings.Join(billing.messages, "\n") != strings.Join(want, "\n") {
+		t.Errorf("billing: got %q, want %q", billing.messages, want)
+	}
+}
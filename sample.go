@@ -0,0 +1,95 @@
+package clog
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// sampler keeps 1 of every n messages logged by a Clogger.
+type sampler struct {
+	n     uint64
+	count atomic.Uint64
+}
+
+// keep reports whether the next message should be logged, i.e. whether it is the first of the next n messages.
+func (s *sampler) keep() bool {
+	return (s.count.Add(1)-1)%s.n == 0
+}
+
+// Sampled returns a new Clogger, with the same configuration as l, that only logs 1 of every n messages: the
+// first, the (n+1)th, and so on. The messages it logs are annotated with "(sampled 1/n)", so that readers know
+// the volume is underrepresented. The messages that would not be written anywhere, e.g. because they are below
+// the LogLevel, are not counted. Sampling is per returned Clogger, not per call site, so it should be created
+// once and reused, e.g. stored in a package variable. Cloggers derived from it, e.g. using WithFields, share its
+// counter. If n is less than 2, every message is logged.
+func (l *Clogger) Sampled(n int) *Clogger {
+	child := l.pooledClone()
+	child.sampler = nil
+	child.sampleNote = 0
+	if n > 1 {
+		child.sampler = &sampler{n: uint64(n)}
+		child.sampleNote = n
+	}
+	return child
+}
+
+// maxSampleSites is the maximum number of call sites that SampleEvery keeps a counter for. Above it, the counter
+// of another call site is forgotten, so that site starts sampling afresh.
+const maxSampleSites = 1024
+
+// sampleSites holds the counters of the calls to SampleEvery, by call site.
+var sampleSites = struct {
+	sync.Mutex
+	bySite map[string]*sampler
+}{bySite: make(map[string]*sampler)}
+
+// siteSampler returns the counter of the calls to SampleEvery from site, creating it if needed.
+func siteSampler(site string, n int) *sampler {
+	sampleSites.Lock()
+	defer sampleSites.Unlock()
+	if s, ok := sampleSites.bySite[site]; ok {
+		return s
+	}
+	if len(sampleSites.bySite) >= maxSampleSites {
+		for other := range sampleSites.bySite {
+			delete(sampleSites.bySite, other)
+			break
+		}
+	}
+	s := &sampler{n: uint64(n)}
+	sampleSites.bySite[site] = s
+	return s
+}
+
+// SampleEvery calls fn for 1 of every n calls to SampleEvery from the same call site, so that the work of
+// building a message on a hot path is skipped as well. fn is given the "Debug" default clogger, which annotates
+// the messages with "(sampled 1/n)". If n is less than 2, fn is called every time. fn is not called, and the
+// call is not counted, while the messages of the "Debug" default clogger would not be written (see WouldLog).
+func SampleEvery(n int, fn func(*Clogger)) {
+	debug := defaultCloggerForLevel(LogLevelDebug)
+	if n < 2 {
+		fn(debug)
+		return
+	}
+	if !debug.WouldLog(LogLevelDebug) {
+		return
+	}
+	if !siteSampler(fmt.Sprintf("%s/%d", callerLocation(), n), n).keep() {
+		return
+	}
+	cl := debug.clone()
+	cl.sampleNote = n
+	fn(cl)
+}
+
+// sample reports whether e should be logged by l, and annotates its message if l is sampled.
+func (l *Clogger) sample(e *Entry) bool {
+	if l.sampler != nil && !l.sampler.keep() {
+		return false
+	}
+	if l.sampleNote > 1 {
+		e.Message += fmt.Sprintf(" (sampled 1/%d)", l.sampleNote)
+	}
+	return true
+}
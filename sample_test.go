@@ -0,0 +1,140 @@
+package clog
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSampled(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	cl := GetCloggerByName("Debug").Sampled(3)
+	got := captureLines(func() {
+		for i := 0; i < 7; i++ {
+			cl.Printf("m%d", i)
+		}
+	})
+	want := []string{"[DEBUG] m0 (sampled 1/3)", "[DEBUG] m3 (sampled 1/3)", "[DEBUG] m6 (sampled 1/3)"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestSampledBelowLogLevel logs messages below the LogLevel, which must not use up the samples.
+func TestSampledBelowLogLevel(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	cl := GetCloggerByName("Debug").Sampled(3)
+	SetLogLevel(LogLevelInfo)
+	got := captureLines(func() {
+		for i := 0; i < 5; i++ {
+			cl.Printf("hidden %d", i)
+		}
+	})
+	if len(got) != 0 {
+		t.Fatalf("logged %q below the LogLevel", got)
+	}
+	SetLogLevel(LogLevelDebug)
+	got = captureLines(func() {
+		for i := 0; i < 4; i++ {
+			cl.Printf("m%d", i)
+		}
+	})
+	want := []string{"[DEBUG] m0 (sampled 1/3)", "[DEBUG] m3 (sampled 1/3)"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestEveryBelowLogLevel(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	cl := GetCloggerByName("Debug").Every(time.Hour)
+	SetLogLevel(LogLevelInfo)
+	cl.Print("hidden")
+	cl.Print("hidden")
+	SetLogLevel(LogLevelDebug)
+	got := captureLines(func() {
+		cl.Print("shown")
+		cl.Print("dropped")
+	})
+	if want := []string{"[DEBUG] shown"}; strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSampledConcurrent(t *testing.T) {
+	setupTest(t)
+	const goroutines, messages, n = 100, 100, 10
+	var out lockedBuffer
+	previous := GetOutput()
+	SetOutput(&out)
+	t.Cleanup(func() { SetOutput(previous) })
+	cl := GetCloggerByName("Info").Sampled(n)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < messages; i++ {
+				cl.Print("message")
+			}
+		}()
+	}
+	wg.Wait()
+	if got := strings.Count(out.String(), "\n"); got != goroutines*messages/n {
+		t.Errorf("got %d lines, want %d", got, goroutines*messages/n)
+	}
+}
+
+func TestSampleEvery(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	sampleSites.Lock()
+	sampleSites.bySite = make(map[string]*sampler)
+	sampleSites.Unlock()
+
+	calls := 0
+	hot := func() {
+		SampleEvery(2, func(cl *Clogger) {
+			calls++
+			cl.Printf("call %d", calls)
+		})
+	}
+	SetLogLevel(LogLevelInfo)
+	for i := 0; i < 3; i++ {
+		hot()
+	}
+	if calls != 0 {
+		t.Fatalf("fn was called %d times below the LogLevel", calls)
+	}
+	SetLogLevel(LogLevelDebug)
+	got := captureLines(func() {
+		for i := 0; i < 4; i++ {
+			hot()
+		}
+	})
+	want := []string{"[DEBUG] call 1 (sampled 1/2)", "[DEBUG] call 2 (sampled 1/2)"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSampleSitesBounded(t *testing.T) {
+	t.Cleanup(func() {
+		sampleSites.Lock()
+		defer sampleSites.Unlock()
+		sampleSites.bySite = make(map[string]*sampler)
+	})
+	for i := 0; i < maxSampleSites+10; i++ {
+		siteSampler(fmt.Sprintf("file.go:%d/2", i), 2)
+	}
+	sampleSites.Lock()
+	defer sampleSites.Unlock()
+	if n := len(sampleSites.bySite); n != maxSampleSites {
+		t.Errorf("%d call sites have a counter, want %d", n, maxSampleSites)
+	}
+}
@@ -0,0 +1,180 @@
+package clog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+/********************************************************************************
+* S C H E M A
+*********************************************************************************/
+
+// SchemaKey describes a key of the structured output.
+type SchemaKey struct {
+	// Name is the key, as emitted.
+	Name string `json:"name"`
+	// Type is the JSON type of the value: "string", "number", "boolean", "object", "array" or "null".
+	Type string `json:"type"`
+	// Description says what the value is.
+	Description string `json:"description,omitempty"`
+	// Values are the possible values, if there is a fixed set of them, e.g. the names of the levels.
+	Values []string `json:"values,omitempty"`
+	// AddedBy is the feature that adds the key, e.g. "PrependCaller", or empty if every entry has it.
+	AddedBy string `json:"added_by,omitempty"`
+	// Enabled reports whether the key is emitted with the current configuration: always for the keys that every
+	// entry has, and for the other keys if the feature that adds them is enabled. Keys added by features such as
+	// Group and Tagged, which are used per Clogger, are reported as enabled.
+	Enabled bool `json:"enabled"`
+}
+
+// SchemaFormatter is a Formatter that declares the keys that it emits, so that OutputSchema can describe them.
+// The JSONFormatter and the LogfmtFormatter implement it.
+type SchemaFormatter interface {
+	Formatter
+	// Keys returns the keys that the Formatter emits for every entry, and those that features may add, before
+	// the fields.
+	Keys() []SchemaKey
+}
+
+// Schema describes the keys of the structured output with the current configuration (see OutputSchema).
+type Schema struct {
+	// Formatter is the type of the global Formatter, e.g. "*clog.JSONFormatter".
+	Formatter string `json:"formatter"`
+	// Structured is false if the Formatter does not implement SchemaFormatter, e.g. the TextFormatter, in which
+	// case Keys is empty.
+	Structured bool `json:"structured"`
+	// Keys are the keys emitted by the Formatter, in the order in which they are emitted.
+	Keys []SchemaKey `json:"keys"`
+	// Fields are the fields known in advance, which follow Keys: the global fields (see SetGlobalFields), with the
+	// type of their current value, and the fingerprint field (see EnableFingerprinting).
+	Fields []SchemaKey `json:"fields"`
+	// ClashPrefix prefixes the fields whose key is one of Keys, e.g. "fields.time".
+	ClashPrefix string `json:"clash_prefix"`
+}
+
+// fieldClashPrefix prefixes the fields named after one of the keys of the entry itself (see structuredKey).
+const fieldClashPrefix = "fields."
+
+// processKey is a key of the structured output that a feature adds to the entries, after the core keys.
+type processKey struct {
+	SchemaKey
+	// enabled reports whether the feature is enabled, or is nil if it is used per Clogger.
+	enabled func() bool
+}
+
+// coreKeys are the keys that the structured formatters emit for every entry, in that order.
+func coreKeys() []SchemaKey {
+	levels := Levels()
+	names := make([]string, len(levels))
+	for i, level := range levels {
+		names[i] = levelName(level)
+	}
+	return []SchemaKey{
+		{Name: "time", Type: "string", Description: "the time at which the message was logged", Enabled: true},
+		{Name: "level", Type: "string", Description: "the level of the message", Values: names, Enabled: true},
+		{Name: "logger", Type: "string", Description: "the name of the Clogger that logged the message", Enabled: true},
+		{Name: "msg", Type: "string", Description: "the message", Enabled: true},
+	}
+}
+
+// processKeys are the keys that features add to the entries, in the order in which processPairs emits them.
+var processKeys = []processKey{
+	{SchemaKey{Name: "host", Type: "string", Description: "the hostname", AddedBy: "PrependHostname"},
+		func() bool { return getFlag(&PrependHostname) }},
+	{SchemaKey{Name: "pid", Type: "number", Description: "the process id", AddedBy: "PrependPID"},
+		func() bool { return getFlag(&PrependPID) }},
+	{SchemaKey{Name: "goroutine", Type: "number", Description: "the id of the goroutine", AddedBy: "PrependGoroutineID"},
+		func() bool { return getFlag(&PrependGoroutineID) }},
+	{SchemaKey{Name: "caller", Type: "string", Description: "the file:line of the log call", AddedBy: "PrependCaller"},
+		func() bool { return getFlag(&PrependCaller) }},
+	{SchemaKey{Name: "scope", Type: "string", Description: "the titles of the groups, joined by \" > \"", AddedBy: "Group"}, nil},
+	{SchemaKey{Name: "tags", Type: "string", Description: "the tags of the Clogger, joined by commas", AddedBy: "Tagged"}, nil},
+}
+
+// structuredKeys returns the core keys, with timeKey as the key of the time, and the keys that features add,
+// with Enabled set.
+func structuredKeys(timeKey string) []SchemaKey {
+	keys := coreKeys()
+	keys[0].Name = timestampKeyOrDefault(timeKey)
+	for _, k := range processKeys {
+		k.Enabled = k.enabled == nil || k.enabled()
+		keys = append(keys, k.SchemaKey)
+	}
+	return keys
+}
+
+// isReservedKey reports whether key is one of the keys of the entry itself.
+func isReservedKey(key string) bool {
+	switch key {
+	case "time", "level", "logger", "msg":
+		return true
+	}
+	for _, k := range processKeys {
+		if k.Name == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Keys returns the keys emitted by f.
+func (f *JSONFormatter) Keys() []SchemaKey {
+	return structuredKeys(f.TimestampKey)
+}
+
+// Keys returns the keys emitted by f.
+func (f *LogfmtFormatter) Keys() []SchemaKey {
+	return structuredKeys(f.TimestampKey)
+}
+
+// OutputSchema returns a JSON description of the keys that the structured output has with the current
+// configuration, for the consumers of the logs: the keys that every entry has, those that features add and
+// whether they are enabled, and the global fields with their types (see Schema). The fields passed to the log
+// calls and those of the Cloggers are not known in advance, so they are not described.
+func OutputSchema() []byte {
+	f := GetFormatter()
+	s := Schema{Formatter: fmt.Sprintf("%T", f), Keys: []SchemaKey{}, Fields: []SchemaKey{}, ClashPrefix: fieldClashPrefix}
+	if sf, ok := f.(SchemaFormatter); ok {
+		s.Structured = true
+		s.Keys = sf.Keys()
+	}
+	for _, field := range getGlobalFields().ordered() {
+		s.Fields = append(s.Fields, SchemaKey{
+			Name:    structuredKey(field.Key),
+			Type:    jsonType(field.Value),
+			AddedBy: "SetGlobalFields",
+			Enabled: true,
+		})
+	}
+	s.Fields = append(s.Fields, SchemaKey{
+		Name:        "fingerprint",
+		Type:        "string",
+		Description: "the fingerprint of the message, at the Warning level and above",
+		AddedBy:     "EnableFingerprinting",
+		Enabled:     fingerprinting.Load(),
+	})
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	enc.Encode(s)
+	return b.Bytes()
+}
+
+// jsonType returns the JSON type of v, as marshaled by the JSONFormatter.
+func jsonType(v interface{}) string {
+	switch marshalJSONValue(v)[0] {
+	case '"':
+		return "string"
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
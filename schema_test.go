@@ -0,0 +1,69 @@
+package clog
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decodeSchema returns the Schema described by OutputSchema.
+func decodeSchema(t *testing.T) Schema {
+	t.Helper()
+	var s Schema
+	if err := json.Unmarshal(OutputSchema(), &s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// TestOutputSchema checks that the schema follows the key names of the Formatter, the features that are enabled
+// and the global fields, and that the keys it describes are those of the output.
+func TestOutputSchema(t *testing.T) {
+	setupTest(t)
+	SetFormatter(&JSONFormatter{TimestampKey: "ts"})
+	SetPrependPID(true)
+	useGlobalFields(t, map[string]interface{}{"env": "prod", "level": 3})
+	s := decodeSchema(t)
+	if s.Formatter != "*clog.JSONFormatter" || !s.Structured || s.ClashPrefix != "fields." {
+		t.Errorf("got %+v", s)
+	}
+	var names, enabled []string
+	for _, k := range s.Keys {
+		names = append(names, k.Name)
+		if k.Enabled {
+			enabled = append(enabled, k.Name)
+		}
+	}
+	if want := []string{"ts", "level", "logger", "msg", "host", "pid", "goroutine", "caller", "scope", "tags"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got keys %q, want %q", names, want)
+	}
+	if want := []string{"ts", "level", "logger", "msg", "pid", "scope", "tags"}; !reflect.DeepEqual(enabled, want) {
+		t.Errorf("got enabled keys %q, want %q", enabled, want)
+	}
+	wantFields := []SchemaKey{
+		{Name: "env", Type: "string", AddedBy: "SetGlobalFields", Enabled: true},
+		{Name: "fields.level", Type: "number", AddedBy: "SetGlobalFields", Enabled: true},
+	}
+	if len(s.Fields) != 3 || !reflect.DeepEqual(s.Fields[:2], wantFields) || s.Fields[2].Name != "fingerprint" {
+		t.Errorf("got fields %+v, want %+v and the fingerprint", s.Fields, wantFields)
+	}
+
+	line := captureOutput(func() { Info("served") })
+	var keys []string
+	for _, part := range strings.Split(strings.Trim(line, "{}"), `,"`) {
+		keys = append(keys, strings.Trim(strings.SplitN(part, `":`, 2)[0], `"`))
+	}
+	if want := []string{"ts", "level", "logger", "msg", "pid", "env", "fields.level"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("the output %s has the keys %q, want %q", line, keys, want)
+	}
+}
+
+// TestOutputSchemaText checks that the keys of a Formatter that does not declare them are not described.
+func TestOutputSchemaText(t *testing.T) {
+	setupTest(t)
+	s := decodeSchema(t)
+	if s.Formatter != "*clog.TextFormatter" || s.Structured || len(s.Keys) != 0 {
+		t.Errorf("got %+v", s)
+	}
+}
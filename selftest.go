@@ -0,0 +1,90 @@
+package clog
+
+import (
+	"fmt"
+	"time"
+)
+
+/********************************************************************************
+* S E L F   T E S T
+*********************************************************************************/
+
+// DestinationStatus is the outcome of the self-test of a destination (see SelfTest).
+type DestinationStatus struct {
+	// Name describes the destination: "output", "syslog", the path of a file destination, or the type of the
+	// destination.
+	Name        string
+	Destination Destination
+	OK          bool
+	Latency     time.Duration
+	Err         error
+}
+
+// SelfTest writes a test entry, at LogLevelDebug and with the field self_test=true, to the output if
+// LogToStdOut is set, to the syslog if LogToSyslog is set, and to every destination (see AddDestination,
+// RouteTag and SetRouter), whatever the LogLevel and the routing rules, e.g. to check the configuration before
+// a service accepts traffic. The destinations that have a Flush() error method are flushed, so that asynchronous
+// writes are included. It returns the outcome for each of them, in that order; a failure is never logged, nor
+// does it stop the test of the next destinations.
+func SelfTest() []DestinationStatus {
+	cl := defaultCloggerForLevel(LogLevelDebug)
+	newTestEntry := func() *Entry {
+		e := cl.newEntry("clog self-test", Fields{{"self_test", true}})
+		e.Level = LogLevelDebug
+		return e
+	}
+
+	var statuses []DestinationStatus
+	if getFlag(&LogToStdOut) {
+		statuses = append(statuses, selfTest("output", nil, func() error {
+			e := newTestEntry()
+			return writeEntryLine(e, formatEntry(GetFormatter(), e))
+		}))
+	}
+	if getFlag(&LogToSyslog) {
+		statuses = append(statuses, selfTest("syslog", nil, func() error {
+			cl.lock.RLock()
+			defer cl.lock.RUnlock()
+			if cl.sysLogger == nil {
+				return fmt.Errorf("%s: the syslog could not be reached", PACKAGE_NAME)
+			}
+			return cl.sysLogger.Output(1, newTestEntry().Message+" self_test=true")
+		}))
+	}
+	for _, dest := range allDestinations() {
+		dest := dest
+		statuses = append(statuses, selfTest(selfTestName(dest), dest, func() error {
+			if err := dest.Write(newTestEntry()); err != nil {
+				return err
+			}
+			if f, ok := dest.(interface{ Flush() error }); ok {
+				return f.Flush()
+			}
+			return nil
+		}))
+	}
+	return statuses
+}
+
+// selfTest runs write, recovering a panic as an error, and returns its outcome.
+func selfTest(name string, dest Destination, write func() error) (status DestinationStatus) {
+	status = DestinationStatus{Name: name, Destination: dest}
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			status.Err = fmt.Errorf("%s: %s panicked: %v", PACKAGE_NAME, name, r)
+		}
+		status.Latency = time.Since(start)
+		status.OK = status.Err == nil
+	}()
+	status.Err = write()
+	return status
+}
+
+// selfTestName describes dest in a DestinationStatus.
+func selfTestName(dest Destination) string {
+	if d, ok := dest.(*WriterDestination); ok && d.file != nil {
+		return d.file.path
+	}
+	return fmt.Sprintf("%T", dest)
+}
@@ -0,0 +1,55 @@
+package clog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// panickingDestination is a Destination whose Write panics.
+type panickingDestination struct{}
+
+func (panickingDestination) Write(e *Entry) error { panic("broken destination") }
+
+func (panickingDestination) Close() error { return nil }
+
+// TestSelfTest checks that SelfTest writes a debug entry to the output and to every destination whatever the
+// LogLevel, and reports the failures of a destination without stopping the test of the next ones.
+func TestSelfTest(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetLogLevel(LogLevelError)
+	var healthy lockedBuffer
+	useDestinations(t,
+		&panickingDestination{},
+		NewDestination(&fullWriter{limit: 0}),
+		NewDestination(&healthy, WithFormatter(&JSONFormatter{})),
+	)
+
+	var statuses []DestinationStatus
+	lines := captureLines(func() { statuses = SelfTest() })
+	if len(lines) != 1 || lines[0] != "[DEBUG] clog self-test self_test=true" {
+		t.Errorf("output: got %q, want the test entry", lines)
+	}
+	if len(statuses) != 4 {
+		t.Fatalf("got %d statuses, want 4: %+v", len(statuses), statuses)
+	}
+	wantErrs := []string{"", "panicked: broken destination", "no space left on device", ""}
+	for i, s := range statuses {
+		if s.OK != (wantErrs[i] == "") || (s.Err == nil) != (wantErrs[i] == "") {
+			t.Errorf("status %d (%s): got OK %t and error %v", i, s.Name, s.OK, s.Err)
+		} else if s.Err != nil && !strings.Contains(s.Err.Error(), wantErrs[i]) {
+			t.Errorf("status %d (%s): got error %q, want %q", i, s.Name, s.Err, wantErrs[i])
+		}
+	}
+	if statuses[0].Name != "output" {
+		t.Errorf("got name %q for the output", statuses[0].Name)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(healthy.String()), &entry); err != nil {
+		t.Fatalf("destination: %v in %q", err, healthy.String())
+	}
+	if entry["msg"] != "clog self-test" || entry["self_test"] != true || entry["level"] != "debug" {
+		t.Errorf("destination: got %v", entry)
+	}
+}
@@ -0,0 +1,76 @@
+//go:build !windows && !js
+
+package clog
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// EnableSignalLevelControl lets the LogLevel of a running process be changed using signals: SIGUSR1 decreases
+// the LogLevel by one (more verbose), and SIGUSR2 increases it by one (less verbose). The LogLevel is kept
+// between LogLevelDebug and LogLevelCrit, and every change is logged using the "Notice" default clogger.
+// Calling the returned function stops listening to the signals.
+func EnableSignalLevelControl() (func(), error) {
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigs, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		for {
+			select {
+			case sig := <-sigs:
+				step := 1
+				if sig == syscall.SIGUSR1 {
+					step = -1
+				}
+				changeLogLevelBy(step, sig.String())
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			signal.Stop(sigs)
+			close(done)
+		})
+	}
+	return cancel, nil
+}
+
+// changeLogLevelBy changes the LogLevel by step, in the order of Levels, keeping it between MinLevel and
+// LogLevelCrit, and logs the change with the reason using the "Notice" default clogger.
+func changeLogLevelBy(step int, reason string) {
+	levels := Levels()
+	logLevelLock.Lock()
+	old := LogLevel
+	i := indexOfLevel(levels, old) + step
+	if i < 0 {
+		i = 0
+	}
+	if max := indexOfLevel(levels, LogLevelCrit); i > max {
+		i = max
+	}
+	level := levels[i]
+	LogLevel = level
+	logLevelLock.Unlock()
+
+	notifyLevelChange(old, level)
+	if level != old {
+		Noticef("log level changed from %s to %s (%s)", levelName(old), levelName(level), reason)
+	}
+}
+
+// indexOfLevel returns the index of level in levels, or 0 if it is not there.
+func indexOfLevel(levels []int, level int) int {
+	for i, l := range levels {
+		if l == level {
+			return i
+		}
+	}
+	return 0
+}
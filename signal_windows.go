@@ -0,0 +1,11 @@
+package clog
+
+import (
+	"fmt"
+)
+
+// EnableSignalLevelControl is not supported on Windows, which does not have the SIGUSR1 and SIGUSR2
+// signals. It does nothing and returns an error.
+func EnableSignalLevelControl() (func(), error) {
+	return func() {}, fmt.Errorf("%s: signal level control is not supported on windows", PACKAGE_NAME)
+}
@@ -0,0 +1,356 @@
+package clog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/********************************************************************************
+* D I S K   S P O O L
+*********************************************************************************/
+
+// segmentSpool stores the records that a destination could not deliver, e.g. the lines of a WriterDestination or
+// the entries of a WebhookDestination, each encoded as a line of JSON, in segment files named spool-SEQ.jsonl
+// after their sequence number. Records are appended to the newest segment until it reaches segmentBytes, so that
+// a record is on the disk as soon as its delivery has failed.
+type segmentSpool struct {
+	dir          string
+	maxBytes     int64
+	segmentBytes int64
+
+	// replayLock keeps the replays in order. It is not held while appending, which uses lock.
+	replayLock sync.Mutex
+
+	lock sync.Mutex
+	// segments are the segments, oldest first, and replayed the number of records of the oldest one that have
+	// already been delivered.
+	segments []spoolSegment
+	replayed int
+	// next is the sequence number of the next segment. The segments before firstSeq were left by a previous
+	// process, and are never appended to, since their last record may be partial.
+	next     uint64
+	firstSeq uint64
+}
+
+// spoolSegment is a segment file of a segmentSpool.
+type spoolSegment struct {
+	path    string
+	seq     uint64
+	size    int64
+	records int
+}
+
+// minSpoolSegmentBytes is the minimum size of the segments of a segmentSpool, which are an eighth of its size.
+const minSpoolSegmentBytes = 4 << 10
+
+// SpoolOption is the option returned by WithDiskSpool. It is both a DestOption and a WebhookOption.
+type SpoolOption struct {
+	dir      string
+	maxBytes int64
+}
+
+// WithDiskSpool makes the destination store what it cannot deliver, e.g. while a network collector is down, in
+// segment files in dir, rather than losing it: the lines that a WriterDestination cannot write, or the entries of
+// the batches that a WebhookDestination cannot post after all the attempts. The spooled records are delivered
+// again, in order, before the next ones once the destination works again, and those spooled before a restart are
+// delivered the same way, so a record may be delivered twice if the process stops in the middle of delivering
+// them. A WriterDestination tries at most once per probe interval (see WithFallbackPolicy) while its writer keeps
+// failing, and a spooled line is not written to its fallback (see WithFallback). If the segments would take more
+// than maxBytes, the oldest ones are deleted, and their records counted as dropped (see Stats and Dropped).
+// Records that cannot be read, e.g. the last one written before a crash, are skipped. If dir cannot be used, the
+// destination has no spool, which is reported to the internal logger (see SetInternalLogger).
+func WithDiskSpool(dir string, maxBytes int64) SpoolOption {
+	return SpoolOption{dir: dir, maxBytes: maxBytes}
+}
+
+// applyToDestination sets the spool of the WriterDestination.
+func (o SpoolOption) applyToDestination(d *WriterDestination) {
+	d.spool = o.open()
+}
+
+// applyToWebhook sets the spool of the WebhookDestination.
+func (o SpoolOption) applyToWebhook(d *WebhookDestination) {
+	d.spool = o.open()
+}
+
+// open opens the spool, or returns nil if it cannot be.
+func (o SpoolOption) open() *segmentSpool {
+	s, err := openSegmentSpool(o.dir, o.maxBytes)
+	if err != nil {
+		internalf(LogLevelError, "destination has no disk spool: %v", err)
+		return nil
+	}
+	return s
+}
+
+// openSegmentSpool opens the spool in dir, with the segments left by a previous process.
+func openSegmentSpool(dir string, maxBytes int64) (*segmentSpool, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("%s: cannot create the spool directory: %v", PACKAGE_NAME, err)
+	}
+	s := &segmentSpool{dir: dir, maxBytes: maxBytes, segmentBytes: maxBytes / 8}
+	if s.segmentBytes < minSpoolSegmentBytes {
+		s.segmentBytes = minSpoolSegmentBytes
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "spool-*.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: cannot list the spool segments: %v", PACKAGE_NAME, err)
+	}
+	for _, p := range paths {
+		seq, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(filepath.Base(p), "spool-"), ".jsonl"), 10, 64)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		s.segments = append(s.segments, spoolSegment{path: p, seq: seq, size: int64(len(data)), records: bytes.Count(data, []byte("\n"))})
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i].seq < s.segments[j].seq })
+	if n := len(s.segments); n > 0 {
+		s.next = s.segments[n-1].seq + 1
+	}
+	s.firstSeq = s.next
+	return s, nil
+}
+
+// pending reports whether s has records to deliver.
+func (s *segmentSpool) pending() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.segments) > 0
+}
+
+// append stores records, which must be JSON values, in the newest segment, or in a new one if it is full, and
+// deletes the oldest segments if needed to stay under the size cap. It returns the number of records dropped to
+// make room, and an error if records could not be stored.
+func (s *segmentSpool) append(records ...[]byte) (int, error) {
+	var buf bytes.Buffer
+	for _, record := range records {
+		buf.Write(record)
+		buf.WriteByte('\n')
+	}
+	if int64(buf.Len()) > s.maxBytes {
+		return 0, fmt.Errorf("%s: the records are larger than the spool", PACKAGE_NAME)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if n := len(s.segments); n == 0 || s.segments[n-1].seq < s.firstSeq ||
+		(s.segments[n-1].size > 0 && s.segments[n-1].size+int64(buf.Len()) > s.segmentBytes) {
+		path := filepath.Join(s.dir, fmt.Sprintf("spool-%020d.jsonl", s.next))
+		s.segments = append(s.segments, spoolSegment{path: path, seq: s.next})
+		s.next++
+	}
+	seg := &s.segments[len(s.segments)-1]
+	f, err := os.OpenFile(seg.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	_, err = f.Write(buf.Bytes())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return 0, err
+	}
+	seg.size += int64(buf.Len())
+	seg.records += len(records)
+
+	var total int64
+	for _, seg := range s.segments {
+		total += seg.size
+	}
+	dropped := 0
+	for len(s.segments) > 1 && total > s.maxBytes {
+		os.Remove(s.segments[0].path)
+		total -= s.segments[0].size
+		dropped += s.segments[0].records - s.replayed
+		s.segments, s.replayed = s.segments[1:], 0
+	}
+	return dropped, nil
+}
+
+// replay delivers the spooled records using deliver, oldest first and at most max at a time, deleting each
+// segment once all its records are delivered. It stops at the first records that cannot be delivered, and returns
+// the error of deliver.
+func (s *segmentSpool) replay(max int, deliver func(records [][]byte) error) error {
+	s.replayLock.Lock()
+	defer s.replayLock.Unlock()
+	for {
+		s.lock.Lock()
+		if len(s.segments) == 0 {
+			s.lock.Unlock()
+			return nil
+		}
+		seg, from := s.segments[0], s.replayed
+		s.lock.Unlock()
+
+		records, err := readSpoolSegment(seg.path)
+		if err != nil && !os.IsNotExist(err) {
+			// the records before the corrupted one are still delivered
+			internalf(LogLevelError, "destination skipped the corrupted end of the spool segment %s: %v", seg.path, err)
+		}
+		for from < len(records) {
+			to := from + max
+			if to > len(records) {
+				to = len(records)
+			}
+			if err := deliver(records[from:to]); err != nil {
+				return err
+			}
+			from = to
+			s.lock.Lock()
+			if len(s.segments) > 0 && s.segments[0].seq == seg.seq {
+				s.replayed = from
+			}
+			s.lock.Unlock()
+		}
+
+		s.lock.Lock()
+		if len(s.segments) > 0 && s.segments[0].seq == seg.seq {
+			if err == nil && s.segments[0].records > from {
+				// records were appended to the segment while it was being delivered
+				s.lock.Unlock()
+				continue
+			}
+			os.Remove(seg.path)
+			s.segments, s.replayed = s.segments[1:], 0
+		}
+		s.lock.Unlock()
+	}
+}
+
+// readSpoolSegment returns the records stored in the segment at path, up to the first one that is not valid JSON,
+// along with the error that stopped the reading, if any.
+func readSpoolSegment(path string) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var records [][]byte
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if !json.Valid(scanner.Bytes()) {
+			return records, fmt.Errorf("invalid record %.20q", scanner.Text())
+		}
+		records = append(records, append([]byte{}, scanner.Bytes()...))
+	}
+	return records, scanner.Err()
+}
+
+// spoolBatch stores the entries of batch in the spool of d, if it has one, and reports whether it did.
+func (d *WebhookDestination) spoolBatch(batch []WebhookEntry) bool {
+	if d.spool == nil {
+		return false
+	}
+	records := make([][]byte, 0, len(batch))
+	for _, we := range batch {
+		record, err := json.Marshal(we)
+		if err != nil {
+			internalf(LogLevelError, "webhook destination could not spool %d entries: %v", len(batch), err)
+			return false
+		}
+		records = append(records, record)
+	}
+	dropped, err := d.spool.append(records...)
+	if dropped > 0 {
+		d.dropped.Add(uint64(dropped))
+		internalf(LogLevelError, "webhook destination spool is full, dropped its %d oldest entries", dropped)
+	}
+	if err != nil {
+		internalf(LogLevelError, "webhook destination could not spool %d entries: %v", len(batch), err)
+		return false
+	}
+	return true
+}
+
+// replaySpool posts the spooled entries of d in order, in batches of at most maxBatch entries, and stops at the
+// first batch that cannot be posted.
+func (d *WebhookDestination) replaySpool() {
+	if d.spool == nil {
+		return
+	}
+	d.spool.replay(d.maxBatch, func(records [][]byte) error {
+		batch := make([]WebhookEntry, 0, len(records))
+		for _, record := range records {
+			var we WebhookEntry
+			if err := json.Unmarshal(record, &we); err == nil {
+				batch = append(batch, we)
+			}
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		err := d.send(batch)
+		var payloadErr webhookPayloadError
+		if errors.As(err, &payloadErr) {
+			// the batch would never be posted, so it is not kept in the spool
+			d.dropped.Add(uint64(len(batch)))
+			internalf(LogLevelError, "webhook destination dropped %d spooled entries, as their payload could not be built: %v", len(batch), payloadErr.err)
+			return nil
+		}
+		return err
+	})
+}
+
+// writeSpooled writes line to the writer of d once the spooled lines have been written back, or appends it to the
+// spool if they cannot be or if its write fails, so that the lines are written in order. It returns an error only
+// if line is lost. d.lock must be held.
+func (d *WriterDestination) writeSpooled(line string) error {
+	if d.spool.pending() {
+		if d.consecutive > 0 && now().Sub(d.lastProbe) < d.probeInterval {
+			return d.spoolLine(line)
+		}
+		d.lastProbe = now()
+		if err := d.spool.replay(1, d.writeSpooledLine); err != nil {
+			d.countFailure()
+			return d.spoolLine(line)
+		}
+	}
+	if err := d.writePrimary(line); err != nil {
+		d.countFailure()
+		d.lastProbe = now()
+		return d.spoolLine(line)
+	}
+	d.consecutive = 0
+	return nil
+}
+
+// writeSpooledLine writes the line of a record of the spool of d to its writer. A record that is not a line is
+// skipped. d.lock must be held.
+func (d *WriterDestination) writeSpooledLine(records [][]byte) error {
+	var line string
+	if err := json.Unmarshal(records[0], &line); err != nil {
+		return nil
+	}
+	return d.writePrimary(line)
+}
+
+// spoolLine appends line to the spool of d. d.lock must be held.
+func (d *WriterDestination) spoolLine(line string) error {
+	record, err := json.Marshal(line)
+	if err != nil {
+		return fmt.Errorf("%s: could not spool a line: %v", PACKAGE_NAME, err)
+	}
+	dropped, err := d.spool.append(record)
+	if dropped > 0 {
+		d.spoolDropped += uint64(dropped)
+		internalf(LogLevelError, "destination spool is full, dropped its %d oldest lines", dropped)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: could not spool a line: %v", PACKAGE_NAME, err)
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package clog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// downWriter fails every write while down is set, and records the lines written otherwise.
+type downWriter struct {
+	down  bool
+	lines []string
+}
+
+func (w *downWriter) Write(p []byte) (int, error) {
+	if w.down {
+		return 0, errors.New("connection refused")
+	}
+	w.lines = append(w.lines, strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+// writeMessages writes an entry with each of msgs to d, logged by the "Info" default clogger.
+func writeMessages(t *testing.T, d Destination, msgs ...string) {
+	t.Helper()
+	cl := GetCloggerByName("Info")
+	for _, msg := range msgs {
+		if err := d.Write(cl.newEntry(msg, nil)); err != nil {
+			t.Fatalf("writing %q: %v", msg, err)
+		}
+	}
+}
+
+// spoolFiles returns the names of the files in dir.
+func spoolFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestWriterSpoolKillAndRestart(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	dir := t.TempDir()
+	// the first process cannot reach its collector, and is killed without closing its destination
+	down := &downWriter{down: true}
+	first := NewDestination(down, WithDiskSpool(dir, 1<<20))
+	writeMessages(t, first, "one", "two", "three")
+	if len(spoolFiles(t, dir)) == 0 {
+		t.Fatal("nothing was spooled")
+	}
+
+	// the next process writes the spooled lines back, in order, before its own
+	up := &downWriter{}
+	second := NewDestination(up, WithDiskSpool(dir, 1<<20))
+	writeMessages(t, second, "four")
+	want := []string{"[INFO] one", "[INFO] two", "[INFO] three", "[INFO] four"}
+	if strings.Join(up.lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", up.lines, want)
+	}
+	if files := spoolFiles(t, dir); len(files) != 0 {
+		t.Errorf("the spool still has %v", files)
+	}
+}
+
+func TestWriterSpoolRecovers(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	w := &downWriter{down: true}
+	d := NewDestination(w, WithDiskSpool(t.TempDir(), 1<<20), WithFallbackPolicy(3, time.Minute))
+	writeMessages(t, d, "one")
+	w.down = false
+	// the writer is only probed once per probe interval
+	writeMessages(t, d, "two")
+	if len(w.lines) != 0 {
+		t.Fatalf("got %q before the probe interval elapsed", w.lines)
+	}
+	SetClock(func() time.Time { return testTime.Add(time.Minute) })
+	writeMessages(t, d, "three")
+	want := []string{"[INFO] one", "[INFO] two", "[INFO] three"}
+	if strings.Join(w.lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", w.lines, want)
+	}
+	if stats := d.Stats(); stats.Failures != 1 || stats.ConsecutiveFailures != 0 {
+		t.Errorf("got %+v, want 1 failure and no consecutive ones", stats)
+	}
+}
+
+func TestWriterSpoolSkipsCorruptedLines(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	events := recordInternal(t)
+	dir := t.TempDir()
+	writeMessages(t, NewDestination(&downWriter{down: true}, WithDiskSpool(dir, 1<<20)), "one", "two")
+	// a crash in the middle of an append leaves a partial line
+	files := spoolFiles(t, dir)
+	f, err := os.OpenFile(filepath.Join(dir, files[len(files)-1]), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`"[INFO] thr`)
+	f.Close()
+
+	up := &downWriter{}
+	writeMessages(t, NewDestination(up, WithDiskSpool(dir, 1<<20)), "four")
+	want := []string{"[INFO] one", "[INFO] two", "[INFO] four"}
+	if strings.Join(up.lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", up.lines, want)
+	}
+	if !events.contains("corrupted end of the spool segment") {
+		t.Error("the corrupted segment was not reported")
+	}
+}
+
+func TestWriterSpoolSizeCap(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	recordInternal(t)
+	dir := t.TempDir()
+	const maxBytes = 4 * minSpoolSegmentBytes
+	d := NewDestination(&downWriter{down: true}, WithDiskSpool(dir, maxBytes))
+	msg := strings.Repeat("x", 1000)
+	var msgs []string
+	for i := 0; i < 40; i++ {
+		msgs = append(msgs, fmt.Sprintf("%02d %s", i, msg))
+	}
+	writeMessages(t, d, msgs...)
+	dropped := d.Stats().SpoolDropped
+	if dropped == 0 {
+		t.Fatal("no line was dropped")
+	}
+	var total int64
+	for _, name := range spoolFiles(t, dir) {
+		info, _ := os.Stat(filepath.Join(dir, name))
+		total += info.Size()
+	}
+	if total > maxBytes {
+		t.Errorf("the spool takes %d bytes, more than %d", total, maxBytes)
+	}
+
+	up := &downWriter{}
+	NewDestination(up, WithDiskSpool(dir, maxBytes)).writeSpooled("last")
+	if kept := uint64(len(up.lines) - 1); kept+dropped != 40 {
+		t.Errorf("%d lines written back and %d dropped, want 40 in total", kept, dropped)
+	}
+	if !strings.HasPrefix(up.lines[0], fmt.Sprintf("[INFO] %02d ", dropped)) {
+		t.Errorf("the first line written back is %.10q, want the oldest that was kept", up.lines[0])
+	}
+}
+
+// webhookCollector is a webhook endpoint that fails while down is set, and records the messages posted otherwise.
+type webhookCollector struct {
+	lock     sync.Mutex
+	down     bool
+	messages []string
+}
+
+func (c *webhookCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.down {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+		return
+	}
+	var batch []WebhookEntry
+	json.NewDecoder(r.Body).Decode(&batch)
+	for _, we := range batch {
+		c.messages = append(c.messages, we.Message)
+	}
+}
+
+func (c *webhookCollector) setDown(down bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.down = down
+}
+
+func (c *webhookCollector) received() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]string{}, c.messages...)
+}
+
+func TestWebhookSpoolKillAndRestart(t *testing.T) {
+	setupTest(t)
+	recordInternal(t)
+	backoff := webhookBackoff
+	webhookBackoff = time.Millisecond
+	t.Cleanup(func() { webhookBackoff = backoff })
+	collector := &webhookCollector{down: true}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+	dir := t.TempDir()
+
+	first := NewWebhookDestination(server.URL, LogLevelDebug, time.Hour, 10, WithDiskSpool(dir, 1<<20))
+	writeMessages(t, first, "one", "two")
+	first.Close()
+	// a segment left corrupted by a crash is skipped
+	os.WriteFile(filepath.Join(dir, fmt.Sprintf("spool-%020d.jsonl", 99)), []byte("{not json\n"), 0o644)
+
+	collector.setDown(false)
+	second := NewWebhookDestination(server.URL, LogLevelDebug, time.Hour, 10, WithDiskSpool(dir, 1<<20))
+	deadline := time.Now().Add(5 * time.Second)
+	for len(collector.received()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	writeMessages(t, second, "three")
+	second.Close()
+	want := []string{"one", "two", "three"}
+	if got := collector.received(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if files := spoolFiles(t, dir); len(files) != 0 {
+		t.Errorf("the spool still has %v", files)
+	}
+}
+
+// TestSpoolDirectoryError checks that both kinds of destinations report a spool directory that cannot be created
+// the same way, and work without a spool.
+func TestSpoolDirectoryError(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(file, "spool")
+
+	events := recordInternal(t)
+	w := &downWriter{}
+	writeMessages(t, NewDestination(w, WithDiskSpool(dir, 1<<20)), "one")
+	if want := []string{"[INFO] one"}; strings.Join(w.lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", w.lines, want)
+	}
+	webhook := NewWebhookDestination("http://127.0.0.1:0", LogLevelDebug, time.Hour, 10, WithDiskSpool(dir, 1<<20))
+	webhook.Close()
+
+	events.lock.Lock()
+	defer events.lock.Unlock()
+	var reported []string
+	for _, msg := range events.messages {
+		if strings.HasPrefix(msg, "destination has no disk spool: Clog: cannot create the spool directory") {
+			reported = append(reported, msg)
+		}
+	}
+	if len(reported) != 2 {
+		t.Errorf("got %q, want the error reported once per destination", events.messages)
+	}
+}
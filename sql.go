@@ -0,0 +1,113 @@
+package clog
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// SQL log modes. They have the same values as gorm's logger.LogLevel constants, so a gorm log
+// level can be converted with a plain int conversion.
+const (
+	SQLSilent = iota + 1
+	SQLError
+	SQLWarn
+	SQLInfo
+)
+
+// DefaultSQLSlowThreshold is the SlowThreshold of the SQLLogger returned by NewSQLLogger.
+const DefaultSQLSlowThreshold = 200 * time.Millisecond
+
+// SQLLogger logs ORM and database/sql queries using the default cloggers. It exposes the methods
+// of gorm's logger.Interface, but with plain values instead of gorm types so that the clog
+// package does not depend on gorm. Glue code satisfying gorm's interface is a few lines:
+//
+//	type gormLogger struct{ *clog.SQLLogger }
+//
+//	func (g gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+//		return gormLogger{g.SQLLogger.LogMode(int(level))}
+//	}
+//
+// Trace can also be called directly after running a query through database/sql.
+type SQLLogger struct {
+	// Mode is one of the SQL log modes (SQLSilent, SQLError, SQLWarn, SQLInfo).
+	Mode int
+	// SlowThreshold is the query duration above which a query is logged as a Warning. Slow
+	// queries are not reported if it is zero.
+	SlowThreshold time.Duration
+	// NotFoundError is the error returned by the ORM when no record is found, e.g. gorm.ErrRecordNotFound.
+	// If IgnoreNotFound is set to true, Trace does not log errors that match it.
+	NotFoundError error
+	// IgnoreNotFound determines whether errors matching NotFoundError are ignored by Trace.
+	IgnoreNotFound bool
+}
+
+// NewSQLLogger creates a new SQLLogger that logs at the SQLWarn mode, and reports queries slower
+// than DefaultSQLSlowThreshold.
+func NewSQLLogger() *SQLLogger {
+	return &SQLLogger{
+		Mode:          SQLWarn,
+		SlowThreshold: DefaultSQLSlowThreshold,
+	}
+}
+
+// LogMode returns a copy of the SQLLogger that logs at the provided mode.
+func (s *SQLLogger) LogMode(mode int) *SQLLogger {
+	c := *s
+	c.Mode = mode
+	return &c
+}
+
+// Info formats msg with data and logs it using the default clogger of the level that SQLInfo maps to (see
+// LevelSourceGorm), "Info" by default, if the mode is SQLInfo.
+func (s *SQLLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if s.Mode >= SQLInfo {
+		mappedClogger(LevelSourceGorm, SQLInfo).Printf(msg, data...)
+	}
+}
+
+// Warn formats msg with data and logs it using the default clogger of the level that SQLWarn maps to,
+// "Warning" by default, if the mode is SQLWarn or higher.
+func (s *SQLLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if s.Mode >= SQLWarn {
+		mappedClogger(LevelSourceGorm, SQLWarn).Printf(msg, data...)
+	}
+}
+
+// Error formats msg with data and logs it using the default clogger of the level that SQLError maps to,
+// "Error" by default, if the mode is SQLError or higher.
+func (s *SQLLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if s.Mode >= SQLError {
+		mappedClogger(LevelSourceGorm, SQLError).Printf(msg, data...)
+	}
+}
+
+// Trace logs a query that started at begin. fc provides the SQL statement and the number of rows
+// affected (-1 if unknown), and is only called if the query is going to be logged. The query is
+// logged like Error if err is not nil, like Warn if it took longer than SlowThreshold, and like Info
+// otherwise.
+func (s *SQLLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if s.Mode <= SQLSilent {
+		return
+	}
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && s.Mode >= SQLError && !(s.IgnoreNotFound && s.NotFoundError != nil && errors.Is(err, s.NotFoundError)):
+		sql, rows := fc()
+		mappedClogger(LevelSourceGorm, SQLError).PrintFields(sql, "elapsed", elapsed, "rows", formatRows(rows), "error", err)
+	case s.SlowThreshold != 0 && elapsed > s.SlowThreshold && s.Mode >= SQLWarn:
+		sql, rows := fc()
+		mappedClogger(LevelSourceGorm, SQLWarn).PrintFields(sql, "elapsed", elapsed, "rows", formatRows(rows), "slow_threshold", s.SlowThreshold)
+	case s.Mode >= SQLInfo:
+		sql, rows := fc()
+		mappedClogger(LevelSourceGorm, SQLInfo).PrintFields(sql, "elapsed", elapsed, "rows", formatRows(rows))
+	}
+}
+
+// formatRows formats the number of rows affected by a query, using "-" when it is unknown.
+func formatRows(rows int64) interface{} {
+	if rows < 0 {
+		return "-"
+	}
+	return rows
+}
@@ -0,0 +1,97 @@
+package clog
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+/********************************************************************************
+* S T A T S
+*********************************************************************************/
+
+// Stats holds counters about the internal behavior of the package.
+type Stats struct {
+	// FormatPanics is the number of panics recovered while formatting messages, e.g. from a String, Error or
+	// MarshalJSON method of a logged value, a Formatter or a Hook.
+	FormatPanics uint64
+	// DestinationFailures is the number of failed writes of the destinations created with NewDestination, and
+	// DestinationsOnFallback the number of them that currently write to their fallback (see WithFallback).
+	DestinationFailures    uint64
+	DestinationsOnFallback int64
+	// DestinationTimeouts is the number of writes of the destinations that did not complete within their write
+	// deadline (see WithWriteDeadline).
+	DestinationTimeouts uint64
+	// FailedExpectations and FailedAsserts are the number of failed checks of Expect and Expectf, and of Assert
+	// and Assertf, including those that were not logged since their call site had already failed.
+	FailedExpectations uint64
+	FailedAsserts      uint64
+}
+
+var formatPanics uint64
+
+// GetStats returns the current values of the internal counters.
+func GetStats() Stats {
+	return Stats{
+		FormatPanics:           atomic.LoadUint64(&formatPanics),
+		DestinationFailures:    atomic.LoadUint64(&destinationFailures),
+		DestinationsOnFallback: atomic.LoadInt64(&destinationsOnFallback),
+		DestinationTimeouts:    atomic.LoadUint64(&destinationTimeouts),
+		FailedExpectations:     atomic.LoadUint64(&failedExpectations),
+		FailedAsserts:          atomic.LoadUint64(&failedAsserts),
+	}
+}
+
+// panicMarker is the text that fmt substitutes for a value whose String or Error method panicked.
+const panicMarker = "(PANIC="
+
+// sprintf formats the message like fmt.Sprintf. fmt already recovers from panics in the String and Error
+// methods of the args, replacing the value with a %!v(PANIC=String method: ...) placeholder, but sprintf
+// counts them.
+func sprintf(formatString string, args ...interface{}) string {
+	msg := fmt.Sprintf(formatString, args...)
+	countFormatPanics(msg)
+	if getFlag(&WarnOnFormatErrors) && hasFormatError(msg) {
+		warnFormatError(formatString)
+	}
+	return msg
+}
+
+// sprint formats v like fmt.Sprint, counting recovered panics like sprintf.
+func sprint(v interface{}) string {
+	s := fmt.Sprint(v)
+	countFormatPanics(s)
+	return s
+}
+
+// sprintArgs formats v like fmt.Sprint. A single string is returned as is.
+func sprintArgs(v []interface{}) string {
+	if len(v) == 1 {
+		if s, ok := v[0].(string); ok {
+			return s
+		}
+	}
+	s := fmt.Sprint(v...)
+	countFormatPanics(s)
+	return s
+}
+
+// sprintlnArgs formats v like fmt.Sprintln, without the trailing newline.
+func sprintlnArgs(v []interface{}) string {
+	s := fmt.Sprintln(v...)
+	countFormatPanics(s)
+	return s[:len(s)-1]
+}
+
+func countFormatPanics(s string) {
+	if strings.Contains(s, panicMarker) {
+		atomic.AddUint64(&formatPanics, 1)
+	}
+}
+
+// recoveredPanic returns the placeholder for a panic recovered from the named method, e.g.
+// "!PANIC(Format method): runtime error: invalid memory address or nil pointer dereference", and counts it.
+func recoveredPanic(method string, r interface{}) string {
+	atomic.AddUint64(&formatPanics, 1)
+	return fmt.Sprintf("!PANIC(%s method): %v", method, r)
+}
@@ -0,0 +1,105 @@
+package clog
+
+import (
+	"strings"
+	"testing"
+)
+
+// panickyStringer is a half-initialized value whose String method panics.
+type panickyStringer struct{}
+
+func (panickyStringer) String() string {
+	panic("half-initialized")
+}
+
+// panickyMarshaler is a value whose MarshalJSON method panics.
+type panickyMarshaler struct{}
+
+func (panickyMarshaler) MarshalJSON() ([]byte, error) {
+	panic("half-initialized")
+}
+
+// panickyFormatter is a Formatter that panics.
+type panickyFormatter struct{}
+
+func (panickyFormatter) Format(e *Entry) string {
+	panic("broken formatter")
+}
+
+// formatPanicsDuring returns the number of format panics counted while fn runs.
+func formatPanicsDuring(fn func()) uint64 {
+	before := GetStats().FormatPanics
+	fn()
+	return GetStats().FormatPanics - before
+}
+
+func TestPanickingStringer(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func()
+		want string
+	}{
+		{"Infof", func() { Infof("state: %v", panickyStringer{}) },
+			"[INFO] state: %!v(PANIC=String method: half-initialized)\n"},
+		{"Info", func() { GetCloggerByName("Info").Println("state:", panickyStringer{}) },
+			"[INFO] state: %!v(PANIC=String method: half-initialized)\n"},
+		{"field", func() { GetCloggerByName("Info").PrintFields("state", "value", panickyStringer{}) },
+			"[INFO] state value=\"%!v(PANIC=String method: half-initialized)\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			SetPrependTimestamp(false)
+			var got string
+			if n := formatPanicsDuring(func() { got = CaptureOutput(tt.log) }); n != 1 {
+				t.Errorf("counted %d format panics, want 1", n)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPanickingMarshaler(t *testing.T) {
+	setupTest(t)
+	SetFormatter(&JSONFormatter{})
+	var got string
+	if n := formatPanicsDuring(func() {
+		got = CaptureOutput(func() { GetCloggerByName("Info").PrintFields("state", "value", panickyMarshaler{}) })
+	}); n != 1 {
+		t.Errorf("counted %d format panics, want 1", n)
+	}
+	if want := `"value":"!PANIC(MarshalJSON method): half-initialized"`; !strings.Contains(got, want) {
+		t.Errorf("got %q, want it to contain %q", got, want)
+	}
+}
+
+func TestPanickingFormatter(t *testing.T) {
+	setupTest(t)
+	events := recordInternal(t)
+	SetFormatter(panickyFormatter{})
+	var got string
+	if n := formatPanicsDuring(func() { got = CaptureOutput(func() { Info("request served") }) }); n != 1 {
+		t.Errorf("counted %d format panics, want 1", n)
+	}
+	if want := "!PANIC(Format method): broken formatter request served\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if !events.contains("panicked: broken formatter") {
+		t.Errorf("the panic was not reported to the internal logger: %q", events.messages)
+	}
+}
+
+func TestPanickingHook(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	addTestHook(t, func(e *Entry) { panic("broken hook") })
+	var got string
+	if n := formatPanicsDuring(func() { got = CaptureOutput(func() { Info("request served") }) }); n != 1 {
+		t.Errorf("counted %d format panics, want 1", n)
+	}
+	if want := "[INFO] request served hook_panic=\"!PANIC(Fire method): broken hook\"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
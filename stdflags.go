@@ -0,0 +1,53 @@
+package clog
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// SetStdFlags configures clog like the flags of the standard library logger, e.g. log.LstdFlags|log.Lshortfile,
+// so that a migration can start by replacing log.SetFlags with clog.SetStdFlags:
+//
+//	log.Ldate, log.Ltime   PrependTimestamp, and TimestampFormat with the date, the time or both
+//	log.Lmicroseconds      TimestampFormat with microseconds, which implies log.Ltime
+//	log.Lshortfile         PrependCaller, with the name of the file
+//	log.Llongfile          PrependCaller and FullCallerPath; log.Lshortfile takes precedence
+//	log.LUTC               UTCTimestamps
+//
+// The flags that are not set turn the corresponding behaviors off, e.g. SetStdFlags(0) removes the timestamps.
+// log.Lmsgprefix, and the bits that are not stdlib flags, have no equivalent: SetStdFlags applies the other flags,
+// and returns an error listing them.
+func SetStdFlags(flags int) error {
+	var unsupported []string
+	if flags&log.Lmsgprefix != 0 {
+		unsupported = append(unsupported, "Lmsgprefix")
+	}
+	known := log.Ldate | log.Ltime | log.Lmicroseconds | log.Llongfile | log.Lshortfile | log.LUTC | log.Lmsgprefix
+	if rest := flags &^ known; rest != 0 {
+		unsupported = append(unsupported, fmt.Sprintf("unknown bits %#x", rest))
+	}
+
+	var layout []string
+	if flags&log.Ldate != 0 {
+		layout = append(layout, "2006/01/02")
+	}
+	switch {
+	case flags&log.Lmicroseconds != 0:
+		layout = append(layout, "15:04:05.000000")
+	case flags&log.Ltime != 0:
+		layout = append(layout, "15:04:05")
+	}
+	if len(layout) > 0 {
+		TimestampFormat = strings.Join(layout, " ")
+	}
+	SetPrependTimestamp(len(layout) > 0)
+	SetPrependCaller(flags&(log.Lshortfile|log.Llongfile) != 0)
+	SetFullCallerPath(flags&log.Lshortfile == 0 && flags&log.Llongfile != 0)
+	SetUTCTimestamps(flags&log.LUTC != 0)
+
+	if len(unsupported) > 0 {
+		return fmt.Errorf("%s: the log flags %s have no equivalent", PACKAGE_NAME, strings.Join(unsupported, ", "))
+	}
+	return nil
+}
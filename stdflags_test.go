@@ -0,0 +1,75 @@
+package clog
+
+import (
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestSetStdFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		flags     int
+		timestamp string // the TimestampFormat, or "" if PrependTimestamp is false
+		caller    bool
+		fullPath  bool
+		utc       bool
+		err       string
+	}{
+		{"none", 0, "", false, false, false, ""},
+		{"Ldate", log.Ldate, "2006/01/02", false, false, false, ""},
+		{"Ltime", log.Ltime, "15:04:05", false, false, false, ""},
+		{"Lmicroseconds", log.Lmicroseconds, "15:04:05.000000", false, false, false, ""},
+		{"Llongfile", log.Llongfile, "", true, true, false, ""},
+		{"Lshortfile", log.Lshortfile, "", true, false, false, ""},
+		{"LUTC", log.LUTC, "", false, false, true, ""},
+		{"Lmsgprefix", log.Lmsgprefix, "", false, false, false, "Lmsgprefix"},
+		{"LstdFlags", log.LstdFlags, "2006/01/02 15:04:05", false, false, false, ""},
+		{"Ldate|Lmicroseconds", log.Ldate | log.Lmicroseconds, "2006/01/02 15:04:05.000000", false, false, false, ""},
+		{"Ltime|Lmicroseconds", log.Ltime | log.Lmicroseconds, "15:04:05.000000", false, false, false, ""},
+		{"LstdFlags|Lmicroseconds", log.LstdFlags | log.Lmicroseconds, "2006/01/02 15:04:05.000000", false, false, false, ""},
+		{"LstdFlags|Lshortfile", log.LstdFlags | log.Lshortfile, "2006/01/02 15:04:05", true, false, false, ""},
+		{"LstdFlags|Llongfile", log.LstdFlags | log.Llongfile, "2006/01/02 15:04:05", true, true, false, ""},
+		{"Llongfile|Lshortfile", log.Llongfile | log.Lshortfile, "", true, false, false, ""},
+		{"LstdFlags|LUTC", log.LstdFlags | log.LUTC, "2006/01/02 15:04:05", false, false, true, ""},
+		{"Ltime|LUTC|Lshortfile", log.Ltime | log.LUTC | log.Lshortfile, "15:04:05", true, false, true, ""},
+		{"all", log.Ldate | log.Ltime | log.Lmicroseconds | log.Llongfile | log.Lshortfile | log.LUTC, "2006/01/02 15:04:05.000000", true, false, true, ""},
+		{"LstdFlags|Lmsgprefix", log.LstdFlags | log.Lmsgprefix, "2006/01/02 15:04:05", false, false, false, "Lmsgprefix"},
+		{"unknown bits", log.Ltime | 1<<10, "15:04:05", false, false, false, "unknown bits 0x400"},
+		{"Lmsgprefix and unknown bits", log.Lmsgprefix | 1<<10, "", false, false, false, "Lmsgprefix, unknown bits 0x400"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			// the opposite of the defaults, so that every setting must be changed
+			TimestampFormat = "unchanged"
+			SetPrependTimestamp(true)
+			SetPrependCaller(true)
+			SetFullCallerPath(true)
+			SetUTCTimestamps(true)
+
+			err := SetStdFlags(tt.flags)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), "log flags "+tt.err+" have no equivalent")):
+				t.Errorf("got error %v, want one listing %s", err, tt.err)
+			}
+			if got := getFlag(&PrependTimestamp); got != (tt.timestamp != "") {
+				t.Errorf("PrependTimestamp = %t", got)
+			}
+			if tt.timestamp != "" && TimestampFormat != tt.timestamp {
+				t.Errorf("TimestampFormat = %q, want %q", TimestampFormat, tt.timestamp)
+			}
+			if got := getFlag(&PrependCaller); got != tt.caller {
+				t.Errorf("PrependCaller = %t, want %t", got, tt.caller)
+			}
+			if got := getFlag(&FullCallerPath); got != tt.fullPath {
+				t.Errorf("FullCallerPath = %t, want %t", got, tt.fullPath)
+			}
+			if got := getFlag(&UTCTimestamps); got != tt.utc {
+				t.Errorf("UTCTimestamps = %t, want %t", got, tt.utc)
+			}
+		})
+	}
+}
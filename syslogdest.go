@@ -0,0 +1,140 @@
+package clog
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"strings"
+)
+
+/********************************************************************************
+* S Y S L O G   D E S T I N A T I O N
+*********************************************************************************/
+
+// AddSyslogDestination adds a destination that writes to the syslog with facility, e.g. syslog.LOG_AUTHPRIV, in
+// addition to the syslog of the Cloggers (see LogToSyslog), which uses DEFAULT_LOG_FACILITY. Each message is
+// written with the severity of its level. Several syslog destinations can be added, each with its own facility
+// and filter, e.g. to send the messages tagged "auth" to the authpriv facility:
+//
+//	clog.AddSyslogDestination(syslog.LOG_AUTHPRIV, clog.WithFilter(clog.MatchTag("auth")))
+//
+// The messages are rendered as set using SetSyslogFormat, without a timestamp, unless opts sets another
+// Formatter (see WithSyslogFormat). Each destination has its own connection, which is dialed using the dialer
+// set with SetSyslogDialer, and dialed again after a failed write. Closing one does not affect the others. It
+// returns an error if the syslog cannot be reached.
+func AddSyslogDestination(facility syslog.Priority, opts ...DestOption) (*WriterDestination, error) {
+	conn := &syslogConn{facility: facility &^ 0x07}
+	if err := conn.dial(); err != nil {
+		return nil, err
+	}
+	opts = append([]DestOption{WithFormatter(syslogFormatter{global: true})}, opts...)
+	d := NewDestination(conn, opts...)
+	d.syslog = conn
+	AddDestination(d)
+	return d, nil
+}
+
+// WithSyslogFormat renders the messages written by a syslog destination in format, rather than as set using
+// SetSyslogFormat (see AddSyslogDestination).
+func WithSyslogFormat(format SyslogFormat) DestOption {
+	return destOptionFunc(func(d *WriterDestination) {
+		_, cookie := getSyslogFormat()
+		d.formatter = syslogFormatter{format: format, cookie: cookie}
+	})
+}
+
+// syslogFormatter renders the entries as the body of a syslog message, in its format, or in the one set using
+// SetSyslogFormat if global is true.
+type syslogFormatter struct {
+	format SyslogFormat
+	cookie string
+	global bool
+}
+
+func (f syslogFormatter) Format(e *Entry) string {
+	if f.global {
+		format, cookie := getSyslogFormat()
+		return syslogMessage(e, format, cookie)
+	}
+	return syslogMessage(e, f.format, f.cookie)
+}
+
+// syslogConn is the connection of a syslog destination to the syslog. The severity of a write is that of the
+// level of the entry being written, which the destination sets under its lock.
+type syslogConn struct {
+	facility syslog.Priority
+	severity syslog.Priority
+	w        io.Writer
+	closed   bool
+}
+
+// dial connects c to the syslog.
+func (c *syslogConn) dial() error {
+	syslogDialerLock.RLock()
+	dial := syslogDialer
+	syslogDialerLock.RUnlock()
+	w, err := dial(c.facility | syslog.LOG_INFO)
+	if err != nil {
+		return fmt.Errorf("%s: could not connect to the syslog: %v", PACKAGE_NAME, err)
+	}
+	c.w = w
+	return nil
+}
+
+// Write writes p, a line, to the syslog with the severity of c, dialing the syslog first if the previous write
+// failed. It fails once c is closed.
+func (c *syslogConn) Write(p []byte) (int, error) {
+	if c.closed {
+		return 0, fmt.Errorf("%s: the syslog destination is closed", PACKAGE_NAME)
+	}
+	if c.w == nil {
+		if err := c.dial(); err != nil {
+			return 0, err
+		}
+	}
+	msg := strings.TrimSuffix(string(p), "\n")
+	var err error
+	if sw, ok := c.w.(*syslog.Writer); ok {
+		err = writeSyslogSeverity(sw, c.severity, msg)
+	} else {
+		_, err = io.WriteString(c.w, msg+"\n")
+	}
+	if err != nil {
+		closeWriter(c.w)
+		c.w = nil
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the connection of c.
+func (c *syslogConn) Close() error {
+	c.closed = true
+	if c.w == nil {
+		return nil
+	}
+	err := closeWriter(c.w)
+	c.w = nil
+	return err
+}
+
+// writeSyslogSeverity writes msg to w with severity, and the facility of w.
+func writeSyslogSeverity(w *syslog.Writer, severity syslog.Priority, msg string) error {
+	switch severity {
+	case syslog.LOG_EMERG:
+		return w.Emerg(msg)
+	case syslog.LOG_ALERT:
+		return w.Alert(msg)
+	case syslog.LOG_CRIT:
+		return w.Crit(msg)
+	case syslog.LOG_ERR:
+		return w.Err(msg)
+	case syslog.LOG_WARNING:
+		return w.Warning(msg)
+	case syslog.LOG_NOTICE:
+		return w.Notice(msg)
+	case syslog.LOG_DEBUG:
+		return w.Debug(msg)
+	}
+	return w.Info(msg)
+}
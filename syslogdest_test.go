@@ -0,0 +1,189 @@
+package clog
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSyslogd is a syslog daemon listening on a unixgram socket, which records the messages it receives.
+type fakeSyslogd struct {
+	path string
+	conn *net.UnixConn
+	lock sync.Mutex
+	msgs []string
+	done chan struct{}
+}
+
+// syslogLine matches the messages written to a local syslog by log/syslog, e.g.
+// "<84>Mar  5 14:07:09 app[42]: login failed".
+var syslogLine = regexp.MustCompile(`^<(\d+)>.* app\[\d+\]: (.*)\n?$`)
+
+// listen starts s on its socket, which is removed first if it exists.
+func (s *fakeSyslogd) listen(t *testing.T) {
+	t.Helper()
+	os.Remove(s.path)
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: s.path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.conn, s.done = conn, make(chan struct{})
+	go func() {
+		defer close(s.done)
+		buf := make([]byte, 4096)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			m := syslogLine.FindStringSubmatch(string(buf[:n]))
+			s.lock.Lock()
+			if m == nil {
+				s.msgs = append(s.msgs, "unexpected: "+string(buf[:n]))
+			} else {
+				s.msgs = append(s.msgs, "<"+m[1]+">"+m[2])
+			}
+			s.lock.Unlock()
+		}
+	}()
+}
+
+// stop stops s, and removes its socket.
+func (s *fakeSyslogd) stop() {
+	s.conn.Close()
+	<-s.done
+	os.Remove(s.path)
+}
+
+// wait returns the messages received by s, once there are n of them or a second has passed.
+func (s *fakeSyslogd) wait(n int) []string {
+	deadline := time.Now().Add(time.Second)
+	for {
+		s.lock.Lock()
+		msgs := append([]string(nil), s.msgs...)
+		s.lock.Unlock()
+		if len(msgs) >= n || time.Now().After(deadline) {
+			return msgs
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// startSyslogds starts a fakeSyslogd for each facility, and connects the syslog destinations to the one of
+// their facility until t is done.
+func startSyslogds(t *testing.T, facilities ...syslog.Priority) map[syslog.Priority]*fakeSyslogd {
+	// the path of a unix socket is limited to about 100 bytes, which t.TempDir may exceed
+	dir, err := os.MkdirTemp("", "clog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	daemons := make(map[syslog.Priority]*fakeSyslogd)
+	for _, facility := range facilities {
+		s := &fakeSyslogd{path: filepath.Join(dir, fmt.Sprint(int(facility)))}
+		s.listen(t)
+		t.Cleanup(s.stop)
+		daemons[facility] = s
+	}
+	SetSyslogDialer(func(priority syslog.Priority) (io.Writer, error) {
+		s, ok := daemons[priority&^0x07]
+		if !ok {
+			return nil, fmt.Errorf("no syslog for facility %d", priority&^0x07)
+		}
+		return syslog.Dial("unixgram", s.path, priority, "app")
+	})
+	t.Cleanup(func() { SetSyslogDialer(nil) })
+	return daemons
+}
+
+func TestTwoSyslogFacilities(t *testing.T) {
+	setupTest(t)
+	useDestinations(t)
+	recordInternal(t)
+	daemons := startSyslogds(t, syslog.LOG_AUTHPRIV, syslog.LOG_LOCAL1)
+	auth, err := AddSyslogDestination(syslog.LOG_AUTHPRIV, WithFilter(MatchTag("auth")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := AddSyslogDestination(syslog.LOG_LOCAL1,
+		WithFilter(MatchNot(MatchTag("auth")), MatchLevels(LogLevelInfo, MaxLevel)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.Close()
+
+	CaptureOutput(func() {
+		GetCloggerByName("Warning").Tagged("auth").Print("login failed")
+		Info("request served")
+		Debug("cache hit")
+		GetCloggerByName("Notice").Tagged("auth").PrintFields("password changed", "user", "bob")
+		Error("disk full")
+	})
+	// the priority is the facility plus the severity of the level: LOG_AUTHPRIV is 80, and LOG_LOCAL1 136
+	wantAuth := []string{"<84>login failed", "<85>password changed user=bob"}
+	if got := daemons[syslog.LOG_AUTHPRIV].wait(2); strings.Join(got, "\n") != strings.Join(wantAuth, "\n") {
+		t.Errorf("authpriv: got %q, want %q", got, wantAuth)
+	}
+	wantLocal := []string{"<142>request served", "<139>disk full"}
+	if got := daemons[syslog.LOG_LOCAL1].wait(2); strings.Join(got, "\n") != strings.Join(wantLocal, "\n") {
+		t.Errorf("local1: got %q, want %q", got, wantLocal)
+	}
+
+	// closing one destination does not affect the other
+	if err := auth.Close(); err != nil {
+		t.Fatal(err)
+	}
+	CaptureOutput(func() {
+		GetCloggerByName("Warning").Tagged("auth").Print("login failed again")
+		Info("request served again")
+	})
+	wantLocal = append(wantLocal, "<142>request served again")
+	if got := daemons[syslog.LOG_LOCAL1].wait(3); strings.Join(got, "\n") != strings.Join(wantLocal, "\n") {
+		t.Errorf("local1: got %q, want %q", got, wantLocal)
+	}
+	// the write to the closed destination happened before the one that local1 received, so there is no wait
+	if got := daemons[syslog.LOG_AUTHPRIV].wait(0); len(got) != 2 {
+		t.Errorf("authpriv: got %q after Close", got)
+	}
+	if stats := auth.Stats(); stats.Failures != 1 {
+		t.Errorf("got %+v, want the write after Close to fail", stats)
+	}
+}
+
+// TestSyslogDestinationReconnects restarts the syslog daemon of one destination, which must reconnect to it
+// while the other destination keeps its connection.
+func TestSyslogDestinationReconnects(t *testing.T) {
+	setupTest(t)
+	useDestinations(t)
+	recordInternal(t)
+	daemons := startSyslogds(t, syslog.LOG_AUTHPRIV, syslog.LOG_LOCAL1)
+	for _, facility := range []syslog.Priority{syslog.LOG_AUTHPRIV, syslog.LOG_LOCAL1} {
+		d, err := AddSyslogDestination(facility)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer d.Close()
+	}
+
+	restarted := daemons[syslog.LOG_AUTHPRIV]
+	restarted.stop()
+	CaptureOutput(func() { Info("lost") })
+	restarted.listen(t)
+	CaptureOutput(func() { Info("back") })
+
+	if got, want := restarted.wait(1), []string{"<86>back"}; strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("authpriv: got %q, want %q", got, want)
+	}
+	want := []string{"<142>lost", "<142>back"}
+	if got := daemons[syslog.LOG_LOCAL1].wait(2); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("local1: got %q, want %q", got, want)
+	}
+}
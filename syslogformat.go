@@ -0,0 +1,157 @@
+package clog
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+/********************************************************************************
+* S Y S L O G   F O R M A T
+*********************************************************************************/
+
+// SyslogFormat determines how the name of the Clogger, the level and the fields of a message are carried in the
+// syslog, so that the body of the message can stay byte-for-byte what it always was, e.g. for SIEM rules.
+// Whatever the SyslogFormat, the messages carry no timestamp, since the syslog daemon adds its own.
+type SyslogFormat int
+
+const (
+	// SyslogFieldsInMessage writes the message followed by its fields as key=value pairs, e.g. "disk full
+	// mount=/var". The name and level are not written: the syslog records the priority on its own. It is the
+	// default.
+	SyslogFieldsInMessage SyslogFormat = iota
+	// SyslogStructuredData writes an RFC 5424 SD-ELEMENT with the name, the level and the fields, followed by
+	// the message, untouched, e.g. `[clog@32473 logger="Error" level="error" mount="/var"] disk full`.
+	SyslogStructuredData
+	// SyslogCEECookie writes the message, untouched, followed by the CEE cookie and a JSON object with the
+	// name, the level and the fields, e.g. `disk full @cee: {"logger":"Error","level":"error","mount":"/var"}`,
+	// for rsyslog setups that parse Lumberjack/CEE. The cookie is set using SetSyslogCEECookie.
+	SyslogCEECookie
+)
+
+// SyslogSDID is the SD-ID of the SD-ELEMENT written with SyslogStructuredData. 32473 is the private enterprise
+// number reserved for documentation by RFC 5612.
+const SyslogSDID = "clog@32473"
+
+// DefaultSyslogCEECookie is the cookie written before the JSON object with SyslogCEECookie by default.
+const DefaultSyslogCEECookie = "@cee:"
+
+var syslogFormat = SyslogFieldsInMessage
+var syslogCEECookie = DefaultSyslogCEECookie
+var syslogFormatLock sync.RWMutex
+
+// SetSyslogFormat sets how the name, the level and the fields of the messages are written to the syslog. It
+// returns an error if format is not one of the SyslogFormat constants.
+func SetSyslogFormat(format SyslogFormat) error {
+	if format < SyslogFieldsInMessage || format > SyslogCEECookie {
+		return fmt.Errorf("%s: invalid SyslogFormat %d", PACKAGE_NAME, format)
+	}
+	syslogFormatLock.Lock()
+	defer syslogFormatLock.Unlock()
+	syslogFormat = format
+	return nil
+}
+
+// SetSyslogCEECookie sets the cookie written before the JSON object with SyslogCEECookie, "@cee:" by default.
+// An empty cookie restores the default.
+func SetSyslogCEECookie(cookie string) {
+	if cookie == "" {
+		cookie = DefaultSyslogCEECookie
+	}
+	syslogFormatLock.Lock()
+	defer syslogFormatLock.Unlock()
+	syslogCEECookie = cookie
+}
+
+// getSyslogFormat returns the SyslogFormat and the CEE cookie.
+func getSyslogFormat() (SyslogFormat, string) {
+	syslogFormatLock.RLock()
+	defer syslogFormatLock.RUnlock()
+	return syslogFormat, syslogCEECookie
+}
+
+// String returns the name of the constant of f, e.g. "SyslogStructuredData".
+func (f SyslogFormat) String() string {
+	switch f {
+	case SyslogFieldsInMessage:
+		return "SyslogFieldsInMessage"
+	case SyslogStructuredData:
+		return "SyslogStructuredData"
+	case SyslogCEECookie:
+		return "SyslogCEECookie"
+	}
+	return fmt.Sprintf("SyslogFormat(%d)", int(f))
+}
+
+// syslogMessage renders e as the body of a syslog message, in format.
+func syslogMessage(e *Entry, format SyslogFormat, cookie string) string {
+	switch format {
+	case SyslogStructuredData:
+		return structuredData(e) + " " + e.Message
+	case SyslogCEECookie:
+		return e.Message + " " + cookie + " " + ceeJSON(e)
+	}
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+	return e.Message + " " + formatFields(e.Fields)
+}
+
+// structuredData renders the name, the level and the fields of e as an RFC 5424 SD-ELEMENT.
+func structuredData(e *Entry) string {
+	var b strings.Builder
+	b.WriteString("[" + SyslogSDID)
+	writeSDParam(&b, "logger", e.Logger)
+	writeSDParam(&b, "level", levelName(e.Level))
+	for _, f := range e.Fields.ordered() {
+		writeSDParam(&b, sdParamName(structuredKey(f.Key)), sprint(f.Value))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// writeSDParam writes ` name="value"` to b, escaping '"', '\' and ']' in value as RFC 5424 requires.
+func writeSDParam(b *strings.Builder, name, value string) {
+	b.WriteString(" " + name + `="`)
+	for _, r := range value {
+		if r == '"' || r == '\\' || r == ']' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+}
+
+// sdParamName returns key as an RFC 5424 PARAM-NAME: at most 32 printable US-ASCII characters other than '=',
+// ' ', ']' and '"'. The other characters are replaced by '_'.
+func sdParamName(key string) string {
+	name := []byte(key)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	for i, c := range name {
+		if c <= ' ' || c >= 0x7f || c == '=' || c == ']' || c == '"' {
+			name[i] = '_'
+		}
+	}
+	if len(name) == 0 {
+		return "_"
+	}
+	return string(name)
+}
+
+// ceeJSON renders the name, the level and the fields of e as a JSON object, without the message.
+func ceeJSON(e *Entry) string {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	writeJSONPair(&b, "logger", e.Logger)
+	b.WriteByte(',')
+	writeJSONPair(&b, "level", levelName(e.Level))
+	for _, f := range e.Fields.ordered() {
+		b.WriteByte(',')
+		writeJSONPair(&b, structuredKey(f.Key), f.Value)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
@@ -0,0 +1,125 @@
+package clog
+
+import (
+	"log/syslog"
+	"strings"
+	"testing"
+)
+
+// useSyslogFormat sets the SyslogFormat and the CEE cookie until tb is done.
+func useSyslogFormat(tb testing.TB, format SyslogFormat, cookie string) {
+	if err := SetSyslogFormat(format); err != nil {
+		tb.Fatal(err)
+	}
+	SetSyslogCEECookie(cookie)
+	tb.Cleanup(func() {
+		SetSyslogFormat(SyslogFieldsInMessage)
+		SetSyslogCEECookie("")
+	})
+}
+
+func TestSyslogFormats(t *testing.T) {
+	tests := []struct {
+		format SyslogFormat
+		cookie string
+		want   []string
+	}{
+		{SyslogFieldsInMessage, "", []string{
+			"disk full",
+			"disk full mount=/var used=93",
+			`quota "bob" exceeded user="bob \"b\" [x]"`,
+		}},
+		{SyslogStructuredData, "", []string{
+			`[clog@32473 logger="Error" level="error"] disk full`,
+			`[clog@32473 logger="Error" level="error" mount="/var" used="93"] disk full`,
+			`[clog@32473 logger="Error" level="error" user="bob \"b\" [x\]"] quota "bob" exceeded`,
+		}},
+		{SyslogCEECookie, "", []string{
+			`disk full @cee: {"logger":"Error","level":"error"}`,
+			`disk full @cee: {"logger":"Error","level":"error","mount":"/var","used":93}`,
+			`quota "bob" exceeded @cee: {"logger":"Error","level":"error","user":"bob \"b\" [x]"}`,
+		}},
+		{SyslogCEECookie, "@json:", []string{
+			`disk full @json: {"logger":"Error","level":"error"}`,
+			`disk full @json: {"logger":"Error","level":"error","mount":"/var","used":93}`,
+			`quota "bob" exceeded @json: {"logger":"Error","level":"error","user":"bob \"b\" [x]"}`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format.String()+tt.cookie, func(t *testing.T) {
+			setupTest(t)
+			rec := recordSyslog(t)
+			useSyslogFormat(t, tt.format, tt.cookie)
+			// the output is not affected by the SyslogFormat
+			got := CaptureOutput(func() {
+				Error("disk full")
+				GetCloggerByName("Error").PrintFields("disk full", "mount", "/var", "used", 93)
+				GetCloggerByName("Error").PrintFields(`quota "bob" exceeded`, "user", `bob "b" [x]`)
+			})
+			want := "2024/03/05 14:07:09 [ERROR] disk full\n" +
+				"2024/03/05 14:07:09 [ERROR] disk full mount=/var used=93\n" +
+				"2024/03/05 14:07:09 [ERROR] quota \"bob\" exceeded user=\"bob \\\"b\\\" [x]\"\n"
+			if got != want {
+				t.Errorf("output: got %q, want %q", got, want)
+			}
+			if got := rec.messages(); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("syslog: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSyslogFormatPerDestination checks that each syslog destination writes in its own SyslogFormat.
+func TestSyslogFormatPerDestination(t *testing.T) {
+	setupTest(t)
+	rec := recordSyslog(t)
+	setFlag(&LogToSyslog, false)
+	useDestinations(t)
+	useSyslogFormat(t, SyslogStructuredData, "")
+	for _, format := range []SyslogFormat{SyslogFieldsInMessage, SyslogCEECookie} {
+		if _, err := AddSyslogDestination(syslog.LOG_LOCAL0, WithSyslogFormat(format)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// without WithSyslogFormat, the destination follows SetSyslogFormat
+	if _, err := AddSyslogDestination(syslog.LOG_LOCAL1); err != nil {
+		t.Fatal(err)
+	}
+	CaptureOutput(func() { GetCloggerByName("Warning").PrintFields("disk full", "mount", "/var") })
+	want := []string{
+		"disk full mount=/var",
+		`disk full @cee: {"logger":"Warning","level":"warning","mount":"/var"}`,
+		`[clog@32473 logger="Warning" level="warning" mount="/var"] disk full`,
+	}
+	if got := rec.messages(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSDParamName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"mount", "mount"},
+		{"user id", "user_id"},
+		{`a=b]c"d`, "a_b_c_d"},
+		{"", "_"},
+		{"é", "__"},
+		{strings.Repeat("k", 40), strings.Repeat("k", 32)},
+	}
+	for _, tt := range tests {
+		if got := sdParamName(tt.key); got != tt.want {
+			t.Errorf("sdParamName(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestSetSyslogFormatInvalid(t *testing.T) {
+	if err := SetSyslogFormat(SyslogFormat(42)); err == nil {
+		t.Error("SetSyslogFormat(42) returned no error")
+	}
+	if got := SyslogFormat(42).String(); got != "SyslogFormat(42)" {
+		t.Errorf("got %q, want \"SyslogFormat(42)\"", got)
+	}
+}
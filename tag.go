@@ -0,0 +1,135 @@
+package clog
+
+import (
+	"reflect"
+	"sync"
+)
+
+/********************************************************************************
+* T A G S
+*********************************************************************************/
+
+var tagRoutes map[string][]Destination = make(map[string][]Destination)
+var suppressedTags map[string]bool = make(map[string]bool)
+var tagsLock sync.RWMutex
+
+// Tagged returns a new Clogger, with the same configuration as l, that tags every message it logs with tags,
+// after the tags of l. Tags are orthogonal to the levels: they can be used to route messages to destinations
+// (see RouteTag) or to suppress them (see SuppressTags). They are shown as a "#tag" suffix in the text output,
+// and as a "tags" key in the structured outputs.
+func (l *Clogger) Tagged(tags ...string) *Clogger {
+	child := l.pooledClone()
+	child.tags = append([]string{}, l.tags...)
+	for _, tag := range tags {
+		if !containsTag(child.tags, tag) {
+			child.tags = append(child.tags, tag)
+		}
+	}
+	return child
+}
+
+// RouteTag routes the messages tagged with tag to dest, in addition to the output and the syslog. A message
+// is only routed if it passes the LogLevel as well.
+func RouteTag(tag string, dest Destination) {
+	tagsLock.Lock()
+	defer tagsLock.Unlock()
+	tagRoutes[tag] = append(tagRoutes[tag], dest)
+}
+
+// SuppressTags drops the messages tagged with any of tags, so that they are not logged anywhere.
+func SuppressTags(tags ...string) {
+	tagsLock.Lock()
+	defer tagsLock.Unlock()
+	for _, tag := range tags {
+		suppressedTags[tag] = true
+	}
+}
+
+// UnsuppressTags undoes SuppressTags for tags.
+func UnsuppressTags(tags ...string) {
+	tagsLock.Lock()
+	defer tagsLock.Unlock()
+	for _, tag := range tags {
+		delete(suppressedTags, tag)
+	}
+}
+
+// tagsSuppressed reports whether any of tags is suppressed.
+func tagsSuppressed(tags []string) bool {
+	if len(tags) == 0 {
+		return false
+	}
+	tagsLock.RLock()
+	defer tagsLock.RUnlock()
+	for _, tag := range tags {
+		if suppressedTags[tag] {
+			return true
+		}
+	}
+	return false
+}
+
+// tagDestinations returns the destinations that the messages tagged with tags are routed to, each only once.
+func tagDestinations(tags []string) []Destination {
+	if len(tags) == 0 {
+		return nil
+	}
+	tagsLock.RLock()
+	defer tagsLock.RUnlock()
+	var dests []Destination
+	for _, tag := range tags {
+		for _, dest := range tagRoutes[tag] {
+			if !containsDestination(dests, dest) {
+				dests = append(dests, dest)
+			}
+		}
+	}
+	return dests
+}
+
+// allTagDestinations returns all the destinations that tags are routed to, each only once.
+func allTagDestinations() []Destination {
+	tagsLock.RLock()
+	defer tagsLock.RUnlock()
+	var dests []Destination
+	for _, routes := range tagRoutes {
+		for _, dest := range routes {
+			if !containsDestination(dests, dest) {
+				dests = append(dests, dest)
+			}
+		}
+	}
+	return dests
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func containsDestination(dests []Destination, dest Destination) bool {
+	for _, d := range dests {
+		if sameDestination(d, dest) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameDestination reports whether a and b are the same destination. Destinations are usually pointers, which are
+// the same if they point to the same value. Comparing values of an uncomparable type, e.g. a struct with a slice
+// field, would panic, so such destinations are never the same: each of them is written to, even if it was added
+// more than once.
+func sameDestination(a, b Destination) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.ValueOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
@@ -0,0 +1,113 @@
+package clog
+
+import (
+	"strings"
+	"testing"
+)
+
+// recordingDestination records the messages written to it.
+type recordingDestination struct {
+	messages []string
+}
+
+func (d *recordingDestination) Write(e *Entry) error {
+	d.messages = append(d.messages, e.Message)
+	return nil
+}
+
+// uncomparableDestination is a Destination whose values cannot be compared using ==.
+type uncomparableDestination struct {
+	messages *[]string
+	extra    []string
+}
+
+func (d uncomparableDestination) Write(e *Entry) error {
+	*d.messages = append(*d.messages, e.Message)
+	return nil
+}
+
+// resetTags removes the tag routes and the suppressed tags, until tb is done as well.
+func resetTags(tb testing.TB) {
+	reset := func() {
+		tagsLock.Lock()
+		tagRoutes = make(map[string][]Destination)
+		suppressedTags = make(map[string]bool)
+		tagsLock.Unlock()
+	}
+	reset()
+	tb.Cleanup(reset)
+}
+
+func TestTagged(t *testing.T) {
+	setupTest(t)
+	resetTags(t)
+	SetPrependTimestamp(false)
+	billing := &recordingDestination{}
+	RouteTag("billing", billing)
+	SuppressTags("verbose-sql")
+	cl := GetCloggerByName("Info").Tagged("billing")
+	got := captureLines(func() {
+		cl.Print("invoice sent")
+		cl.Tagged("verbose-sql").Print("SELECT 1")
+		Info("untagged")
+	})
+	want := []string{"[INFO] invoice sent #billing", "[INFO] untagged"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("output: got %q, want %q", got, want)
+	}
+	if strings.Join(billing.messages, "\n") != "invoice sent" {
+		t.Errorf("billing destination: got %q, want only the tagged message", billing.messages)
+	}
+}
+
+func TestRouteTagComposesWithLevel(t *testing.T) {
+	setupTest(t)
+	resetTags(t)
+	SetLogLevel(LogLevelWarning)
+	billing := &recordingDestination{}
+	RouteTag("billing", billing)
+	CaptureOutput(func() {
+		GetCloggerByName("Info").Tagged("billing").Print("below the level")
+		GetCloggerByName("Error").Tagged("billing").Print("above the level")
+	})
+	if strings.Join(billing.messages, "\n") != "above the level" {
+		t.Errorf("got %q, want only the message that passes the level", billing.messages)
+	}
+}
+
+func TestUncomparableDestinations(t *testing.T) {
+	setupTest(t)
+	resetTags(t)
+	var messages []string
+	dest := uncomparableDestination{messages: &messages, extra: []string{"x"}}
+	RouteTag("a", dest)
+	RouteTag("b", dest)
+	CaptureOutput(func() { GetCloggerByName("Info").Tagged("a", "b").Print("routed") })
+	// uncomparable destinations cannot be told apart, so the message is written once per route
+	if len(messages) != 2 {
+		t.Errorf("got %q, want the message written once per route", messages)
+	}
+}
+
+func TestSameDestination(t *testing.T) {
+	a, b := &recordingDestination{}, &recordingDestination{}
+	var messages []string
+	u := uncomparableDestination{messages: &messages}
+	tests := []struct {
+		name string
+		x, y Destination
+		want bool
+	}{
+		{"same pointer", a, a, true},
+		{"different pointers", a, b, false},
+		{"different types", a, u, false},
+		{"uncomparable", u, u, false},
+		{"nil", nil, nil, true},
+		{"nil and pointer", nil, a, false},
+	}
+	for _, tt := range tests {
+		if got := sameDestination(tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: got %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package clog
+
+import (
+	"sort"
+	"strings"
+)
+
+// V holds the values of the named placeholders of a message template, e.g.
+// clog.InfoT("deploy {service} to {env}", clog.V{"service": "api", "env": "prod"}).
+type V map[string]interface{}
+
+// renderTemplate replaces the {name} placeholders in template with the matching values, formatted using
+// fmt.Sprint. "{{" and "}}" are rendered as literal braces, and placeholders without a value are rendered
+// as {name?}. It also returns the values as fields, in the order of their first placeholder, followed by the
+// values without a placeholder sorted by name.
+func renderTemplate(template string, values map[string]interface{}) (string, Fields) {
+	var b strings.Builder
+	fields := make(Fields, 0, len(values))
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		switch {
+		case c == '{' && i+1 < len(template) && template[i+1] == '{':
+			b.WriteByte('{')
+			i++
+		case c == '}' && i+1 < len(template) && template[i+1] == '}':
+			b.WriteByte('}')
+			i++
+		case c == '{':
+			end := strings.IndexByte(template[i+1:], '}')
+			if end < 0 {
+				b.WriteString(template[i:])
+				i = len(template)
+				continue
+			}
+			name := template[i+1 : i+1+end]
+			if v, ok := values[name]; ok {
+				b.WriteString(sprint(v))
+				fields = fields.set(name, v)
+			} else {
+				b.WriteString("{" + name + "?}")
+			}
+			i += end + 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		if _, ok := fields.Get(name); !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fields = append(fields, Field{name, values[name]})
+	}
+	return b.String(), fields
+}
+
+// PrintT logs the message rendered from template, replacing the {name} placeholders with the matching
+// values, e.g. PrintT("deploy {service} to {env}", clog.V{"service": "api", "env": "prod"}). Use "{{" and
+// "}}" for literal braces; placeholders without a value are rendered as {name?}. The values are attached
+// to the message as fields as well, so that structured formatters emit the raw values.
+func (l *Clogger) PrintT(template string, values map[string]interface{}) {
+	msg, fields := renderTemplate(template, values)
+	l.printEntry(l.newEntry(msg, fields))
+}
+
+// DebugT renders the message template with values (see Clogger.PrintT) and logs it using the "Debug" default clogger.
+func DebugT(template string, values map[string]interface{}) {
+	defaultCloggerForLevel(LogLevelDebug).PrintT(template, values)
+}
+
+// InfoT renders the message template with values (see Clogger.PrintT) and logs it using the "Info" default clogger.
+func InfoT(template string, values map[string]interface{}) {
+	defaultCloggerForLevel(LogLevelInfo).PrintT(template, values)
+}
+
+// NoticeT renders the message template with values (see Clogger.PrintT) and logs it using the "Notice" default clogger.
+func NoticeT(template string, values map[string]interface{}) {
+	defaultCloggerForLevel(LogLevelNotice).PrintT(template, values)
+}
+
+// WarningT renders the message template with values (see Clogger.PrintT) and logs it using the "Warning" default clogger.
+func WarningT(template string, values map[string]interface{}) {
+	defaultCloggerForLevel(LogLevelWarning).PrintT(template, values)
+}
+
+// ErrorT renders the message template with values (see Clogger.PrintT) and logs it using the "Error" default clogger.
+func ErrorT(template string, values map[string]interface{}) {
+	defaultCloggerForLevel(LogLevelError).PrintT(template, values)
+}
+
+// CritT renders the message template with values (see Clogger.PrintT) and logs it using the "Crit" default clogger.
+func CritT(template string, values map[string]interface{}) {
+	defaultCloggerForLevel(LogLevelCrit).PrintT(template, values)
+}
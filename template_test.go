@@ -0,0 +1,28 @@
+package clog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintT(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	lines := captureLines(func() {
+		InfoT("deploy {service} to {env} in {{braces}} {region}", V{"env": "prod", "service": "api", "by": "ci", "attempt": 2})
+	})
+	want := "[INFO] deploy api to prod in {braces} {region?} service=api env=prod attempt=2 by=ci"
+	if len(lines) != 1 || lines[0] != want {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+}
+
+// TestPrintTJSON checks that the structured formatters get the raw values of the placeholders.
+func TestPrintTJSON(t *testing.T) {
+	setupTest(t)
+	SetFormatter(&JSONFormatter{})
+	out := CaptureOutput(func() { ErrorT("retry {attempt} of {service}", V{"attempt": 3, "service": "api"}) })
+	if !strings.Contains(out, `"msg":"retry 3 of api","attempt":3,"service":"api"}`) {
+		t.Errorf("got %q, want the message and the raw values", out)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package clog
+
+import "os"
+
+// terminalWidth returns 0, since the width of the terminal cannot be determined on this platform. The COLUMNS
+// environment variable is used instead.
+func terminalWidth(f *os.File) int {
+	return 0
+}
@@ -0,0 +1,101 @@
+package clog
+
+import (
+	"log/syslog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTimestampPerDestination logs a single Infof to the console in the short local format, to a JSON file with
+// "ts" in RFC3339Nano UTC, and to the syslog without a timestamp.
+func TestTimestampPerDestination(t *testing.T) {
+	setupTest(t)
+	rec := recordSyslog(t)
+	useDestinations(t)
+	local := testTime.In(time.FixedZone("CET", 3600))
+	SetClock(func() time.Time { return local })
+	var jsonFile, logfmtFile lockedBuffer
+	AddDestination(NewDestination(&jsonFile, WithFormatter(
+		NewJSONFormatter(WithTimestampKey("ts"), WithTimestampLayout(time.RFC3339Nano, true)))))
+	AddDestination(NewDestination(&logfmtFile, WithFormatter(
+		NewLogfmtFormatter(WithTimestampKey("ts"), WithTimestampLayout(time.RFC3339, true)))))
+	if _, err := AddSyslogDestination(syslog.LOG_LOCAL0); err != nil {
+		t.Fatal(err)
+	}
+
+	console := CaptureOutput(func() { Infof("user %d signed in", 42) })
+	if want := "2024/03/05 15:07:09 [INFO] user 42 signed in\n"; console != want {
+		t.Errorf("console: got %q, want %q", console, want)
+	}
+	want := `{"ts":"2024-03-05T14:07:09.123456789Z","level":"info","logger":"Info","msg":"user 42 signed in"}` + "\n"
+	if got := jsonFile.String(); got != want {
+		t.Errorf("JSON: got %q, want %q", got, want)
+	}
+	want = `ts=2024-03-05T14:07:09Z level=info logger=Info msg="user 42 signed in"` + "\n"
+	if got := logfmtFile.String(); got != want {
+		t.Errorf("logfmt: got %q, want %q", got, want)
+	}
+	// the syslog of the Info Clogger, and the syslog destination
+	wantSyslog := []string{"user 42 signed in", "user 42 signed in"}
+	if got := rec.messages(); strings.Join(got, "\n") != strings.Join(wantSyslog, "\n") {
+		t.Errorf("syslog: got %q, want %q", got, wantSyslog)
+	}
+}
+
+func TestTimestampOptions(t *testing.T) {
+	setupTest(t)
+	e := GetCloggerByName("Warning").newEntry("disk full", Fields{{"ts", "field"}})
+	e.Time = testTime.In(time.FixedZone("CET", 3600))
+	tests := []struct {
+		name string
+		f    Formatter
+		want string
+	}{
+		{"JSON default", NewJSONFormatter(),
+			`{"time":"2024-03-05T15:07:09.123456789+01:00","level":"warning","logger":"Warning","msg":"disk full","ts":"field"}`},
+		{"JSON local", NewJSONFormatter(WithTimestampKey("ts"), WithTimestampLayout(time.Kitchen, false)),
+			`{"ts":"3:07PM","level":"warning","logger":"Warning","msg":"disk full","fields.ts":"field"}`},
+		{"logfmt UTC", NewLogfmtFormatter(WithTimestampKey("at"), WithTimestampLayout(time.DateTime, true)),
+			`at="2024-03-05 14:07:09" level=warning logger=Warning msg="disk full" ts=field`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e.formatted = nil
+			if got := formatEntry(tt.f, e); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWithTimestampFormat checks that the timestamp format and the location of a destination override those of
+// the output, of the Formatter and of the Clogger, without affecting the other destinations.
+func TestWithTimestampFormat(t *testing.T) {
+	setupTest(t)
+	SetClock(func() time.Time { return testTime.In(time.FixedZone("CET", 3600)) })
+	var utc, tokyo, plain lockedBuffer
+	useDestinations(t,
+		NewDestination(&utc, WithTimestampFormat(time.RFC3339Nano, time.UTC)),
+		NewDestination(&tokyo, WithTimestampFormat("", time.FixedZone("JST", 9*3600))),
+		NewDestination(&plain, WithFormatter(&JSONFormatter{}), WithTimestampFormat(time.Kitchen, nil)),
+	)
+	cl := NewClogger("Billing", LogLevelInfo, OptTimestampFormat(time.RFC1123))
+	console := CaptureOutput(func() { cl.Print("invoice sent") })
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"console", console, "Tue, 05 Mar 2024 15:07:09 CET [BILLING] invoice sent\n"},
+		{"UTC", utc.String(), "2024-03-05T14:07:09.123456789Z [BILLING] invoice sent\n"},
+		{"location only", tokyo.String(), "Tue, 05 Mar 2024 23:07:09 JST [BILLING] invoice sent\n"},
+		{"JSON", plain.String(), `{"time":"3:07PM","level":"info","logger":"Billing","msg":"invoice sent"}` + "\n"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
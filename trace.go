@@ -0,0 +1,57 @@
+package clog
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// routingTraces is the number of calls to TraceRouting that are running.
+var routingTraces atomic.Int32
+
+// TraceRouting calls fn, and while it runs, writes a line to the standard error for every logged message that
+// describes where the message was routed and why: whether it was suppressed by a tag or by sampling, whether
+// it was written to the syslog, the output and the destinations, which formatter was used, and which filters
+// skipped it. It helps debugging the configuration of clog. Messages logged by other goroutines while fn runs
+// are traced as well.
+func TraceRouting(fn func()) {
+	routingTraces.Add(1)
+	defer routingTraces.Add(-1)
+	fn()
+}
+
+// routingTrace records the routing decisions for an entry, if TraceRouting is running. A nil routingTrace
+// records nothing, so that the decisions cost nothing otherwise.
+type routingTrace struct {
+	e         *Entry
+	decisions []string
+}
+
+// newRoutingTrace returns a routingTrace for e, or nil if TraceRouting is not running.
+func newRoutingTrace(e *Entry) *routingTrace {
+	if routingTraces.Load() == 0 {
+		return nil
+	}
+	return &routingTrace{e: e}
+}
+
+func (t *routingTrace) note(decision string) {
+	if t != nil {
+		t.decisions = append(t.decisions, decision)
+	}
+}
+
+func (t *routingTrace) notef(format string, args ...interface{}) {
+	if t != nil {
+		t.decisions = append(t.decisions, fmt.Sprintf(format, args...))
+	}
+}
+
+// emit writes the decisions to the standard error, unformatted, so that tracing never logs recursively.
+func (t *routingTrace) emit() {
+	if t == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s: routing %s %q (%s): %s\n", PACKAGE_NAME, strings.TrimSpace(namePrefix(t.e.Logger)), t.e.Message, levelName(t.e.Level), strings.Join(t.decisions, "; "))
+}
@@ -0,0 +1,56 @@
+package clog
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// captureStderr returns what is written to the standard error while fn runs.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	stderr := os.Stderr
+	os.Stderr = f
+	defer func() { os.Stderr = stderr }()
+	fn()
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestTraceRouting(t *testing.T) {
+	setupTest(t)
+	SetLogLevel(LogLevelInfo)
+	SuppressTags("noisy")
+	t.Cleanup(func() { UnsuppressTags("noisy") })
+	var out string
+	trace := captureStderr(t, func() {
+		out = CaptureOutput(func() {
+			TraceRouting(func() {
+				Info("user signed in")
+				Debug("cache miss")
+				GetCloggerByName("Info").Tagged("noisy").Print("heartbeat")
+			})
+			Info("not traced")
+		})
+	})
+	want := []string{
+		`Clog: routing [INFO] "user signed in" (info): syslog: skipped, LogToSyslog is false; output: written using *clog.TextFormatter`,
+		`Clog: routing [DEBUG] "cache miss" (debug): syslog: skipped, LogToSyslog is false; output and destinations: skipped, level debug is below the LogLevel info`,
+		`Clog: routing [INFO] "heartbeat" (info): suppressed by SuppressTags`,
+	}
+	if got := strings.Split(strings.TrimSuffix(trace, "\n"), "\n"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if !strings.Contains(out, "not traced") {
+		t.Errorf("got the output %q, want the messages logged after fn as well", out)
+	}
+}
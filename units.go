@@ -0,0 +1,89 @@
+package clog
+
+import (
+	"fmt"
+	"time"
+)
+
+/********************************************************************************
+* U N I T S
+*********************************************************************************/
+
+// unitValue is a field value with a unit. It is rendered in a human friendly form by the text and logfmt
+// formatters, using String, and as a number in that unit by the JSON formatter, using MarshalJSON.
+type unitValue interface {
+	fmt.Stringer
+	MarshalJSON() ([]byte, error)
+	unit() string
+}
+
+// Dur returns a field for the duration d, e.g. Dur("latency", elapsed), to be passed along the keys and values
+// of a message. It is rendered as 1.2s in text and as a number of milliseconds in JSON.
+func Dur(key string, d time.Duration) Field {
+	return Field{key, durationValue(d)}
+}
+
+// Bytes returns a field for the size n in bytes, e.g. Bytes("size", int64(len(body))), to be passed along the keys and
+// values of a message. It is rendered as 3.4MiB in text and as a number of bytes in JSON.
+func Bytes(key string, n int64) Field {
+	return Field{key, bytesValue(n)}
+}
+
+type durationValue time.Duration
+
+// String rounds the duration to about 2 significant digits, keeping the units of time.Duration.
+func (v durationValue) String() string {
+	d := time.Duration(v)
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs >= time.Minute:
+		d = d.Round(time.Second)
+	case abs >= time.Second:
+		d = d.Round(100 * time.Millisecond)
+	case abs >= time.Millisecond:
+		d = d.Round(100 * time.Microsecond)
+	case abs >= time.Microsecond:
+		d = d.Round(100 * time.Nanosecond)
+	}
+	return d.String()
+}
+
+func (v durationValue) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprint(float64(v) / float64(time.Millisecond))), nil
+}
+
+func (v durationValue) unit() string {
+	return "ms"
+}
+
+type bytesValue int64
+
+// String renders the size with binary prefixes, e.g. 512B, 1.5KiB or 3.4MiB.
+func (v bytesValue) String() string {
+	const units = "KMGTPE"
+	n := int64(v)
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs < 1024 {
+		return fmt.Sprintf("%dB", n)
+	}
+	size, i := float64(n)/1024, 0
+	for (size >= 1024 || size <= -1024) && i < len(units)-1 {
+		size /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f%ciB", size, units[i])
+}
+
+func (v bytesValue) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprint(int64(v))), nil
+}
+
+func (v bytesValue) unit() string {
+	return "bytes"
+}
@@ -0,0 +1,50 @@
+package clog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnitFields(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	cl := GetCloggerByName("Info")
+	log := func() {
+		cl.PrintFields("uploaded", Dur("latency", 1234567*time.Microsecond), Bytes("size", 3565158), Bytes("header", 512))
+	}
+	if got, want := CaptureOutput(log), "[INFO] uploaded latency=1.2s size=3.4MiB header=512B\n"; got != want {
+		t.Errorf("text: got %q, want %q", got, want)
+	}
+	SetFormatter(&LogfmtFormatter{})
+	if got := CaptureOutput(log); !strings.HasSuffix(got, " latency=1.2s size=3.4MiB header=512B\n") {
+		t.Errorf("logfmt: got %q", got)
+	}
+	SetFormatter(&JSONFormatter{})
+	if got := CaptureOutput(log); !strings.HasSuffix(got, `"latency":1234.567,"size":3565158,"header":512}`+"\n") {
+		t.Errorf("JSON: got %q", got)
+	}
+	SetFormatter(&JSONFormatter{UnitKeys: true})
+	if got := CaptureOutput(log); !strings.HasSuffix(got, `"latency_ms":1234.567,"size_bytes":3565158,"header_bytes":512}`+"\n") {
+		t.Errorf("JSON with UnitKeys: got %q", got)
+	}
+}
+
+func TestUnitValueStrings(t *testing.T) {
+	tests := []struct {
+		value interface{ String() string }
+		want  string
+	}{
+		{durationValue(1234567 * time.Nanosecond), "1.2ms"},
+		{durationValue(-90 * time.Second), "-1m30s"},
+		{durationValue(999), "999ns"},
+		{bytesValue(1023), "1023B"},
+		{bytesValue(1536), "1.5KiB"},
+		{bytesValue(-5 << 30), "-5.0GiB"},
+	}
+	for _, tt := range tests {
+		if got := tt.value.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !windows
+
+package clog
+
+// windowsVTState describes whether virtual terminal processing, which is needed for the escape sequences of
+// the decorations, is enabled in the Windows console.
+const windowsVTState = "not applicable"
@@ -0,0 +1,6 @@
+package clog
+
+// windowsVTState describes whether virtual terminal processing, which is needed for the escape sequences of
+// the decorations, is enabled in the Windows console. clog does not enable it, so the decorations are only
+// shown by terminals that enable it on their own, such as Windows Terminal.
+const windowsVTState = "not enabled by clog"
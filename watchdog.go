@@ -0,0 +1,95 @@
+package clog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/********************************************************************************
+* E R R O R   T H R E S H O L D
+*********************************************************************************/
+
+// errorWatchdog counts the entries logged at the Error level or above in a sliding window.
+var errorWatchdog = struct {
+	sync.Mutex
+	n      int
+	within time.Duration
+	// times is a ring of the times of the last n errors, and next is the index of the oldest one.
+	times    []time.Time
+	next     int
+	exitCode int
+	callback func(count int)
+}{exitCode: 1}
+
+// errorWatchdogEnabled is set while a threshold is set, so that the entries are only counted then.
+var errorWatchdogEnabled atomic.Bool
+
+// AbortAfterErrors terminates the process once n messages have been logged at the Error level or above within
+// the sliding window within, e.g. so that a batch job stops when the errors show a systemic failure. A Crit
+// summary is logged, and the process exits like Fatal: the exit hooks are run, the output is flushed, and
+// ExitFunc is called with the exit code set using SetAbortExitCode, 1 by default. Use OnErrorThreshold to do
+// something else than exiting. A n of 0 or less removes the threshold.
+func AbortAfterErrors(n int, within time.Duration) {
+	errorWatchdog.Lock()
+	defer errorWatchdog.Unlock()
+	errorWatchdog.n = n
+	errorWatchdog.within = within
+	errorWatchdog.times = nil
+	errorWatchdog.next = 0
+	errorWatchdogEnabled.Store(n > 0)
+}
+
+// SetAbortExitCode sets the exit code of the process when it is terminated by AbortAfterErrors.
+func SetAbortExitCode(code int) {
+	errorWatchdog.Lock()
+	defer errorWatchdog.Unlock()
+	errorWatchdog.exitCode = code
+}
+
+// OnErrorThreshold makes the threshold set using AbortAfterErrors call fn, with the number of errors in the
+// window, instead of terminating the process, e.g. to start a graceful shutdown. The errors are counted again
+// from zero after fn is called, so that it is only called again if the threshold is reached again. A nil fn
+// restores the exit.
+func OnErrorThreshold(fn func(count int)) {
+	errorWatchdog.Lock()
+	defer errorWatchdog.Unlock()
+	errorWatchdog.callback = fn
+}
+
+// countError counts e towards the threshold set using AbortAfterErrors, if it is logged at the Error level or
+// above, and terminates the process or calls the callback if the threshold is reached.
+func countError(e *Entry) {
+	if !errorWatchdogEnabled.Load() || !IsAtLeast(e.Level, LogLevelError) {
+		return
+	}
+	w := &errorWatchdog
+	w.Lock()
+	if w.n <= 0 {
+		w.Unlock()
+		return
+	}
+	if len(w.times) < w.n {
+		w.times = append(w.times, e.Time)
+	} else {
+		w.times[w.next] = e.Time
+		w.next = (w.next + 1) % w.n
+	}
+	// the oldest of the last n errors is within the window
+	oldest := w.times[w.next%len(w.times)]
+	if len(w.times) < w.n || e.Time.Sub(oldest) > w.within {
+		w.Unlock()
+		return
+	}
+	n, within, code, callback := w.n, w.within, w.exitCode, w.callback
+	w.times, w.next = nil, 0
+	w.Unlock()
+
+	if callback != nil {
+		callback(n)
+		return
+	}
+	msg := sprintf("aborting: %d errors were logged within %s", n, within)
+	defaultCloggerForLevel(LogLevelCrit).Print(msg)
+	fatalExit(code, msg)
+}
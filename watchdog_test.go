@@ -0,0 +1,67 @@
+package clog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// useErrorThreshold sets the threshold of AbortAfterErrors until tb is done.
+func useErrorThreshold(tb testing.TB, n int, within time.Duration) {
+	AbortAfterErrors(n, within)
+	tb.Cleanup(func() {
+		AbortAfterErrors(0, 0)
+		OnErrorThreshold(nil)
+		SetAbortExitCode(1)
+	})
+}
+
+// TestOnErrorThreshold checks that the callback is called once n errors are logged within the sliding window,
+// that the messages below the Error level are not counted, and that the errors are counted from zero afterwards.
+func TestOnErrorThreshold(t *testing.T) {
+	setupTest(t)
+	codes := stubExit(t)
+	useErrorThreshold(t, 3, time.Minute)
+	var counts []int
+	OnErrorThreshold(func(count int) { counts = append(counts, count) })
+	at := func(d time.Duration) { SetClock(func() time.Time { return testTime.Add(d) }) }
+	CaptureOutput(func() {
+		for _, d := range []time.Duration{0, 40 * time.Second, 80 * time.Second} {
+			at(d)
+			Error("query failed")
+			Warning("retrying")
+		}
+		if len(counts) != 0 {
+			t.Errorf("the errors spread over more than a minute reached the threshold")
+		}
+		at(100 * time.Second)
+		Crit("query failed")
+		at(101 * time.Second)
+		Error("query failed")
+		Error("query failed")
+	})
+	if !reflect.DeepEqual(counts, []int{3}) || len(*codes) != 0 {
+		t.Errorf("got callbacks %v and exit codes %v, want a single callback and no exit", counts, *codes)
+	}
+}
+
+// TestAbortAfterErrors checks that the process exits like Fatal, with the exit code set using SetAbortExitCode,
+// after a Crit summary.
+func TestAbortAfterErrors(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	codes := stubExit(t)
+	useErrorThreshold(t, 2, time.Second)
+	SetAbortExitCode(3)
+	lines := captureLines(func() {
+		Error("query failed")
+		Error("query failed")
+	})
+	want := []string{"[ERROR] query failed", "[ERROR] query failed", "[CRIT] aborting: 2 errors were logged within 1s"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+	if !reflect.DeepEqual(*codes, []int{3}) {
+		t.Errorf("got exit codes %v, want [3]", *codes)
+	}
+}
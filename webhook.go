@@ -0,0 +1,239 @@
+package clog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// webhookMaxAttempts is the number of times a batch is posted before it is dropped.
+const webhookMaxAttempts = 4
+
+// webhookBackoff is the time waited after the first failed attempt to post a batch. It doubles after every
+// failed attempt.
+var webhookBackoff = 500 * time.Millisecond
+
+// WebhookEntry is the JSON representation of an entry in the payload posted by a WebhookDestination. The values
+// of Fields are json.RawMessage values, rendered when the message was logged.
+type WebhookEntry struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Logger  string                 `json:"logger"`
+	Message string                 `json:"msg"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// WebhookDestination is a Destination that posts the entries at or above a minimum level to a URL, e.g. a chat
+// webhook, in batches. Entries are accumulated for a batch window, starting with the first entry of the batch,
+// or until the batch is full, and then posted in a single request. By default, the payload is a JSON array of
+// WebhookEntry objects:
+//
+//	[{"time": "2006-01-02T15:04:05Z", "level": "crit", "logger": "Crit", "msg": "...", "fields": {...}}]
+//
+// A failed request is retried with exponential backoff. Entries are dropped if the queue is full or a batch
+// cannot be posted, see Dropped, unless it has a disk spool (see WithDiskSpool).
+type WebhookDestination struct {
+	// PayloadBuilder, if set, builds the body and the content type of the request for a batch of entries,
+	// e.g. to format them for a specific chat service. It must be set before the first entry is written.
+	PayloadBuilder func(entries []WebhookEntry) (body []byte, contentType string, err error)
+
+	url         string
+	minLevel    int
+	batchWindow time.Duration
+	maxBatch    int
+	client      *http.Client
+	queue       chan WebhookEntry
+	dropped     atomic.Uint64
+	done        chan struct{}
+
+	lock   sync.RWMutex
+	closed bool
+
+	// spool stores the entries of the batches that could not be posted, if set (see WithDiskSpool).
+	spool *segmentSpool
+}
+
+// WebhookOption configures a WebhookDestination created with NewWebhookDestination. The SpoolOption returned
+// by WithDiskSpool is a WebhookOption.
+type WebhookOption interface {
+	applyToWebhook(d *WebhookDestination)
+}
+
+// NewWebhookDestination returns a WebhookDestination that posts the entries logged at minLevel or above to
+// url, in batches of at most maxBatch entries accumulated for batchWindow. It must be closed to post the last
+// batch.
+func NewWebhookDestination(url string, minLevel int, batchWindow time.Duration, maxBatch int, opts ...WebhookOption) *WebhookDestination {
+	if maxBatch < 1 {
+		maxBatch = 1
+	}
+	d := &WebhookDestination{
+		url:         url,
+		minLevel:    minLevel,
+		batchWindow: batchWindow,
+		maxBatch:    maxBatch,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		queue:       make(chan WebhookEntry, 10*maxBatch),
+		done:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt.applyToWebhook(d)
+	}
+	go d.run()
+	// the batches spooled before a restart are posted as well
+	go d.replaySpool()
+	return d
+}
+
+// Write queues e to be posted, if it is at or above the minimum level of d. It never blocks: e is dropped if
+// the queue is full.
+func (d *WebhookDestination) Write(e *Entry) error {
+	if !IsAtLeast(e.Level, d.minLevel) {
+		return nil
+	}
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	if d.closed {
+		d.dropped.Add(1)
+		return fmt.Errorf("%s: webhook destination is closed", PACKAGE_NAME)
+	}
+	select {
+	case d.queue <- newWebhookEntry(e):
+	default:
+		// the first drop is reported, and every 1000th after it, to not flood the internal logger
+		if n := d.dropped.Add(1); n%1000 == 1 {
+			internalf(LogLevelError, "webhook destination queue is full, %d entries dropped so far", n)
+		}
+	}
+	return nil
+}
+
+// Dropped returns the number of entries that were dropped, because the queue was full or their batch could not
+// be posted.
+func (d *WebhookDestination) Dropped() uint64 {
+	return d.dropped.Load()
+}
+
+// Close posts the queued entries and stops d. Entries written afterwards are dropped.
+func (d *WebhookDestination) Close() error {
+	d.lock.Lock()
+	if d.closed {
+		d.lock.Unlock()
+		return nil
+	}
+	d.closed = true
+	close(d.queue)
+	d.lock.Unlock()
+	<-d.done
+	return nil
+}
+
+func newWebhookEntry(e *Entry) WebhookEntry {
+	we := WebhookEntry{Time: e.Time, Level: levelName(e.Level), Logger: e.Logger, Message: e.Message}
+	// the values are rendered now, rather than when the batch is posted, so that a value mutated after the log
+	// call, e.g. a slice, is posted as it was at the time of the call
+	if len(e.Fields) > 0 {
+		we.Fields = make(map[string]interface{}, len(e.Fields))
+		for _, f := range e.Fields.ordered() {
+			we.Fields[f.Key] = json.RawMessage(marshalJSONValue(f.Value))
+		}
+	}
+	return we
+}
+
+// run accumulates the queued entries into batches and posts them, until the queue is closed.
+func (d *WebhookDestination) run() {
+	defer close(d.done)
+	var batch []WebhookEntry
+	var window <-chan time.Time
+	for {
+		select {
+		case we, ok := <-d.queue:
+			if !ok {
+				d.post(batch)
+				return
+			}
+			if len(batch) == 0 {
+				window = time.After(d.batchWindow)
+			}
+			batch = append(batch, we)
+			if len(batch) < d.maxBatch {
+				continue
+			}
+		case <-window:
+		}
+		d.post(batch)
+		batch, window = nil, nil
+	}
+}
+
+// post posts batch, retrying with exponential backoff if it fails. If all the attempts fail, the entries are
+// spooled if d has a disk spool, and counted as dropped otherwise. After a successful post, the spooled batches
+// are posted as well.
+func (d *WebhookDestination) post(batch []WebhookEntry) {
+	if len(batch) == 0 {
+		return
+	}
+	err := d.send(batch)
+	var payloadErr webhookPayloadError
+	switch {
+	case err == nil:
+		d.replaySpool()
+	case errors.As(err, &payloadErr):
+		d.dropped.Add(uint64(len(batch)))
+		internalf(LogLevelError, "webhook destination dropped %d entries, as their payload could not be built: %v", len(batch), payloadErr.err)
+	case d.spoolBatch(batch):
+	default:
+		d.dropped.Add(uint64(len(batch)))
+		internalf(LogLevelError, "webhook destination dropped %d entries after %d attempts to post them: %v", len(batch), webhookMaxAttempts, err)
+	}
+}
+
+// webhookPayloadError is returned by send if the payload of the batch could not be built.
+type webhookPayloadError struct {
+	err error
+}
+
+func (e webhookPayloadError) Error() string {
+	return e.err.Error()
+}
+
+// send posts batch, retrying with exponential backoff if it fails, and returns the error of the last attempt.
+func (d *WebhookDestination) send(batch []WebhookEntry) error {
+	build := d.PayloadBuilder
+	if build == nil {
+		build = jsonWebhookPayload
+	}
+	body, contentType, err := build(batch)
+	if err != nil {
+		return webhookPayloadError{err}
+	}
+	var lastErr error
+	backoff := webhookBackoff
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		resp, err := d.client.Post(d.url, contentType, bytes.NewReader(body))
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 300 {
+				return nil
+			}
+			err = fmt.Errorf("status %s", resp.Status)
+		}
+		lastErr = err
+		if attempt < webhookMaxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return lastErr
+}
+
+// jsonWebhookPayload is the default PayloadBuilder, which builds a JSON array of the entries.
+func jsonWebhookPayload(entries []WebhookEntry) ([]byte, string, error) {
+	body, err := json.Marshal(entries)
+	return body, "application/json", err
+}
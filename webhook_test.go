@@ -0,0 +1,41 @@
+package clog
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestWebhookRendersFieldsAtCallTime changes the fields after the message is queued, which must not change what
+// is posted once the batch window ends.
+func TestWebhookRendersFieldsAtCallTime(t *testing.T) {
+	setupTest(t)
+	var lock sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lock.Lock()
+		defer lock.Unlock()
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	d := NewWebhookDestination(server.URL, LogLevelDebug, time.Hour, 10)
+	ids := []int{1, 2}
+	base := GetCloggerByName("Info").WithFields("ids", ids)
+	if err := d.Write(base.newEntry("queued", nil)); err != nil {
+		t.Fatal(err)
+	}
+	ids[0] = 9
+	d.Close()
+
+	lock.Lock()
+	defer lock.Unlock()
+	want := `[{"time":"2024-03-05T14:07:09.123456789Z","level":"info","logger":"Info","msg":"queued","fields":{"ids":[1,2]}}]`
+	if len(bodies) != 1 || bodies[0] != want {
+		t.Errorf("got %q, want [%q]", bodies, want)
+	}
+}
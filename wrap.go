@@ -0,0 +1,74 @@
+package clog
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WrapMessages flag determines whether long messages logged to a terminal are wrapped at word boundaries, with
+// the continuation lines indented to align after the timestamp and the name of the logger. It is ignored if the
+// output is not a terminal. It only applies to the TextFormatter.
+var WrapMessages bool = false
+
+// SetWrapMessages sets the WrapMessages flag. It is safe to call while other goroutines are logging.
+func SetWrapMessages(b bool) {
+	setFlag(&WrapMessages, b)
+}
+
+// WrapWidth is the width, in characters, that messages are wrapped at if WrapMessages is set to true. If it
+// is 0, the width of the terminal is used.
+var WrapWidth int = 0
+
+// minWrapWidth is the minimum width left for the message, below which it is not wrapped at all.
+const minWrapWidth = 20
+
+// defaultTerminalWidth is the width used if the width of the terminal cannot be determined.
+const defaultTerminalWidth = 80
+
+// wrapWidth returns the width that messages should be wrapped at, or 0 if they should not be wrapped.
+func wrapWidth() int {
+	if !getFlag(&WrapMessages) {
+		return 0
+	}
+	colorsLock.RLock()
+	outputIsTerminal := colors.outputIsTerminal
+	colorsLock.RUnlock()
+	if !outputIsTerminal {
+		return 0
+	}
+	if WrapWidth > 0 {
+		return WrapWidth
+	}
+	if f, ok := GetOutput().(*os.File); ok {
+		if w := terminalWidth(f); w > 0 {
+			return w
+		}
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return defaultTerminalWidth
+}
+
+// wrapText splits s into lines at word boundaries, so that each line is at most width characters wide, as
+// measured by VisibleLength. A word that is longer than the width is never broken. Any newline in s is preserved.
+func wrapText(s string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		var line string
+		for _, word := range strings.Fields(paragraph) {
+			switch {
+			case line == "":
+				line = word
+			case VisibleLength(line)+1+VisibleLength(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,39 @@
+package clog
+
+import (
+	"reflect"
+	"testing"
+)
+
+const longMessage = "the replica lagged behind the primary for longer than the configured threshold"
+
+func TestWrapMessages(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetWrapMessages(true)
+	WrapWidth = 40
+	lines := captureLines(func() {
+		setOutputIsTerminal(true)
+		Info(longMessage)
+	})
+	want := []string{
+		"[INFO] the replica lagged behind the",
+		"       primary for longer than the",
+		"       configured threshold",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+}
+
+// TestWrapMessagesNotTerminal checks that the messages are not wrapped if the output is not a terminal, e.g. a
+// file read by a log shipper.
+func TestWrapMessagesNotTerminal(t *testing.T) {
+	setupTest(t)
+	SetPrependTimestamp(false)
+	SetWrapMessages(true)
+	WrapWidth = 40
+	if lines := captureLines(func() { Info(longMessage) }); len(lines) != 1 {
+		t.Errorf("got %q, want a single line", lines)
+	}
+}